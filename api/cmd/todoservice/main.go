@@ -2,21 +2,61 @@ package main
 
 import (
 	"context"
+	_ "embed"
 	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 
+	"overengineeredtodo/internal/attachment"
+	"overengineeredtodo/internal/blob"
+	"overengineeredtodo/internal/caldav"
+	"overengineeredtodo/internal/comment"
 	"overengineeredtodo/internal/config"
 	"overengineeredtodo/internal/database"
+	"overengineeredtodo/internal/feed"
+	"overengineeredtodo/internal/importer"
+	"overengineeredtodo/internal/notification"
+	"overengineeredtodo/internal/outbox"
+	"overengineeredtodo/internal/project"
+	"overengineeredtodo/internal/smartlist"
+	"overengineeredtodo/internal/stats"
+	"overengineeredtodo/internal/stream"
+	"overengineeredtodo/internal/template"
+	"overengineeredtodo/internal/timeentry"
 	"overengineeredtodo/internal/todo"
+	"overengineeredtodo/internal/userclient"
+	"overengineeredtodo/internal/webhook"
+	"overengineeredtodo/pkg/grpcserver"
 	"overengineeredtodo/pkg/httpserver"
+	"overengineeredtodo/pkg/openapi"
 )
 
 const serviceName = "todo-service"
 
+// specDocument is the OpenAPI document of the todo API.
+//
+//go:embed openapi.yaml
+var specDocument []byte
+
+// sweepInterval is how often the blobs of deleted attachments and todos are removed.
+const sweepInterval = 5 * time.Minute
+
+// webhookInterval is how often due webhook deliveries are sent.
+const webhookInterval = 5 * time.Second
+
+// webhookTimeout bounds a single webhook delivery.
+const webhookTimeout = 10 * time.Second
+
+// streamInterval is how often todo changes are read for the streaming endpoints.
+const streamInterval = time.Second
+
 func main() {
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 
@@ -29,6 +69,30 @@ func main() {
 		os.Exit(1)
 	}
 
+	blobCfg, err := config.BlobFromEnv()
+	if err != nil {
+		logger.Error("failed to load config", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	outboxCfg, err := config.OutboxFromEnv()
+	if err != nil {
+		logger.Error("failed to load config", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	spec, err := openapi.Load(specDocument)
+	if err != nil {
+		logger.Error("failed to load openapi document", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
+	store, err := newBlobStore(blobCfg)
+	if err != nil {
+		logger.Error("failed to open blob store", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
 	pool, err := database.NewPool(ctx, cfg.DatabaseURL)
 	if err != nil {
 		logger.Error("failed to connect to database", slog.String("error", err.Error()))
@@ -42,20 +106,160 @@ func main() {
 
 	engine := gin.New()
 	engine.Use(gin.Recovery())
+	if cfg.ValidateRequests {
+		engine.Use(spec.ValidateRequests())
+	}
 
 	repo := todo.NewRepository(pool)
-	v1 := engine.Group("/v1")
-	todo.RegisterRoutes(v1.Group("/todos"), repo)
+	projects := project.NewRepository(pool)
+	imports := importer.NewRepository(pool)
+	runner := importer.NewRunner(imports, repo, projects, logger)
+	runner.Recover(ctx)
+	attachments := attachment.NewRepository(pool)
+	sweeper := attachment.NewSweeper(attachments, store, sweepInterval, logger)
+	sweeper.Start(ctx)
+	timeEntries := timeentry.NewRepository(pool)
 
-	engine.GET("/healthz", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok", "service": serviceName})
+	webhooks := webhook.NewRepository(pool)
+	dispatcher := webhook.NewDispatcher(webhooks, webhook.NewClient(webhookTimeout), webhookInterval, logger)
+	dispatcher.Start(ctx)
+
+	users := userclient.New(config.UserServiceURL(), nil)
+
+	// Events always reach the webhooks and the todos of deleted users, and the configured
+	// sink as well.
+	sinks := outbox.Sinks{webhook.NewSink(webhooks), todo.NewUserSink(repo, users)}
+	if outboxCfg.Sink != "" {
+		sink, err := newOutboxSink(outboxCfg)
+		if err != nil {
+			logger.Error("failed to open outbox sink", slog.String("error", err.Error()))
+			os.Exit(1)
+		}
+		sinks = append(sinks, sink)
+	}
+	relay := outbox.NewRelay(outbox.NewRepository(pool), sinks, outboxCfg.Interval, logger)
+	relay.Start(ctx)
+
+	hub := stream.NewHub(outbox.NewRepository(pool), stream.NewRepository(pool), streamInterval, logger)
+	hub.Start(ctx)
+
+	registerRoutes(engine, spec, services{
+		pool:        pool,
+		todos:       repo,
+		users:       users,
+		hub:         hub,
+		attachments: attachments,
+		store:       store,
+		limits:      attachment.Limits{MaxBytes: blobCfg.MaxBytes, QuotaBytes: blobCfg.QuotaBytes},
+		timeEntries: timeEntries,
+		webhooks:    webhooks,
+		dispatcher:  dispatcher,
+		projects:    projects,
+		imports:     imports,
+		runner:      runner,
+		logger:      logger,
 	})
 
+	// The gRPC API is served on its own port and stops together with the HTTP server.
+	grpcServer := grpcserver.New()
+	todo.RegisterGRPC(grpcServer, repo, users, logger)
+	grpcDone := make(chan struct{})
+	go func() {
+		defer close(grpcDone)
+		logger.Info("starting grpc server", slog.String("service", serviceName), slog.String("port", cfg.GRPCPort))
+		if err := grpcServer.Run(ctx, cfg.GRPCPort, cfg.ShutdownTimeout); err != nil {
+			logger.Error("grpc server exited with error", slog.String("error", err.Error()))
+			stop()
+		}
+	}()
+
 	logger.Info("starting http server", slog.String("service", serviceName), slog.String("port", cfg.Port))
 	if err := httpserver.Run(ctx, engine, cfg.Port, cfg.ShutdownTimeout); err != nil {
 		logger.Error("server exited with error", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
+	<-grpcDone
+
+	// Let background imports finish so that their jobs do not stay "running" forever.
+	runner.Wait()
+	sweeper.Wait()
+	relay.Wait()
+	dispatcher.Wait()
+	hub.Wait()
 
 	logger.Info("shutdown complete", slog.String("service", serviceName))
 }
+
+type pgxPool interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// services are what the HTTP routes are served from.
+type services struct {
+	pool        pgxPool
+	todos       *todo.Repository
+	users       *userclient.Client
+	hub         *stream.Hub
+	attachments *attachment.Repository
+	store       blob.Store
+	limits      attachment.Limits
+	timeEntries *timeentry.Repository
+	webhooks    *webhook.Repository
+	dispatcher  *webhook.Dispatcher
+	projects    *project.Repository
+	imports     *importer.Repository
+	runner      *importer.Runner
+	logger      *slog.Logger
+}
+
+// registerRoutes wires the HTTP API, its OpenAPI document and the health probe.
+func registerRoutes(engine *gin.Engine, spec *openapi.Spec, s services) {
+	v1 := engine.Group("/v1")
+	todo.RegisterRoutes(v1.Group("/todos"), s.todos, s.users, s.logger)
+	stream.RegisterRoutes(v1.Group("/todos"), s.hub)
+	attachment.RegisterRoutes(v1.Group("/todos/:id/attachments"), s.attachments, s.todos, s.store, s.limits)
+	comment.RegisterRoutes(v1.Group("/todos/:id/comments"), comment.NewRepository(s.pool), s.todos, s.users)
+	timeentry.RegisterTodoRoutes(v1.Group("/todos/:id/time-entries"), s.timeEntries, s.todos)
+	timeentry.RegisterRoutes(v1.Group("/time-entries"), s.timeEntries, s.todos)
+	webhook.RegisterRoutes(v1.Group("/webhooks"), s.webhooks, s.dispatcher)
+	notification.RegisterRoutes(v1.Group("/notifications"), notification.NewRepository(s.pool))
+	project.RegisterRoutes(v1.Group("/projects"), s.projects)
+	importer.RegisterRoutes(v1.Group("/imports"), s.runner, s.imports)
+	smartlist.RegisterRoutes(v1.Group("/smart-lists"), smartlist.NewRepository(s.pool), s.todos)
+	template.RegisterRoutes(v1.Group("/templates"), template.NewRepository(s.pool), s.todos, s.projects)
+	stats.RegisterRoutes(v1.Group("/users"), stats.NewRepository(s.pool))
+	feed.RegisterRoutes(v1.Group("/feeds"), feed.NewRepository(s.pool), s.todos)
+	caldav.RegisterRoutes(engine.Group("/caldav"), s.todos)
+
+	openapi.RegisterRoutes(engine, spec)
+	engine.GET("/healthz", func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "ok", "service": serviceName})
+	})
+}
+
+func newBlobStore(cfg config.Blob) (blob.Store, error) {
+	if cfg.Store == "s3" {
+		return blob.NewS3(blob.S3Config{
+			Endpoint:        cfg.S3Endpoint,
+			Bucket:          cfg.S3Bucket,
+			Region:          cfg.S3Region,
+			AccessKeyID:     cfg.S3AccessKeyID,
+			SecretAccessKey: cfg.S3SecretAccessKey,
+		})
+	}
+	return blob.NewLocal(cfg.Dir)
+}
+
+func newOutboxSink(cfg config.Outbox) (outbox.Sink, error) {
+	switch cfg.Sink {
+	case "http":
+		return outbox.NewHTTPSink(cfg.URL, &http.Client{Timeout: 10 * time.Second}), nil
+	case "nats":
+		return outbox.NewNATSSink(cfg.URL, cfg.Subject)
+	default:
+		return outbox.NewWriterSink(os.Stdout), nil
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+
+	"overengineeredtodo/internal/customfield"
+	"overengineeredtodo/internal/outbox"
+	"overengineeredtodo/internal/todo"
+	"overengineeredtodo/internal/todo/todotest"
+	"overengineeredtodo/internal/user"
+	"overengineeredtodo/internal/userclient"
+	"overengineeredtodo/pkg/openapi"
+)
+
+func TestRoutesMatchSpec(t *testing.T) {
+	spec, err := openapi.Load(specDocument)
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	registerRoutes(engine, spec, services{})
+
+	undocumented, unserved := spec.Compare(engine.Routes(), "/v1/todos", "/v1/users")
+	require.Empty(t, undocumented, "routes missing from openapi.yaml")
+	require.Empty(t, unserved, "operations in openapi.yaml without a route")
+}
+
+func TestResponsesMatchSpec(t *testing.T) {
+	spec, err := openapi.Load(specDocument)
+	require.NoError(t, err)
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	userService := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(user.User{ID: userID, Name: "Alice", Email: "alice@example.com"})
+	}))
+	defer userService.Close()
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(spec.ValidateResponses(func(err error) { t.Error(err) }), spec.ValidateRequests())
+	registerRoutes(engine, spec, services{
+		pool:   mock,
+		todos:  todo.NewRepository(mock),
+		users:  userclient.New(userService.URL, nil),
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+
+	now := time.Now().UTC()
+	created := todo.Todo{
+		ID: uuid.New(), UserID: userID, Title: "Title", Status: "todo", Labels: []string{"home"},
+		CustomFields: customfield.Values{}, CreatedAt: now, UpdatedAt: now,
+	}
+	args := make([]any, 20)
+	for i := range args {
+		args[i] = pgxmock.AnyArg()
+	}
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO todos").
+		WithArgs(args...).
+		WillReturnRows(todotest.Rows(created))
+	mock.ExpectExec("INSERT INTO outbox_events").
+		WithArgs(pgxmock.AnyArg(), outbox.TodoCreated, outbox.SchemaVersion, outbox.AggregateTodo, created.ID, int64(0), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery("FROM todos").WithArgs(userID).WillReturnRows(todotest.Rows())
+	mock.ExpectQuery("SELECT .* FROM todos WHERE id = \\$1").WithArgs(created.ID, userID).WillReturnError(pgx.ErrNoRows)
+
+	for _, tc := range []struct {
+		method, target, body string
+		status               int
+	}{
+		{method: http.MethodPost, target: "/v1/todos", body: `{"user_id": "` + userID.String() + `", "title": "Title", "labels": ["home"]}`, status: http.StatusCreated},
+		{method: http.MethodPost, target: "/v1/todos", body: `{"user_id": "` + userID.String() + `", "priority": 7}`, status: http.StatusBadRequest},
+		{method: http.MethodGet, target: "/v1/todos?user_id=" + userID.String(), status: http.StatusOK},
+		{method: http.MethodGet, target: "/v1/todos?user_id=" + userID.String() + "&order=up", status: http.StatusBadRequest},
+		{method: http.MethodGet, target: "/v1/todos/" + created.ID.String() + "?user_id=" + userID.String(), status: http.StatusNotFound},
+		{method: http.MethodGet, target: "/v1/todos/nope?user_id=" + userID.String(), status: http.StatusBadRequest},
+		{method: http.MethodGet, target: "/v1/users/nope/stats", status: http.StatusBadRequest},
+	} {
+		req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		require.Equal(t, tc.status, rec.Code, tc.method+" "+tc.target+": "+rec.Body.String())
+	}
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	_ "embed"
 	"log/slog"
 	"os"
 	"os/signal"
@@ -12,11 +13,18 @@ import (
 	"overengineeredtodo/internal/config"
 	"overengineeredtodo/internal/database"
 	"overengineeredtodo/internal/user"
+	"overengineeredtodo/pkg/grpcserver"
 	"overengineeredtodo/pkg/httpserver"
+	"overengineeredtodo/pkg/openapi"
 )
 
 const serviceName = "user-service"
 
+// specDocument is the OpenAPI document of the HTTP API.
+//
+//go:embed openapi.yaml
+var specDocument []byte
+
 func main() {
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 
@@ -29,6 +37,12 @@ func main() {
 		os.Exit(1)
 	}
 
+	spec, err := openapi.Load(specDocument)
+	if err != nil {
+		logger.Error("failed to load openapi document", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+
 	pool, err := database.NewPool(ctx, cfg.DatabaseURL)
 	if err != nil {
 		logger.Error("failed to connect to database", slog.String("error", err.Error()))
@@ -42,20 +56,43 @@ func main() {
 
 	engine := gin.New()
 	engine.Use(gin.Recovery())
+	if cfg.ValidateRequests {
+		engine.Use(spec.ValidateRequests())
+	}
 
 	repo := user.NewRepository(pool)
-	v1 := engine.Group("/v1")
-	user.RegisterRoutes(v1.Group("/users"), repo)
+	registerRoutes(engine, spec, repo)
 
-	engine.GET("/healthz", func(c *gin.Context) {
-		c.JSON(200, gin.H{"status": "ok", "service": serviceName})
-	})
+	// The gRPC API is served on its own port and stops together with the HTTP server.
+	grpcServer := grpcserver.New()
+	user.RegisterGRPC(grpcServer, repo)
+	grpcDone := make(chan struct{})
+	go func() {
+		defer close(grpcDone)
+		logger.Info("starting grpc server", slog.String("service", serviceName), slog.String("port", cfg.GRPCPort))
+		if err := grpcServer.Run(ctx, cfg.GRPCPort, cfg.ShutdownTimeout); err != nil {
+			logger.Error("grpc server exited with error", slog.String("error", err.Error()))
+			stop()
+		}
+	}()
 
 	logger.Info("starting http server", slog.String("service", serviceName), slog.String("port", cfg.Port))
 	if err := httpserver.Run(ctx, engine, cfg.Port, cfg.ShutdownTimeout); err != nil {
 		logger.Error("server exited with error", slog.String("error", err.Error()))
 		os.Exit(1)
 	}
+	<-grpcDone
 
 	logger.Info("shutdown complete", slog.String("service", serviceName))
 }
+
+// registerRoutes wires the HTTP API, its OpenAPI document and the health probe.
+func registerRoutes(engine *gin.Engine, spec *openapi.Spec, repo *user.Repository) {
+	v1 := engine.Group("/v1")
+	user.RegisterRoutes(v1.Group("/users"), repo)
+
+	openapi.RegisterRoutes(engine, spec)
+	engine.GET("/healthz", func(c *gin.Context) {
+		c.JSON(200, gin.H{"status": "ok", "service": serviceName})
+	})
+}
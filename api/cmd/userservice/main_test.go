@@ -0,0 +1,85 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+
+	"overengineeredtodo/internal/user"
+	"overengineeredtodo/pkg/openapi"
+)
+
+func TestRoutesMatchSpec(t *testing.T) {
+	spec, err := openapi.Load(specDocument)
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	registerRoutes(engine, spec, nil)
+
+	undocumented, unserved := spec.Compare(engine.Routes(), "/v1")
+	require.Empty(t, undocumented, "routes missing from openapi.yaml")
+	require.Empty(t, unserved, "operations in openapi.yaml without a route")
+}
+
+func TestResponsesMatchSpec(t *testing.T) {
+	spec, err := openapi.Load(specDocument)
+	require.NoError(t, err)
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(spec.ValidateResponses(func(err error) { t.Error(err) }), spec.ValidateRequests())
+	registerRoutes(engine, spec, user.NewRepository(mock))
+
+	alice := user.User{ID: uuid.New(), Name: "Alice", Email: "alice@example.com", CreatedAt: time.Now()}
+	columns := []string{"id", "name", "email", "created_at"}
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO users").
+		WithArgs(pgxmock.AnyArg(), alice.Name, alice.Email).
+		WillReturnRows(pgxmock.NewRows(columns).AddRow(alice.ID, alice.Name, alice.Email, alice.CreatedAt))
+	mock.ExpectExec("INSERT INTO outbox_events").
+		WithArgs(pgxmock.AnyArg(), "UserCreated", 1, "user", alice.ID, int64(1), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery("SELECT id, name, email, created_at FROM users").
+		WithArgs(100).
+		WillReturnRows(pgxmock.NewRows(columns))
+	mock.ExpectQuery("SELECT id, name, email, created_at FROM users").
+		WithArgs(alice.ID).
+		WillReturnError(pgx.ErrNoRows)
+	mock.ExpectQuery("SELECT .* FROM users WHERE lower\\(email\\) = ANY\\(\\$1\\)").
+		WithArgs(pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows(append(columns, "lower", "lower")).
+			AddRow(alice.ID, alice.Name, alice.Email, alice.CreatedAt, "alice@example.com", "alice"))
+
+	for _, tc := range []struct {
+		method, target, body string
+		status               int
+	}{
+		{method: http.MethodPost, target: "/v1/users", body: `{"name": "Alice", "email": "alice@example.com"}`, status: http.StatusCreated},
+		{method: http.MethodPost, target: "/v1/users", body: `{"name": "Alice", "email": "alice"}`, status: http.StatusBadRequest},
+		{method: http.MethodGet, target: "/v1/users", status: http.StatusOK},
+		{method: http.MethodGet, target: "/v1/users/" + alice.ID.String(), status: http.StatusNotFound},
+		{method: http.MethodGet, target: "/v1/users/nope", status: http.StatusBadRequest},
+		{method: http.MethodGet, target: "/v1/users/lookup?handle=Alice@example.com", status: http.StatusOK},
+		{method: http.MethodGet, target: "/v1/users/lookup", status: http.StatusBadRequest},
+	} {
+		req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		engine.ServeHTTP(rec, req)
+		require.Equal(t, tc.status, rec.Code, tc.method+" "+tc.target+": "+rec.Body.String())
+	}
+	require.NoError(t, mock.ExpectationsWereMet())
+}
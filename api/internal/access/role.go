@@ -0,0 +1,34 @@
+package access
+
+import "errors"
+
+// Role is a member's role in a shared project.
+type Role string
+
+// Roles, from least to most privileged.
+const (
+	// RoleNone means the user cannot see the resource at all.
+	RoleNone Role = ""
+	// RoleViewer may read the project's todos and comment on them.
+	RoleViewer Role = "viewer"
+	// RoleEditor may also create, change and delete the project's todos.
+	RoleEditor Role = "editor"
+	// RoleOwner may also manage the project, its settings and its members.
+	RoleOwner Role = "owner"
+)
+
+// ErrInvalidRole indicates a role outside viewer, editor and owner.
+var ErrInvalidRole = errors.New("role must be viewer, editor or owner")
+
+var ranks = map[Role]int{RoleViewer: 1, RoleEditor: 2, RoleOwner: 3}
+
+// Valid reports whether r is one of the member roles.
+func (r Role) Valid() bool {
+	_, ok := ranks[r]
+	return ok
+}
+
+// Allows reports whether r grants at least the required role.
+func (r Role) Allows(required Role) bool {
+	return ranks[r] >= ranks[required] && ranks[r] > 0
+}
@@ -0,0 +1,17 @@
+package access
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoleAllows(t *testing.T) {
+	require.True(t, RoleOwner.Allows(RoleEditor))
+	require.True(t, RoleEditor.Allows(RoleEditor))
+	require.False(t, RoleViewer.Allows(RoleEditor))
+	require.True(t, RoleViewer.Allows(RoleViewer))
+	require.False(t, RoleNone.Allows(RoleViewer))
+	require.False(t, RoleNone.Allows(RoleNone))
+	require.False(t, Role("admin").Valid())
+}
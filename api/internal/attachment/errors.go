@@ -0,0 +1,10 @@
+package attachment
+
+import "errors"
+
+var (
+	// ErrNotFound indicates the requested attachment could not be located.
+	ErrNotFound = errors.New("attachment not found")
+	// ErrQuotaExceeded indicates the upload would take the user over their quota.
+	ErrQuotaExceeded = errors.New("attachment quota exceeded")
+)
@@ -0,0 +1,250 @@
+package attachment
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"overengineeredtodo/internal/access"
+	"overengineeredtodo/internal/blob"
+	"overengineeredtodo/internal/todo"
+)
+
+// multipartOverhead is allowed on top of the file size for the multipart framing.
+const multipartOverhead = 1 << 20
+
+// maxFilenameLength bounds stored filenames, in bytes.
+const maxFilenameLength = 255
+
+// RegisterRoutes wires the attachment HTTP handlers to a sub-router of a todo, whose
+// path holds the todo's :id.
+func RegisterRoutes(router *gin.RouterGroup, repo *Repository, todos *todo.Repository, store blob.Store, limits Limits) {
+	handler := &Handler{repo: repo, todos: todos, store: store, limits: limits}
+
+	router.POST("", handler.uploadAttachment)
+	router.GET("", handler.listAttachments)
+	router.GET("/:attachment_id", handler.downloadAttachment)
+	router.DELETE("/:attachment_id", handler.deleteAttachment)
+}
+
+// Handler exposes HTTP endpoints for todo attachments.
+type Handler struct {
+	repo   *Repository
+	todos  *todo.Repository
+	store  blob.Store
+	limits Limits
+}
+
+// uploadAttachment stores the multipart field "file" as an attachment of the todo. The
+// blob is written before the row, so a rejected or failed insert only has to remove it.
+func (h *Handler) uploadAttachment(c *gin.Context) {
+	t, ok := h.todo(c, access.RoleEditor)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.limits.MaxBytes+multipartOverhead)
+	header, err := c.FormFile("file")
+	var tooLarge *http.MaxBytesError
+	switch {
+	case errors.As(err, &tooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file too large", "max_bytes": h.limits.MaxBytes})
+		return
+	case err != nil:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "multipart field file is required"})
+		return
+	}
+	if header.Size > h.limits.MaxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file too large", "max_bytes": h.limits.MaxBytes})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	a := Attachment{
+		ID:       uuid.New(),
+		TodoID:   t.ID,
+		UserID:   t.UserID,
+		Filename: cleanFilename(header.Filename),
+		Size:     header.Size,
+	}
+	if a.ContentType, err = contentType(a.Filename, file); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.store.Put(ctx, a.Key(), file, a.Size, a.ContentType); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, err := h.repo.Create(ctx, a, h.limits.QuotaBytes)
+	if err != nil {
+		_ = h.store.Delete(ctx, a.Key())
+	}
+	switch {
+	case err == nil:
+		c.JSON(http.StatusCreated, created)
+	case err == ErrQuotaExceeded:
+		c.JSON(http.StatusInsufficientStorage, gin.H{"error": err.Error(), "quota_bytes": h.limits.QuotaBytes})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (h *Handler) listAttachments(c *gin.Context) {
+	t, ok := h.todo(c, access.RoleViewer)
+	if !ok {
+		return
+	}
+
+	attachments, err := h.repo.ListByTodo(c.Request.Context(), t.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, attachments)
+}
+
+// downloadAttachment serves the content, answering conditional and Range requests.
+func (h *Handler) downloadAttachment(c *gin.Context) {
+	a, ok := h.attachment(c, access.RoleViewer)
+	if !ok {
+		return
+	}
+
+	content, err := h.store.Open(c.Request.Context(), a.Key())
+	switch {
+	case err == blob.ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "attachment content not found"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer content.Close()
+
+	c.Header("Content-Type", a.ContentType)
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename}))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("ETag", `"`+a.ID.String()+`"`)
+	http.ServeContent(c.Writer, c.Request, a.Filename, a.CreatedAt, content)
+}
+
+// deleteAttachment detaches the attachment, then removes its blob and row. Should that
+// fail, the Sweeper finishes the job later.
+func (h *Handler) deleteAttachment(c *gin.Context) {
+	a, ok := h.attachment(c, access.RoleEditor)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	detached, err := h.repo.Detach(ctx, a.TodoID, a.ID)
+	switch {
+	case err == nil:
+		_ = remove(ctx, h.repo, h.store, detached)
+		c.Status(http.StatusNoContent)
+	case err == ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "attachment not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// todo loads the todo of the request path, provided the user_id query parameter names
+// someone holding at least the required role on it.
+func (h *Handler) todo(c *gin.Context, required access.Role) (todo.Todo, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return todo.Todo{}, false
+	}
+	userID, err := uuid.Parse(c.Query("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+		return todo.Todo{}, false
+	}
+
+	t, err := h.todos.Authorize(c.Request.Context(), id, userID, required)
+	switch {
+	case err == nil:
+		return t, true
+	case err == todo.ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "todo not found"})
+	case err == todo.ErrForbidden:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+	return todo.Todo{}, false
+}
+
+// attachment loads the attachment of the request path once the todo check passed.
+func (h *Handler) attachment(c *gin.Context, required access.Role) (Attachment, bool) {
+	t, ok := h.todo(c, required)
+	if !ok {
+		return Attachment{}, false
+	}
+	id, err := uuid.Parse(c.Param("attachment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return Attachment{}, false
+	}
+
+	a, err := h.repo.Get(c.Request.Context(), t.ID, id)
+	switch {
+	case err == nil:
+		return a, true
+	case err == ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "attachment not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+	return Attachment{}, false
+}
+
+// cleanFilename drops any directories a client sent along and bounds the length.
+func cleanFilename(name string) string {
+	name = strings.TrimSpace(filepath.Base(strings.ReplaceAll(name, `\`, "/")))
+	if name == "." || name == "/" || name == "" {
+		return "attachment"
+	}
+	for len(name) > maxFilenameLength {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
+
+// contentType derives the type from the file extension, sniffing the first bytes when
+// the extension is unknown. The file is rewound afterwards.
+func contentType(filename string, file io.ReadSeeker) (string, error) {
+	if ct := mime.TypeByExtension(filepath.Ext(filename)); ct != "" {
+		return ct, nil
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", err
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return http.DetectContentType(head[:n]), nil
+}
@@ -0,0 +1,62 @@
+package attachment
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+
+	"overengineeredtodo/internal/blob"
+	"overengineeredtodo/internal/todo"
+	"overengineeredtodo/internal/todo/todotest"
+)
+
+func TestDownloadAttachmentRange(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	store, err := blob.NewLocal(t.TempDir())
+	require.NoError(t, err)
+
+	a := Attachment{ID: uuid.New(), TodoID: uuid.New(), UserID: uuid.New(), Filename: "Übersicht.txt", ContentType: "text/plain; charset=utf-8", Size: 11, CreatedAt: time.Now()}
+	require.NoError(t, store.Put(context.Background(), a.Key(), strings.NewReader("hello world"), a.Size, a.ContentType))
+
+	mock.ExpectQuery("SELECT .* FROM todos WHERE id = \\$1").
+		WithArgs(a.TodoID, a.UserID).
+		WillReturnRows(todotest.OwnRow(a.TodoID, a.UserID))
+	mock.ExpectQuery("SELECT .* FROM attachments WHERE id = \\$1 AND todo_id = \\$2").
+		WithArgs(a.ID, a.TodoID).
+		WillReturnRows(attachmentRow(a))
+
+	engine := gin.New()
+	RegisterRoutes(engine.Group("/todos/:id/attachments"), NewRepository(mock), todo.NewRepository(mock), store, Limits{MaxBytes: 100, QuotaBytes: 1000})
+
+	req := httptest.NewRequest(http.MethodGet, "/todos/"+a.TodoID.String()+"/attachments/"+a.ID.String()+"?user_id="+a.UserID.String(), nil)
+	req.Header.Set("Range", "bytes=6-")
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusPartialContent, rec.Code)
+	require.Equal(t, "world", rec.Body.String())
+	require.Equal(t, "bytes 6-10/11", rec.Header().Get("Content-Range"))
+	require.Equal(t, "text/plain; charset=utf-8", rec.Header().Get("Content-Type"))
+	require.Equal(t, "attachment; filename*=utf-8''%C3%9Cbersicht.txt", rec.Header().Get("Content-Disposition"))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCleanFilename(t *testing.T) {
+	require.Equal(t, "report.pdf", cleanFilename(`C:\Users\me\report.pdf`))
+	require.Equal(t, "passwd", cleanFilename("../../etc/passwd"))
+	require.Equal(t, "attachment", cleanFilename(" "))
+	require.Len(t, cleanFilename(strings.Repeat("ä", 200)), 254)
+}
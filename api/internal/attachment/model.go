@@ -0,0 +1,32 @@
+package attachment
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Attachment is a file uploaded to a todo. The content lives in the blob store under Key.
+type Attachment struct {
+	ID     uuid.UUID `json:"id"`
+	TodoID uuid.UUID `json:"todo_id"`
+	// UserID is the owner of the todo; uploads count against their quota.
+	UserID      uuid.UUID `json:"user_id"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Key is the blob store key of the attachment's content.
+func (a Attachment) Key() string {
+	return "attachments/" + a.UserID.String() + "/" + a.ID.String()
+}
+
+// Limits bounds the size of uploads.
+type Limits struct {
+	// MaxBytes is the largest file that may be uploaded.
+	MaxBytes int64
+	// QuotaBytes is the total size of the attachments a user may keep.
+	QuotaBytes int64
+}
@@ -0,0 +1,155 @@
+package attachment
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const attachmentColumns = `id, todo_id, user_id, filename, content_type, size, created_at`
+
+// Repository provides Cockroach-backed persistence for attachment metadata.
+type Repository struct {
+	pool pgxPool
+}
+
+type pgxPool interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// NewRepository constructs a repository around the supplied pgx pool.
+func NewRepository(pool pgxPool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// Create records an attachment unless it would take the user's attachments past quota
+// bytes, in which case it fails with ErrQuotaExceeded. The check and the insert are a
+// single statement, so concurrent uploads cannot overshoot the quota together.
+func (r *Repository) Create(ctx context.Context, a Attachment, quota int64) (Attachment, error) {
+	query := `
+		INSERT INTO attachments (id, todo_id, user_id, filename, content_type, size)
+		SELECT $1, $2, $3, $4, $5, $6
+		WHERE (SELECT COALESCE(sum(size), 0) FROM attachments WHERE user_id = $3) + $6 <= $7
+		RETURNING ` + attachmentColumns
+
+	created, err := scanAttachment(r.pool.QueryRow(ctx, query, a.ID, a.TodoID, a.UserID, a.Filename, a.ContentType, a.Size, quota))
+	switch {
+	case err == nil:
+		return created, nil
+	case err == pgx.ErrNoRows:
+		return Attachment{}, ErrQuotaExceeded
+	default:
+		return Attachment{}, fmt.Errorf("insert attachment: %w", err)
+	}
+}
+
+// Get fetches an attachment of the todo.
+func (r *Repository) Get(ctx context.Context, todoID, id uuid.UUID) (Attachment, error) {
+	query := `
+		SELECT ` + attachmentColumns + `
+		FROM attachments
+		WHERE id = $1 AND todo_id = $2
+	`
+
+	a, err := scanAttachment(r.pool.QueryRow(ctx, query, id, todoID))
+	switch {
+	case err == nil:
+		return a, nil
+	case err == pgx.ErrNoRows:
+		return Attachment{}, ErrNotFound
+	default:
+		return Attachment{}, fmt.Errorf("select attachment: %w", err)
+	}
+}
+
+// ListByTodo returns the todo's attachments, oldest first.
+func (r *Repository) ListByTodo(ctx context.Context, todoID uuid.UUID) ([]Attachment, error) {
+	query := `
+		SELECT ` + attachmentColumns + `
+		FROM attachments
+		WHERE todo_id = $1
+		ORDER BY created_at ASC, id ASC
+	`
+
+	return r.list(ctx, query, todoID)
+}
+
+// Detach unlinks an attachment from its todo and returns it. Detached attachments are
+// no longer listed and are removed by the Sweeper if deleting them right away fails.
+func (r *Repository) Detach(ctx context.Context, todoID, id uuid.UUID) (Attachment, error) {
+	query := `
+		UPDATE attachments
+		SET todo_id = NULL
+		WHERE id = $1 AND todo_id = $2
+		RETURNING ` + attachmentColumns
+
+	a, err := scanAttachment(r.pool.QueryRow(ctx, query, id, todoID))
+	switch {
+	case err == nil:
+		return a, nil
+	case err == pgx.ErrNoRows:
+		return Attachment{}, ErrNotFound
+	default:
+		return Attachment{}, fmt.Errorf("detach attachment: %w", err)
+	}
+}
+
+// ListDetached returns up to limit attachments whose todo is gone, either because the
+// attachment was deleted or because the todo was.
+func (r *Repository) ListDetached(ctx context.Context, limit int) ([]Attachment, error) {
+	query := `
+		SELECT ` + attachmentColumns + `
+		FROM attachments
+		WHERE todo_id IS NULL
+		ORDER BY created_at ASC
+		LIMIT $1
+	`
+
+	return r.list(ctx, query, limit)
+}
+
+// Delete removes an attachment's row. The blob must be deleted first.
+func (r *Repository) Delete(ctx context.Context, id uuid.UUID) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM attachments WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("delete attachment: %w", err)
+	}
+	return nil
+}
+
+func (r *Repository) list(ctx context.Context, query string, args ...any) ([]Attachment, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query attachments: %w", err)
+	}
+	defer rows.Close()
+
+	result := []Attachment{}
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan attachment: %w", err)
+		}
+		result = append(result, a)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate attachments: %w", rows.Err())
+	}
+
+	return result, nil
+}
+
+func scanAttachment(row pgx.Row) (Attachment, error) {
+	var a Attachment
+	var todoID *uuid.UUID
+	err := row.Scan(&a.ID, &todoID, &a.UserID, &a.Filename, &a.ContentType, &a.Size, &a.CreatedAt)
+	if todoID != nil {
+		a.TodoID = *todoID
+	}
+	return a, err
+}
@@ -0,0 +1,109 @@
+package attachment
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+
+	"overengineeredtodo/internal/blob"
+)
+
+var attachmentRowColumns = []string{"id", "todo_id", "user_id", "filename", "content_type", "size", "created_at"}
+
+func attachmentRow(a Attachment) *pgxmock.Rows {
+	var todoID *uuid.UUID
+	if a.TodoID != uuid.Nil {
+		todoID = &a.TodoID
+	}
+	return pgxmock.NewRows(attachmentRowColumns).AddRow(a.ID, todoID, a.UserID, a.Filename, a.ContentType, a.Size, a.CreatedAt)
+}
+
+func TestRepositoryCreate(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	a := Attachment{ID: uuid.New(), TodoID: uuid.New(), UserID: uuid.New(), Filename: "notes.txt", ContentType: "text/plain", Size: 42}
+
+	mock.ExpectQuery("INSERT INTO attachments .* SELECT .* WHERE \\(SELECT COALESCE\\(sum\\(size\\), 0\\) FROM attachments WHERE user_id = \\$3\\) \\+ \\$6 <= \\$7").
+		WithArgs(a.ID, a.TodoID, a.UserID, "notes.txt", "text/plain", int64(42), int64(1000)).
+		WillReturnRows(attachmentRow(Attachment{ID: a.ID, TodoID: a.TodoID, UserID: a.UserID, Filename: "notes.txt", ContentType: "text/plain", Size: 42, CreatedAt: time.Now()}))
+
+	created, err := repo.Create(context.Background(), a, 1000)
+	require.NoError(t, err)
+	require.Equal(t, a.TodoID, created.TodoID)
+	require.Equal(t, "attachments/"+a.UserID.String()+"/"+a.ID.String(), created.Key())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryCreateQuotaExceeded(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	a := Attachment{ID: uuid.New(), TodoID: uuid.New(), UserID: uuid.New(), Filename: "big.bin", ContentType: "application/octet-stream", Size: 500}
+
+	mock.ExpectQuery("INSERT INTO attachments").
+		WithArgs(a.ID, a.TodoID, a.UserID, a.Filename, a.ContentType, a.Size, int64(100)).
+		WillReturnError(pgx.ErrNoRows)
+
+	_, err = repo.Create(context.Background(), a, 100)
+	require.ErrorIs(t, err, ErrQuotaExceeded)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryDetach(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	todoID, id := uuid.New(), uuid.New()
+
+	mock.ExpectQuery("UPDATE attachments SET todo_id = NULL WHERE id = \\$1 AND todo_id = \\$2").
+		WithArgs(id, todoID).
+		WillReturnError(pgx.ErrNoRows)
+
+	_, err = repo.Detach(context.Background(), todoID, id)
+	require.ErrorIs(t, err, ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSweeperRemovesDetachedBlobs(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	store, err := blob.NewLocal(t.TempDir())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	orphan := Attachment{ID: uuid.New(), UserID: uuid.New(), Filename: "a.txt", ContentType: "text/plain", Size: 3, CreatedAt: time.Now()}
+	require.NoError(t, store.Put(ctx, orphan.Key(), strings.NewReader("abc"), 3, orphan.ContentType))
+
+	mock.ExpectQuery("SELECT .* FROM attachments WHERE todo_id IS NULL").
+		WithArgs(sweepBatch).
+		WillReturnRows(attachmentRow(orphan))
+	mock.ExpectExec("DELETE FROM attachments WHERE id = \\$1").
+		WithArgs(orphan.ID).
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+
+	sweeper := NewSweeper(NewRepository(mock), store, time.Minute, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	removed, err := sweeper.Sweep(ctx)
+	require.NoError(t, err)
+	require.Equal(t, 1, removed)
+
+	_, err = store.Open(ctx, orphan.Key())
+	require.ErrorIs(t, err, blob.ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,88 @@
+package attachment
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"overengineeredtodo/internal/blob"
+)
+
+// sweepBatch is the number of detached attachments removed per query.
+const sweepBatch = 100
+
+// Sweeper deletes the blobs of detached attachments, which are left behind when a todo
+// or its owner is deleted, and then their rows.
+type Sweeper struct {
+	repo     *Repository
+	store    blob.Store
+	interval time.Duration
+	logger   *slog.Logger
+	wg       sync.WaitGroup
+}
+
+// NewSweeper constructs a Sweeper that runs every interval.
+func NewSweeper(repo *Repository, store blob.Store, interval time.Duration, logger *slog.Logger) *Sweeper {
+	return &Sweeper{repo: repo, store: store, interval: interval, logger: logger}
+}
+
+// Start sweeps in the background until ctx is cancelled.
+func (s *Sweeper) Start(ctx context.Context) {
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			if _, err := s.Sweep(ctx); err != nil && ctx.Err() == nil {
+				s.logger.Error("attachment sweep failed", slog.String("error", err.Error()))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Wait blocks until a started sweeper has stopped.
+func (s *Sweeper) Wait() {
+	s.wg.Wait()
+}
+
+// Sweep removes all detached attachments and reports how many were removed. A blob that
+// cannot be deleted keeps its row, so the next sweep tries again.
+func (s *Sweeper) Sweep(ctx context.Context) (int, error) {
+	removed := 0
+	for {
+		detached, err := s.repo.ListDetached(ctx, sweepBatch)
+		if err != nil {
+			return removed, err
+		}
+
+		progress := false
+		for _, a := range detached {
+			if err := remove(ctx, s.repo, s.store, a); err != nil {
+				s.logger.Warn("failed to remove attachment", slog.String("attachment_id", a.ID.String()), slog.String("error", err.Error()))
+				continue
+			}
+			removed++
+			progress = true
+		}
+
+		if len(detached) < sweepBatch || !progress {
+			return removed, nil
+		}
+	}
+}
+
+// remove deletes a detached attachment's blob, then its row.
+func remove(ctx context.Context, repo *Repository, store blob.Store, a Attachment) error {
+	if err := store.Delete(ctx, a.Key()); err != nil {
+		return err
+	}
+	return repo.Delete(ctx, a.ID)
+}
@@ -0,0 +1,51 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocalRoundTrip(t *testing.T) {
+	store, err := NewLocal(t.TempDir())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.NoError(t, store.Put(ctx, "attachments/u1/a1", strings.NewReader("hello world"), 11, "text/plain"))
+
+	r, err := store.Open(ctx, "attachments/u1/a1")
+	require.NoError(t, err)
+	_, err = r.Seek(6, io.SeekStart)
+	require.NoError(t, err)
+	rest, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, "world", string(rest))
+	require.NoError(t, r.Close())
+
+	require.NoError(t, store.Delete(ctx, "attachments/u1/a1"))
+	require.NoError(t, store.Delete(ctx, "attachments/u1/a1"))
+	_, err = store.Open(ctx, "attachments/u1/a1")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestLocalRejectsShortUploads(t *testing.T) {
+	store, err := NewLocal(t.TempDir())
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	require.Error(t, store.Put(ctx, "short", strings.NewReader("abc"), 5, ""))
+	_, err = store.Open(ctx, "short")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestLocalRejectsEscapingKeys(t *testing.T) {
+	store, err := NewLocal(t.TempDir())
+	require.NoError(t, err)
+
+	for _, key := range []string{"", "../etc/passwd", "a/../../b", "/abs", "a//b", "a b"} {
+		require.Error(t, store.Put(context.Background(), key, strings.NewReader("x"), 1, ""), key)
+	}
+}
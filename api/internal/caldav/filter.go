@@ -0,0 +1,99 @@
+package caldav
+
+import (
+	"strings"
+	"time"
+
+	"overengineeredtodo/internal/ical"
+)
+
+// matches evaluates a calendar-query comp-filter against a component (RFC 4791 section 9.7).
+// Only the subset of the filter grammar that task clients use in practice is honoured:
+// nested comp-filters, is-not-defined, prop-filter text-match and time-range on DUE.
+func (f compFilter) matches(c *ical.Component) bool {
+	if !strings.EqualFold(f.Name, c.Name) {
+		return false
+	}
+
+	if f.TimeRange != nil && !f.TimeRange.matches(c) {
+		return false
+	}
+
+	for _, pf := range f.PropFilters {
+		if !pf.matches(c) {
+			return false
+		}
+	}
+
+	for _, cf := range f.CompFilters {
+		children := c.Children(cf.Name)
+		if cf.IsNotDefined != nil {
+			if len(children) > 0 {
+				return false
+			}
+			continue
+		}
+
+		matched := false
+		for _, child := range children {
+			if cf.matches(child) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (f propFilter) matches(c *ical.Component) bool {
+	p, ok := c.Get(f.Name)
+	if f.IsNotDefined != nil {
+		return !ok
+	}
+	if !ok {
+		return false
+	}
+	if f.TextMatch == nil {
+		return true
+	}
+
+	contains := strings.Contains(
+		strings.ToLower(ical.UnescapeText(p.Value)),
+		strings.ToLower(strings.TrimSpace(f.TextMatch.Value)),
+	)
+	if f.TextMatch.NegateCondition == "yes" {
+		return !contains
+	}
+	return contains
+}
+
+// matches checks a VTODO against the range using its DUE date. Todos without a
+// due date overlap every range, as RFC 4791 section 9.9 prescribes.
+func (r timeRange) matches(c *ical.Component) bool {
+	due, ok := c.Get("DUE")
+	if !ok {
+		return true
+	}
+
+	at, err := ical.ParseDateTime(due, time.UTC)
+	if err != nil {
+		return true
+	}
+
+	if r.Start != "" {
+		if start, err := ical.ParseDateTime(ical.Property{Value: r.Start}, time.UTC); err == nil && at.Before(start) {
+			return false
+		}
+	}
+	if r.End != "" {
+		if end, err := ical.ParseDateTime(ical.Property{Value: r.End}, time.UTC); err == nil && !at.Before(end) {
+			return false
+		}
+	}
+
+	return true
+}
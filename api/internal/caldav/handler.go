@@ -0,0 +1,636 @@
+package caldav
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"overengineeredtodo/internal/access"
+	"overengineeredtodo/internal/ical"
+	"overengineeredtodo/internal/todo"
+)
+
+const (
+	methodPropfind = "PROPFIND"
+	methodReport   = "REPORT"
+
+	collectionName  = "todos"
+	syncTokenPrefix = "http://overengineered-todo/ns/sync/"
+	maxBodyBytes    = 1 << 20
+
+	statusOK       = "HTTP/1.1 200 OK"
+	statusNotFound = "HTTP/1.1 404 Not Found"
+
+	// syncLookback is how far before a sync token changes are reported again. Modification
+	// times are taken when a transaction starts, so a change can commit after a sync that
+	// already went past its time.
+	syncLookback = 30 * time.Second
+)
+
+// RegisterRoutes mounts a minimal RFC 4791 server exposing each user's todos as a single
+// VTODO calendar collection:
+//
+//	/<base>/{user_id}/                 principal and calendar home
+//	/<base>/{user_id}/todos/           calendar collection
+//	/<base>/{user_id}/todos/{uid}.ics  calendar object resource
+func RegisterRoutes(router *gin.RouterGroup, todos *todo.Repository) {
+	handler := &Handler{todos: todos, base: strings.TrimSuffix(router.BasePath(), "/")}
+
+	for _, p := range []string{"/:user_id/", "/:user_id/todos/", "/:user_id/todos/:name"} {
+		router.OPTIONS(p, handler.options)
+	}
+
+	router.Handle(methodPropfind, "/:user_id/", handler.propfindHome)
+	router.Handle(methodPropfind, "/:user_id/todos/", handler.propfindCollection)
+	router.Handle(methodReport, "/:user_id/todos/", handler.report)
+	router.Handle(methodPropfind, "/:user_id/todos/:name", handler.propfindObject)
+	router.GET("/:user_id/todos/:name", handler.getObject)
+	router.PUT("/:user_id/todos/:name", handler.putObject)
+	router.DELETE("/:user_id/todos/:name", handler.deleteObject)
+}
+
+// Handler serves the CalDAV endpoints on top of todo.Repository.
+type Handler struct {
+	todos *todo.Repository
+	base  string
+}
+
+func (h *Handler) options(c *gin.Context) {
+	c.Header("DAV", "1, 3, calendar-access")
+	c.Header("Allow", "OPTIONS, GET, PUT, DELETE, PROPFIND, REPORT")
+	c.Status(http.StatusOK)
+}
+
+func (h *Handler) homeHref(userID uuid.UUID) string {
+	return h.base + "/" + userID.String() + "/"
+}
+
+func (h *Handler) collectionHref(userID uuid.UUID) string {
+	return h.homeHref(userID) + collectionName + "/"
+}
+
+func (h *Handler) objectHref(userID uuid.UUID, uid string) string {
+	return h.collectionHref(userID) + url.PathEscape(uid) + ".ics"
+}
+
+// ETag derives the entity tag of a todo from its version.
+func ETag(t todo.Todo) string {
+	return `"` + strconv.Itoa(t.Version) + `"`
+}
+
+func (h *Handler) propfindHome(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	req, ok := decodePropfind(c)
+	if !ok {
+		return
+	}
+
+	ms := newMultistatus()
+	ms.Responses = append(ms.Responses, buildResponse(h.homeHref(userID), h.homeProps(userID), req))
+
+	if depth(c) > 0 {
+		props, err := h.collectionProps(c, userID)
+		if err != nil {
+			c.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+		ms.Responses = append(ms.Responses, buildResponse(h.collectionHref(userID), props, req))
+	}
+
+	writeMultistatus(c, ms)
+}
+
+func (h *Handler) propfindCollection(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	req, ok := decodePropfind(c)
+	if !ok {
+		return
+	}
+
+	props, err := h.collectionProps(c, userID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ms := newMultistatus()
+	ms.Responses = append(ms.Responses, buildResponse(h.collectionHref(userID), props, req))
+
+	if depth(c) > 0 {
+		todos, err := h.todos.ListByUser(c.Request.Context(), userID)
+		if err != nil {
+			c.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+		for _, t := range todos {
+			ms.Responses = append(ms.Responses, buildResponse(h.objectHref(userID, t.UID()), objectProps(t), req))
+		}
+	}
+
+	writeMultistatus(c, ms)
+}
+
+func (h *Handler) propfindObject(c *gin.Context) {
+	userID, t, ok := h.lookupObject(c)
+	if !ok {
+		return
+	}
+
+	req, ok := decodePropfind(c)
+	if !ok {
+		return
+	}
+
+	ms := newMultistatus()
+	ms.Responses = append(ms.Responses, buildResponse(h.objectHref(userID, t.UID()), objectProps(t), req))
+	writeMultistatus(c, ms)
+}
+
+func (h *Handler) report(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+
+	var req reportRequest
+	if _, err := decodeBody(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodyBytes), &req); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	propReq := propfindRequest{Prop: req.Prop}
+
+	switch req.XMLName {
+	case xml.Name{Space: nsCalDAV, Local: "calendar-query"}:
+		h.calendarQuery(c, userID, req, propReq)
+	case xml.Name{Space: nsCalDAV, Local: "calendar-multiget"}:
+		h.calendarMultiget(c, userID, req, propReq)
+	case xml.Name{Space: nsDAV, Local: "sync-collection"}:
+		h.syncCollection(c, userID, req, propReq)
+	default:
+		c.Data(http.StatusForbidden, "application/xml; charset=utf-8",
+			[]byte(errorXML(xml.Name{Space: nsDAV, Local: "supported-report"})))
+	}
+}
+
+func (h *Handler) calendarQuery(c *gin.Context, userID uuid.UUID, req reportRequest, propReq propfindRequest) {
+	todos, err := h.todos.ListByUser(c.Request.Context(), userID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ms := newMultistatus()
+	for _, t := range todos {
+		if req.Filter != nil && !req.Filter.CompFilter.matches(todo.NewCalendar([]todo.Todo{t})) {
+			continue
+		}
+		ms.Responses = append(ms.Responses, buildResponse(h.objectHref(userID, t.UID()), objectProps(t), propReq))
+	}
+
+	writeMultistatus(c, ms)
+}
+
+func (h *Handler) calendarMultiget(c *gin.Context, userID uuid.UUID, req reportRequest, propReq propfindRequest) {
+	ms := newMultistatus()
+	for _, href := range req.Hrefs {
+		uid, ok := h.uidFromHref(userID, href)
+		if !ok {
+			ms.Responses = append(ms.Responses, response{Href: href, Status: statusNotFound})
+			continue
+		}
+
+		t, err := h.todos.FindByUID(c.Request.Context(), userID, uid)
+		switch {
+		case err == todo.ErrNotFound:
+			ms.Responses = append(ms.Responses, response{Href: href, Status: statusNotFound})
+		case err != nil:
+			c.String(http.StatusInternalServerError, err.Error())
+			return
+		default:
+			ms.Responses = append(ms.Responses, buildResponse(href, objectProps(t), propReq))
+		}
+	}
+
+	writeMultistatus(c, ms)
+}
+
+// syncCollection implements RFC 6578. Tokens encode the modification time up to which
+// the client is in sync; deletions are reported from the todo tombstones. Changes within
+// syncLookback before the token are reported again, which clients tell apart by the ETag.
+func (h *Handler) syncCollection(c *gin.Context, userID uuid.UUID, req reportRequest, propReq propfindRequest) {
+	var since time.Time
+	if req.SyncToken != "" {
+		parsed, err := parseSyncToken(req.SyncToken)
+		if err != nil {
+			c.Data(http.StatusForbidden, "application/xml; charset=utf-8",
+				[]byte(errorXML(xml.Name{Space: nsDAV, Local: "valid-sync-token"})))
+			return
+		}
+		since = parsed
+	}
+
+	ctx := c.Request.Context()
+	todos, err := h.todos.ListByUser(ctx, userID)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	// from is where the reported changes start; the token moves on from since.
+	var (
+		from       time.Time
+		tombstones []todo.Tombstone
+	)
+	if !since.IsZero() {
+		from = since.Add(-syncLookback)
+		tombstones, err = h.todos.ListTombstonesSince(ctx, userID, from)
+		if err != nil {
+			c.String(http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	ms := newMultistatus()
+	latest := since
+	live := make(map[string]struct{}, len(todos))
+
+	for _, t := range todos {
+		live[t.UID()] = struct{}{}
+		if t.UpdatedAt.After(latest) {
+			latest = t.UpdatedAt
+		}
+		if !t.UpdatedAt.After(from) {
+			continue
+		}
+		ms.Responses = append(ms.Responses, buildResponse(h.objectHref(userID, t.UID()), objectProps(t), propReq))
+	}
+
+	for _, ts := range tombstones {
+		if ts.DeletedAt.After(latest) {
+			latest = ts.DeletedAt
+		}
+		if _, ok := live[ts.UID]; ok {
+			continue
+		}
+		ms.Responses = append(ms.Responses, response{Href: h.objectHref(userID, ts.UID), Status: statusNotFound})
+	}
+
+	ms.SyncToken = formatSyncToken(latest)
+	writeMultistatus(c, ms)
+}
+
+func (h *Handler) getObject(c *gin.Context) {
+	_, t, ok := h.lookupObject(c)
+	if !ok {
+		return
+	}
+
+	etag := ETag(t)
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", t.UpdatedAt.UTC().Format(http.TimeFormat))
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := ical.Encode(&buf, todo.NewCalendar([]todo.Todo{t})); err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", buf.Bytes())
+}
+
+func (h *Handler) putObject(c *gin.Context) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return
+	}
+	uid, ok := uidFromName(c.Param("name"))
+	if !ok {
+		c.String(http.StatusNotFound, "unknown resource")
+		return
+	}
+
+	cal, err := ical.Decode(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodyBytes))
+	if err != nil {
+		c.Data(http.StatusForbidden, "application/xml; charset=utf-8",
+			[]byte(errorXML(xml.Name{Space: nsCalDAV, Local: "valid-calendar-data"})))
+		return
+	}
+
+	components := cal.Children(ical.ComponentTodo)
+	if cal.Name != ical.ComponentCalendar || len(components) != 1 {
+		c.Data(http.StatusForbidden, "application/xml; charset=utf-8",
+			[]byte(errorXML(xml.Name{Space: nsCalDAV, Local: "supported-calendar-component"})))
+		return
+	}
+
+	item, err := todo.FromVTODO(components[0])
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return
+	}
+	if item.UID != uid {
+		c.String(http.StatusBadRequest, "resource name must match the UID of the VTODO")
+		return
+	}
+
+	ctx := c.Request.Context()
+	existing, err := h.todos.FindByUID(ctx, userID, uid)
+	exists := err == nil
+	if err != nil && err != todo.ErrNotFound {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	if c.GetHeader("If-None-Match") == "*" && exists {
+		c.Status(http.StatusPreconditionFailed)
+		return
+	}
+	match := c.GetHeader("If-Match")
+	if match != "" && (!exists || (match != "*" && match != ETag(existing))) {
+		c.Status(http.StatusPreconditionFailed)
+		return
+	}
+	// The todo may still change before it is written; the update then fails rather than
+	// overwrite the change.
+	var ifVersion *int
+	if match != "" && match != "*" {
+		ifVersion = &existing.Version
+	}
+
+	t, result, err := todo.UpsertICal(ctx, h.todos, userID, item, ifVersion)
+	switch {
+	case err == todo.ErrConflict && ifVersion != nil:
+		c.Status(http.StatusPreconditionFailed)
+		return
+	case errors.Is(err, todo.ErrInvalidTransition), err == todo.ErrConflict:
+		c.String(http.StatusConflict, err.Error())
+		return
+	case err == todo.ErrForbidden:
+		c.String(http.StatusForbidden, err.Error())
+		return
+	case err != nil:
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Header("ETag", ETag(t))
+	if result == todo.UpsertCreated {
+		c.Header("Location", h.objectHref(userID, t.UID()))
+		c.Status(http.StatusCreated)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) deleteObject(c *gin.Context) {
+	userID, t, ok := h.lookupObject(c)
+	if !ok {
+		return
+	}
+
+	match := c.GetHeader("If-Match")
+	if match != "" && match != "*" && match != ETag(t) {
+		c.Status(http.StatusPreconditionFailed)
+		return
+	}
+
+	_, err := h.todos.Authorize(c.Request.Context(), t.ID, userID, access.RoleEditor)
+	switch {
+	case err != nil:
+	case match != "" && match != "*":
+		err = h.todos.DeleteVersion(c.Request.Context(), t.ID, t.Version)
+	default:
+		err = h.todos.Delete(c.Request.Context(), t.ID)
+	}
+	switch {
+	case err == todo.ErrConflict:
+		c.Status(http.StatusPreconditionFailed)
+		return
+	case err == todo.ErrNotFound:
+		c.String(http.StatusNotFound, "todo not found")
+		return
+	case err == todo.ErrForbidden:
+		c.String(http.StatusForbidden, err.Error())
+		return
+	case err != nil:
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// lookupObject resolves the {user_id}/todos/{name} path to a todo, writing an error response on failure.
+func (h *Handler) lookupObject(c *gin.Context) (uuid.UUID, todo.Todo, bool) {
+	userID, ok := userIDParam(c)
+	if !ok {
+		return uuid.Nil, todo.Todo{}, false
+	}
+
+	uid, ok := uidFromName(c.Param("name"))
+	if !ok {
+		c.String(http.StatusNotFound, "unknown resource")
+		return uuid.Nil, todo.Todo{}, false
+	}
+
+	t, err := h.todos.FindByUID(c.Request.Context(), userID, uid)
+	switch {
+	case err == todo.ErrNotFound:
+		c.String(http.StatusNotFound, "todo not found")
+		return uuid.Nil, todo.Todo{}, false
+	case err != nil:
+		c.String(http.StatusInternalServerError, err.Error())
+		return uuid.Nil, todo.Todo{}, false
+	}
+
+	return userID, t, true
+}
+
+func (h *Handler) homeProps(userID uuid.UUID) map[xml.Name]string {
+	home := hrefXML(h.homeHref(userID))
+	return map[xml.Name]string{
+		propResourceType:     "<D:collection/><D:principal/>",
+		propDisplayName:      escape(userID.String()),
+		propCurrentUserPrinc: home,
+		propPrincipalURL:     home,
+		propCalendarHomeSet:  home,
+	}
+}
+
+func (h *Handler) collectionProps(c *gin.Context, userID uuid.UUID) (map[xml.Name]string, error) {
+	latest, err := h.todos.LatestChange(c.Request.Context(), userID)
+	if err != nil {
+		return nil, err
+	}
+
+	token := escape(formatSyncToken(latest))
+	home := hrefXML(h.homeHref(userID))
+
+	return map[xml.Name]string{
+		propResourceType:        "<D:collection/><C:calendar/>",
+		propDisplayName:         "Todos",
+		propCalendarDescription: "Todos managed by the todo service",
+		propSupportedCompSet:    `<C:comp name="VTODO"/>`,
+		propSyncToken:           token,
+		propGetCTag:             token,
+		propGetETag:             escape(`"` + strconv.FormatInt(latest.UnixMicro(), 10) + `"`),
+		propCurrentUserPrinc:    home,
+		propOwner:               home,
+		propSupportedReportSet: "<D:supported-report><D:report><C:calendar-query/></D:report></D:supported-report>" +
+			"<D:supported-report><D:report><C:calendar-multiget/></D:report></D:supported-report>" +
+			"<D:supported-report><D:report><D:sync-collection/></D:report></D:supported-report>",
+		propCurrentUserPrivSet: "<D:privilege><D:read/></D:privilege><D:privilege><D:write/></D:privilege>" +
+			"<D:privilege><D:write-content/></D:privilege><D:privilege><D:bind/></D:privilege>" +
+			"<D:privilege><D:unbind/></D:privilege>",
+	}, nil
+}
+
+func objectProps(t todo.Todo) map[xml.Name]string {
+	var buf bytes.Buffer
+	_ = ical.Encode(&buf, todo.NewCalendar([]todo.Todo{t}))
+
+	return map[xml.Name]string{
+		propResourceType:    "",
+		propGetETag:         escape(ETag(t)),
+		propGetContentType:  "text/calendar; charset=utf-8; component=VTODO",
+		propGetLastModified: t.UpdatedAt.UTC().Format(http.TimeFormat),
+		propCalendarData:    escape(buf.String()),
+	}
+}
+
+// buildResponse answers the requested properties from props; unknown ones are reported with 404.
+// allprop (or an empty request) returns everything except calendar-data.
+func buildResponse(href string, props map[xml.Name]string, req propfindRequest) response {
+	found := propstat{Status: statusOK}
+	missing := propstat{Status: statusNotFound}
+
+	if len(req.Prop) == 0 {
+		names := make([]xml.Name, 0, len(props))
+		for name := range props {
+			if name != propCalendarData {
+				names = append(names, name)
+			}
+		}
+		sort.Slice(names, func(i, j int) bool {
+			return names[i].Space+names[i].Local < names[j].Space+names[j].Local
+		})
+		for _, name := range names {
+			found.Prop.Props = append(found.Prop.Props, property{XMLName: prefixed(name), Inner: props[name]})
+		}
+	} else {
+		for _, name := range req.Prop {
+			if value, ok := props[name]; ok {
+				found.Prop.Props = append(found.Prop.Props, property{XMLName: prefixed(name), Inner: value})
+				continue
+			}
+			missing.Prop.Props = append(missing.Prop.Props, property{XMLName: prefixed(name)})
+		}
+	}
+
+	resp := response{Href: href}
+	if len(found.Prop.Props) > 0 {
+		resp.Propstats = append(resp.Propstats, found)
+	}
+	if len(missing.Prop.Props) > 0 {
+		resp.Propstats = append(resp.Propstats, missing)
+	}
+	return resp
+}
+
+func decodePropfind(c *gin.Context) (propfindRequest, bool) {
+	var req propfindRequest
+	if _, err := decodeBody(http.MaxBytesReader(c.Writer, c.Request.Body, maxBodyBytes), &req); err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+		return propfindRequest{}, false
+	}
+	return req, true
+}
+
+func writeMultistatus(c *gin.Context, ms *multistatus) {
+	out, err := xml.Marshal(ms)
+	if err != nil {
+		c.String(http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.Data(http.StatusMultiStatus, "application/xml; charset=utf-8", append([]byte(xml.Header), out...))
+}
+
+// depth returns 0 or 1; infinity is treated as 1 since collections are never nested.
+func depth(c *gin.Context) int {
+	if c.GetHeader("Depth") == "0" {
+		return 0
+	}
+	return 1
+}
+
+func userIDParam(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		c.String(http.StatusNotFound, "unknown principal")
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+func uidFromName(name string) (string, bool) {
+	uid, ok := strings.CutSuffix(name, ".ics")
+	if !ok || uid == "" {
+		return "", false
+	}
+	return uid, true
+}
+
+func (h *Handler) uidFromHref(userID uuid.UUID, href string) (string, bool) {
+	if u, err := url.Parse(href); err == nil {
+		href = u.Path
+	}
+	dir, name := path.Split(href)
+	if dir != h.collectionHref(userID) {
+		return "", false
+	}
+	name, err := url.PathUnescape(name)
+	if err != nil {
+		return "", false
+	}
+	return uidFromName(name)
+}
+
+func formatSyncToken(t time.Time) string {
+	return syncTokenPrefix + strconv.FormatInt(t.UnixMicro(), 10)
+}
+
+func parseSyncToken(token string) (time.Time, error) {
+	raw, ok := strings.CutPrefix(token, syncTokenPrefix)
+	if !ok {
+		return time.Time{}, fmt.Errorf("unknown sync token %q", token)
+	}
+	micros, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("parse sync token: %w", err)
+	}
+	return time.UnixMicro(micros).UTC(), nil
+}
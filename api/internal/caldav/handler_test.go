@@ -0,0 +1,255 @@
+package caldav
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+
+	"overengineeredtodo/internal/customfield"
+	"overengineeredtodo/internal/todo"
+	"overengineeredtodo/internal/todo/todotest"
+	"overengineeredtodo/internal/workflow"
+)
+
+func newTestServer(t *testing.T) (*gin.Engine, pgxmock.PgxPoolIface) {
+	t.Helper()
+
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	t.Cleanup(mock.Close)
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	RegisterRoutes(engine.Group("/caldav"), todo.NewRepository(mock))
+	return engine, mock
+}
+
+func serve(engine *gin.Engine, method, target, body string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestPropfindCollectionListsTodos(t *testing.T) {
+	engine, mock := newTestServer(t)
+	userID := uuid.New()
+	item := todo.Todo{ID: uuid.New(), UserID: userID, Title: "Write report", CreatedAt: time.Now(), UpdatedAt: time.Now(), Version: 3}
+
+	mock.ExpectQuery("SELECT greatest").
+		WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"greatest"}).AddRow(item.UpdatedAt))
+	mock.ExpectQuery("SELECT .* FROM todos WHERE \\(\\(project_id IS NULL AND user_id = \\$1\\) .* ORDER BY created_at DESC").
+		WithArgs(userID).
+		WillReturnRows(todotest.Rows(item))
+
+	body := `<?xml version="1.0"?><D:propfind xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">` +
+		`<D:prop><D:resourcetype/><D:getetag/><C:supported-calendar-component-set/><D:quota-used-bytes/></D:prop></D:propfind>`
+	rec := serve(engine, methodPropfind, "/caldav/"+userID.String()+"/todos/", body, map[string]string{"Depth": "1"})
+
+	require.Equal(t, http.StatusMultiStatus, rec.Code)
+	out := rec.Body.String()
+	require.Contains(t, out, "<D:href>/caldav/"+userID.String()+"/todos/</D:href>")
+	require.Contains(t, out, "<D:collection/><C:calendar/>")
+	require.Contains(t, out, `<C:comp name="VTODO"/>`)
+	require.Contains(t, out, "<D:href>/caldav/"+userID.String()+"/todos/"+item.ID.String()+".ics</D:href>")
+	require.Contains(t, out, "<D:getetag>&#34;3&#34;</D:getetag>")
+	require.Contains(t, out, statusNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPutCreatesTodo(t *testing.T) {
+	engine, mock := newTestServer(t)
+	userID := uuid.New()
+	uid := "c0ffee@client"
+	created := uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT .* FROM todos WHERE \\(\\(project_id IS NULL AND user_id = \\$1\\)").
+		WithArgs(userID, uid, uuid.Nil).
+		WillReturnError(pgx.ErrNoRows)
+	mock.ExpectQuery("SELECT .* FROM todos WHERE \\(\\(project_id IS NULL AND user_id = \\$1\\)").
+		WithArgs(userID, uid, uuid.Nil).
+		WillReturnError(pgx.ErrNoRows)
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO todos").
+		WithArgs(pgxmock.AnyArg(), userID, "Call plumber", "", (*time.Time)(nil), false, &uid, (*uuid.UUID)(nil), []string{}, 0, (*string)(nil), (*string)(nil), (*time.Time)(nil), workflow.StatusBacklog, customfield.Values{}, (*uuid.UUID)(nil), "", (*uuid.UUID)(nil), (*int)(nil), (*time.Time)(nil)).
+		WillReturnRows(todotest.Rows(todo.Todo{ID: created, UserID: userID, Title: "Call plumber", ICalUID: &uid, CreatedAt: now, UpdatedAt: now, Version: 1}))
+	mock.ExpectExec("INSERT INTO outbox_events").
+		WithArgs(pgxmock.AnyArg(), "TodoCreated", 1, "todo", created, int64(1), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+
+	body := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VTODO\r\nUID:" + uid + "\r\nSUMMARY:Call plumber\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+	rec := serve(engine, http.MethodPut, "/caldav/"+userID.String()+"/todos/"+uid+".ics", body, map[string]string{"If-None-Match": "*"})
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.Equal(t, `"1"`, rec.Header().Get("ETag"))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPutRejectsStaleETag(t *testing.T) {
+	engine, mock := newTestServer(t)
+	userID := uuid.New()
+	existing := todo.Todo{ID: uuid.New(), UserID: userID, Title: "Old", CreatedAt: time.Now(), UpdatedAt: time.Now(), Version: 5}
+
+	mock.ExpectQuery("SELECT .* FROM todos WHERE \\(\\(project_id IS NULL AND user_id = \\$1\\)").
+		WithArgs(userID, existing.ID.String(), existing.ID).
+		WillReturnRows(todotest.Rows(existing))
+
+	body := "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nUID:" + existing.ID.String() + "\r\nSUMMARY:New\r\nSTATUS:COMPLETED\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+	rec := serve(engine, http.MethodPut, "/caldav/"+userID.String()+"/todos/"+existing.ID.String()+".ics", body, map[string]string{"If-Match": `"4"`})
+
+	require.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCalendarQueryFiltersCompleted(t *testing.T) {
+	engine, mock := newTestServer(t)
+	userID := uuid.New()
+	now := time.Now()
+	open := todo.Todo{ID: uuid.New(), UserID: userID, Title: "Open", CreatedAt: now, UpdatedAt: now, Version: 1}
+	done := todo.Todo{ID: uuid.New(), UserID: userID, Title: "Done", Completed: true, CreatedAt: now, UpdatedAt: now, Version: 2}
+
+	mock.ExpectQuery("SELECT .* FROM todos WHERE \\(\\(project_id IS NULL AND user_id = \\$1\\)").
+		WithArgs(userID).
+		WillReturnRows(todotest.Rows(open, done))
+
+	body := `<C:calendar-query xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav">` +
+		`<D:prop><D:getetag/><C:calendar-data/></D:prop>` +
+		`<C:filter><C:comp-filter name="VCALENDAR"><C:comp-filter name="VTODO">` +
+		`<C:prop-filter name="COMPLETED"><C:is-not-defined/></C:prop-filter>` +
+		`</C:comp-filter></C:comp-filter></C:filter></C:calendar-query>`
+	rec := serve(engine, methodReport, "/caldav/"+userID.String()+"/todos/", body, nil)
+
+	require.Equal(t, http.StatusMultiStatus, rec.Code)
+	out := rec.Body.String()
+	require.Contains(t, out, open.ID.String()+".ics")
+	require.NotContains(t, out, done.ID.String()+".ics")
+	require.Contains(t, out, "SUMMARY:Open")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSyncCollectionReportsChangesAndDeletions(t *testing.T) {
+	engine, mock := newTestServer(t)
+	userID := uuid.New()
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	unchanged := todo.Todo{ID: uuid.New(), UserID: userID, Title: "Old", CreatedAt: since, UpdatedAt: since.Add(-time.Hour), Version: 1}
+	changed := todo.Todo{ID: uuid.New(), UserID: userID, Title: "New", CreatedAt: since, UpdatedAt: since.Add(time.Minute), Version: 2}
+	deletedAt := since.Add(2 * time.Minute)
+
+	mock.ExpectQuery("SELECT .* FROM todos WHERE \\(\\(project_id IS NULL AND user_id = \\$1\\)").
+		WithArgs(userID).
+		WillReturnRows(todotest.Rows(unchanged, changed))
+	mock.ExpectQuery("SELECT id, user_id, uid, deleted_at FROM todo_tombstones").
+		WithArgs(userID, since.Add(-syncLookback)).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "uid", "deleted_at"}).AddRow(uuid.New(), userID, "gone", deletedAt))
+
+	body := `<D:sync-collection xmlns:D="DAV:"><D:sync-token>` + formatSyncToken(since) + `</D:sync-token>` +
+		`<D:sync-level>1</D:sync-level><D:prop><D:getetag/></D:prop></D:sync-collection>`
+	rec := serve(engine, methodReport, "/caldav/"+userID.String()+"/todos/", body, nil)
+
+	require.Equal(t, http.StatusMultiStatus, rec.Code)
+	out := rec.Body.String()
+	require.Contains(t, out, changed.ID.String()+".ics")
+	require.NotContains(t, out, unchanged.ID.String()+".ics")
+	require.Contains(t, out, "<D:href>/caldav/"+userID.String()+"/todos/gone.ics</D:href><D:status>"+statusNotFound)
+	require.Contains(t, out, "<D:sync-token>"+formatSyncToken(deletedAt)+"</D:sync-token>")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSyncCollectionReportsLateCommits(t *testing.T) {
+	engine, mock := newTestServer(t)
+	userID := uuid.New()
+	since := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	// The change was made before the last sync but committed after it.
+	late := todo.Todo{ID: uuid.New(), UserID: userID, Title: "Late", CreatedAt: since, UpdatedAt: since.Add(-10 * time.Second), Version: 2}
+
+	mock.ExpectQuery("SELECT .* FROM todos WHERE \\(\\(project_id IS NULL AND user_id = \\$1\\)").
+		WithArgs(userID).
+		WillReturnRows(todotest.Rows(late))
+	mock.ExpectQuery("SELECT id, user_id, uid, deleted_at FROM todo_tombstones").
+		WithArgs(userID, since.Add(-syncLookback)).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "uid", "deleted_at"}))
+
+	body := `<D:sync-collection xmlns:D="DAV:"><D:sync-token>` + formatSyncToken(since) + `</D:sync-token>` +
+		`<D:sync-level>1</D:sync-level><D:prop><D:getetag/></D:prop></D:sync-collection>`
+	rec := serve(engine, methodReport, "/caldav/"+userID.String()+"/todos/", body, nil)
+
+	require.Equal(t, http.StatusMultiStatus, rec.Code)
+	out := rec.Body.String()
+	require.Contains(t, out, late.ID.String()+".ics")
+	require.Contains(t, out, "<D:sync-token>"+formatSyncToken(since)+"</D:sync-token>")
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSyncCollectionRejectsForeignToken(t *testing.T) {
+	engine, _ := newTestServer(t)
+
+	body := `<D:sync-collection xmlns:D="DAV:"><D:sync-token>urn:other:1</D:sync-token><D:prop/></D:sync-collection>`
+	rec := serve(engine, methodReport, "/caldav/"+uuid.NewString()+"/todos/", body, nil)
+
+	require.Equal(t, http.StatusForbidden, rec.Code)
+	require.Contains(t, rec.Body.String(), "valid-sync-token")
+}
+
+func TestPutFailsWhenTodoChangesAfterETagCheck(t *testing.T) {
+	engine, mock := newTestServer(t)
+	userID := uuid.New()
+	existing := todo.Todo{ID: uuid.New(), UserID: userID, Title: "Old", CreatedAt: time.Now(), UpdatedAt: time.Now(), Version: 5}
+
+	for range 2 {
+		mock.ExpectQuery("SELECT .* FROM todos WHERE \\(\\(project_id IS NULL AND user_id = \\$1\\)").
+			WithArgs(userID, existing.ID.String(), existing.ID).
+			WillReturnRows(todotest.Rows(existing))
+	}
+	mock.ExpectQuery("SELECT .*, \\(SELECT m.role FROM project_members m .*\\) FROM todos WHERE id = \\$1").
+		WithArgs(existing.ID, userID).
+		WillReturnRows(todotest.AuthorizeRows(existing, nil))
+	// Another client changed the todo after its ETag was checked.
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE todos SET title = \\$1, .* WHERE id = \\$2 AND version = \\$3").
+		WithArgs("New", existing.ID, 5).
+		WillReturnError(pgx.ErrNoRows)
+	mock.ExpectRollback()
+
+	body := "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nUID:" + existing.ID.String() + "\r\nSUMMARY:New\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+	rec := serve(engine, http.MethodPut, "/caldav/"+userID.String()+"/todos/"+existing.ID.String()+".ics", body, map[string]string{"If-Match": `"5"`})
+
+	require.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteFailsWhenTodoChangesAfterETagCheck(t *testing.T) {
+	engine, mock := newTestServer(t)
+	userID := uuid.New()
+	existing := todo.Todo{ID: uuid.New(), UserID: userID, Title: "Old", CreatedAt: time.Now(), UpdatedAt: time.Now(), Version: 5}
+
+	mock.ExpectQuery("SELECT .* FROM todos WHERE \\(\\(project_id IS NULL AND user_id = \\$1\\)").
+		WithArgs(userID, existing.ID.String(), existing.ID).
+		WillReturnRows(todotest.Rows(existing))
+	mock.ExpectQuery("SELECT .*, \\(SELECT m.role FROM project_members m .*\\) FROM todos WHERE id = \\$1").
+		WithArgs(existing.ID, userID).
+		WillReturnRows(todotest.AuthorizeRows(existing, nil))
+	mock.ExpectBegin()
+	mock.ExpectQuery("DELETE FROM todos WHERE id = \\$1 AND version = \\$2").
+		WithArgs(existing.ID, 5).
+		WillReturnError(pgx.ErrNoRows)
+	mock.ExpectRollback()
+
+	rec := serve(engine, http.MethodDelete, "/caldav/"+userID.String()+"/todos/"+existing.ID.String()+".ics", "", map[string]string{"If-Match": `"5"`})
+
+	require.Equal(t, http.StatusPreconditionFailed, rec.Code)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,179 @@
+package caldav
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+)
+
+const (
+	nsDAV    = "DAV:"
+	nsCalDAV = "urn:ietf:params:xml:ns:caldav"
+	nsCS     = "http://calendarserver.org/ns/"
+)
+
+var (
+	propResourceType        = xml.Name{Space: nsDAV, Local: "resourcetype"}
+	propDisplayName         = xml.Name{Space: nsDAV, Local: "displayname"}
+	propGetETag             = xml.Name{Space: nsDAV, Local: "getetag"}
+	propGetContentType      = xml.Name{Space: nsDAV, Local: "getcontenttype"}
+	propGetLastModified     = xml.Name{Space: nsDAV, Local: "getlastmodified"}
+	propCurrentUserPrinc    = xml.Name{Space: nsDAV, Local: "current-user-principal"}
+	propPrincipalURL        = xml.Name{Space: nsDAV, Local: "principal-URL"}
+	propOwner               = xml.Name{Space: nsDAV, Local: "owner"}
+	propSyncToken           = xml.Name{Space: nsDAV, Local: "sync-token"}
+	propSupportedReportSet  = xml.Name{Space: nsDAV, Local: "supported-report-set"}
+	propCurrentUserPrivSet  = xml.Name{Space: nsDAV, Local: "current-user-privilege-set"}
+	propCalendarHomeSet     = xml.Name{Space: nsCalDAV, Local: "calendar-home-set"}
+	propSupportedCompSet    = xml.Name{Space: nsCalDAV, Local: "supported-calendar-component-set"}
+	propCalendarData        = xml.Name{Space: nsCalDAV, Local: "calendar-data"}
+	propCalendarDescription = xml.Name{Space: nsCalDAV, Local: "calendar-description"}
+	propGetCTag             = xml.Name{Space: nsCS, Local: "getctag"}
+)
+
+// propNames collects the element names listed inside a DAV:prop request element.
+type propNames []xml.Name
+
+func (p *propNames) UnmarshalXML(d *xml.Decoder, _ xml.StartElement) error {
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			*p = append(*p, t.Name)
+			if err := d.Skip(); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			return nil
+		}
+	}
+}
+
+type propfindRequest struct {
+	XMLName xml.Name  `xml:"DAV: propfind"`
+	AllProp *struct{} `xml:"DAV: allprop"`
+	Prop    propNames `xml:"DAV: prop"`
+}
+
+// reportRequest covers calendar-query, calendar-multiget and sync-collection bodies;
+// XMLName tells them apart.
+type reportRequest struct {
+	XMLName   xml.Name
+	Prop      propNames `xml:"DAV: prop"`
+	Hrefs     []string  `xml:"DAV: href"`
+	SyncToken string    `xml:"DAV: sync-token"`
+	Filter    *filter   `xml:"urn:ietf:params:xml:ns:caldav filter"`
+}
+
+type filter struct {
+	CompFilter compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+type compFilter struct {
+	Name         string       `xml:"name,attr"`
+	IsNotDefined *struct{}    `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	TimeRange    *timeRange   `xml:"urn:ietf:params:xml:ns:caldav time-range"`
+	PropFilters  []propFilter `xml:"urn:ietf:params:xml:ns:caldav prop-filter"`
+	CompFilters  []compFilter `xml:"urn:ietf:params:xml:ns:caldav comp-filter"`
+}
+
+type propFilter struct {
+	Name         string     `xml:"name,attr"`
+	IsNotDefined *struct{}  `xml:"urn:ietf:params:xml:ns:caldav is-not-defined"`
+	TextMatch    *textMatch `xml:"urn:ietf:params:xml:ns:caldav text-match"`
+}
+
+type textMatch struct {
+	Value           string `xml:",chardata"`
+	NegateCondition string `xml:"negate-condition,attr"`
+}
+
+type timeRange struct {
+	Start string `xml:"start,attr"`
+	End   string `xml:"end,attr"`
+}
+
+// decodeBody unmarshals an optional XML request body. It reports false for an empty body.
+func decodeBody(r io.Reader, v any) (bool, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return false, err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return false, nil
+	}
+	if err := xml.Unmarshal(data, v); err != nil {
+		return false, errors.New("invalid XML body: " + err.Error())
+	}
+	return true, nil
+}
+
+// multistatus is rendered with fixed namespace prefixes, which keeps the output
+// readable and is what most CalDAV clients expect.
+type multistatus struct {
+	XMLName   xml.Name   `xml:"D:multistatus"`
+	DAV       string     `xml:"xmlns:D,attr"`
+	CalDAV    string     `xml:"xmlns:C,attr"`
+	CS        string     `xml:"xmlns:CS,attr"`
+	Responses []response `xml:"D:response"`
+	SyncToken string     `xml:"D:sync-token,omitempty"`
+}
+
+type response struct {
+	Href      string     `xml:"D:href"`
+	Status    string     `xml:"D:status,omitempty"`
+	Propstats []propstat `xml:"D:propstat,omitempty"`
+}
+
+type propstat struct {
+	Prop   propList `xml:"D:prop"`
+	Status string   `xml:"D:status"`
+}
+
+type propList struct {
+	Props []property
+}
+
+// property is a single rendered property; Inner holds pre-escaped XML.
+type property struct {
+	XMLName xml.Name
+	Inner   string `xml:",innerxml"`
+}
+
+func newMultistatus() *multistatus {
+	return &multistatus{DAV: nsDAV, CalDAV: nsCalDAV, CS: nsCS}
+}
+
+// prefixed maps well-known namespaces onto the prefixes declared on multistatus.
+func prefixed(name xml.Name) xml.Name {
+	switch name.Space {
+	case nsDAV:
+		return xml.Name{Local: "D:" + name.Local}
+	case nsCalDAV:
+		return xml.Name{Local: "C:" + name.Local}
+	case nsCS:
+		return xml.Name{Local: "CS:" + name.Local}
+	default:
+		return name
+	}
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func hrefXML(href string) string {
+	return "<D:href>" + escape(href) + "</D:href>"
+}
+
+func errorXML(name xml.Name) string {
+	n := prefixed(name)
+	return xml.Header + `<D:error xmlns:D="DAV:" xmlns:C="urn:ietf:params:xml:ns:caldav"><` + n.Local + `/></D:error>`
+}
@@ -0,0 +1,12 @@
+package comment
+
+import "errors"
+
+var (
+	// ErrNotFound indicates the requested comment could not be located.
+	ErrNotFound = errors.New("comment not found")
+	// ErrNotAuthor indicates a user tried to change someone else's comment.
+	ErrNotAuthor = errors.New("only the author may change a comment")
+	// ErrUnknownAuthor indicates the author is not a known user.
+	ErrUnknownAuthor = errors.New("unknown author")
+)
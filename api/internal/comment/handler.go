@@ -0,0 +1,263 @@
+package comment
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"overengineeredtodo/internal/access"
+	"overengineeredtodo/internal/todo"
+	"overengineeredtodo/internal/user"
+)
+
+// Resolver looks up the users named by mention handles, emails or names. The result is
+// keyed by the lower-cased handle; handles without a unique user are left out.
+type Resolver interface {
+	Lookup(ctx context.Context, handles []string) (map[string]user.User, error)
+}
+
+// RegisterRoutes wires the comment HTTP handlers to a sub-router of a todo, whose path
+// holds the todo's :id.
+func RegisterRoutes(router *gin.RouterGroup, repo *Repository, todos *todo.Repository, users Resolver) {
+	handler := &Handler{repo: repo, todos: todos, users: users}
+
+	router.POST("", handler.createComment)
+	router.GET("", handler.listComments)
+	router.PUT("/:comment_id", handler.updateComment)
+	router.DELETE("/:comment_id", handler.deleteComment)
+	router.GET("/:comment_id/history", handler.getHistory)
+}
+
+// Handler exposes HTTP endpoints for todo comments.
+type Handler struct {
+	repo  *Repository
+	todos *todo.Repository
+	users Resolver
+}
+
+// createComment adds a comment as input.UserID; viewers of the todo may comment.
+func (h *Handler) createComment(c *gin.Context) {
+	var input CreateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	todoID, ok := h.todoID(c, input.UserID)
+	if !ok {
+		return
+	}
+	body, ok := validBody(c, input.Body)
+	if !ok {
+		return
+	}
+
+	mentions, ok := h.resolveMentions(c, body)
+	if !ok {
+		return
+	}
+
+	comment, err := h.repo.Create(c.Request.Context(), todoID, input.UserID, body, mentions)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusCreated, comment)
+	case err == ErrUnknownAuthor:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (h *Handler) listComments(c *gin.Context) {
+	userID, ok := queryUserID(c)
+	if !ok {
+		return
+	}
+	todoID, ok := h.todoID(c, userID)
+	if !ok {
+		return
+	}
+
+	comments, err := h.repo.ListByTodo(c.Request.Context(), todoID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, comments)
+}
+
+func (h *Handler) updateComment(c *gin.Context) {
+	var input UpdateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	todoID, id, ok := h.commentPath(c, input.UserID)
+	if !ok {
+		return
+	}
+	body, ok := validBody(c, input.Body)
+	if !ok {
+		return
+	}
+
+	mentions, ok := h.resolveMentions(c, body)
+	if !ok {
+		return
+	}
+
+	comment, err := h.repo.Update(c.Request.Context(), todoID, id, input.UserID, body, mentions)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, comment)
+	case err == ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "comment not found"})
+	case err == ErrNotAuthor:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (h *Handler) deleteComment(c *gin.Context) {
+	userID, ok := queryUserID(c)
+	if !ok {
+		return
+	}
+	todoID, id, ok := h.commentPath(c, userID)
+	if !ok {
+		return
+	}
+
+	comment, err := h.repo.Get(c.Request.Context(), todoID, id)
+	if err == nil && comment.AuthorID != userID {
+		err = ErrNotAuthor
+	}
+	if err == nil {
+		err = h.repo.Delete(c.Request.Context(), todoID, id)
+	}
+
+	switch {
+	case err == nil:
+		c.Status(http.StatusNoContent)
+	case err == ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "comment not found"})
+	case err == ErrNotAuthor:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (h *Handler) getHistory(c *gin.Context) {
+	userID, ok := queryUserID(c)
+	if !ok {
+		return
+	}
+	todoID, id, ok := h.commentPath(c, userID)
+	if !ok {
+		return
+	}
+
+	if _, err := h.repo.Get(c.Request.Context(), todoID, id); err != nil {
+		if err == ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "comment not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	revisions, err := h.repo.History(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+// todoID parses the todo of the request path and checks that the user may see it. Any
+// role on the todo's project is enough to read and write comments.
+func (h *Handler) todoID(c *gin.Context, userID uuid.UUID) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return uuid.Nil, false
+	}
+
+	_, err = h.todos.Authorize(c.Request.Context(), id, userID, access.RoleViewer)
+	switch {
+	case err == nil:
+		return id, true
+	case err == todo.ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "todo not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+	return uuid.Nil, false
+}
+
+// resolveMentions looks up the users mentioned in the body. Handles that do not name a
+// user are ignored; a failing user service fails the request rather than silently
+// dropping notifications.
+func (h *Handler) resolveMentions(c *gin.Context, body string) ([]uuid.UUID, bool) {
+	handles := Mentions(body)
+	if len(handles) == 0 {
+		return nil, true
+	}
+
+	users, err := h.users.Lookup(c.Request.Context(), handles)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	var mentions []uuid.UUID
+	for _, handle := range handles {
+		if u, ok := users[handle]; ok && !slices.Contains(mentions, u.ID) {
+			mentions = append(mentions, u.ID)
+		}
+	}
+	return mentions, true
+}
+
+func (h *Handler) commentPath(c *gin.Context, userID uuid.UUID) (uuid.UUID, uuid.UUID, bool) {
+	todoID, ok := h.todoID(c, userID)
+	if !ok {
+		return uuid.Nil, uuid.Nil, false
+	}
+	id, err := uuid.Parse(c.Param("comment_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return todoID, id, true
+}
+
+func queryUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.Query("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
+
+func validBody(c *gin.Context, body string) (string, bool) {
+	body = strings.TrimSpace(body)
+	switch {
+	case body == "":
+		c.JSON(http.StatusBadRequest, gin.H{"error": "body is required"})
+		return "", false
+	case utf8.RuneCountInString(body) > MaxBodyLength:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "body is too long"})
+		return "", false
+	}
+	return body, true
+}
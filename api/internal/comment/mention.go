@@ -0,0 +1,29 @@
+package comment
+
+import (
+	"regexp"
+	"strings"
+)
+
+// mentionPattern matches "@alice@example.com" and "@alice". The @ must not follow a word
+// character, so plain email addresses in the text are not mentions.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_@.])@([\w.+-]+@[\w-]+(?:\.[\w-]+)+|[\p{L}\p{N}_][\p{L}\p{N}_.-]*)`)
+
+// Mentions returns the distinct handles mentioned in a comment body, lower-cased and in
+// order of appearance.
+func Mentions(body string) []string {
+	var handles []string
+	seen := make(map[string]bool)
+	for _, m := range mentionPattern.FindAllStringSubmatch(body, -1) {
+		handle := strings.ToLower(m[1])
+		if !strings.Contains(handle, "@") {
+			// Sentence punctuation right after a name is not part of it.
+			handle = strings.TrimRight(handle, ".-")
+		}
+		if handle != "" && !seen[handle] {
+			seen[handle] = true
+			handles = append(handles, handle)
+		}
+	}
+	return handles
+}
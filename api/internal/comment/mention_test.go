@@ -0,0 +1,25 @@
+package comment
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMentions(t *testing.T) {
+	cases := []struct {
+		body string
+		want []string
+	}{
+		{"@alice can you look at this?", []string{"alice"}},
+		{"cc @Bob.Smith, @alice@example.com.", []string{"bob.smith", "alice@example.com"}},
+		{"Thanks @jürgen!", []string{"jürgen"}},
+		{"@alice and again @ALICE", []string{"alice"}},
+		{"mail me at carol@example.com", nil},
+		{"(@dave) and @erin.", []string{"dave", "erin"}},
+		{"no mentions @ all", nil},
+	}
+	for _, tc := range cases {
+		require.Equal(t, tc.want, Mentions(tc.body), tc.body)
+	}
+}
@@ -0,0 +1,43 @@
+package comment
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxBodyLength bounds the length of a comment, in characters.
+const MaxBodyLength = 10000
+
+// Comment is a message on a todo.
+type Comment struct {
+	ID       uuid.UUID `json:"id"`
+	TodoID   uuid.UUID `json:"todo_id"`
+	AuthorID uuid.UUID `json:"author_id"`
+	Body     string    `json:"body"`
+	// Mentions holds the users mentioned in the body.
+	Mentions  []uuid.UUID `json:"mentions"`
+	CreatedAt time.Time   `json:"created_at"`
+	UpdatedAt time.Time   `json:"updated_at"`
+	// EditedAt is set once the body has been changed.
+	EditedAt *time.Time `json:"edited_at,omitempty"`
+}
+
+// Revision is an earlier body of an edited comment.
+type Revision struct {
+	Body string `json:"body"`
+	// ReplacedAt is when the edit replaced this body.
+	ReplacedAt time.Time `json:"replaced_at"`
+}
+
+// CreateInput holds the payload required to create a comment.
+type CreateInput struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+	Body   string    `json:"body" binding:"required"`
+}
+
+// UpdateInput replaces the body of a comment. Only its author may edit it.
+type UpdateInput struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+	Body   string    `json:"body" binding:"required"`
+}
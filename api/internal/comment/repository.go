@@ -0,0 +1,254 @@
+package comment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"overengineeredtodo/internal/notification"
+)
+
+const commentColumns = `id, todo_id, author_id, body, mentions, created_at, updated_at, edited_at`
+
+// foreignKeyViolation is the SQLSTATE reported for references to missing rows.
+const foreignKeyViolation = "23503"
+
+// Repository provides Cockroach-backed persistence for comments and their history.
+type Repository struct {
+	pool pgxPool
+}
+
+type pgxPool interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// NewRepository constructs a repository around the supplied pgx pool.
+func NewRepository(pool pgxPool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// Create inserts a comment and notifies the mentioned users, other than the author, in
+// the same transaction.
+func (r *Repository) Create(ctx context.Context, todoID, authorID uuid.UUID, body string, mentions []uuid.UUID) (Comment, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return Comment{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	query := `
+		INSERT INTO comments (id, todo_id, author_id, body, mentions)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + commentColumns
+
+	c, err := scanComment(tx.QueryRow(ctx, query, uuid.New(), todoID, authorID, body, nonNil(mentions)))
+	if err != nil {
+		if isForeignKeyViolation(err) {
+			return Comment{}, ErrUnknownAuthor
+		}
+		return Comment{}, fmt.Errorf("insert comment: %w", err)
+	}
+
+	if err := notification.Insert(ctx, tx, mentionNotifications(c, mentions)...); err != nil {
+		return Comment{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Comment{}, fmt.Errorf("commit comment: %w", err)
+	}
+	return c, nil
+}
+
+// Get fetches a comment of the todo.
+func (r *Repository) Get(ctx context.Context, todoID, id uuid.UUID) (Comment, error) {
+	query := `
+		SELECT ` + commentColumns + `
+		FROM comments
+		WHERE id = $1 AND todo_id = $2
+	`
+
+	c, err := scanComment(r.pool.QueryRow(ctx, query, id, todoID))
+	switch {
+	case err == nil:
+		return c, nil
+	case err == pgx.ErrNoRows:
+		return Comment{}, ErrNotFound
+	default:
+		return Comment{}, fmt.Errorf("select comment: %w", err)
+	}
+}
+
+// ListByTodo returns the todo's comments in chronological order.
+func (r *Repository) ListByTodo(ctx context.Context, todoID uuid.UUID) ([]Comment, error) {
+	query := `
+		SELECT ` + commentColumns + `
+		FROM comments
+		WHERE todo_id = $1
+		ORDER BY created_at ASC, id ASC
+	`
+
+	rows, err := r.pool.Query(ctx, query, todoID)
+	if err != nil {
+		return nil, fmt.Errorf("query comments: %w", err)
+	}
+	defer rows.Close()
+
+	result := []Comment{}
+	for rows.Next() {
+		c, err := scanComment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan comment: %w", err)
+		}
+		result = append(result, c)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate comments: %w", rows.Err())
+	}
+
+	return result, nil
+}
+
+// Update replaces the body of the author's comment, keeping the previous body as a
+// revision. Users mentioned for the first time are notified.
+func (r *Repository) Update(ctx context.Context, todoID, id, authorID uuid.UUID, body string, mentions []uuid.UUID) (Comment, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return Comment{}, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	current, err := scanComment(tx.QueryRow(ctx, `
+		SELECT `+commentColumns+`
+		FROM comments
+		WHERE id = $1 AND todo_id = $2
+		FOR UPDATE`, id, todoID))
+	switch {
+	case err == pgx.ErrNoRows:
+		return Comment{}, ErrNotFound
+	case err != nil:
+		return Comment{}, fmt.Errorf("select comment: %w", err)
+	case current.AuthorID != authorID:
+		return Comment{}, ErrNotAuthor
+	case current.Body == body:
+		return current, nil
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO comment_revisions (id, comment_id, body, replaced_at)
+		VALUES ($1, $2, $3, now())`, uuid.New(), id, current.Body); err != nil {
+		return Comment{}, fmt.Errorf("insert comment revision: %w", err)
+	}
+
+	updated, err := scanComment(tx.QueryRow(ctx, `
+		UPDATE comments
+		SET body = $1, mentions = $2, updated_at = now(), edited_at = now()
+		WHERE id = $3
+		RETURNING `+commentColumns, body, nonNil(mentions), id))
+	if err != nil {
+		return Comment{}, fmt.Errorf("update comment: %w", err)
+	}
+
+	var added []uuid.UUID
+	for _, m := range mentions {
+		if !slices.Contains(current.Mentions, m) {
+			added = append(added, m)
+		}
+	}
+	if err := notification.Insert(ctx, tx, mentionNotifications(updated, added)...); err != nil {
+		return Comment{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Comment{}, fmt.Errorf("commit comment: %w", err)
+	}
+	return updated, nil
+}
+
+// Delete removes a comment together with its history.
+func (r *Repository) Delete(ctx context.Context, todoID, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM comments WHERE id = $1 AND todo_id = $2`, id, todoID)
+	if err != nil {
+		return fmt.Errorf("delete comment: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// History returns the earlier bodies of a comment, oldest first.
+func (r *Repository) History(ctx context.Context, id uuid.UUID) ([]Revision, error) {
+	query := `
+		SELECT body, replaced_at
+		FROM comment_revisions
+		WHERE comment_id = $1
+		ORDER BY replaced_at ASC, id ASC
+	`
+
+	rows, err := r.pool.Query(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("query comment revisions: %w", err)
+	}
+	defer rows.Close()
+
+	result := []Revision{}
+	for rows.Next() {
+		var rev Revision
+		if err := rows.Scan(&rev.Body, &rev.ReplacedAt); err != nil {
+			return nil, fmt.Errorf("scan comment revision: %w", err)
+		}
+		result = append(result, rev)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate comment revisions: %w", rows.Err())
+	}
+
+	return result, nil
+}
+
+// mentionNotifications builds a notification for each mentioned user but the author.
+func mentionNotifications(c Comment, users []uuid.UUID) []notification.Notification {
+	var result []notification.Notification
+	for _, userID := range users {
+		if userID == c.AuthorID {
+			continue
+		}
+		result = append(result, notification.Notification{
+			UserID:    userID,
+			Kind:      notification.KindMention,
+			TodoID:    c.TodoID,
+			CommentID: &c.ID,
+			ActorID:   c.AuthorID,
+		})
+	}
+	return result
+}
+
+func scanComment(row pgx.Row) (Comment, error) {
+	var c Comment
+	err := row.Scan(&c.ID, &c.TodoID, &c.AuthorID, &c.Body, &c.Mentions, &c.CreatedAt, &c.UpdatedAt, &c.EditedAt)
+	c.Mentions = nonNil(c.Mentions)
+	return c, err
+}
+
+func nonNil(ids []uuid.UUID) []uuid.UUID {
+	if ids == nil {
+		return []uuid.UUID{}
+	}
+	return ids
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation
+}
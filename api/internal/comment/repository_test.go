@@ -0,0 +1,141 @@
+package comment
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+
+	"overengineeredtodo/internal/notification"
+)
+
+var commentRowColumns = []string{"id", "todo_id", "author_id", "body", "mentions", "created_at", "updated_at", "edited_at"}
+
+func commentRow(c Comment) *pgxmock.Rows {
+	return pgxmock.NewRows(commentRowColumns).AddRow(c.ID, c.TodoID, c.AuthorID, c.Body, c.Mentions, c.CreatedAt, c.UpdatedAt, c.EditedAt)
+}
+
+func TestRepositoryCreateNotifiesMentions(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	todoID, authorID, bob := uuid.New(), uuid.New(), uuid.New()
+	now := time.Now()
+	created := Comment{ID: uuid.New(), TodoID: todoID, AuthorID: authorID, Body: "@bob and @me", Mentions: []uuid.UUID{bob, authorID}, CreatedAt: now, UpdatedAt: now}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO comments").
+		WithArgs(pgxmock.AnyArg(), todoID, authorID, "@bob and @me", []uuid.UUID{bob, authorID}).
+		WillReturnRows(commentRow(created))
+	mock.ExpectExec("INSERT INTO notifications").
+		WithArgs(pgxmock.AnyArg(), bob, notification.KindMention, todoID, &created.ID, authorID).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	c, err := repo.Create(context.Background(), todoID, authorID, "@bob and @me", []uuid.UUID{bob, authorID})
+	require.NoError(t, err)
+	require.Equal(t, created.ID, c.ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryCreateUnknownAuthor(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	todoID, authorID := uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO comments").
+		WithArgs(pgxmock.AnyArg(), todoID, authorID, "hi", []uuid.UUID{}).
+		WillReturnError(&pgconn.PgError{Code: foreignKeyViolation})
+	mock.ExpectRollback()
+
+	_, err = repo.Create(context.Background(), todoID, authorID, "hi", nil)
+	require.ErrorIs(t, err, ErrUnknownAuthor)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryUpdateKeepsHistory(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	todoID, authorID, bob, carol := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	now := time.Now()
+	current := Comment{ID: uuid.New(), TodoID: todoID, AuthorID: authorID, Body: "ping @bob", Mentions: []uuid.UUID{bob}, CreatedAt: now, UpdatedAt: now}
+	updated := current
+	updated.Body = "ping @bob and @carol"
+	updated.Mentions = []uuid.UUID{bob, carol}
+	updated.EditedAt = &now
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .* FROM comments WHERE id = \\$1 AND todo_id = \\$2 FOR UPDATE").
+		WithArgs(current.ID, todoID).
+		WillReturnRows(commentRow(current))
+	mock.ExpectExec("INSERT INTO comment_revisions").
+		WithArgs(pgxmock.AnyArg(), current.ID, "ping @bob").
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectQuery("UPDATE comments SET body = \\$1, mentions = \\$2, updated_at = now\\(\\), edited_at = now\\(\\)").
+		WithArgs(updated.Body, updated.Mentions, current.ID).
+		WillReturnRows(commentRow(updated))
+	mock.ExpectExec("INSERT INTO notifications").
+		WithArgs(pgxmock.AnyArg(), carol, notification.KindMention, todoID, &current.ID, authorID).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+	mock.ExpectRollback()
+
+	c, err := repo.Update(context.Background(), todoID, current.ID, authorID, updated.Body, updated.Mentions)
+	require.NoError(t, err)
+	require.Equal(t, updated.Body, c.Body)
+	require.NotNil(t, c.EditedAt)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryUpdateByOtherUser(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	now := time.Now()
+	current := Comment{ID: uuid.New(), TodoID: uuid.New(), AuthorID: uuid.New(), Body: "mine", Mentions: []uuid.UUID{}, CreatedAt: now, UpdatedAt: now}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .* FROM comments").
+		WithArgs(current.ID, current.TodoID).
+		WillReturnRows(commentRow(current))
+	mock.ExpectRollback()
+
+	_, err = repo.Update(context.Background(), current.TodoID, current.ID, uuid.New(), "yours now", nil)
+	require.ErrorIs(t, err, ErrNotAuthor)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryHistory(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	id := uuid.New()
+	first := time.Now().Add(-time.Hour)
+
+	mock.ExpectQuery("SELECT body, replaced_at FROM comment_revisions WHERE comment_id = \\$1").
+		WithArgs(id).
+		WillReturnRows(pgxmock.NewRows([]string{"body", "replaced_at"}).AddRow("first draft", first))
+
+	revisions, err := repo.History(context.Background(), id)
+	require.NoError(t, err)
+	require.Equal(t, []Revision{{Body: "first draft", ReplacedAt: first}}, revisions)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
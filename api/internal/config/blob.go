@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"os"
+)
+
+// Blob holds the blob store configuration of the todo service.
+type Blob struct {
+	// Store is "local" or "s3".
+	Store             string
+	Dir               string
+	S3Endpoint        string
+	S3Bucket          string
+	S3Region          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	// MaxBytes and QuotaBytes bound single attachments and the attachments of a user.
+	MaxBytes   int64
+	QuotaBytes int64
+}
+
+const (
+	defaultBlobDir              = "data/blobs"
+	defaultAttachmentMaxBytes   = 25 << 20
+	defaultAttachmentQuotaBytes = 1 << 30
+)
+
+// BlobFromEnv loads the blob store configuration.
+// Recognised variables:
+//   - BLOB_STORE: "local" (default) or "s3"
+//   - BLOB_DIR: directory of the local store (defaults to data/blobs)
+//   - S3_ENDPOINT, S3_BUCKET, S3_REGION, S3_ACCESS_KEY_ID, S3_SECRET_ACCESS_KEY: the
+//     S3-compatible service; all required for the s3 store
+//   - ATTACHMENT_MAX_BYTES: largest attachment (defaults to 25 MiB)
+//   - ATTACHMENT_QUOTA_BYTES: total attachment size per user (defaults to 1 GiB)
+func BlobFromEnv() (Blob, error) {
+	cfg := Blob{
+		Store:             valueOrDefault("BLOB_STORE", "local"),
+		Dir:               valueOrDefault("BLOB_DIR", defaultBlobDir),
+		S3Endpoint:        os.Getenv("S3_ENDPOINT"),
+		S3Bucket:          os.Getenv("S3_BUCKET"),
+		S3Region:          valueOrDefault("S3_REGION", "us-east-1"),
+		S3AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+		S3SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+		MaxBytes:          int64(parseIntWithDefault("ATTACHMENT_MAX_BYTES", defaultAttachmentMaxBytes)),
+		QuotaBytes:        int64(parseIntWithDefault("ATTACHMENT_QUOTA_BYTES", defaultAttachmentQuotaBytes)),
+	}
+
+	switch cfg.Store {
+	case "local":
+	case "s3":
+		if cfg.S3Endpoint == "" || cfg.S3Bucket == "" || cfg.S3AccessKeyID == "" || cfg.S3SecretAccessKey == "" {
+			return Blob{}, fmt.Errorf("S3_ENDPOINT, S3_BUCKET, S3_ACCESS_KEY_ID and S3_SECRET_ACCESS_KEY are required for BLOB_STORE=s3")
+		}
+	default:
+		return Blob{}, fmt.Errorf("unknown BLOB_STORE %q", cfg.Store)
+	}
+	return cfg, nil
+}
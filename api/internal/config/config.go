@@ -11,20 +11,27 @@ import (
 type Config struct {
 	ServiceName     string
 	Port            string
+	GRPCPort        string
 	DatabaseURL     string
 	ShutdownTimeout time.Duration
+	// ValidateRequests rejects requests that do not match the service's OpenAPI document.
+	ValidateRequests bool
 }
 
 const (
 	defaultPort            = "8080"
+	defaultGRPCPort        = "9090"
 	defaultShutdownSeconds = 10
+	defaultUserServiceURL  = "http://localhost:8080"
 )
 
 // FromEnv loads service configuration using conventional environment variables.
 // Recognised variables:
 //   - PORT: TCP port for the HTTP listener (defaults to 8080)
+//   - GRPC_PORT: TCP port for the gRPC listener (defaults to 9090)
 //   - DATABASE_URL: PostgreSQL-compatible connection string (required)
 //   - SHUTDOWN_TIMEOUT_SECONDS: graceful shutdown timeout (defaults to 10 seconds)
+//   - OPENAPI_VALIDATE: validate requests against the OpenAPI document (defaults to false)
 func FromEnv(serviceName string) (Config, error) {
 	port := valueOrDefault("PORT", defaultPort)
 	connString := os.Getenv("DATABASE_URL")
@@ -35,13 +42,21 @@ func FromEnv(serviceName string) (Config, error) {
 	timeoutSeconds := parseIntWithDefault("SHUTDOWN_TIMEOUT_SECONDS", defaultShutdownSeconds)
 
 	return Config{
-		ServiceName:     serviceName,
-		Port:            port,
-		DatabaseURL:     connString,
-		ShutdownTimeout: time.Duration(timeoutSeconds) * time.Second,
+		ServiceName:      serviceName,
+		Port:             port,
+		GRPCPort:         valueOrDefault("GRPC_PORT", defaultGRPCPort),
+		DatabaseURL:      connString,
+		ShutdownTimeout:  time.Duration(timeoutSeconds) * time.Second,
+		ValidateRequests: parseBoolWithDefault("OPENAPI_VALIDATE", false),
 	}, nil
 }
 
+// UserServiceURL returns the base URL of the user service from USER_SERVICE_URL
+// (defaults to http://localhost:8080).
+func UserServiceURL() string {
+	return valueOrDefault("USER_SERVICE_URL", defaultUserServiceURL)
+}
+
 func valueOrDefault(key, fallback string) string {
 	if val := os.Getenv(key); val != "" {
 		return val
@@ -57,3 +72,12 @@ func parseIntWithDefault(key string, fallback int) int {
 	}
 	return fallback
 }
+
+func parseBoolWithDefault(key string, fallback bool) bool {
+	if val := os.Getenv(key); val != "" {
+		if parsed, err := strconv.ParseBool(val); err == nil {
+			return parsed
+		}
+	}
+	return fallback
+}
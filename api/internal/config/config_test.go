@@ -13,20 +13,26 @@ func TestFromEnvDefaults(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, "userservice", cfg.ServiceName)
 	require.Equal(t, "8080", cfg.Port)
+	require.Equal(t, "9090", cfg.GRPCPort)
 	require.Equal(t, 10*time.Second, cfg.ShutdownTimeout)
+	require.False(t, cfg.ValidateRequests)
 	require.Equal(t, "postgres://root@localhost:26257/todoapp?sslmode=disable", cfg.DatabaseURL)
 }
 
 func TestFromEnvOverrides(t *testing.T) {
 	t.Setenv("DATABASE_URL", "postgres://root@localhost:26257/todoapp?sslmode=verify-full")
 	t.Setenv("PORT", "9090")
+	t.Setenv("GRPC_PORT", "9091")
 	t.Setenv("SHUTDOWN_TIMEOUT_SECONDS", "30")
+	t.Setenv("OPENAPI_VALIDATE", "true")
 
 	cfg, err := FromEnv("todoservice")
 	require.NoError(t, err)
 	require.Equal(t, "todoservice", cfg.ServiceName)
 	require.Equal(t, "9090", cfg.Port)
+	require.Equal(t, "9091", cfg.GRPCPort)
 	require.Equal(t, 30*time.Second, cfg.ShutdownTimeout)
+	require.True(t, cfg.ValidateRequests)
 	require.Equal(t, "postgres://root@localhost:26257/todoapp?sslmode=verify-full", cfg.DatabaseURL)
 }
 
@@ -34,3 +40,49 @@ func TestFromEnvMissingDatabaseURL(t *testing.T) {
 	_, err := FromEnv("userservice")
 	require.Error(t, err)
 }
+
+func TestBlobFromEnvDefaults(t *testing.T) {
+	cfg, err := BlobFromEnv()
+	require.NoError(t, err)
+	require.Equal(t, "local", cfg.Store)
+	require.Equal(t, "data/blobs", cfg.Dir)
+	require.EqualValues(t, 25<<20, cfg.MaxBytes)
+	require.EqualValues(t, 1<<30, cfg.QuotaBytes)
+}
+
+func TestBlobFromEnvS3RequiresCredentials(t *testing.T) {
+	t.Setenv("BLOB_STORE", "s3")
+	t.Setenv("S3_ENDPOINT", "http://localhost:9000")
+	t.Setenv("S3_BUCKET", "attachments")
+	_, err := BlobFromEnv()
+	require.Error(t, err)
+
+	t.Setenv("S3_ACCESS_KEY_ID", "minio")
+	t.Setenv("S3_SECRET_ACCESS_KEY", "minio123")
+	cfg, err := BlobFromEnv()
+	require.NoError(t, err)
+	require.Equal(t, "us-east-1", cfg.S3Region)
+}
+
+func TestOutboxFromEnvDefaults(t *testing.T) {
+	cfg, err := OutboxFromEnv()
+	require.NoError(t, err)
+	require.Empty(t, cfg.Sink)
+	require.Equal(t, "todoapp", cfg.Subject)
+	require.Equal(t, 5*time.Second, cfg.Interval)
+}
+
+func TestOutboxFromEnvRequiresURL(t *testing.T) {
+	t.Setenv("OUTBOX_SINK", "nats")
+	_, err := OutboxFromEnv()
+	require.Error(t, err)
+
+	t.Setenv("OUTBOX_URL", "nats://localhost:4222")
+	cfg, err := OutboxFromEnv()
+	require.NoError(t, err)
+	require.Equal(t, "nats", cfg.Sink)
+
+	t.Setenv("OUTBOX_SINK", "kafka")
+	_, err = OutboxFromEnv()
+	require.Error(t, err)
+}
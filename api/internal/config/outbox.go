@@ -0,0 +1,50 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+// Outbox holds the configuration of the relay that publishes domain events.
+type Outbox struct {
+	// Sink is "stdout", "http" or "nats"; empty only hands events to webhooks.
+	Sink     string
+	URL      string
+	Subject  string
+	Interval time.Duration
+}
+
+const (
+	defaultOutboxSubject         = "todoapp"
+	defaultOutboxIntervalSeconds = 5
+)
+
+// OutboxFromEnv loads the outbox relay configuration.
+// Recognised variables:
+//   - OUTBOX_SINK: "stdout", "http" or "nats" (unset only feeds webhooks)
+//   - OUTBOX_URL: endpoint of the http sink or nats:// address of the nats sink
+//   - OUTBOX_SUBJECT: prefix of the NATS subjects (defaults to todoapp)
+//   - OUTBOX_INTERVAL_SECONDS: how often the relay polls (defaults to 5 seconds)
+func OutboxFromEnv() (Outbox, error) {
+	cfg := Outbox{
+		Sink:     os.Getenv("OUTBOX_SINK"),
+		URL:      os.Getenv("OUTBOX_URL"),
+		Subject:  valueOrDefault("OUTBOX_SUBJECT", defaultOutboxSubject),
+		Interval: time.Duration(parseIntWithDefault("OUTBOX_INTERVAL_SECONDS", defaultOutboxIntervalSeconds)) * time.Second,
+	}
+
+	switch cfg.Sink {
+	case "", "stdout":
+	case "http", "nats":
+		if cfg.URL == "" {
+			return Outbox{}, fmt.Errorf("OUTBOX_URL is required for OUTBOX_SINK=%s", cfg.Sink)
+		}
+	default:
+		return Outbox{}, fmt.Errorf("unknown OUTBOX_SINK %q", cfg.Sink)
+	}
+	if cfg.Interval <= 0 {
+		return Outbox{}, fmt.Errorf("OUTBOX_INTERVAL_SECONDS must be positive")
+	}
+	return cfg, nil
+}
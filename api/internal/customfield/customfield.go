@@ -0,0 +1,270 @@
+package customfield
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Field types.
+const (
+	TypeText        = "text"
+	TypeNumber      = "number"
+	TypeDate        = "date"
+	TypeSelect      = "select"
+	TypeMultiSelect = "multi_select"
+	TypeURL         = "url"
+)
+
+// DateLayout is the format date values are stored in, so that they sort as text.
+const DateLayout = time.DateOnly
+
+var (
+	// ErrInvalidSchema indicates a custom field definition is inconsistent.
+	ErrInvalidSchema = errors.New("invalid custom fields")
+	// ErrInvalidValue indicates a value that does not match its field.
+	ErrInvalidValue = errors.New("invalid custom field value")
+)
+
+var keyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,62}$`)
+
+// Field declares a typed custom field. Select fields restrict values to Options.
+type Field struct {
+	Key     string   `json:"key"`
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Options []string `json:"options,omitempty"`
+}
+
+// Schema lists the custom fields of a project in display order.
+type Schema []Field
+
+// Values maps field keys to values: strings for text, date, select and URL fields,
+// numbers for number fields and string lists for multi-select fields.
+type Values map[string]any
+
+// Validate checks that keys are unique lower-case identifiers, types are known and
+// only select fields declare options.
+func (s Schema) Validate() error {
+	keys := make(map[string]bool, len(s))
+	for _, f := range s {
+		if !keyPattern.MatchString(f.Key) {
+			return fmt.Errorf("%w: key %q must start with a letter and contain only a-z, 0-9 and _", ErrInvalidSchema, f.Key)
+		}
+		if keys[f.Key] {
+			return fmt.Errorf("%w: duplicate key %q", ErrInvalidSchema, f.Key)
+		}
+		keys[f.Key] = true
+
+		switch f.Type {
+		case TypeSelect, TypeMultiSelect:
+			if len(f.Options) == 0 {
+				return fmt.Errorf("%w: %q needs at least one option", ErrInvalidSchema, f.Key)
+			}
+			seen := make(map[string]bool, len(f.Options))
+			for _, o := range f.Options {
+				if strings.TrimSpace(o) == "" || seen[o] {
+					return fmt.Errorf("%w: %q has an empty or duplicate option", ErrInvalidSchema, f.Key)
+				}
+				seen[o] = true
+			}
+		case TypeText, TypeNumber, TypeDate, TypeURL:
+			if len(f.Options) > 0 {
+				return fmt.Errorf("%w: only select fields take options", ErrInvalidSchema)
+			}
+		default:
+			return fmt.Errorf("%w: %q has unknown type %q", ErrInvalidSchema, f.Key, f.Type)
+		}
+	}
+	return nil
+}
+
+// Field returns the field with the given key.
+func (s Schema) Field(key string) (Field, bool) {
+	for _, f := range s {
+		if f.Key == key {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+// Check validates values against the schema and returns them normalised. Nil and
+// empty values are kept as nil so that callers can use them to clear a field.
+func (s Schema) Check(values Values) (Values, error) {
+	result := make(Values, len(values))
+	for key, value := range values {
+		f, ok := s.Field(key)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown field %q", ErrInvalidValue, key)
+		}
+		normalized, err := f.normalize(value)
+		if err != nil {
+			return nil, err
+		}
+		result[key] = normalized
+	}
+	return result, nil
+}
+
+// Filter keeps the values that are valid for the schema and drops the rest, e.g. when a
+// todo moves to another project.
+func (s Schema) Filter(values Values) Values {
+	result := make(Values, len(values))
+	for key, value := range values {
+		f, ok := s.Field(key)
+		if !ok {
+			continue
+		}
+		if normalized, err := f.normalize(value); err == nil && normalized != nil {
+			result[key] = normalized
+		}
+	}
+	return result
+}
+
+// Retained returns the keys of fields that exist in previous with the same type, whose
+// stored values therefore survive replacing previous with s.
+func (s Schema) Retained(previous Schema) []string {
+	keys := make([]string, 0, len(s))
+	for _, f := range s {
+		if old, ok := previous.Field(f.Key); ok && old.Type == f.Type {
+			keys = append(keys, f.Key)
+		}
+	}
+	return keys
+}
+
+// Merge applies patch to values; nil entries remove the field.
+func Merge(values, patch Values) Values {
+	result := make(Values, len(values)+len(patch))
+	for key, value := range values {
+		result[key] = value
+	}
+	for key, value := range patch {
+		if value == nil {
+			delete(result, key)
+		} else {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// Parse reads a value of the field from its text form, as used in query strings. For
+// multi-select fields it returns a single-element list.
+func (f Field) Parse(raw string) (any, error) {
+	switch f.Type {
+	case TypeNumber:
+		n, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %q is not a number", ErrInvalidValue, f.Key)
+		}
+		return f.normalize(n)
+	case TypeMultiSelect:
+		return f.normalize([]any{raw})
+	default:
+		return f.normalize(raw)
+	}
+}
+
+func (f Field) normalize(value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	switch f.Type {
+	case TypeNumber:
+		var n float64
+		switch v := value.(type) {
+		case float64:
+			n = v
+		case int:
+			n = float64(v)
+		default:
+			return nil, f.invalid("must be a number")
+		}
+		if math.IsNaN(n) || math.IsInf(n, 0) {
+			return nil, f.invalid("must be a finite number")
+		}
+		return n, nil
+	case TypeMultiSelect:
+		list, err := stringList(value)
+		if err != nil {
+			return nil, f.invalid("must be a list of options")
+		}
+		result := make([]string, 0, len(list))
+		for _, v := range list {
+			if !slices.Contains(f.Options, v) {
+				return nil, f.invalid(fmt.Sprintf("has no option %q", v))
+			}
+			if !slices.Contains(result, v) {
+				result = append(result, v)
+			}
+		}
+		if len(result) == 0 {
+			return nil, nil
+		}
+		return result, nil
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return nil, f.invalid("must be a string")
+	}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+
+	switch f.Type {
+	case TypeDate:
+		if d, err := time.Parse(DateLayout, s); err == nil {
+			return d.Format(DateLayout), nil
+		}
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			return t.Format(DateLayout), nil
+		}
+		return nil, f.invalid("must be a date (YYYY-MM-DD)")
+	case TypeSelect:
+		if !slices.Contains(f.Options, s) {
+			return nil, f.invalid(fmt.Sprintf("has no option %q", s))
+		}
+	case TypeURL:
+		u, err := url.Parse(s)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, f.invalid("must be an http or https URL")
+		}
+	}
+	return s, nil
+}
+
+func (f Field) invalid(reason string) error {
+	return fmt.Errorf("%w: %q %s", ErrInvalidValue, f.Key, reason)
+}
+
+// stringList accepts the []any produced by JSON decoding as well as []string.
+func stringList(value any) ([]string, error) {
+	switch v := value.(type) {
+	case []string:
+		return v, nil
+	case []any:
+		result := make([]string, len(v))
+		for i, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, errors.New("not a string")
+			}
+			result[i] = s
+		}
+		return result, nil
+	default:
+		return nil, errors.New("not a list")
+	}
+}
@@ -0,0 +1,102 @@
+package customfield
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testSchema = Schema{
+	{Key: "points", Name: "Story points", Type: TypeNumber},
+	{Key: "customer", Name: "Customer", Type: TypeText},
+	{Key: "deadline", Name: "Deadline", Type: TypeDate},
+	{Key: "size", Name: "Size", Type: TypeSelect, Options: []string{"S", "M", "L"}},
+	{Key: "teams", Name: "Teams", Type: TypeMultiSelect, Options: []string{"web", "ios", "android"}},
+	{Key: "ticket", Name: "Ticket", Type: TypeURL},
+}
+
+func TestCheckNormalizesValues(t *testing.T) {
+	require.NoError(t, testSchema.Validate())
+
+	values, err := testSchema.Check(Values{
+		"points":   float64(3),
+		"customer": "  ACME  ",
+		"deadline": "2025-06-01T15:00:00Z",
+		"size":     "M",
+		"teams":    []any{"web", "ios", "web"},
+		"ticket":   "https://tracker.example.com/T-1",
+	})
+	require.NoError(t, err)
+	require.Equal(t, Values{
+		"points":   float64(3),
+		"customer": "ACME",
+		"deadline": "2025-06-01",
+		"size":     "M",
+		"teams":    []string{"web", "ios"},
+		"ticket":   "https://tracker.example.com/T-1",
+	}, values)
+
+	// Empty values clear the field.
+	values, err = testSchema.Check(Values{"customer": "", "teams": []any{}})
+	require.NoError(t, err)
+	require.Equal(t, Values{"customer": nil, "teams": nil}, values)
+}
+
+func TestCheckRejectsInvalidValues(t *testing.T) {
+	cases := map[string]Values{
+		"unknown field":  {"estimate": "1d"},
+		"number":         {"points": "three"},
+		"date":           {"deadline": "next week"},
+		"select option":  {"size": "XL"},
+		"multi option":   {"teams": []any{"web", "desktop"}},
+		"multi not list": {"teams": "web"},
+		"url scheme":     {"ticket": "ftp://example.com/file"},
+		"text type":      {"customer": 42.0},
+	}
+	for name, values := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := testSchema.Check(values)
+			require.ErrorIs(t, err, ErrInvalidValue)
+		})
+	}
+}
+
+func TestValidateRejectsInconsistentSchemas(t *testing.T) {
+	cases := map[string]Schema{
+		"bad key":          {{Key: "Story Points", Type: TypeNumber}},
+		"duplicate key":    {{Key: "a", Type: TypeText}, {Key: "a", Type: TypeNumber}},
+		"unknown type":     {{Key: "a", Type: "money"}},
+		"select no option": {{Key: "a", Type: TypeSelect}},
+		"text with option": {{Key: "a", Type: TypeText, Options: []string{"x"}}},
+	}
+	for name, schema := range cases {
+		t.Run(name, func(t *testing.T) {
+			require.ErrorIs(t, schema.Validate(), ErrInvalidSchema)
+		})
+	}
+}
+
+func TestFilterAndRetained(t *testing.T) {
+	next := Schema{
+		{Key: "points", Type: TypeText},
+		{Key: "size", Type: TypeSelect, Options: []string{"S", "M"}},
+	}
+
+	require.Equal(t, Values{"size": "M"}, next.Filter(Values{"points": 3.0, "size": "M", "customer": "ACME"}))
+	require.Equal(t, []string{"size"}, next.Retained(testSchema))
+}
+
+func TestMergeAndParse(t *testing.T) {
+	merged := Merge(Values{"points": 3.0, "size": "S"}, Values{"size": nil, "customer": "ACME"})
+	require.Equal(t, Values{"points": 3.0, "customer": "ACME"}, merged)
+
+	points, _ := testSchema.Field("points")
+	value, err := points.Parse("5")
+	require.NoError(t, err)
+	require.Equal(t, 5.0, value)
+
+	teams, _ := testSchema.Field("teams")
+	value, err = teams.Parse("ios")
+	require.NoError(t, err)
+	require.Equal(t, []string{"ios"}, value)
+}
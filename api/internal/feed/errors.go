@@ -0,0 +1,12 @@
+package feed
+
+import "errors"
+
+// ErrNotFound indicates the feed token is unknown or has been revoked.
+var ErrNotFound = errors.New("feed not found")
+
+var (
+	errInvalidFormat    = errors.New("format must be vtodo or vevent")
+	errInvalidCompleted = errors.New("completed must be a boolean")
+	errInvalidProject   = errors.New("invalid project_id")
+)
@@ -0,0 +1,194 @@
+package feed
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"overengineeredtodo/internal/ical"
+	"overengineeredtodo/internal/todo"
+)
+
+const (
+	formatVTODO  = "vtodo"
+	formatVEVENT = "vevent"
+
+	// feedMaxAge tells calendar clients how long they may reuse a fetched feed.
+	feedMaxAge = 5 * time.Minute
+)
+
+// RegisterRoutes wires the feed token management and the public feed onto the router group.
+func RegisterRoutes(router *gin.RouterGroup, repo *Repository, todos *todo.Repository) {
+	handler := &Handler{repo: repo, todos: todos}
+
+	router.POST("/token", handler.rotateToken)
+	router.DELETE("/token", handler.revokeToken)
+	router.GET("/:token/todos.ics", handler.serveFeed)
+}
+
+// Handler exposes the calendar feed endpoints.
+type Handler struct {
+	repo  *Repository
+	todos *todo.Repository
+}
+
+// Options narrows down what a feed contains.
+type Options struct {
+	// Completed keeps only completed (true) or open (false) todos when set.
+	Completed *bool
+	// ProjectID keeps only the todos of one project when set.
+	ProjectID *uuid.UUID
+	// Format selects VTODO or VEVENT entries.
+	Format string
+}
+
+func (h *Handler) rotateToken(c *gin.Context) {
+	userID, err := uuid.Parse(c.Query("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+		return
+	}
+
+	token, err := h.repo.Rotate(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, token)
+}
+
+func (h *Handler) revokeToken(c *gin.Context) {
+	userID, err := uuid.Parse(c.Query("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+		return
+	}
+
+	if err := h.repo.Revoke(c.Request.Context(), userID); err != nil {
+		if err == ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "feed not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) serveFeed(c *gin.Context) {
+	opts, err := parseOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := h.repo.UserForToken(c.Request.Context(), c.Param("token"))
+	switch {
+	case err == ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "feed not found"})
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	todos, err := h.todos.ListByUser(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := ical.Encode(&buf, Build(todos, opts)); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	c.Header("Cache-Control", "private, max-age="+strconv.Itoa(int(feedMaxAge.Seconds())))
+	c.Header("ETag", etag)
+	if modified := lastModified(todos); !modified.IsZero() {
+		c.Header("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+
+	if match := c.GetHeader("If-None-Match"); match != "" && strings.Contains(match, etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, "text/calendar; charset=utf-8", buf.Bytes())
+}
+
+func parseOptions(c *gin.Context) (Options, error) {
+	opts := Options{Format: strings.ToLower(c.DefaultQuery("format", formatVTODO))}
+	if opts.Format != formatVTODO && opts.Format != formatVEVENT {
+		return Options{}, errInvalidFormat
+	}
+
+	if raw := c.Query("completed"); raw != "" {
+		completed, err := strconv.ParseBool(raw)
+		if err != nil {
+			return Options{}, errInvalidCompleted
+		}
+		opts.Completed = &completed
+	}
+
+	if raw := c.Query("project_id"); raw != "" {
+		projectID, err := uuid.Parse(raw)
+		if err != nil {
+			return Options{}, errInvalidProject
+		}
+		opts.ProjectID = &projectID
+	}
+
+	return opts, nil
+}
+
+// Build renders the todos that match opts as a calendar. VEVENT feeds only
+// contain todos with a due date, since an event needs a start time.
+func Build(todos []todo.Todo, opts Options) *ical.Component {
+	cal := ical.NewCalendar(todo.ICalProdID)
+	cal.Set("X-WR-CALNAME", "Todos")
+	cal.Add(ical.Property{Name: "REFRESH-INTERVAL", Params: map[string]string{"VALUE": "DURATION"}, Value: "PT5M"})
+
+	for _, t := range todos {
+		if opts.Completed != nil && t.Completed != *opts.Completed {
+			continue
+		}
+		if opts.ProjectID != nil && (t.ProjectID == nil || *t.ProjectID != *opts.ProjectID) {
+			continue
+		}
+
+		if opts.Format == formatVEVENT {
+			if t.DueDate == nil {
+				continue
+			}
+			cal.Append(todo.ToVEVENT(t))
+			continue
+		}
+		cal.Append(todo.ToVTODO(t))
+	}
+
+	return cal
+}
+
+func lastModified(todos []todo.Todo) time.Time {
+	var latest time.Time
+	for _, t := range todos {
+		if t.UpdatedAt.After(latest) {
+			latest = t.UpdatedAt
+		}
+	}
+	return latest
+}
@@ -0,0 +1,36 @@
+package feed
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"overengineeredtodo/internal/ical"
+	"overengineeredtodo/internal/todo"
+)
+
+func TestBuildFiltersAndFormats(t *testing.T) {
+	due := time.Now().Add(24 * time.Hour)
+	projectID := uuid.New()
+	todos := []todo.Todo{
+		{ID: uuid.New(), Title: "open with due", DueDate: &due, ProjectID: &projectID},
+		{ID: uuid.New(), Title: "open without due"},
+		{ID: uuid.New(), Title: "done with due", DueDate: &due, Completed: true},
+	}
+
+	all := Build(todos, Options{Format: formatVTODO})
+	require.Len(t, all.Children(ical.ComponentTodo), 3)
+
+	open := false
+	openOnly := Build(todos, Options{Format: formatVTODO, Completed: &open})
+	require.Len(t, openOnly.Children(ical.ComponentTodo), 2)
+
+	events := Build(todos, Options{Format: formatVEVENT, Completed: &open})
+	require.Len(t, events.Children(ical.ComponentEvent), 1)
+	require.Equal(t, "open with due", events.Children(ical.ComponentEvent)[0].Text("SUMMARY"))
+
+	project := Build(todos, Options{Format: formatVTODO, ProjectID: &projectID})
+	require.Len(t, project.Children(ical.ComponentTodo), 1)
+}
@@ -0,0 +1,16 @@
+package feed
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Token is a freshly issued feed secret. The plain token is only returned once;
+// the database stores a hash of it.
+type Token struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Token     string    `json:"token"`
+	Path      string    `json:"path"`
+	CreatedAt time.Time `json:"created_at"`
+}
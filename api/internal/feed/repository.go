@@ -0,0 +1,85 @@
+package feed
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const tokenBytes = 32
+
+// Repository persists per-user calendar feed tokens.
+type Repository struct {
+	pool pgxPool
+}
+
+type pgxPool interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// NewRepository constructs a Repository backed by the supplied pgx pool.
+func NewRepository(pool pgxPool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// Rotate issues a new token for the user, replacing any previous one.
+func (r *Repository) Rotate(ctx context.Context, userID uuid.UUID) (Token, error) {
+	raw := make([]byte, tokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return Token{}, fmt.Errorf("generate feed token: %w", err)
+	}
+	secret := base64.RawURLEncoding.EncodeToString(raw)
+
+	query := `
+		UPSERT INTO feed_tokens (user_id, token_hash, created_at)
+		VALUES ($1, $2, now())
+		RETURNING user_id, created_at
+	`
+
+	t := Token{Token: secret, Path: "/v1/feeds/" + secret + "/todos.ics"}
+	if err := r.pool.QueryRow(ctx, query, userID, hashToken(secret)).Scan(&t.UserID, &t.CreatedAt); err != nil {
+		return Token{}, fmt.Errorf("upsert feed token: %w", err)
+	}
+
+	return t, nil
+}
+
+// Revoke deletes the user's token. Returns ErrNotFound when none exists.
+func (r *Repository) Revoke(ctx context.Context, userID uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM feed_tokens WHERE user_id = $1`, userID)
+	if err != nil {
+		return fmt.Errorf("delete feed token: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// UserForToken resolves a plain token to the owning user.
+func (r *Repository) UserForToken(ctx context.Context, token string) (uuid.UUID, error) {
+	var userID uuid.UUID
+	err := r.pool.QueryRow(ctx, `SELECT user_id FROM feed_tokens WHERE token_hash = $1`, hashToken(token)).Scan(&userID)
+
+	switch {
+	case err == nil:
+		return userID, nil
+	case err == pgx.ErrNoRows:
+		return uuid.Nil, ErrNotFound
+	default:
+		return uuid.Nil, fmt.Errorf("select feed token: %w", err)
+	}
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
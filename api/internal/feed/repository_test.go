@@ -0,0 +1,74 @@
+package feed
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepositoryRotate(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	userID := uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery("UPSERT INTO feed_tokens").
+		WithArgs(userID, pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"user_id", "created_at"}).AddRow(userID, now))
+
+	token, err := repo.Rotate(context.Background(), userID)
+	require.NoError(t, err)
+	require.Equal(t, userID, token.UserID)
+	require.Len(t, token.Token, 43)
+	require.Equal(t, "/v1/feeds/"+token.Token+"/todos.ics", token.Path)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryRevokeNotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	userID := uuid.New()
+
+	mock.ExpectExec("DELETE FROM feed_tokens").
+		WithArgs(userID).
+		WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+	err = repo.Revoke(context.Background(), userID)
+	require.ErrorIs(t, err, ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryUserForTokenLooksUpHash(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	userID := uuid.New()
+
+	mock.ExpectQuery("SELECT user_id FROM feed_tokens").
+		WithArgs(hashToken("secret")).
+		WillReturnRows(pgxmock.NewRows([]string{"user_id"}).AddRow(userID))
+	mock.ExpectQuery("SELECT user_id FROM feed_tokens").
+		WithArgs(hashToken("revoked")).
+		WillReturnError(pgx.ErrNoRows)
+
+	got, err := repo.UserForToken(context.Background(), "secret")
+	require.NoError(t, err)
+	require.Equal(t, userID, got)
+
+	_, err = repo.UserForToken(context.Background(), "revoked")
+	require.ErrorIs(t, err, ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
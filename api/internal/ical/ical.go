@@ -0,0 +1,410 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Common component names used by the todo service.
+const (
+	ComponentCalendar = "VCALENDAR"
+	ComponentTodo     = "VTODO"
+	ComponentEvent    = "VEVENT"
+)
+
+const (
+	dateTimeUTCLayout = "20060102T150405Z"
+	dateTimeLayout    = "20060102T150405"
+	dateLayout        = "20060102"
+	maxLineOctets     = 75
+)
+
+// ErrMalformed indicates the input could not be parsed as iCalendar data.
+var ErrMalformed = errors.New("malformed icalendar data")
+
+// Property is a single content line such as "DUE;TZID=Europe/Berlin:20250101T090000".
+type Property struct {
+	Name   string
+	Params map[string]string
+	Value  string
+}
+
+// Param returns the named parameter value or an empty string.
+func (p Property) Param(name string) string {
+	if p.Params == nil {
+		return ""
+	}
+	return p.Params[strings.ToUpper(name)]
+}
+
+// Component is a BEGIN/END delimited block with properties and nested components.
+type Component struct {
+	Name       string
+	Properties []Property
+	Components []*Component
+}
+
+// NewComponent returns an empty component with the supplied name.
+func NewComponent(name string) *Component {
+	return &Component{Name: strings.ToUpper(name)}
+}
+
+// NewCalendar returns a VCALENDAR carrying the mandatory VERSION and PRODID properties.
+func NewCalendar(prodID string) *Component {
+	cal := NewComponent(ComponentCalendar)
+	cal.Set("VERSION", "2.0")
+	cal.Set("PRODID", prodID)
+	cal.Set("CALSCALE", "GREGORIAN")
+	return cal
+}
+
+// Get returns the first property with the given name.
+func (c *Component) Get(name string) (Property, bool) {
+	name = strings.ToUpper(name)
+	for _, p := range c.Properties {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Property{}, false
+}
+
+// Text returns the unescaped TEXT value of the named property.
+func (c *Component) Text(name string) string {
+	p, ok := c.Get(name)
+	if !ok {
+		return ""
+	}
+	return UnescapeText(p.Value)
+}
+
+// Set replaces any existing property with the given name.
+func (c *Component) Set(name, value string) {
+	c.Remove(name)
+	c.Add(Property{Name: strings.ToUpper(name), Value: value})
+}
+
+// SetText sets a TEXT property, escaping the value as required by RFC 5545.
+func (c *Component) SetText(name, value string) {
+	c.Set(name, EscapeText(value))
+}
+
+// SetDateTime sets a DATE-TIME property in UTC form.
+func (c *Component) SetDateTime(name string, t time.Time) {
+	c.Set(name, FormatDateTime(t))
+}
+
+// Add appends a property without removing existing ones.
+func (c *Component) Add(p Property) {
+	p.Name = strings.ToUpper(p.Name)
+	c.Properties = append(c.Properties, p)
+}
+
+// Remove deletes all properties with the given name.
+func (c *Component) Remove(name string) {
+	name = strings.ToUpper(name)
+	kept := c.Properties[:0]
+	for _, p := range c.Properties {
+		if p.Name != name {
+			kept = append(kept, p)
+		}
+	}
+	c.Properties = kept
+}
+
+// Children returns nested components with the given name.
+func (c *Component) Children(name string) []*Component {
+	name = strings.ToUpper(name)
+	var result []*Component
+	for _, child := range c.Components {
+		if child.Name == name {
+			result = append(result, child)
+		}
+	}
+	return result
+}
+
+// Append nests child components.
+func (c *Component) Append(children ...*Component) {
+	c.Components = append(c.Components, children...)
+}
+
+// Encode writes the component tree using CRLF line endings and 75-octet folding.
+func Encode(w io.Writer, c *Component) error {
+	bw := bufio.NewWriter(w)
+	if err := encodeComponent(bw, c); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+func encodeComponent(w *bufio.Writer, c *Component) error {
+	if err := writeLine(w, "BEGIN:"+c.Name); err != nil {
+		return err
+	}
+	for _, p := range c.Properties {
+		if err := writeLine(w, formatProperty(p)); err != nil {
+			return err
+		}
+	}
+	for _, child := range c.Components {
+		if err := encodeComponent(w, child); err != nil {
+			return err
+		}
+	}
+	return writeLine(w, "END:"+c.Name)
+}
+
+func formatProperty(p Property) string {
+	var b strings.Builder
+	b.WriteString(p.Name)
+
+	keys := make([]string, 0, len(p.Params))
+	for k := range p.Params {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		v := p.Params[k]
+		b.WriteByte(';')
+		b.WriteString(k)
+		b.WriteByte('=')
+		if strings.ContainsAny(v, ";:,") {
+			b.WriteString(`"` + v + `"`)
+		} else {
+			b.WriteString(v)
+		}
+	}
+
+	b.WriteByte(':')
+	b.WriteString(p.Value)
+	return b.String()
+}
+
+// writeLine folds a content line so that no physical line exceeds 75 octets,
+// taking care not to split multi-byte UTF-8 sequences.
+func writeLine(w *bufio.Writer, line string) error {
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		if _, err := w.WriteString(line[:cut] + "\r\n "); err != nil {
+			return err
+		}
+		line = line[cut:]
+		// Continuation lines lose one octet to the leading space.
+		limit = maxLineOctets - 1
+	}
+	_, err := w.WriteString(line + "\r\n")
+	return err
+}
+
+// Decode parses a single top-level component (normally a VCALENDAR) from r.
+func Decode(r io.Reader) (*Component, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		root  *Component
+		stack []*Component
+	)
+
+	for i, line := range lines {
+		p, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+
+		switch p.Name {
+		case "BEGIN":
+			comp := NewComponent(p.Value)
+			if len(stack) > 0 {
+				stack[len(stack)-1].Append(comp)
+			} else if root != nil {
+				return nil, fmt.Errorf("line %d: %w: multiple top-level components", i+1, ErrMalformed)
+			} else {
+				root = comp
+			}
+			stack = append(stack, comp)
+		case "END":
+			if len(stack) == 0 || stack[len(stack)-1].Name != strings.ToUpper(p.Value) {
+				return nil, fmt.Errorf("line %d: %w: unexpected END:%s", i+1, ErrMalformed, p.Value)
+			}
+			stack = stack[:len(stack)-1]
+		default:
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("line %d: %w: property outside component", i+1, ErrMalformed)
+			}
+			stack[len(stack)-1].Add(p)
+		}
+	}
+
+	if root == nil {
+		return nil, fmt.Errorf("%w: no component found", ErrMalformed)
+	}
+	if len(stack) != 0 {
+		return nil, fmt.Errorf("%w: unterminated %s", ErrMalformed, stack[len(stack)-1].Name)
+	}
+
+	return root, nil
+}
+
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read icalendar: %w", err)
+	}
+	return lines, nil
+}
+
+func parseLine(line string) (Property, error) {
+	var (
+		p        Property
+		inQuotes bool
+		start    int
+		key      string
+		stage    = 0 // 0: name, 1: param name, 2: param value
+	)
+
+	for i := 0; i < len(line); i++ {
+		ch := line[i]
+		switch {
+		case ch == '"':
+			inQuotes = !inQuotes
+		case inQuotes:
+		case ch == ';' || ch == ':':
+			switch stage {
+			case 0:
+				p.Name = strings.ToUpper(line[start:i])
+			case 2:
+				if p.Params == nil {
+					p.Params = make(map[string]string)
+				}
+				p.Params[key] = strings.Trim(line[start:i], `"`)
+			default:
+				return Property{}, fmt.Errorf("%w: parameter without value", ErrMalformed)
+			}
+			if ch == ':' {
+				p.Value = line[i+1:]
+				if p.Name == "" {
+					return Property{}, fmt.Errorf("%w: missing property name", ErrMalformed)
+				}
+				return p, nil
+			}
+			stage = 1
+			start = i + 1
+		case ch == '=' && stage == 1:
+			key = strings.ToUpper(line[start:i])
+			stage = 2
+			start = i + 1
+		}
+	}
+
+	return Property{}, fmt.Errorf("%w: missing ':' separator", ErrMalformed)
+}
+
+// EscapeText escapes a TEXT value per RFC 5545 section 3.3.11.
+func EscapeText(s string) string {
+	replacer := strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	)
+	return replacer.Replace(s)
+}
+
+// UnescapeText reverses EscapeText.
+func UnescapeText(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i == len(s)-1 {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
+
+// SplitText splits a multi-valued TEXT property such as CATEGORIES on its
+// unescaped commas and unescapes each value.
+func SplitText(s string) []string {
+	var (
+		values []string
+		start  int
+	)
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case ',':
+			values = append(values, UnescapeText(s[start:i]))
+			start = i + 1
+		}
+	}
+	return append(values, UnescapeText(s[start:]))
+}
+
+// FormatDateTime renders t as a UTC DATE-TIME value.
+func FormatDateTime(t time.Time) string {
+	return t.UTC().Format(dateTimeUTCLayout)
+}
+
+// ParseDateTime interprets a DATE or DATE-TIME property. Floating times and
+// unknown TZIDs are interpreted in the fallback location.
+func ParseDateTime(p Property, fallback *time.Location) (time.Time, error) {
+	if fallback == nil {
+		fallback = time.UTC
+	}
+
+	loc := fallback
+	if tzid := p.Param("TZID"); tzid != "" {
+		if l, err := time.LoadLocation(tzid); err == nil {
+			loc = l
+		}
+	}
+
+	value := strings.TrimSpace(p.Value)
+	switch {
+	case strings.HasSuffix(value, "Z"):
+		return time.Parse(dateTimeUTCLayout, value)
+	case len(value) == len(dateLayout) || strings.EqualFold(p.Param("VALUE"), "DATE"):
+		return time.ParseInLocation(dateLayout, value, loc)
+	default:
+		return time.ParseInLocation(dateTimeLayout, value, loc)
+	}
+}
@@ -0,0 +1,112 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecodeRoundTrip(t *testing.T) {
+	cal := NewCalendar("-//test//EN")
+	todo := NewComponent(ComponentTodo)
+	todo.SetText("UID", "abc-123")
+	todo.SetText("SUMMARY", "Buy milk, eggs; bread")
+	todo.SetText("DESCRIPTION", "line one\nline two \\ done")
+	todo.SetDateTime("DUE", time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC))
+	cal.Append(todo)
+
+	var buf bytes.Buffer
+	require.NoError(t, Encode(&buf, cal))
+	require.Contains(t, buf.String(), "SUMMARY:Buy milk\\, eggs\\; bread\r\n")
+
+	decoded, err := Decode(&buf)
+	require.NoError(t, err)
+	require.Equal(t, ComponentCalendar, decoded.Name)
+	require.Equal(t, "2.0", decoded.Text("VERSION"))
+
+	todos := decoded.Children(ComponentTodo)
+	require.Len(t, todos, 1)
+	require.Equal(t, "abc-123", todos[0].Text("UID"))
+	require.Equal(t, "Buy milk, eggs; bread", todos[0].Text("SUMMARY"))
+	require.Equal(t, "line one\nline two \\ done", todos[0].Text("DESCRIPTION"))
+}
+
+func TestEncodeFoldsLongLines(t *testing.T) {
+	c := NewComponent(ComponentTodo)
+	c.SetText("SUMMARY", strings.Repeat("ä", 100))
+
+	var buf bytes.Buffer
+	require.NoError(t, Encode(&buf, c))
+
+	for _, line := range strings.Split(strings.TrimSuffix(buf.String(), "\r\n"), "\r\n") {
+		require.LessOrEqual(t, len(line), 75)
+	}
+
+	decoded, err := Decode(&buf)
+	require.NoError(t, err)
+	require.Equal(t, strings.Repeat("ä", 100), decoded.Text("SUMMARY"))
+}
+
+func TestDecodeParameters(t *testing.T) {
+	input := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VTODO\r\n" +
+		"UID:1\r\n" +
+		"DUE;TZID=Europe/Berlin:20250101T090000\r\n" +
+		"X-NOTE;ALTREP=\"http://example.com/a:b\";LANGUAGE=de:hallo\r\n" +
+		"END:VTODO\r\n" +
+		"END:VCALENDAR\r\n"
+
+	cal, err := Decode(strings.NewReader(input))
+	require.NoError(t, err)
+
+	todo := cal.Children(ComponentTodo)[0]
+	due, ok := todo.Get("DUE")
+	require.True(t, ok)
+	require.Equal(t, "Europe/Berlin", due.Param("tzid"))
+
+	parsed, err := ParseDateTime(due, time.UTC)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2025, 1, 1, 8, 0, 0, 0, time.UTC), parsed.UTC())
+
+	note, ok := todo.Get("X-NOTE")
+	require.True(t, ok)
+	require.Equal(t, "http://example.com/a:b", note.Param("ALTREP"))
+	require.Equal(t, "hallo", note.Value)
+}
+
+func TestParseDateTimeForms(t *testing.T) {
+	utc, err := ParseDateTime(Property{Value: "20250102T030405Z"}, nil)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC), utc)
+
+	date, err := ParseDateTime(Property{Value: "20250102", Params: map[string]string{"VALUE": "DATE"}}, nil)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC), date)
+
+	_, err = ParseDateTime(Property{Value: "tomorrow"}, nil)
+	require.Error(t, err)
+}
+
+func TestDecodeRejectsMalformedInput(t *testing.T) {
+	cases := map[string]string{
+		"unterminated":   "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nEND:VCALENDAR\r\n",
+		"missing colon":  "BEGIN:VCALENDAR\r\nSUMMARY\r\nEND:VCALENDAR\r\n",
+		"orphan END":     "END:VTODO\r\n",
+		"empty document": "",
+	}
+
+	for name, input := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Decode(strings.NewReader(input))
+			require.ErrorIs(t, err, ErrMalformed)
+		})
+	}
+}
+
+func TestSplitText(t *testing.T) {
+	require.Equal(t, []string{"work", "a,b", "home"}, SplitText(`work,a\,b,home`))
+	require.Equal(t, []string{"single"}, SplitText("single"))
+}
@@ -0,0 +1,12 @@
+package importer
+
+import "errors"
+
+var (
+	// ErrNotFound indicates the requested import job does not exist.
+	ErrNotFound = errors.New("import job not found")
+	// ErrUnknownSource indicates the source is not one of the supported tools.
+	ErrUnknownSource = errors.New("source must be todoist, trello or mstodo")
+	// ErrInvalidExport indicates the uploaded file is not a readable export of the source.
+	ErrInvalidExport = errors.New("invalid export")
+)
@@ -0,0 +1,110 @@
+package importer
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const maxExportBytes = 64 << 20
+
+// RegisterRoutes wires the import job endpoints onto the router group.
+func RegisterRoutes(router *gin.RouterGroup, runner *Runner, jobs *Repository) {
+	handler := &Handler{runner: runner, jobs: jobs}
+
+	router.POST("", handler.startImport)
+	router.GET("/:id", handler.getJob)
+}
+
+// Handler exposes HTTP endpoints for third-party imports.
+type Handler struct {
+	runner *Runner
+	jobs   *Repository
+}
+
+// startImport parses the uploaded export (raw body or multipart field "file") and
+// queues the import. Clients poll the returned job for progress.
+func (h *Handler) startImport(c *gin.Context) {
+	userID, err := uuid.Parse(c.Query("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+		return
+	}
+
+	source := strings.ToLower(c.Query("source"))
+	switch source {
+	case SourceTodoist, SourceTrello, SourceMicrosoftToDo:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": ErrUnknownSource.Error()})
+		return
+	}
+
+	body, filename, err := uploadBody(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	defer body.Close()
+
+	items, err := Parse(source, body, filename)
+	if err != nil {
+		if errors.Is(err, ErrInvalidExport) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	job, err := h.runner.Start(c.Request.Context(), userID, source, items)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Location", strings.TrimSuffix(c.FullPath(), "/")+"/"+job.ID.String())
+	c.JSON(http.StatusAccepted, job)
+}
+
+func (h *Handler) getJob(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userID, err := uuid.Parse(c.Query("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+		return
+	}
+
+	job, err := h.jobs.Get(c.Request.Context(), id, userID)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, job)
+	case err == ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "import job not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func uploadBody(c *gin.Context) (io.ReadCloser, string, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxExportBytes)
+
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, "", err
+		}
+		file, err := header.Open()
+		return file, header.Filename, err
+	}
+
+	return c.Request.Body, c.Query("filename"), nil
+}
@@ -0,0 +1,53 @@
+package importer
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Sources of third-party exports understood by the importer.
+const (
+	SourceTodoist       = "todoist"
+	SourceTrello        = "trello"
+	SourceMicrosoftToDo = "mstodo"
+)
+
+// Job statuses, in the order a job passes through them.
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Item is a task read from a third-party export, already mapped onto todo semantics.
+type Item struct {
+	// ExternalID identifies the task in the source tool and drives deduplication.
+	ExternalID  string
+	Project     string
+	Title       string
+	Description string
+	Labels      []string
+	Priority    int
+	DueDate     *time.Time
+	Completed   bool
+}
+
+// Job tracks a background import and its progress.
+type Job struct {
+	ID         uuid.UUID  `json:"id"`
+	UserID     uuid.UUID  `json:"user_id"`
+	Source     string     `json:"source"`
+	Status     string     `json:"status"`
+	Total      int        `json:"total"`
+	Processed  int        `json:"processed"`
+	Created    int        `json:"created"`
+	Updated    int        `json:"updated"`
+	Unchanged  int        `json:"unchanged"`
+	Skipped    int        `json:"skipped"`
+	Error      *string    `json:"error,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
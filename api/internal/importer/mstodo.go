@@ -0,0 +1,77 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"overengineeredtodo/internal/todo"
+)
+
+// microsoftToDoExport holds task lists as returned by the Microsoft Graph To Do API
+// (GET /me/todo/lists with each list's tasks expanded). Microsoft To Do has no file
+// export of its own, so export tools write this shape. Both a "lists" and a Graph
+// style "value" array are accepted.
+type microsoftToDoExport struct {
+	Lists []microsoftToDoList `json:"lists"`
+	Value []microsoftToDoList `json:"value"`
+}
+
+type microsoftToDoList struct {
+	ID          string `json:"id"`
+	DisplayName string `json:"displayName"`
+	Tasks       []struct {
+		ID    string `json:"id"`
+		Title string `json:"title"`
+		Body  *struct {
+			Content string `json:"content"`
+		} `json:"body"`
+		Importance  string   `json:"importance"`
+		Status      string   `json:"status"`
+		Categories  []string `json:"categories"`
+		DueDateTime *struct {
+			DateTime string `json:"dateTime"`
+			TimeZone string `json:"timeZone"`
+		} `json:"dueDateTime"`
+	} `json:"tasks"`
+}
+
+func parseMicrosoftToDo(r io.Reader) ([]Item, error) {
+	var export microsoftToDoExport
+	if err := decodeJSON(r, &export); err != nil {
+		return nil, err
+	}
+
+	lists := append(export.Lists, export.Value...)
+	if len(lists) == 0 {
+		return nil, fmt.Errorf("%w: no task lists found", ErrInvalidExport)
+	}
+
+	var items []Item
+	for _, list := range lists {
+		for _, task := range list.Tasks {
+			item := Item{
+				ExternalID: task.ID,
+				Project:    list.DisplayName,
+				Title:      strings.TrimSpace(task.Title),
+				Labels:     task.Categories,
+				Completed:  strings.EqualFold(task.Status, "completed"),
+			}
+			if task.Body != nil {
+				item.Description = strings.TrimSpace(task.Body.Content)
+			}
+			switch strings.ToLower(task.Importance) {
+			case "high":
+				item.Priority = todo.PriorityHigh
+			case "low":
+				item.Priority = todo.PriorityLow
+			}
+			if task.DueDateTime != nil {
+				item.DueDate, _ = parseDate(task.DueDateTime.DateTime, location(task.DueDateTime.TimeZone))
+			}
+			items = append(items, item)
+		}
+	}
+
+	return items, nil
+}
@@ -0,0 +1,119 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+// Parse reads an export of the given source. Todoist exports may be CSV (one file per
+// project, named after it) or JSON; Trello and Microsoft To Do exports are JSON.
+func Parse(source string, r io.Reader, filename string) ([]Item, error) {
+	br := bufio.NewReader(r)
+	asJSON, err := looksLikeJSON(br)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidExport, err)
+	}
+
+	switch source {
+	case SourceTodoist:
+		if asJSON {
+			return parseTodoistJSON(br)
+		}
+		return parseTodoistCSV(br, projectFromFilename(filename))
+	case SourceTrello:
+		return parseTrello(br)
+	case SourceMicrosoftToDo:
+		return parseMicrosoftToDo(br)
+	default:
+		return nil, ErrUnknownSource
+	}
+}
+
+// looksLikeJSON peeks at the first significant byte without consuming it.
+func looksLikeJSON(br *bufio.Reader) (bool, error) {
+	for {
+		b, err := br.Peek(1)
+		if err == io.EOF {
+			return false, errors.New("empty file")
+		}
+		if err != nil {
+			return false, err
+		}
+		switch b[0] {
+		case ' ', '\t', '\r', '\n':
+			_, _ = br.ReadByte()
+		case 0xEF:
+			// UTF-8 byte order mark, as written by spreadsheet tools.
+			bom, err := br.Peek(3)
+			if err == nil && bytes.Equal(bom, []byte{0xEF, 0xBB, 0xBF}) {
+				_, _ = br.Discard(3)
+				continue
+			}
+			return false, nil
+		default:
+			return b[0] == '{' || b[0] == '[', nil
+		}
+	}
+}
+
+func decodeJSON(r io.Reader, v any) error {
+	if err := json.NewDecoder(r).Decode(v); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidExport, err)
+	}
+	return nil
+}
+
+func projectFromFilename(filename string) string {
+	name := strings.TrimSuffix(path.Base(filename), path.Ext(filename))
+	if name == "" || name == "." || name == "/" {
+		return ""
+	}
+	return name
+}
+
+// parseDate accepts the date forms used by the supported exports. Values without
+// a zone are interpreted in loc.
+func parseDate(raw string, loc *time.Location) (*time.Time, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, true
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		t = t.UTC()
+		return &t, true
+	}
+	for _, layout := range []string{"2006-01-02T15:04:05.9999999", "2006-01-02T15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, raw, loc); err == nil {
+			t = t.UTC()
+			return &t, true
+		}
+	}
+	return nil, false
+}
+
+// flexibleID accepts ids encoded as JSON strings or numbers; older Todoist exports use numbers.
+type flexibleID string
+
+func (id *flexibleID) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		*id = flexibleID(s)
+		return nil
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*id = flexibleID(n.String())
+	return nil
+}
@@ -0,0 +1,127 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"overengineeredtodo/internal/todo"
+)
+
+func TestParseTodoistJSON(t *testing.T) {
+	input := `{
+		"projects": [{"id": "p1", "name": "Work"}],
+		"items": [
+			{"id": "1", "project_id": "p1", "content": "Ship release", "priority": 4, "labels": ["urgent"],
+			 "due": {"date": "2025-06-01T10:00:00", "timezone": "Europe/Berlin"}},
+			{"id": 2, "project_id": "p1", "content": "Done already", "priority": 1, "checked": true, "due": null},
+			{"id": "3", "project_id": "p1", "content": "Gone", "is_deleted": true}
+		]
+	}`
+
+	items, err := Parse(SourceTodoist, strings.NewReader(input), "")
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	require.Equal(t, "1", items[0].ExternalID)
+	require.Equal(t, "Work", items[0].Project)
+	require.Equal(t, todo.PriorityHigh, items[0].Priority)
+	require.Equal(t, []string{"urgent"}, items[0].Labels)
+	require.Equal(t, time.Date(2025, 6, 1, 8, 0, 0, 0, time.UTC), *items[0].DueDate)
+
+	require.Equal(t, "2", items[1].ExternalID)
+	require.True(t, items[1].Completed)
+	require.Equal(t, todo.PriorityNone, items[1].Priority)
+	require.Nil(t, items[1].DueDate)
+}
+
+func TestParseTodoistCSV(t *testing.T) {
+	input := "\ufeffTYPE,CONTENT,DESCRIPTION,PRIORITY,INDENT,AUTHOR,RESPONSIBLE,DATE,DATE_LANG,TIMEZONE\n" +
+		"section,Backlog,,,,,,,,\n" +
+		"task,Buy milk @errands @home,,2,1,,,2025-06-01,en,UTC\n" +
+		"task,Buy milk @errands,,1,1,,,every day,en,UTC\n"
+
+	items, err := Parse(SourceTodoist, strings.NewReader(input), "exports/Groceries.csv")
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	require.Equal(t, "Groceries", items[0].Project)
+	require.Equal(t, "Buy milk", items[0].Title)
+	require.Equal(t, []string{"errands", "home"}, items[0].Labels)
+	require.Equal(t, todo.PriorityMedium, items[0].Priority)
+	require.NotNil(t, items[0].DueDate)
+
+	// Identical tasks stay distinct, and recurring dates are not imported.
+	require.NotEqual(t, items[0].ExternalID, items[1].ExternalID)
+	require.Nil(t, items[1].DueDate)
+	require.Equal(t, todo.PriorityHigh, items[1].Priority)
+}
+
+func TestParseTrello(t *testing.T) {
+	input := `{
+		"name": "Launch",
+		"lists": [{"id": "l1", "name": "Doing"}, {"id": "l2", "name": "Done"}, {"id": "l3", "name": "Old", "closed": true}],
+		"cards": [
+			{"id": "c1", "name": "Write copy", "desc": "landing page", "due": "2025-06-01T10:00:00.000Z", "idList": "l1",
+			 "labels": [{"name": "marketing", "color": "green"}, {"name": "", "color": "red"}]},
+			{"id": "c2", "name": "Pick name", "idList": "l2"},
+			{"id": "c3", "name": "Archived", "idList": "l1", "closed": true},
+			{"id": "c4", "name": "On archived list", "idList": "l3"}
+		]
+	}`
+
+	items, err := Parse(SourceTrello, strings.NewReader(input), "board.json")
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	require.Equal(t, Item{
+		ExternalID:  "c1",
+		Project:     "Launch",
+		Title:       "Write copy",
+		Description: "landing page",
+		Labels:      []string{"marketing", "red"},
+		DueDate:     ptrTo(time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)),
+	}, items[0])
+	require.True(t, items[1].Completed)
+}
+
+func TestParseMicrosoftToDo(t *testing.T) {
+	input := `{"value": [{"id": "list", "displayName": "Tasks", "tasks": [
+		{"id": "t1", "title": "Renew passport", "importance": "high", "status": "notStarted",
+		 "body": {"content": "bring photos", "contentType": "text"}, "categories": ["Admin"],
+		 "dueDateTime": {"dateTime": "2025-06-01T00:00:00.0000000", "timeZone": "UTC"}},
+		{"id": "t2", "title": "Call mum", "importance": "normal", "status": "completed"}
+	]}]}`
+
+	items, err := Parse(SourceMicrosoftToDo, strings.NewReader(input), "")
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+
+	require.Equal(t, "Tasks", items[0].Project)
+	require.Equal(t, todo.PriorityHigh, items[0].Priority)
+	require.Equal(t, "bring photos", items[0].Description)
+	require.Equal(t, []string{"Admin"}, items[0].Labels)
+	require.Equal(t, time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC), *items[0].DueDate)
+	require.True(t, items[1].Completed)
+	require.Equal(t, todo.PriorityNone, items[1].Priority)
+}
+
+func TestParseRejectsInvalidExports(t *testing.T) {
+	_, err := Parse(SourceTrello, strings.NewReader("{not json"), "")
+	require.ErrorIs(t, err, ErrInvalidExport)
+
+	_, err = Parse(SourceMicrosoftToDo, strings.NewReader(`{"lists": []}`), "")
+	require.ErrorIs(t, err, ErrInvalidExport)
+
+	_, err = Parse(SourceTodoist, strings.NewReader("TYPE,TITLE\ntask,x\n"), "x.csv")
+	require.ErrorIs(t, err, ErrInvalidExport)
+
+	_, err = Parse("asana", strings.NewReader("{}"), "")
+	require.ErrorIs(t, err, ErrUnknownSource)
+}
+
+func ptrTo[T any](v T) *T {
+	return &v
+}
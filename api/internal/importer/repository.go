@@ -0,0 +1,136 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const jobColumns = `id, user_id, source, status, total, processed, created, updated, unchanged, skipped, error, created_at, updated_at, finished_at`
+
+// Repository persists import jobs.
+type Repository struct {
+	pool pgxPool
+}
+
+type pgxPool interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// NewRepository constructs a Repository backed by the supplied pgx pool.
+func NewRepository(pool pgxPool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// Create records a queued job for total items.
+func (r *Repository) Create(ctx context.Context, userID uuid.UUID, source string, total int) (Job, error) {
+	query := `
+		INSERT INTO import_jobs (id, user_id, source, status, total)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + jobColumns
+
+	job, err := scanJob(r.pool.QueryRow(ctx, query, uuid.New(), userID, source, StatusQueued, total))
+	if err != nil {
+		return Job{}, fmt.Errorf("insert import job: %w", err)
+	}
+
+	return job, nil
+}
+
+// Get fetches a job of the user by id.
+func (r *Repository) Get(ctx context.Context, id, userID uuid.UUID) (Job, error) {
+	query := `
+		SELECT ` + jobColumns + `
+		FROM import_jobs
+		WHERE id = $1 AND user_id = $2
+	`
+
+	job, err := scanJob(r.pool.QueryRow(ctx, query, id, userID))
+
+	switch {
+	case err == nil:
+		return job, nil
+	case err == pgx.ErrNoRows:
+		return Job{}, ErrNotFound
+	default:
+		return Job{}, fmt.Errorf("select import job: %w", err)
+	}
+}
+
+// SaveProgress stores the status and counters of a job.
+func (r *Repository) SaveProgress(ctx context.Context, job Job) error {
+	query := `
+		UPDATE import_jobs
+		SET status = $2, processed = $3, created = $4, updated = $5, unchanged = $6, skipped = $7,
+		    error = $8, finished_at = $9, updated_at = current_timestamp
+		WHERE id = $1
+	`
+
+	_, err := r.pool.Exec(ctx, query,
+		job.ID,
+		job.Status,
+		job.Processed,
+		job.Created,
+		job.Updated,
+		job.Unchanged,
+		job.Skipped,
+		job.Error,
+		job.FinishedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("update import job: %w", err)
+	}
+	return nil
+}
+
+// Touch marks the jobs as still being worked on.
+func (r *Repository) Touch(ctx context.Context, ids []uuid.UUID) error {
+	query := `UPDATE import_jobs SET updated_at = current_timestamp WHERE id = ANY($1) AND finished_at IS NULL`
+
+	if _, err := r.pool.Exec(ctx, query, ids); err != nil {
+		return fmt.Errorf("touch import jobs: %w", err)
+	}
+	return nil
+}
+
+// FailStale fails the unfinished jobs that have not been updated since before and
+// reports how many there were.
+func (r *Repository) FailStale(ctx context.Context, before time.Time, message string) (int64, error) {
+	query := `
+		UPDATE import_jobs
+		SET status = $2, error = $3, finished_at = current_timestamp, updated_at = current_timestamp
+		WHERE finished_at IS NULL AND updated_at < $1
+	`
+
+	tag, err := r.pool.Exec(ctx, query, before, StatusFailed, message)
+	if err != nil {
+		return 0, fmt.Errorf("fail stale import jobs: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func scanJob(row pgx.Row) (Job, error) {
+	var job Job
+	err := row.Scan(
+		&job.ID,
+		&job.UserID,
+		&job.Source,
+		&job.Status,
+		&job.Total,
+		&job.Processed,
+		&job.Created,
+		&job.Updated,
+		&job.Unchanged,
+		&job.Skipped,
+		&job.Error,
+		&job.CreatedAt,
+		&job.UpdatedAt,
+		&job.FinishedAt,
+	)
+	return job, err
+}
@@ -0,0 +1,75 @@
+package importer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+)
+
+var jobRowColumns = []string{
+	"id", "user_id", "source", "status", "total", "processed", "created", "updated", "unchanged", "skipped",
+	"error", "created_at", "updated_at", "finished_at",
+}
+
+func TestRepositoryCreate(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	userID := uuid.New()
+	id := uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery("INSERT INTO import_jobs").
+		WithArgs(pgxmock.AnyArg(), userID, SourceTodoist, StatusQueued, 12).
+		WillReturnRows(pgxmock.NewRows(jobRowColumns).AddRow(id, userID, SourceTodoist, StatusQueued, 12, 0, 0, 0, 0, 0, nil, now, now, nil))
+
+	job, err := repo.Create(context.Background(), userID, SourceTodoist, 12)
+	require.NoError(t, err)
+	require.Equal(t, id, job.ID)
+	require.Equal(t, StatusQueued, job.Status)
+	require.Equal(t, 12, job.Total)
+	require.Nil(t, job.FinishedAt)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryGetNotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	id, userID := uuid.New(), uuid.New()
+
+	mock.ExpectQuery("SELECT .* FROM import_jobs WHERE id = \\$1 AND user_id = \\$2").
+		WithArgs(id, userID).
+		WillReturnError(pgx.ErrNoRows)
+
+	_, err = repo.Get(context.Background(), id, userID)
+	require.ErrorIs(t, err, ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryFailStale(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	before := time.Now().Add(-staleAfter)
+
+	mock.ExpectExec("UPDATE import_jobs SET status = \\$2, .* WHERE finished_at IS NULL AND updated_at < \\$1").
+		WithArgs(before, StatusFailed, interruptedMessage).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+
+	failed, err := repo.FailStale(context.Background(), before, interruptedMessage)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, failed)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
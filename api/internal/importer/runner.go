@@ -0,0 +1,271 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"overengineeredtodo/internal/project"
+	"overengineeredtodo/internal/todo"
+)
+
+// chunkSize is the number of items handled between two progress updates.
+const chunkSize = 200
+
+// heartbeatInterval is how often a runner marks its imports as alive and looks for jobs
+// interrupted by a restart.
+const heartbeatInterval = time.Minute
+
+// staleAfter is how long an unfinished job may go without an update before it is
+// considered interrupted.
+const staleAfter = 5 * time.Minute
+
+// interruptedMessage is the error of jobs whose replica stopped before they finished.
+const interruptedMessage = "import was interrupted"
+
+// Runner executes imports in the background and records their progress on the job.
+type Runner struct {
+	jobs     *Repository
+	todos    *todo.Repository
+	projects *project.Repository
+	logger   *slog.Logger
+	wg       sync.WaitGroup
+
+	mu     sync.Mutex
+	active map[uuid.UUID]bool
+}
+
+// NewRunner constructs a Runner writing through the supplied repositories.
+func NewRunner(jobs *Repository, todos *todo.Repository, projects *project.Repository, logger *slog.Logger) *Runner {
+	return &Runner{jobs: jobs, todos: todos, projects: projects, logger: logger, active: make(map[uuid.UUID]bool)}
+}
+
+// Start records a job for the items and imports them in the background. The import
+// outlives the request that started it.
+func (r *Runner) Start(ctx context.Context, userID uuid.UUID, source string, items []Item) (Job, error) {
+	job, err := r.jobs.Create(ctx, userID, source, len(items))
+	if err != nil {
+		return Job{}, err
+	}
+
+	r.mu.Lock()
+	r.active[job.ID] = true
+	r.mu.Unlock()
+
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer func() {
+			r.mu.Lock()
+			delete(r.active, job.ID)
+			r.mu.Unlock()
+		}()
+		r.run(context.WithoutCancel(ctx), job, items)
+	}()
+
+	return job, nil
+}
+
+// Recover keeps the runner's imports alive and fails the jobs that no replica is running
+// any more, such as those of a replica that restarted, until ctx is cancelled.
+func (r *Runner) Recover(ctx context.Context) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(heartbeatInterval)
+		defer ticker.Stop()
+		for {
+			if err := r.heartbeat(ctx); err != nil && ctx.Err() == nil {
+				r.logger.Error("import heartbeat failed", slog.String("error", err.Error()))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Wait blocks until all running imports and a started recovery have finished.
+func (r *Runner) Wait() {
+	r.wg.Wait()
+}
+
+// heartbeat touches the jobs running here and fails the unfinished jobs that nobody has
+// touched for staleAfter.
+func (r *Runner) heartbeat(ctx context.Context) error {
+	r.mu.Lock()
+	ids := make([]uuid.UUID, 0, len(r.active))
+	for id := range r.active {
+		ids = append(ids, id)
+	}
+	r.mu.Unlock()
+
+	if len(ids) > 0 {
+		if err := r.jobs.Touch(ctx, ids); err != nil {
+			return err
+		}
+	}
+
+	failed, err := r.jobs.FailStale(ctx, time.Now().Add(-staleAfter), interruptedMessage)
+	if err != nil {
+		return err
+	}
+	if failed > 0 {
+		r.logger.Warn("failed interrupted imports", slog.Int64("jobs", failed))
+	}
+	return nil
+}
+
+func (r *Runner) run(ctx context.Context, job Job, items []Item) {
+	job.Status = StatusRunning
+	r.save(ctx, job)
+
+	err := r.importItems(ctx, &job, items)
+
+	finished := time.Now().UTC()
+	job.FinishedAt = &finished
+	job.Status = StatusSucceeded
+	if err != nil {
+		message := err.Error()
+		job.Status = StatusFailed
+		job.Error = &message
+	}
+	r.save(ctx, job)
+}
+
+// importItems creates todos for new items and updates the ones imported before from
+// the same source. Items without a title and repeated ids are skipped.
+func (r *Runner) importItems(ctx context.Context, job *Job, items []Item) error {
+	existing, err := r.todos.ListExternal(ctx, job.UserID, job.Source)
+	if err != nil {
+		return err
+	}
+
+	projects := make(map[string]uuid.UUID)
+	seen := make(map[string]bool, len(items))
+
+	for start := 0; start < len(items); start += chunkSize {
+		end := min(start+chunkSize, len(items))
+
+		var creates []todo.CreateInput
+		for _, item := range items[start:end] {
+			if item.Title == "" || item.ExternalID == "" || seen[item.ExternalID] {
+				job.Skipped++
+				continue
+			}
+			seen[item.ExternalID] = true
+
+			projectID, err := r.projectID(ctx, job.UserID, item.Project, projects)
+			if err != nil {
+				return err
+			}
+			item.Labels = todo.NormalizeLabels(item.Labels)
+
+			if current, ok := existing[item.ExternalID]; ok {
+				input := changes(item, projectID, current)
+				if input.IsEmpty() {
+					job.Unchanged++
+					continue
+				}
+				_, err := r.todos.Update(ctx, current.ID, input)
+				if errors.Is(err, todo.ErrInvalidTransition) || err == todo.ErrConflict {
+					// The project's workflow does not allow the change, so keep the todo as it is.
+					job.Skipped++
+					continue
+				}
+				if err != nil {
+					return err
+				}
+				job.Updated++
+				continue
+			}
+
+			creates = append(creates, todo.CreateInput{
+				UserID:      job.UserID,
+				Title:       item.Title,
+				Description: item.Description,
+				DueDate:     item.DueDate,
+				Completed:   item.Completed,
+				ProjectID:   projectID,
+				Labels:      item.Labels,
+				Priority:    item.Priority,
+				External:    &todo.ExternalRef{Source: job.Source, ID: item.ExternalID},
+			})
+		}
+
+		if len(creates) > 0 {
+			if _, err := r.todos.CreateBatch(ctx, creates); err != nil {
+				return err
+			}
+			job.Created += len(creates)
+		}
+
+		job.Processed = end
+		r.save(ctx, *job)
+	}
+
+	return nil
+}
+
+// projectID resolves a project name to the user's project, creating it on first use.
+func (r *Runner) projectID(ctx context.Context, userID uuid.UUID, name string, cache map[string]uuid.UUID) (*uuid.UUID, error) {
+	if name == "" {
+		return nil, nil
+	}
+	if id, ok := cache[name]; ok {
+		return &id, nil
+	}
+
+	p, err := r.projects.Ensure(ctx, userID, name)
+	if err != nil {
+		return nil, err
+	}
+	cache[name] = p.ID
+	return &p.ID, nil
+}
+
+// save persists progress. A failure only loses progress information, so it is logged.
+func (r *Runner) save(ctx context.Context, job Job) {
+	if err := r.jobs.SaveProgress(ctx, job); err != nil {
+		r.logger.Error("failed to save import progress", slog.String("job_id", job.ID.String()), slog.String("error", err.Error()))
+	}
+}
+
+// changes returns the update needed to bring a previously imported todo in line with item.
+// Todos are only moved between projects when the item names one.
+func changes(item Item, projectID *uuid.UUID, existing todo.Todo) todo.UpdateInput {
+	var input todo.UpdateInput
+	if item.Title != existing.Title {
+		input.Title = &item.Title
+	}
+	if item.Description != existing.Description {
+		input.Description = &item.Description
+	}
+	if item.Completed != existing.Completed {
+		input.Completed = &item.Completed
+	}
+	switch {
+	case item.DueDate == nil && existing.DueDate != nil:
+		input.ClearDueDate = true
+	case item.DueDate != nil && (existing.DueDate == nil || !item.DueDate.Equal(*existing.DueDate)):
+		input.DueDate = item.DueDate
+	}
+	if !slices.Equal(item.Labels, existing.Labels) {
+		input.Labels = &item.Labels
+	}
+	if item.Priority != existing.Priority {
+		input.Priority = &item.Priority
+	}
+	if projectID != nil && (existing.ProjectID == nil || *existing.ProjectID != *projectID) {
+		input.ProjectID = projectID
+	}
+	return input
+}
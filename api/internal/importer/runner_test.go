@@ -0,0 +1,100 @@
+package importer
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+
+	"overengineeredtodo/internal/customfield"
+	"overengineeredtodo/internal/project"
+	"overengineeredtodo/internal/todo"
+	"overengineeredtodo/internal/todo/todotest"
+	"overengineeredtodo/internal/workflow"
+)
+
+func TestRunnerDeduplicatesReimports(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	runner := NewRunner(NewRepository(mock), todo.NewRepository(mock), project.NewRepository(mock), slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	userID := uuid.New()
+	projectID := uuid.New()
+	now := time.Now()
+	job := Job{ID: uuid.New(), UserID: userID, Source: SourceTrello, Status: StatusQueued, Total: 4}
+
+	unchanged := todo.Todo{ID: uuid.New(), UserID: userID, Title: "Same", ProjectID: &projectID, Labels: []string{}, CreatedAt: now, UpdatedAt: now}
+	renamed := todo.Todo{ID: uuid.New(), UserID: userID, Title: "Old title", ProjectID: &projectID, Labels: []string{}, CreatedAt: now, UpdatedAt: now}
+
+	items := []Item{
+		{ExternalID: "c1", Project: "Launch", Title: "Same"},
+		{ExternalID: "c2", Project: "Launch", Title: "New title"},
+		{ExternalID: "c2", Project: "Launch", Title: "Duplicate in file"},
+		{ExternalID: "c3", Project: "Launch"},
+	}
+
+	mock.ExpectExec("UPDATE import_jobs").
+		WithArgs(job.ID, StatusRunning, 0, 0, 0, 0, 0, (*string)(nil), (*time.Time)(nil)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	existing := pgxmock.NewRows(append([]string{"external_id"}, todotest.Columns...))
+	for externalID, t := range map[string]todo.Todo{"c1": unchanged, "c2": renamed} {
+		existing.AddRow(externalID, t.ID, t.UserID, t.Title, t.Description, t.DueDate, t.Completed, t.CreatedAt, t.UpdatedAt,
+			t.ICalUID, 1, t.ProjectID, t.Labels, t.Priority, t.CompletedAt, t.Status, t.CustomFields, t.ParentID, t.Recurrence, t.AssigneeID, t.EstimateMinutes, t.StartDate, t.SnoozedUntil)
+	}
+	mock.ExpectQuery("SELECT external_id, .* FROM todos WHERE user_id = \\$1 AND external_source = \\$2").
+		WithArgs(userID, SourceTrello).
+		WillReturnRows(existing)
+
+	mock.ExpectQuery("INSERT INTO projects").
+		WithArgs(pgxmock.AnyArg(), userID, "Launch").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "name", "workflow", "custom_fields", "created_at", "updated_at"}).AddRow(projectID, userID, "Launch", nil, customfield.Schema{}, now, now))
+
+	updated := renamed
+	updated.Title = "New title"
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE todos SET title = \\$1").
+		WithArgs("New title", renamed.ID).
+		WillReturnRows(pgxmock.NewRows(todotest.Columns).AddRow(updated.ID, userID, updated.Title, "", nil, false, now, now, nil, 2, &projectID, []string{}, 0, nil, workflow.StatusBacklog, customfield.Values{}, nil, "", nil, nil, nil, nil))
+	mock.ExpectExec("INSERT INTO outbox_events").
+		WithArgs(pgxmock.AnyArg(), "TodoUpdated", 1, "todo", updated.ID, int64(2), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+
+	mock.ExpectExec("UPDATE import_jobs").
+		WithArgs(job.ID, StatusRunning, 4, 0, 1, 1, 2, (*string)(nil), (*time.Time)(nil)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec("UPDATE import_jobs").
+		WithArgs(job.ID, StatusSucceeded, 4, 0, 1, 1, 2, (*string)(nil), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	runner.run(context.Background(), job, items)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRunnerHeartbeatKeepsOwnJobsAndFailsStaleOnes(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	runner := NewRunner(NewRepository(mock), todo.NewRepository(mock), project.NewRepository(mock), slog.New(slog.NewTextHandler(io.Discard, nil)))
+	running := uuid.New()
+	runner.active[running] = true
+
+	mock.ExpectExec("UPDATE import_jobs SET updated_at = current_timestamp WHERE id = ANY\\(\\$1\\)").
+		WithArgs([]uuid.UUID{running}).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec("UPDATE import_jobs SET status = \\$2, .* WHERE finished_at IS NULL AND updated_at < \\$1").
+		WithArgs(pgxmock.AnyArg(), StatusFailed, interruptedMessage).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	require.NoError(t, runner.heartbeat(context.Background()))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,194 @@
+package importer
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"overengineeredtodo/internal/todo"
+)
+
+const todoistDefaultProject = "Todoist"
+
+// todoistExport is the subset of a Todoist sync/backup JSON document that is imported.
+type todoistExport struct {
+	Projects []struct {
+		ID   flexibleID `json:"id"`
+		Name string     `json:"name"`
+	} `json:"projects"`
+	Items []struct {
+		ID          flexibleID `json:"id"`
+		ProjectID   flexibleID `json:"project_id"`
+		Content     string     `json:"content"`
+		Description string     `json:"description"`
+		Priority    int        `json:"priority"`
+		Labels      []string   `json:"labels"`
+		Checked     bool       `json:"checked"`
+		IsDeleted   bool       `json:"is_deleted"`
+		Due         *struct {
+			Date     string `json:"date"`
+			Timezone string `json:"timezone"`
+		} `json:"due"`
+	} `json:"items"`
+}
+
+func parseTodoistJSON(r io.Reader) ([]Item, error) {
+	var export todoistExport
+	if err := decodeJSON(r, &export); err != nil {
+		return nil, err
+	}
+
+	projects := make(map[flexibleID]string, len(export.Projects))
+	for _, p := range export.Projects {
+		projects[p.ID] = p.Name
+	}
+
+	items := make([]Item, 0, len(export.Items))
+	for _, task := range export.Items {
+		if task.IsDeleted {
+			continue
+		}
+
+		item := Item{
+			ExternalID:  string(task.ID),
+			Project:     projects[task.ProjectID],
+			Title:       strings.TrimSpace(task.Content),
+			Description: task.Description,
+			Labels:      task.Labels,
+			Priority:    todoistAPIPriority(task.Priority),
+			Completed:   task.Checked,
+		}
+		if task.Due != nil {
+			item.DueDate, _ = parseDate(task.Due.Date, location(task.Due.Timezone))
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
+
+// todoistAPIPriority maps the API scale, where 4 is the most urgent (shown as p1).
+func todoistAPIPriority(priority int) int {
+	switch priority {
+	case 4:
+		return todo.PriorityHigh
+	case 3:
+		return todo.PriorityMedium
+	case 2:
+		return todo.PriorityLow
+	default:
+		return todo.PriorityNone
+	}
+}
+
+// todoistCSVPriority maps the CSV template scale, where 1 is the most urgent.
+func todoistCSVPriority(priority int) int {
+	switch priority {
+	case 1:
+		return todo.PriorityHigh
+	case 2:
+		return todo.PriorityMedium
+	case 3:
+		return todo.PriorityLow
+	default:
+		return todo.PriorityNone
+	}
+}
+
+// parseTodoistCSV reads a project exported with Todoist's CSV template. The format has
+// no task ids, so tasks are identified by project and content; labels are written
+// inline as @label.
+func parseTodoistCSV(r io.Reader, project string) ([]Item, error) {
+	if project == "" {
+		project = todoistDefaultProject
+	}
+
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: read csv header: %v", ErrInvalidExport, err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToUpper(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["CONTENT"]; !ok {
+		return nil, fmt.Errorf("%w: missing CONTENT column", ErrInvalidExport)
+	}
+
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	var items []Item
+	occurrences := make(map[string]int)
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidExport, err)
+		}
+		if kind := field(record, "TYPE"); kind != "" && !strings.EqualFold(kind, "task") {
+			continue
+		}
+
+		title, labels := splitInlineLabels(field(record, "CONTENT"))
+		priority, _ := strconv.Atoi(field(record, "PRIORITY"))
+		due, _ := parseDate(field(record, "DATE"), location(field(record, "TIMEZONE")))
+
+		key := project + "\x00" + title
+		occurrences[key]++
+		sum := sha256.Sum256([]byte(key + "\x00" + strconv.Itoa(occurrences[key])))
+
+		items = append(items, Item{
+			ExternalID:  "csv:" + hex.EncodeToString(sum[:12]),
+			Project:     project,
+			Title:       title,
+			Description: field(record, "DESCRIPTION"),
+			Labels:      labels,
+			Priority:    todoistCSVPriority(priority),
+			DueDate:     due,
+		})
+	}
+
+	return items, nil
+}
+
+// splitInlineLabels removes @label tokens from content and returns them separately.
+func splitInlineLabels(content string) (string, []string) {
+	var (
+		words  []string
+		labels []string
+	)
+	for _, word := range strings.Fields(content) {
+		if len(word) > 1 && word[0] == '@' {
+			labels = append(labels, word[1:])
+			continue
+		}
+		words = append(words, word)
+	}
+	return strings.Join(words, " "), labels
+}
+
+func location(name string) *time.Location {
+	if name == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
@@ -0,0 +1,78 @@
+package importer
+
+import (
+	"io"
+	"strings"
+	"time"
+)
+
+// trelloBoard is the subset of a Trello board JSON export that is imported.
+type trelloBoard struct {
+	Name  string `json:"name"`
+	Lists []struct {
+		ID     string `json:"id"`
+		Name   string `json:"name"`
+		Closed bool   `json:"closed"`
+	} `json:"lists"`
+	Cards []struct {
+		ID          string  `json:"id"`
+		Name        string  `json:"name"`
+		Desc        string  `json:"desc"`
+		Due         *string `json:"due"`
+		DueComplete bool    `json:"dueComplete"`
+		Closed      bool    `json:"closed"`
+		IDList      string  `json:"idList"`
+		Labels      []struct {
+			Name  string `json:"name"`
+			Color string `json:"color"`
+		} `json:"labels"`
+	} `json:"cards"`
+}
+
+// parseTrello imports the cards of a board into a project named after it. Archived
+// cards and cards on archived lists are left out. A card counts as completed when
+// its due date is marked complete or it sits on a list called "Done".
+func parseTrello(r io.Reader) ([]Item, error) {
+	var board trelloBoard
+	if err := decodeJSON(r, &board); err != nil {
+		return nil, err
+	}
+
+	type list struct {
+		name   string
+		closed bool
+	}
+	lists := make(map[string]list, len(board.Lists))
+	for _, l := range board.Lists {
+		lists[l.ID] = list{name: l.Name, closed: l.Closed}
+	}
+
+	items := make([]Item, 0, len(board.Cards))
+	for _, card := range board.Cards {
+		l := lists[card.IDList]
+		if card.Closed || l.closed {
+			continue
+		}
+
+		item := Item{
+			ExternalID:  card.ID,
+			Project:     board.Name,
+			Title:       strings.TrimSpace(card.Name),
+			Description: card.Desc,
+			Completed:   card.DueComplete || strings.EqualFold(strings.TrimSpace(l.name), "done"),
+		}
+		for _, label := range card.Labels {
+			if label.Name != "" {
+				item.Labels = append(item.Labels, label.Name)
+			} else if label.Color != "" {
+				item.Labels = append(item.Labels, label.Color)
+			}
+		}
+		if card.Due != nil {
+			item.DueDate, _ = parseDate(*card.Due, time.UTC)
+		}
+		items = append(items, item)
+	}
+
+	return items, nil
+}
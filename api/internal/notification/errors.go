@@ -0,0 +1,6 @@
+package notification
+
+import "errors"
+
+// ErrNotFound indicates the requested notification could not be located.
+var ErrNotFound = errors.New("notification not found")
@@ -0,0 +1,73 @@
+package notification
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 200
+)
+
+// RegisterRoutes wires the notification HTTP handlers to a sub-router.
+func RegisterRoutes(router *gin.RouterGroup, repo *Repository) {
+	handler := &Handler{repo: repo}
+
+	router.GET("", handler.listNotifications)
+	router.PATCH("/:id/read", handler.markRead)
+}
+
+// Handler exposes HTTP endpoints for notifications.
+type Handler struct {
+	repo *Repository
+}
+
+func (h *Handler) listNotifications(c *gin.Context) {
+	userID, err := uuid.Parse(c.Query("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+		return
+	}
+
+	limit := defaultListLimit
+	if raw := c.Query("limit"); raw != "" {
+		if parsed, err := strconv.Atoi(raw); err == nil && parsed > 0 {
+			limit = min(parsed, maxListLimit)
+		}
+	}
+
+	notifications, err := h.repo.ListByUser(c.Request.Context(), userID, c.Query("unread") == "true", limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, notifications)
+}
+
+func (h *Handler) markRead(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID, err := uuid.Parse(c.Query("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+		return
+	}
+
+	n, err := h.repo.MarkRead(c.Request.Context(), id, userID)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, n)
+	case err == ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "notification not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
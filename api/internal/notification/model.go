@@ -0,0 +1,28 @@
+package notification
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of notifications.
+const (
+	// KindMention tells a user they were mentioned in a comment.
+	KindMention = "mention"
+	// KindAssignment tells a user a todo was assigned to them.
+	KindAssignment = "assignment"
+)
+
+// Notification tells a user about something that happened on a todo.
+type Notification struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	Kind      string     `json:"kind"`
+	TodoID    uuid.UUID  `json:"todo_id"`
+	CommentID *uuid.UUID `json:"comment_id,omitempty"`
+	// ActorID is the user who caused the notification.
+	ActorID   uuid.UUID  `json:"actor_id"`
+	CreatedAt time.Time  `json:"created_at"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+}
@@ -0,0 +1,109 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const notificationColumns = `id, user_id, kind, todo_id, comment_id, actor_id, created_at, read_at`
+
+// Repository provides Cockroach-backed persistence for notifications.
+type Repository struct {
+	pool pgxPool
+}
+
+type pgxPool interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// Execer is satisfied by pools and transactions, so notifications can be written in the
+// transaction of the change that causes them.
+type Execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// NewRepository constructs a repository around the supplied pgx pool.
+func NewRepository(pool pgxPool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// Insert writes the notifications through db.
+func Insert(ctx context.Context, db Execer, notifications ...Notification) error {
+	for _, n := range notifications {
+		if n.ID == uuid.Nil {
+			n.ID = uuid.New()
+		}
+		if _, err := db.Exec(ctx, `
+			INSERT INTO notifications (id, user_id, kind, todo_id, comment_id, actor_id)
+			VALUES ($1, $2, $3, $4, $5, $6)`,
+			n.ID, n.UserID, n.Kind, n.TodoID, n.CommentID, n.ActorID); err != nil {
+			return fmt.Errorf("insert notification: %w", err)
+		}
+	}
+	return nil
+}
+
+// ListByUser returns the user's notifications, newest first, optionally only the unread.
+func (r *Repository) ListByUser(ctx context.Context, userID uuid.UUID, unreadOnly bool, limit int) ([]Notification, error) {
+	query := `
+		SELECT ` + notificationColumns + `
+		FROM notifications
+		WHERE user_id = $1
+		  AND ($2 = false OR read_at IS NULL)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, unreadOnly, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query notifications: %w", err)
+	}
+	defer rows.Close()
+
+	result := []Notification{}
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan notification: %w", err)
+		}
+		result = append(result, n)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate notifications: %w", rows.Err())
+	}
+
+	return result, nil
+}
+
+// MarkRead marks one of the user's notifications as read. Marking it again keeps the
+// first read time.
+func (r *Repository) MarkRead(ctx context.Context, id, userID uuid.UUID) (Notification, error) {
+	query := `
+		UPDATE notifications
+		SET read_at = COALESCE(read_at, now())
+		WHERE id = $1 AND user_id = $2
+		RETURNING ` + notificationColumns
+
+	n, err := scanNotification(r.pool.QueryRow(ctx, query, id, userID))
+	switch {
+	case err == nil:
+		return n, nil
+	case err == pgx.ErrNoRows:
+		return Notification{}, ErrNotFound
+	default:
+		return Notification{}, fmt.Errorf("mark notification read: %w", err)
+	}
+}
+
+func scanNotification(row pgx.Row) (Notification, error) {
+	var n Notification
+	err := row.Scan(&n.ID, &n.UserID, &n.Kind, &n.TodoID, &n.CommentID, &n.ActorID, &n.CreatedAt, &n.ReadAt)
+	return n, err
+}
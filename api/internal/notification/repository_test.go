@@ -0,0 +1,70 @@
+package notification
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+)
+
+var notificationRowColumns = []string{"id", "user_id", "kind", "todo_id", "comment_id", "actor_id", "created_at", "read_at"}
+
+func TestInsert(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	n := Notification{UserID: uuid.New(), Kind: KindMention, TodoID: uuid.New(), CommentID: ptrTo(uuid.New()), ActorID: uuid.New()}
+
+	mock.ExpectExec("INSERT INTO notifications").
+		WithArgs(pgxmock.AnyArg(), n.UserID, KindMention, n.TodoID, n.CommentID, n.ActorID).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	require.NoError(t, Insert(context.Background(), mock, n))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryListByUserUnread(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	userID := uuid.New()
+	n := Notification{ID: uuid.New(), UserID: userID, Kind: KindMention, TodoID: uuid.New(), ActorID: uuid.New(), CreatedAt: time.Now()}
+
+	mock.ExpectQuery("SELECT .* FROM notifications WHERE user_id = \\$1 AND \\(\\$2 = false OR read_at IS NULL\\)").
+		WithArgs(userID, true, 50).
+		WillReturnRows(pgxmock.NewRows(notificationRowColumns).
+			AddRow(n.ID, n.UserID, n.Kind, n.TodoID, (*uuid.UUID)(nil), n.ActorID, n.CreatedAt, (*time.Time)(nil)))
+
+	notifications, err := repo.ListByUser(context.Background(), userID, true, 50)
+	require.NoError(t, err)
+	require.Equal(t, []Notification{n}, notifications)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryMarkReadNotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	id, userID := uuid.New(), uuid.New()
+
+	mock.ExpectQuery("UPDATE notifications SET read_at = COALESCE\\(read_at, now\\(\\)\\)").
+		WithArgs(id, userID).
+		WillReturnError(pgx.ErrNoRows)
+
+	_, err = repo.MarkRead(context.Background(), id, userID)
+	require.ErrorIs(t, err, ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func ptrTo[T any](v T) *T {
+	return &v
+}
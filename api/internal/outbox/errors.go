@@ -0,0 +1,6 @@
+package outbox
+
+import "errors"
+
+// ErrNotFound indicates the requested event could not be located, or has been pruned.
+var ErrNotFound = errors.New("event not found")
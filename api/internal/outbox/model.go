@@ -0,0 +1,91 @@
+package outbox
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Aggregates whose changes are recorded.
+const (
+	AggregateTodo = "todo"
+	AggregateUser = "user"
+)
+
+// Event types. TodoCompleted takes the place of TodoUpdated for the change that completes
+// a todo, so every version of an aggregate is announced once.
+const (
+	TodoCreated   = "TodoCreated"
+	TodoUpdated   = "TodoUpdated"
+	TodoCompleted = "TodoCompleted"
+	TodoDeleted   = "TodoDeleted"
+	UserCreated   = "UserCreated"
+	UserDeleted   = "UserDeleted"
+)
+
+// SchemaVersion is the version of the payloads written by this build. It changes when a
+// payload changes incompatibly.
+const SchemaVersion = 1
+
+// Event is a change of a todo or user, recorded in the transaction of the change and
+// published afterwards by the Relay.
+type Event struct {
+	ID            uuid.UUID `json:"id"`
+	Type          string    `json:"type"`
+	SchemaVersion int       `json:"schema_version"`
+	AggregateType string    `json:"aggregate_type"`
+	AggregateID   uuid.UUID `json:"aggregate_id"`
+	// AggregateVersion grows with every change of the aggregate; events of one aggregate
+	// are published in this order.
+	AggregateVersion int64           `json:"aggregate_version"`
+	OccurredAt       time.Time       `json:"occurred_at"`
+	Payload          json.RawMessage `json:"payload"`
+	// Attempts counts failed deliveries.
+	Attempts int `json:"-"`
+}
+
+// NewEvent builds an event with payload encoded as JSON.
+func NewEvent(eventType, aggregateType string, aggregateID uuid.UUID, aggregateVersion int64, payload any) (Event, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Event{}, fmt.Errorf("encode %s payload: %w", eventType, err)
+	}
+	return Event{
+		ID:               uuid.New(),
+		Type:             eventType,
+		SchemaVersion:    SchemaVersion,
+		AggregateType:    aggregateType,
+		AggregateID:      aggregateID,
+		AggregateVersion: aggregateVersion,
+		OccurredAt:       time.Now().UTC(),
+		Payload:          data,
+	}, nil
+}
+
+// DeletedTodo is the payload of TodoDeleted events. The other todo events carry the todo
+// as the API returns it.
+type DeletedTodo struct {
+	ID        uuid.UUID  `json:"id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	ProjectID *uuid.UUID `json:"project_id,omitempty"`
+}
+
+// DeletedUser is the payload of UserDeleted events. UserCreated events carry the user as
+// the API returns it.
+type DeletedUser struct {
+	ID uuid.UUID `json:"id"`
+}
+
+// Cursor is a position in the order events occurred in. Events that occurred at the same
+// time are ordered by ID.
+type Cursor struct {
+	OccurredAt time.Time
+	ID         uuid.UUID
+}
+
+// Cursor returns the position of the event.
+func (e Event) Cursor() Cursor {
+	return Cursor{OccurredAt: e.OccurredAt, ID: e.ID}
+}
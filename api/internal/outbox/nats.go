@@ -0,0 +1,160 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// natsTimeout bounds connecting to the server and each publish when ctx has no deadline.
+const natsTimeout = 10 * time.Second
+
+// NATSSink publishes events to a NATS-compatible server using the NATS client protocol.
+// Events go to the subject <prefix>.<aggregate type>.<event type> and carry their ID as
+// Nats-Msg-Id, which JetStream streams use to drop redeliveries. A publish is confirmed
+// by a PING round trip, so the server has processed the message when Publish returns.
+type NATSSink struct {
+	addr   string
+	prefix string
+
+	mu   sync.Mutex
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// NewNATSSink returns a sink for the server at rawURL, such as nats://localhost:4222.
+func NewNATSSink(rawURL, prefix string) (*NATSSink, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || (u.Scheme != "nats" && u.Scheme != "tcp") {
+		return nil, fmt.Errorf("invalid NATS url %q", rawURL)
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "4222")
+	}
+	return &NATSSink{addr: addr, prefix: strings.TrimSuffix(prefix, ".")}, nil
+}
+
+// Subject returns the subject the event is published to.
+func (s *NATSSink) Subject(e Event) string {
+	return s.prefix + "." + e.AggregateType + "." + e.Type
+}
+
+// Publish sends the event and waits for the server to confirm it. A failed connection is
+// dropped and opened again by the next publish.
+func (s *NATSSink) Publish(ctx context.Context, e Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encode event: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.publish(ctx, e, data); err != nil {
+		s.closeConn()
+		return fmt.Errorf("publish to nats: %w", err)
+	}
+	return nil
+}
+
+// Close closes the connection to the server.
+func (s *NATSSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closeConn()
+	return nil
+}
+
+func (s *NATSSink) publish(ctx context.Context, e Event, data []byte) error {
+	if s.conn == nil {
+		if err := s.connect(ctx); err != nil {
+			return err
+		}
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(natsTimeout)
+	}
+	if err := s.conn.SetDeadline(deadline); err != nil {
+		return err
+	}
+
+	headers := "NATS/1.0\r\nNats-Msg-Id: " + e.ID.String() + "\r\n\r\n"
+	msg := fmt.Sprintf("HPUB %s %d %d\r\n%s%s\r\nPING\r\n", s.Subject(e), len(headers), len(headers)+len(data), headers, data)
+	if _, err := s.conn.Write([]byte(msg)); err != nil {
+		return err
+	}
+	return s.awaitPong()
+}
+
+// connect opens the connection and completes the handshake: the server introduces itself
+// with INFO, the client answers with CONNECT and a PING that the server acknowledges.
+func (s *NATSSink) connect(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: natsTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return err
+	}
+	if err := conn.SetDeadline(time.Now().Add(natsTimeout)); err != nil {
+		conn.Close()
+		return err
+	}
+	s.conn, s.r = conn, bufio.NewReader(conn)
+
+	line, err := s.readLine()
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		return fmt.Errorf("unexpected greeting %q", line)
+	}
+
+	if _, err := conn.Write([]byte("CONNECT {\"verbose\":false,\"pedantic\":false,\"headers\":true,\"name\":\"todo-outbox\"}\r\nPING\r\n")); err != nil {
+		return err
+	}
+	return s.awaitPong()
+}
+
+// awaitPong reads until the server answers the last PING, answering its own PINGs.
+func (s *NATSSink) awaitPong() error {
+	for {
+		line, err := s.readLine()
+		if err != nil {
+			return err
+		}
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := s.conn.Write([]byte("PONG\r\n")); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return errors.New(strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
+
+func (s *NATSSink) readLine() (string, error) {
+	line, err := s.r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (s *NATSSink) closeConn() {
+	if s.conn != nil {
+		_ = s.conn.Close()
+		s.conn, s.r = nil, nil
+	}
+}
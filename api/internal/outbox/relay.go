@@ -0,0 +1,197 @@
+package outbox
+
+import (
+	"cmp"
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// relayBatch is the number of events read per query.
+	relayBatch = 100
+	// retention is how long published events are kept before they are pruned.
+	retention = 7 * 24 * time.Hour
+	// relayLease names the lease that lets a single relay publish at a time.
+	relayLease = "relay"
+	// leaseTTL is how long the lease outlives its last renewal. A relay that stops
+	// renewing it, such as a crashed replica, is taken over after that.
+	leaseTTL = 30 * time.Second
+	// releaseTimeout bounds giving up the lease on shutdown.
+	releaseTimeout = 5 * time.Second
+	// maxAttempts is the number of failed attempts after which an event is given up.
+	maxAttempts = 10
+	// baseBackoff is the wait before the first retry of an event; every further retry
+	// waits twice as long, up to maxBackoff.
+	baseBackoff = 10 * time.Second
+	maxBackoff  = time.Hour
+)
+
+// Sink delivers events to other systems. Publish returns only once the event has been
+// accepted; events may be delivered more than once, so consumers deduplicate by ID.
+type Sink interface {
+	Publish(ctx context.Context, e Event) error
+}
+
+// Relay publishes recorded events to a sink. Delivery is at least once: an event is
+// marked published only after the sink accepted it. Events of one aggregate are
+// published in the order of their versions, and a failed event holds back the later
+// events of its aggregate until it has been delivered. Failed events are retried with
+// exponential backoff and given up after maxAttempts. Every replica may run a relay:
+// only the one holding the relay lease publishes, the others take over when it stops.
+type Relay struct {
+	id       uuid.UUID
+	repo     *Repository
+	sink     Sink
+	interval time.Duration
+	logger   *slog.Logger
+	wg       sync.WaitGroup
+}
+
+// NewRelay constructs a Relay that polls for events every interval.
+func NewRelay(repo *Repository, sink Sink, interval time.Duration, logger *slog.Logger) *Relay {
+	return &Relay{id: uuid.New(), repo: repo, sink: sink, interval: interval, logger: logger}
+}
+
+// Start publishes in the background until ctx is cancelled.
+func (r *Relay) Start(ctx context.Context) {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+
+		ticker := time.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			if _, err := r.Publish(ctx); err != nil && ctx.Err() == nil {
+				r.logger.Error("outbox relay failed", slog.String("error", err.Error()))
+			}
+			if _, err := r.repo.Prune(ctx, time.Now().Add(-retention)); err != nil && ctx.Err() == nil {
+				r.logger.Error("outbox prune failed", slog.String("error", err.Error()))
+			}
+			select {
+			case <-ctx.Done():
+				r.release(ctx)
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// release hands the lease on to the relay of another replica right away.
+func (r *Relay) release(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), releaseTimeout)
+	defer cancel()
+	if err := r.repo.Release(ctx, relayLease, r.id); err != nil {
+		r.logger.Error("failed to release outbox lease", slog.String("error", err.Error()))
+	}
+}
+
+// Wait blocks until a started relay has stopped.
+func (r *Relay) Wait() {
+	r.wg.Wait()
+}
+
+// Publish delivers pending events until none are left or a batch makes no progress, and
+// reports how many were delivered. It renews the relay lease before every batch and
+// publishes nothing while another relay holds it.
+func (r *Relay) Publish(ctx context.Context) (int, error) {
+	published := 0
+	for {
+		held, err := r.repo.Acquire(ctx, relayLease, r.id, leaseTTL)
+		if err != nil || !held {
+			return published, err
+		}
+
+		events, err := r.repo.Pending(ctx, relayBatch)
+		if err != nil {
+			return published, err
+		}
+		orderPerAggregate(events)
+
+		failed := make(map[aggregateKey]bool)
+		var delivered []uuid.UUID
+		for _, e := range events {
+			key := aggregateKey{e.AggregateType, e.AggregateID}
+			if failed[key] {
+				continue
+			}
+			if err := r.sink.Publish(ctx, e); err != nil {
+				failed[key] = true
+				r.logger.Warn("failed to publish event",
+					slog.String("event_id", e.ID.String()), slog.String("type", e.Type), slog.String("error", err.Error()))
+				if err := r.markFailed(ctx, e, err); err != nil {
+					return published, err
+				}
+				continue
+			}
+			delivered = append(delivered, e.ID)
+		}
+
+		if err := r.repo.MarkPublished(ctx, delivered); err != nil {
+			return published, err
+		}
+		published += len(delivered)
+
+		if len(events) < relayBatch || len(delivered) == 0 {
+			return published, nil
+		}
+	}
+}
+
+// markFailed schedules the next attempt of the event, or gives it up after its last.
+func (r *Relay) markFailed(ctx context.Context, e Event, cause error) error {
+	attempts := e.Attempts + 1
+	if attempts >= maxAttempts {
+		r.logger.Error("gave up publishing event",
+			slog.String("event_id", e.ID.String()), slog.String("type", e.Type), slog.Int("attempts", attempts))
+		return r.repo.MarkFailed(ctx, e.ID, cause, nil)
+	}
+	retryAt := time.Now().Add(backoff(attempts))
+	return r.repo.MarkFailed(ctx, e.ID, cause, &retryAt)
+}
+
+// backoff is the wait after the given number of failed attempts.
+func backoff(attempts int) time.Duration {
+	wait := baseBackoff
+	for i := 1; i < attempts && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, maxBackoff)
+}
+
+type aggregateKey struct {
+	typ string
+	id  uuid.UUID
+}
+
+// orderPerAggregate sorts the events of every aggregate by version, keeping the
+// positions the aggregate's events take in the batch. Events are read in the order they
+// occurred, which can disagree with their versions when clocks of database nodes differ.
+func orderPerAggregate(events []Event) {
+	positions := make(map[aggregateKey][]int)
+	for i, e := range events {
+		key := aggregateKey{e.AggregateType, e.AggregateID}
+		positions[key] = append(positions[key], i)
+	}
+
+	for _, indexes := range positions {
+		if len(indexes) < 2 {
+			continue
+		}
+		group := make([]Event, len(indexes))
+		for j, i := range indexes {
+			group[j] = events[i]
+		}
+		slices.SortStableFunc(group, func(a, b Event) int {
+			return cmp.Compare(a.AggregateVersion, b.AggregateVersion)
+		})
+		for j, i := range indexes {
+			events[i] = group[j]
+		}
+	}
+}
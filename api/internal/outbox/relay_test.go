@@ -0,0 +1,323 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSink remembers the events it accepted and fails the ones listed in fail.
+type recordingSink struct {
+	published []Event
+	fail      map[uuid.UUID]bool
+}
+
+func (s *recordingSink) Publish(_ context.Context, e Event) error {
+	if s.fail[e.ID] {
+		return errors.New("unavailable")
+	}
+	s.published = append(s.published, e)
+	return nil
+}
+
+// hookSink accepts every event and calls hook while publishing it.
+type hookSink struct {
+	hook func()
+}
+
+func (s *hookSink) Publish(context.Context, Event) error {
+	s.hook()
+	return nil
+}
+
+func newEventRows(events ...Event) *pgxmock.Rows {
+	rows := pgxmock.NewRows(strings.Split(eventColumns, ", "))
+	for _, e := range events {
+		rows.AddRow(e.ID, e.Type, e.SchemaVersion, e.AggregateType, e.AggregateID, e.AggregateVersion, e.OccurredAt, e.Payload, e.Attempts)
+	}
+	return rows
+}
+
+func testEvent(t *testing.T, eventType string, aggregateID uuid.UUID, version int64) Event {
+	t.Helper()
+	e, err := NewEvent(eventType, AggregateTodo, aggregateID, version, map[string]string{"id": aggregateID.String()})
+	require.NoError(t, err)
+	return e
+}
+
+func newTestRelay(mock pgxmock.PgxPoolIface, sink Sink) *Relay {
+	return NewRelay(NewRepository(mock), sink, time.Minute, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+// expectLease expects relay to renew the relay lease and get it if held is set.
+func expectLease(mock pgxmock.PgxPoolIface, relay *Relay, held bool) {
+	expectation := mock.ExpectQuery("INSERT INTO outbox_leases .* ON CONFLICT \\(name\\) DO UPDATE .* WHERE outbox_leases.holder = excluded.holder OR outbox_leases.expires_at < now\\(\\) RETURNING holder").
+		WithArgs(relayLease, relay.id, leaseTTL.Seconds())
+	if held {
+		expectation.WillReturnRows(pgxmock.NewRows([]string{"holder"}).AddRow(relay.id))
+	} else {
+		expectation.WillReturnError(pgx.ErrNoRows)
+	}
+}
+
+func TestNewEvent(t *testing.T) {
+	id := uuid.New()
+	e, err := NewEvent(TodoCompleted, AggregateTodo, id, 4, map[string]string{"title": "Ship"})
+	require.NoError(t, err)
+	require.NotEqual(t, uuid.Nil, e.ID)
+	require.Equal(t, SchemaVersion, e.SchemaVersion)
+	require.Equal(t, id, e.AggregateID)
+	require.EqualValues(t, 4, e.AggregateVersion)
+	require.JSONEq(t, `{"title":"Ship"}`, string(e.Payload))
+
+	data, err := json.Marshal(e)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "attempts")
+}
+
+func TestRelayPublishesInVersionOrder(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	todoID := uuid.New()
+	updated := testEvent(t, TodoUpdated, todoID, 2)
+	created := testEvent(t, TodoCreated, todoID, 1)
+	sink := &recordingSink{}
+	relay := newTestRelay(mock, sink)
+
+	expectLease(mock, relay, true)
+	// The update is read first, as if it had been stamped by a node whose clock runs behind.
+	mock.ExpectQuery("SELECT .* FROM outbox_events e\\s+WHERE published_at IS NULL AND dead_at IS NULL\\s+AND NOT EXISTS \\(.*w.next_attempt_at > now\\(\\)\\s+\\)\\s+ORDER BY occurred_at ASC, aggregate_version ASC\\s+LIMIT \\$1").
+		WithArgs(relayBatch).
+		WillReturnRows(newEventRows(updated, created))
+	mock.ExpectExec("UPDATE outbox_events SET published_at = now\\(\\) WHERE id = ANY\\(\\$1\\)").
+		WithArgs([]uuid.UUID{created.ID, updated.ID}).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 2))
+
+	published, err := relay.Publish(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 2, published)
+	require.Equal(t, []uuid.UUID{created.ID, updated.ID}, []uuid.UUID{sink.published[0].ID, sink.published[1].ID})
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRelayHoldsBackAggregateAfterFailure(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	first, second := uuid.New(), uuid.New()
+	created := testEvent(t, TodoCreated, first, 1)
+	completed := testEvent(t, TodoCompleted, first, 2)
+	other := testEvent(t, TodoCreated, second, 1)
+	sink := &recordingSink{fail: map[uuid.UUID]bool{created.ID: true}}
+	relay := newTestRelay(mock, sink)
+
+	expectLease(mock, relay, true)
+	mock.ExpectQuery("SELECT .* FROM outbox_events").
+		WithArgs(relayBatch).
+		WillReturnRows(newEventRows(created, completed, other))
+	mock.ExpectExec("UPDATE outbox_events\\s+SET attempts = attempts \\+ 1, last_error = \\$2, next_attempt_at = \\$3").
+		WithArgs(created.ID, "unavailable", retryWithin(backoff(1))).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec("UPDATE outbox_events SET published_at = now\\(\\)").
+		WithArgs([]uuid.UUID{other.ID}).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	published, err := relay.Publish(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, published)
+	require.Len(t, sink.published, 1)
+	require.Equal(t, other.ID, sink.published[0].ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRelayBacksOffPoisonEvents(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	// A full batch of events the sink rejects comes before an event it accepts.
+	poison := make([]Event, relayBatch)
+	fail := make(map[uuid.UUID]bool, relayBatch)
+	for i := range poison {
+		poison[i] = testEvent(t, TodoCreated, uuid.New(), 1)
+		fail[poison[i].ID] = true
+	}
+	poison[0].Attempts = maxAttempts - 1
+	good := testEvent(t, TodoCreated, uuid.New(), 1)
+	sink := &recordingSink{fail: fail}
+	relay := newTestRelay(mock, sink)
+
+	expectLease(mock, relay, true)
+	mock.ExpectQuery("SELECT .* FROM outbox_events e").
+		WithArgs(relayBatch).
+		WillReturnRows(newEventRows(poison...))
+	// The event out of attempts is given up, the others wait for their retry.
+	mock.ExpectExec("UPDATE outbox_events\\s+SET attempts = attempts \\+ 1").
+		WithArgs(poison[0].ID, "unavailable", (*time.Time)(nil)).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	for _, e := range poison[1:] {
+		mock.ExpectExec("UPDATE outbox_events\\s+SET attempts = attempts \\+ 1").
+			WithArgs(e.ID, "unavailable", retryWithin(backoff(1))).
+			WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	}
+
+	published, err := relay.Publish(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, published)
+
+	// Until their retries are due, the next poll reads past them.
+	expectLease(mock, relay, true)
+	mock.ExpectQuery("SELECT .* FROM outbox_events e").
+		WithArgs(relayBatch).
+		WillReturnRows(newEventRows(good))
+	mock.ExpectExec("UPDATE outbox_events SET published_at = now\\(\\)").
+		WithArgs([]uuid.UUID{good.ID}).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	published, err = relay.Publish(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, published)
+	require.Equal(t, good.ID, sink.published[0].ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBackoffDoublesUpToMaximum(t *testing.T) {
+	require.Equal(t, baseBackoff, backoff(1))
+	require.Equal(t, 2*baseBackoff, backoff(2))
+	require.Equal(t, maxBackoff, backoff(maxAttempts))
+}
+
+// retryWithin matches a retry time about wait from now.
+type retryWithin time.Duration
+
+func (r retryWithin) Match(v any) bool {
+	at, ok := v.(*time.Time)
+	if !ok || at == nil {
+		return false
+	}
+	wait := time.Until(*at)
+	return wait > time.Duration(r)-time.Minute && wait <= time.Duration(r)
+}
+
+func TestRelaysSharingRepositoryPublishOneAtATime(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	e := testEvent(t, TodoCreated, uuid.New(), 1)
+	followerSink := &recordingSink{}
+	follower := newTestRelay(mock, followerSink)
+
+	// The follower polls while the leader is publishing, finds the lease taken and leaves
+	// the pending events alone.
+	var followerPublished int
+	leaderSink := &hookSink{hook: func() {
+		var err error
+		followerPublished, err = follower.Publish(context.Background())
+		require.NoError(t, err)
+	}}
+	leader := newTestRelay(mock, leaderSink)
+
+	expectLease(mock, leader, true)
+	mock.ExpectQuery("SELECT .* FROM outbox_events").
+		WithArgs(relayBatch).
+		WillReturnRows(newEventRows(e))
+	expectLease(mock, follower, false)
+	mock.ExpectExec("UPDATE outbox_events SET published_at = now\\(\\)").
+		WithArgs([]uuid.UUID{e.ID}).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	published, err := leader.Publish(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, published)
+	require.Zero(t, followerPublished)
+	require.Empty(t, followerSink.published)
+
+	// Once the leader lets go, the follower takes over.
+	mock.ExpectExec("DELETE FROM outbox_leases WHERE name = \\$1 AND holder = \\$2").
+		WithArgs(relayLease, leader.id).
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	expectLease(mock, follower, true)
+	mock.ExpectQuery("SELECT .* FROM outbox_events").
+		WithArgs(relayBatch).
+		WillReturnRows(newEventRows())
+
+	leader.release(context.Background())
+	published, err = follower.Publish(context.Background())
+	require.NoError(t, err)
+	require.Zero(t, published)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertWritesEvents(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	e := testEvent(t, TodoDeleted, uuid.New(), 3)
+	mock.ExpectExec("INSERT INTO outbox_events \\(id, type, schema_version, aggregate_type, aggregate_id, aggregate_version, occurred_at, payload\\)").
+		WithArgs(e.ID, TodoDeleted, SchemaVersion, AggregateTodo, e.AggregateID, int64(3), e.OccurredAt, e.Payload).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	require.NoError(t, Insert(context.Background(), mock, e))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryPrune(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	cutoff := time.Now().Add(-retention)
+	mock.ExpectExec("DELETE FROM outbox_events WHERE published_at < \\$1").
+		WithArgs(cutoff).
+		WillReturnResult(pgxmock.NewResult("DELETE", 5))
+
+	pruned, err := NewRepository(mock).Prune(context.Background(), cutoff)
+	require.NoError(t, err)
+	require.EqualValues(t, 5, pruned)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryAfterReadsFromCursor(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	last, next := testEvent(t, TodoCreated, uuid.New(), 1), testEvent(t, TodoUpdated, uuid.New(), 2)
+	mock.ExpectQuery("WHERE aggregate_type = \\$1 AND \\(occurred_at, id\\) > \\(\\$2, \\$3\\)").
+		WithArgs(AggregateTodo, last.OccurredAt, last.ID, 10).
+		WillReturnRows(newEventRows(next))
+
+	events, err := NewRepository(mock).After(context.Background(), AggregateTodo, last.Cursor(), 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	require.Equal(t, next.ID, events[0].ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryGetNotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	id := uuid.New()
+	mock.ExpectQuery("FROM outbox_events WHERE id = \\$1").WithArgs(id).WillReturnRows(newEventRows())
+
+	_, err = NewRepository(mock).Get(context.Background(), id)
+	require.Equal(t, ErrNotFound, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
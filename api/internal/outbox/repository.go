@@ -0,0 +1,197 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const eventColumns = `id, type, schema_version, aggregate_type, aggregate_id, aggregate_version, occurred_at, payload, attempts`
+
+// Execer is satisfied by pools and transactions, so events are written in the
+// transaction of the change they record.
+type Execer interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// Insert records the events through db.
+func Insert(ctx context.Context, db Execer, events ...Event) error {
+	for _, e := range events {
+		if _, err := db.Exec(ctx, `
+			INSERT INTO outbox_events (id, type, schema_version, aggregate_type, aggregate_id, aggregate_version, occurred_at, payload)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+			e.ID, e.Type, e.SchemaVersion, e.AggregateType, e.AggregateID, e.AggregateVersion, e.OccurredAt, e.Payload); err != nil {
+			return fmt.Errorf("insert outbox event: %w", err)
+		}
+	}
+	return nil
+}
+
+// Repository reads and settles the recorded events for the Relay.
+type Repository struct {
+	pool pgxPool
+}
+
+type pgxPool interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// NewRepository constructs a repository around the supplied pgx pool.
+func NewRepository(pool pgxPool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// Pending returns up to limit unpublished events that are due, oldest first. Events that
+// were given up are left out, and so are the events of an aggregate from one that waits
+// to be retried on, so that they are not published ahead of it.
+func (r *Repository) Pending(ctx context.Context, limit int) ([]Event, error) {
+	query := `
+		SELECT ` + eventColumns + `
+		FROM outbox_events e
+		WHERE published_at IS NULL AND dead_at IS NULL
+		  AND NOT EXISTS (
+			SELECT 1 FROM outbox_events w
+			WHERE w.aggregate_type = e.aggregate_type AND w.aggregate_id = e.aggregate_id
+			  AND w.aggregate_version <= e.aggregate_version
+			  AND w.published_at IS NULL AND w.dead_at IS NULL AND w.next_attempt_at > now()
+		  )
+		ORDER BY occurred_at ASC, aggregate_version ASC
+		LIMIT $1
+	`
+
+	rows, err := r.pool.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query outbox events: %w", err)
+	}
+	return collectEvents(rows)
+}
+
+// After returns up to limit events of the aggregate type that occurred after the cursor,
+// in the order they occurred, whether published or not. Published events are kept for
+// the retention period, so readers can follow the changes from them.
+func (r *Repository) After(ctx context.Context, aggregateType string, after Cursor, limit int) ([]Event, error) {
+	query := `
+		SELECT ` + eventColumns + `
+		FROM outbox_events
+		WHERE aggregate_type = $1 AND (occurred_at, id) > ($2, $3)
+		ORDER BY occurred_at ASC, id ASC
+		LIMIT $4
+	`
+
+	rows, err := r.pool.Query(ctx, query, aggregateType, after.OccurredAt, after.ID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query outbox events: %w", err)
+	}
+	return collectEvents(rows)
+}
+
+// Get fetches an event that has not been pruned yet.
+func (r *Repository) Get(ctx context.Context, id uuid.UUID) (Event, error) {
+	e, err := scanEvent(r.pool.QueryRow(ctx, `SELECT `+eventColumns+` FROM outbox_events WHERE id = $1`, id))
+	switch {
+	case err == nil:
+		return e, nil
+	case err == pgx.ErrNoRows:
+		return Event{}, ErrNotFound
+	default:
+		return Event{}, fmt.Errorf("select outbox event: %w", err)
+	}
+}
+
+func collectEvents(rows pgx.Rows) ([]Event, error) {
+	defer rows.Close()
+
+	var result []Event
+	for rows.Next() {
+		e, err := scanEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan outbox event: %w", err)
+		}
+		result = append(result, e)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate outbox events: %w", rows.Err())
+	}
+	return result, nil
+}
+
+// MarkPublished records that the events were delivered.
+func (r *Repository) MarkPublished(ctx context.Context, ids []uuid.UUID) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if _, err := r.pool.Exec(ctx, `UPDATE outbox_events SET published_at = now() WHERE id = ANY($1)`, ids); err != nil {
+		return fmt.Errorf("mark outbox events published: %w", err)
+	}
+	return nil
+}
+
+// MarkFailed records a failed delivery of the event, which is tried again at retryAt. A
+// nil retryAt gives the event up: it stays in the table as a dead letter but is no longer
+// published, nor does it hold back the later events of its aggregate.
+func (r *Repository) MarkFailed(ctx context.Context, id uuid.UUID, cause error, retryAt *time.Time) error {
+	query := `
+		UPDATE outbox_events
+		SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3,
+			dead_at = CASE WHEN $3::TIMESTAMPTZ IS NULL THEN now() END
+		WHERE id = $1
+	`
+	if _, err := r.pool.Exec(ctx, query, id, cause.Error(), retryAt); err != nil {
+		return fmt.Errorf("mark outbox event failed: %w", err)
+	}
+	return nil
+}
+
+// Acquire takes or renews the named lease for holder until ttl from now and reports
+// whether holder has it. A lease held by someone else is only taken over once it expired.
+func (r *Repository) Acquire(ctx context.Context, name string, holder uuid.UUID, ttl time.Duration) (bool, error) {
+	query := `
+		INSERT INTO outbox_leases (name, holder, expires_at)
+		VALUES ($1, $2, now() + $3 * INTERVAL '1 second')
+		ON CONFLICT (name) DO UPDATE SET holder = excluded.holder, expires_at = excluded.expires_at
+		WHERE outbox_leases.holder = excluded.holder OR outbox_leases.expires_at < now()
+		RETURNING holder
+	`
+
+	var current uuid.UUID
+	err := r.pool.QueryRow(ctx, query, name, holder, ttl.Seconds()).Scan(&current)
+	switch {
+	case err == nil:
+		return current == holder, nil
+	case err == pgx.ErrNoRows:
+		return false, nil
+	default:
+		return false, fmt.Errorf("acquire outbox lease: %w", err)
+	}
+}
+
+// Release gives up the named lease if holder has it, so that another holder can take it
+// over without waiting for it to expire.
+func (r *Repository) Release(ctx context.Context, name string, holder uuid.UUID) error {
+	if _, err := r.pool.Exec(ctx, `DELETE FROM outbox_leases WHERE name = $1 AND holder = $2`, name, holder); err != nil {
+		return fmt.Errorf("release outbox lease: %w", err)
+	}
+	return nil
+}
+
+// Prune deletes events published before the cutoff and reports how many were deleted.
+func (r *Repository) Prune(ctx context.Context, before time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM outbox_events WHERE published_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("prune outbox events: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+func scanEvent(row pgx.Row) (Event, error) {
+	var e Event
+	err := row.Scan(&e.ID, &e.Type, &e.SchemaVersion, &e.AggregateType, &e.AggregateID, &e.AggregateVersion, &e.OccurredAt, &e.Payload, &e.Attempts)
+	return e, err
+}
@@ -0,0 +1,151 @@
+package outbox
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriterSinkWritesJSONLines(t *testing.T) {
+	var buf bytes.Buffer
+	sink := NewWriterSink(&buf)
+	first := testEvent(t, TodoCreated, uuid.New(), 1)
+	second := testEvent(t, TodoDeleted, first.AggregateID, 2)
+
+	require.NoError(t, sink.Publish(context.Background(), first))
+	require.NoError(t, sink.Publish(context.Background(), second))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	var decoded Event
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &decoded))
+	require.Equal(t, second.ID, decoded.ID)
+	require.Equal(t, TodoDeleted, decoded.Type)
+	require.EqualValues(t, 2, decoded.AggregateVersion)
+}
+
+func TestHTTPSinkPostsEvent(t *testing.T) {
+	e := testEvent(t, TodoUpdated, uuid.New(), 3)
+	var received Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, http.MethodPost, r.Method)
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.Equal(t, e.ID.String(), r.Header.Get("Idempotency-Key"))
+		require.Equal(t, TodoUpdated, r.Header.Get("X-Event-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	require.NoError(t, NewHTTPSink(server.URL, server.Client()).Publish(context.Background(), e))
+	require.Equal(t, e.ID, received.ID)
+}
+
+func TestHTTPSinkRejectsErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	err := NewHTTPSink(server.URL, server.Client()).Publish(context.Background(), testEvent(t, TodoCreated, uuid.New(), 1))
+	require.ErrorContains(t, err, "unexpected status 503")
+}
+
+func TestNewNATSSinkValidatesURL(t *testing.T) {
+	sink, err := NewNATSSink("nats://queue.internal", "todoapp.")
+	require.NoError(t, err)
+	require.Equal(t, "queue.internal:4222", sink.addr)
+	require.Equal(t, "todoapp.todo.TodoCreated", sink.Subject(Event{AggregateType: AggregateTodo, Type: TodoCreated}))
+
+	_, err = NewNATSSink("http://queue.internal", "todoapp")
+	require.Error(t, err)
+}
+
+// natsMessage is a message received by fakeNATS.
+type natsMessage struct {
+	subject string
+	headers string
+	payload []byte
+}
+
+// fakeNATS accepts one client, speaks enough of the NATS protocol for the sink and
+// forwards every published message to the returned channel.
+func fakeNATS(t *testing.T) (string, <-chan natsMessage) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	messages := make(chan natsMessage, 10)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		_, _ = conn.Write([]byte("INFO {\"server_id\":\"fake\",\"headers\":true}\r\n"))
+
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			fields := strings.Fields(line)
+			switch {
+			case len(fields) == 0:
+			case fields[0] == "PING":
+				_, _ = conn.Write([]byte("PONG\r\n"))
+			case fields[0] == "HPUB" && len(fields) == 4:
+				headerLen, _ := strconv.Atoi(fields[2])
+				totalLen, _ := strconv.Atoi(fields[3])
+				body := make([]byte, totalLen+2)
+				if _, err := io.ReadFull(r, body); err != nil {
+					return
+				}
+				messages <- natsMessage{subject: fields[1], headers: string(body[:headerLen]), payload: body[headerLen:totalLen]}
+			}
+		}
+	}()
+	return listener.Addr().String(), messages
+}
+
+func TestNATSSinkPublishes(t *testing.T) {
+	addr, messages := fakeNATS(t)
+	sink, err := NewNATSSink("nats://"+addr, "todoapp")
+	require.NoError(t, err)
+	defer sink.Close()
+
+	e := testEvent(t, TodoCompleted, uuid.New(), 2)
+	require.NoError(t, sink.Publish(context.Background(), e))
+
+	msg := <-messages
+	require.Equal(t, "todoapp.todo.TodoCompleted", msg.subject)
+	require.Contains(t, msg.headers, "Nats-Msg-Id: "+e.ID.String())
+	var decoded Event
+	require.NoError(t, json.Unmarshal(msg.payload, &decoded))
+	require.Equal(t, e.ID, decoded.ID)
+}
+
+func TestSinksPublishToAll(t *testing.T) {
+	var first, second bytes.Buffer
+	failing := &recordingSink{}
+	e := testEvent(t, TodoCreated, uuid.New(), 1)
+	failing.fail = map[uuid.UUID]bool{e.ID: true}
+
+	err := Sinks{NewWriterSink(&first), failing, NewWriterSink(&second)}.Publish(context.Background(), e)
+	require.ErrorContains(t, err, "unavailable")
+	require.NotEmpty(t, first.String())
+	require.NotEmpty(t, second.String())
+}
@@ -0,0 +1,18 @@
+package project
+
+import "errors"
+
+var (
+	// ErrNotFound indicates the requested project does not exist.
+	ErrNotFound = errors.New("project not found")
+	// ErrNameTaken indicates the user already has a project with the same name.
+	ErrNameTaken = errors.New("project name already in use")
+	// ErrForbidden indicates the user's role in the project does not allow the change.
+	ErrForbidden = errors.New("your role does not allow this change")
+	// ErrMemberNotFound indicates the user is not a member of the project.
+	ErrMemberNotFound = errors.New("member not found")
+	// ErrLastOwner indicates the change would leave the project without an owner.
+	ErrLastOwner = errors.New("a project needs at least one owner")
+	// ErrUnknownUser indicates the user to add does not exist.
+	ErrUnknownUser = errors.New("user does not exist")
+)
@@ -0,0 +1,363 @@
+package project
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"overengineeredtodo/internal/access"
+	"overengineeredtodo/internal/customfield"
+	"overengineeredtodo/internal/workflow"
+)
+
+// RegisterRoutes wires the project HTTP handlers to a sub-router.
+func RegisterRoutes(router *gin.RouterGroup, repo *Repository) {
+	handler := &Handler{repo: repo}
+
+	router.POST("", handler.createProject)
+	router.GET("/:id", handler.getProject)
+	router.GET("", handler.listProjects)
+	router.PUT("/:id", handler.updateProject)
+	router.DELETE("/:id", handler.deleteProject)
+	router.GET("/:id/workflow", handler.getWorkflow)
+	router.PUT("/:id/workflow", handler.setWorkflow)
+	router.DELETE("/:id/workflow", handler.resetWorkflow)
+	router.GET("/:id/fields", handler.getFields)
+	router.PUT("/:id/fields", handler.setFields)
+	router.GET("/:id/members", handler.listMembers)
+	router.PUT("/:id/members/:member_id", handler.setMember)
+	router.DELETE("/:id/members/:member_id", handler.removeMember)
+}
+
+// Handler exposes HTTP endpoints for projects.
+type Handler struct {
+	repo *Repository
+}
+
+func (h *Handler) createProject(c *gin.Context) {
+	var input CreateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.UserID == uuid.Nil || strings.TrimSpace(input.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user_id and name are required"})
+		return
+	}
+
+	p, err := h.repo.Create(c.Request.Context(), input)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusCreated, p)
+	case err == ErrNameTaken:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (h *Handler) getProject(c *gin.Context) {
+	id, role, ok := h.authorize(c, access.RoleViewer)
+	if !ok {
+		return
+	}
+
+	p, err := h.repo.Get(c.Request.Context(), id)
+	switch {
+	case err == nil:
+		p.Role = role
+		c.JSON(http.StatusOK, p)
+	case err == ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (h *Handler) listProjects(c *gin.Context) {
+	userID, err := uuid.Parse(c.Query("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+		return
+	}
+
+	projects, err := h.repo.ListByUser(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, projects)
+}
+
+func (h *Handler) updateProject(c *gin.Context) {
+	id, role, ok := h.authorize(c, access.RoleOwner)
+	if !ok {
+		return
+	}
+
+	var input UpdateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.Name != nil && strings.TrimSpace(*input.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name must not be empty"})
+		return
+	}
+
+	p, err := h.repo.Update(c.Request.Context(), id, input)
+	switch {
+	case err == nil:
+		p.Role = role
+		c.JSON(http.StatusOK, p)
+	case err == ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+	case err == ErrNameTaken:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// deleteProject removes the project for all of its members; only owners may do so.
+func (h *Handler) deleteProject(c *gin.Context) {
+	id, _, ok := h.authorize(c, access.RoleOwner)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Delete(c.Request.Context(), id); err != nil {
+		if err == ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// getWorkflow returns the workflow in effect for the project.
+func (h *Handler) getWorkflow(c *gin.Context) {
+	id, _, ok := h.authorize(c, access.RoleViewer)
+	if !ok {
+		return
+	}
+
+	p, err := h.repo.Get(c.Request.Context(), id)
+	switch {
+	case err == nil:
+	case err == ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+		return
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if p.Workflow == nil {
+		c.JSON(http.StatusOK, workflow.Default())
+		return
+	}
+	c.JSON(http.StatusOK, p.Workflow)
+}
+
+func (h *Handler) setWorkflow(c *gin.Context) {
+	var input workflow.Workflow
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := input.Validate(); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.saveWorkflow(c, &input)
+}
+
+// resetWorkflow switches the project back to the default workflow.
+func (h *Handler) resetWorkflow(c *gin.Context) {
+	h.saveWorkflow(c, nil)
+}
+
+func (h *Handler) saveWorkflow(c *gin.Context, custom *workflow.Workflow) {
+	id, role, ok := h.authorize(c, access.RoleOwner)
+	if !ok {
+		return
+	}
+
+	p, err := h.repo.SetWorkflow(c.Request.Context(), id, custom)
+	switch {
+	case err == nil:
+		p.Role = role
+		c.JSON(http.StatusOK, p)
+	case err == ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (h *Handler) getFields(c *gin.Context) {
+	id, _, ok := h.authorize(c, access.RoleViewer)
+	if !ok {
+		return
+	}
+
+	p, err := h.repo.Get(c.Request.Context(), id)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, p.CustomFields)
+	case err == ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (h *Handler) setFields(c *gin.Context) {
+	id, role, ok := h.authorize(c, access.RoleOwner)
+	if !ok {
+		return
+	}
+
+	var fields customfield.Schema
+	if err := c.ShouldBindJSON(&fields); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if fields == nil {
+		fields = customfield.Schema{}
+	}
+
+	if err := fields.Validate(); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	p, err := h.repo.SetCustomFields(c.Request.Context(), id, fields)
+	switch {
+	case err == nil:
+		p.Role = role
+		c.JSON(http.StatusOK, p)
+	case err == ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (h *Handler) listMembers(c *gin.Context) {
+	id, _, ok := h.authorize(c, access.RoleViewer)
+	if !ok {
+		return
+	}
+
+	members, err := h.repo.ListMembers(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, members)
+}
+
+// setMember shares the project with a user or changes their role; only owners may do so.
+func (h *Handler) setMember(c *gin.Context) {
+	id, _, ok := h.authorize(c, access.RoleOwner)
+	if !ok {
+		return
+	}
+	memberID, err := uuid.Parse(c.Param("member_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var input MemberInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	m, err := h.repo.SetMember(c.Request.Context(), id, memberID, input.Role)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, m)
+	case err == access.ErrInvalidRole:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case err == ErrUnknownUser:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case err == ErrLastOwner, err == ErrNameTaken:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// removeMember takes a member out of the project. Owners may remove anyone, and every
+// member may remove themselves to leave the project.
+func (h *Handler) removeMember(c *gin.Context) {
+	memberID, err := uuid.Parse(c.Param("member_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	required := access.RoleOwner
+	if c.Query("user_id") == memberID.String() {
+		required = access.RoleViewer
+	}
+	id, _, ok := h.authorize(c, required)
+	if !ok {
+		return
+	}
+
+	err = h.repo.RemoveMember(c.Request.Context(), id, memberID)
+	switch {
+	case err == nil:
+		c.Status(http.StatusNoContent)
+	case err == ErrMemberNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err == ErrLastOwner, err == ErrNameTaken:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// authorize parses the project of the request path and checks that the user_id query
+// parameter names a member holding at least the required role. Projects the user is no
+// member of are reported as not found.
+func (h *Handler) authorize(c *gin.Context, required access.Role) (uuid.UUID, access.Role, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return uuid.Nil, access.RoleNone, false
+	}
+	userID, err := uuid.Parse(c.Query("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+		return uuid.Nil, access.RoleNone, false
+	}
+
+	role, err := h.repo.Role(c.Request.Context(), id, userID)
+	switch {
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	case role == access.RoleNone:
+		c.JSON(http.StatusNotFound, gin.H{"error": "project not found"})
+	case !role.Allows(required):
+		c.JSON(http.StatusForbidden, gin.H{"error": ErrForbidden.Error()})
+	default:
+		return id, role, true
+	}
+	return uuid.Nil, access.RoleNone, false
+}
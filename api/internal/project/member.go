@@ -0,0 +1,188 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"overengineeredtodo/internal/access"
+	"overengineeredtodo/internal/todo"
+)
+
+const memberColumns = `project_id, user_id, role, created_at`
+
+// foreignKeyViolation is the SQLSTATE reported when a referenced row is missing.
+const foreignKeyViolation = "23503"
+
+// handover moves projects.user_id to the longest-standing other owner when $2 stops
+// owning project $1. It expects a preceding CTE named changed that returns a row only
+// when the membership change went through.
+const handover = `
+	handover AS (
+		UPDATE projects
+		SET user_id = (
+		        SELECT o.user_id FROM project_members o
+		        WHERE o.project_id = $1 AND o.role = 'owner' AND o.user_id <> $2
+		        ORDER BY o.created_at ASC
+		        LIMIT 1
+		    ),
+		    updated_at = current_timestamp
+		WHERE id = $1 AND user_id = $2 AND EXISTS (SELECT 1 FROM changed WHERE role <> 'owner' OR removed)
+		RETURNING id
+	)
+`
+
+// Role returns the user's role in the project, or RoleNone when they are not a member.
+func (r *Repository) Role(ctx context.Context, projectID, userID uuid.UUID) (access.Role, error) {
+	var role string
+	err := r.pool.QueryRow(ctx, `SELECT role FROM project_members WHERE project_id = $1 AND user_id = $2`, projectID, userID).Scan(&role)
+	switch {
+	case err == pgx.ErrNoRows:
+		return access.RoleNone, nil
+	case err != nil:
+		return access.RoleNone, fmt.Errorf("select project role: %w", err)
+	}
+	return access.Role(role), nil
+}
+
+// ListMembers returns the members of a project in the order they joined.
+func (r *Repository) ListMembers(ctx context.Context, projectID uuid.UUID) ([]Member, error) {
+	query := `
+		SELECT ` + memberColumns + `
+		FROM project_members
+		WHERE project_id = $1
+		ORDER BY created_at ASC, user_id ASC
+	`
+
+	rows, err := r.pool.Query(ctx, query, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("query project members: %w", err)
+	}
+	defer rows.Close()
+
+	result := []Member{}
+	for rows.Next() {
+		m, err := scanMember(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan project member: %w", err)
+		}
+		result = append(result, m)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate project members: %w", rows.Err())
+	}
+
+	return result, nil
+}
+
+// SetMember adds the user to the project or changes their role. Demoting the last owner
+// fails with ErrLastOwner; when the project's user is demoted, the project passes to
+// another owner.
+func (r *Repository) SetMember(ctx context.Context, projectID, userID uuid.UUID, role access.Role) (Member, error) {
+	if !role.Valid() {
+		return Member{}, access.ErrInvalidRole
+	}
+
+	query := `
+		WITH changed AS (
+			INSERT INTO project_members (project_id, user_id, role)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (project_id, user_id) DO UPDATE
+			SET role = excluded.role
+			WHERE excluded.role = 'owner'
+			   OR project_members.role <> 'owner'
+			   OR (SELECT count(*) FROM project_members o WHERE o.project_id = $1 AND o.role = 'owner') > 1
+			RETURNING ` + memberColumns + `, false AS removed
+		), ` + handover + `
+		SELECT ` + memberColumns + `
+		FROM changed
+	`
+
+	m, err := scanMember(r.pool.QueryRow(ctx, query, projectID, userID, string(role)))
+	switch {
+	case err == nil:
+		return m, nil
+	case err == pgx.ErrNoRows:
+		return Member{}, ErrLastOwner
+	case isForeignKeyViolation(err):
+		return Member{}, ErrUnknownUser
+	case isUniqueViolation(err):
+		return Member{}, ErrNameTaken
+	default:
+		return Member{}, fmt.Errorf("upsert project member: %w", err)
+	}
+}
+
+// RemoveMember takes the user out of the project, which is also how members leave.
+// Their todos stay in the project, and todos assigned to them become unassigned. The last
+// owner cannot leave; when the project's user leaves, the project passes to another owner.
+func (r *Repository) RemoveMember(ctx context.Context, projectID, userID uuid.UUID) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin delete project member: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	query := `
+		WITH changed AS (
+			DELETE FROM project_members
+			WHERE project_id = $1 AND user_id = $2
+			  AND (role <> 'owner'
+			       OR (SELECT count(*) FROM project_members o WHERE o.project_id = $1 AND o.role = 'owner') > 1)
+			RETURNING role, true AS removed
+		), ` + handover + `
+		SELECT count(*) FROM changed
+	`
+
+	var removed int
+	err = tx.QueryRow(ctx, query, projectID, userID).Scan(&removed)
+	switch {
+	case isUniqueViolation(err):
+		return ErrNameTaken
+	case err != nil:
+		return fmt.Errorf("delete project member: %w", err)
+	case removed > 0:
+		unassign := `
+			UPDATE todos
+			SET assignee_id = NULL, updated_at = current_timestamp, version = version + 1
+			WHERE project_id = $1 AND assignee_id = $2
+			RETURNING ` + todo.ReturningColumns
+		if err := updateTodos(ctx, tx, unassign, projectID, userID); err != nil {
+			return fmt.Errorf("unassign todos: %w", err)
+		}
+		if err := tx.Commit(ctx); err != nil {
+			return fmt.Errorf("commit delete project member: %w", err)
+		}
+		return nil
+	}
+
+	// Nothing changed; end the transaction before telling why.
+	_ = tx.Rollback(ctx)
+	role, err := r.Role(ctx, projectID, userID)
+	switch {
+	case err != nil:
+		return err
+	case role == access.RoleNone:
+		return ErrMemberNotFound
+	default:
+		return ErrLastOwner
+	}
+}
+
+func scanMember(row pgx.Row) (Member, error) {
+	var m Member
+	var role string
+	err := row.Scan(&m.ProjectID, &m.UserID, &role, &m.CreatedAt)
+	m.Role = access.Role(role)
+	return m, err
+}
+
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation
+}
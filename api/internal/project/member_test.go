@@ -0,0 +1,128 @@
+package project
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+
+	"overengineeredtodo/internal/access"
+	"overengineeredtodo/internal/todo"
+	"overengineeredtodo/internal/todo/todotest"
+)
+
+var memberRowColumns = []string{"project_id", "user_id", "role", "created_at"}
+
+func TestRepositorySetMember(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	projectID, userID := uuid.New(), uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery("WITH changed AS \\( INSERT INTO project_members .* ON CONFLICT \\(project_id, user_id\\) DO UPDATE .*handover AS \\( UPDATE projects").
+		WithArgs(projectID, userID, "editor").
+		WillReturnRows(pgxmock.NewRows(memberRowColumns).AddRow(projectID, userID, "editor", now))
+
+	m, err := repo.SetMember(context.Background(), projectID, userID, access.RoleEditor)
+	require.NoError(t, err)
+	require.Equal(t, access.RoleEditor, m.Role)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositorySetMemberErrors(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	projectID, userID := uuid.New(), uuid.New()
+
+	mock.ExpectQuery("WITH changed AS").
+		WithArgs(projectID, userID, "viewer").
+		WillReturnError(pgx.ErrNoRows)
+	mock.ExpectQuery("WITH changed AS").
+		WithArgs(projectID, userID, "viewer").
+		WillReturnError(&pgconn.PgError{Code: foreignKeyViolation})
+
+	_, err = repo.SetMember(context.Background(), projectID, userID, access.RoleViewer)
+	require.ErrorIs(t, err, ErrLastOwner)
+	_, err = repo.SetMember(context.Background(), projectID, userID, access.RoleViewer)
+	require.ErrorIs(t, err, ErrUnknownUser)
+	_, err = repo.SetMember(context.Background(), projectID, userID, access.Role("admin"))
+	require.ErrorIs(t, err, access.ErrInvalidRole)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryRemoveMember(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	projectID, userID := uuid.New(), uuid.New()
+
+	now := time.Now()
+	unassigned := todo.Todo{ID: uuid.New(), UserID: uuid.New(), Title: "Review", ProjectID: &projectID, Version: 2, CreatedAt: now, UpdatedAt: now}
+
+	// Todos assigned to the member that left are unassigned, each with an event.
+	mock.ExpectBegin()
+	mock.ExpectQuery("WITH changed AS \\( DELETE FROM project_members").
+		WithArgs(projectID, userID).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery("UPDATE todos SET assignee_id = NULL.* WHERE project_id = \\$1 AND assignee_id = \\$2 RETURNING todos.id").
+		WithArgs(projectID, userID).
+		WillReturnRows(todotest.Rows(unassigned))
+	expectTodoUpdated(mock, unassigned)
+	mock.ExpectCommit()
+	require.NoError(t, repo.RemoveMember(context.Background(), projectID, userID))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("WITH changed AS \\( DELETE FROM project_members").
+		WithArgs(projectID, userID).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectRollback()
+	mock.ExpectQuery("SELECT role FROM project_members").
+		WithArgs(projectID, userID).
+		WillReturnRows(pgxmock.NewRows([]string{"role"}).AddRow("owner"))
+	require.ErrorIs(t, repo.RemoveMember(context.Background(), projectID, userID), ErrLastOwner)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("WITH changed AS \\( DELETE FROM project_members").
+		WithArgs(projectID, userID).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectRollback()
+	mock.ExpectQuery("SELECT role FROM project_members").
+		WithArgs(projectID, userID).
+		WillReturnError(pgx.ErrNoRows)
+	require.ErrorIs(t, repo.RemoveMember(context.Background(), projectID, userID), ErrMemberNotFound)
+
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryListByUserIncludesRole(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	userID := uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery("FROM projects WHERE id IN \\(SELECT project_id FROM project_members WHERE user_id = \\$1\\) ORDER BY name ASC").
+		WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows(append(projectRowColumns, "role")).AddRow(uuid.New(), uuid.New(), "Household", nil, nil, now, now, "editor"))
+
+	projects, err := repo.ListByUser(context.Background(), userID)
+	require.NoError(t, err)
+	require.Len(t, projects, 1)
+	require.Equal(t, access.RoleEditor, projects[0].Role)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
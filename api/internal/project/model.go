@@ -0,0 +1,51 @@
+package project
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"overengineeredtodo/internal/access"
+	"overengineeredtodo/internal/customfield"
+	"overengineeredtodo/internal/workflow"
+)
+
+// Project groups a user's todos, mirroring projects, boards and lists of other tools.
+type Project struct {
+	ID     uuid.UUID `json:"id"`
+	UserID uuid.UUID `json:"user_id"`
+	Name   string    `json:"name"`
+	// Workflow is the project's custom status workflow; nil means the default workflow.
+	Workflow *workflow.Workflow `json:"workflow,omitempty"`
+	// CustomFields declares the typed fields the project's todos carry.
+	CustomFields customfield.Schema `json:"custom_fields"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+	// Role is the requesting user's role in the project.
+	Role access.Role `json:"role,omitempty"`
+}
+
+// Member grants a user a role in a project. UserID of the project always names one of
+// its owners.
+type Member struct {
+	ProjectID uuid.UUID   `json:"project_id"`
+	UserID    uuid.UUID   `json:"user_id"`
+	Role      access.Role `json:"role"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// MemberInput holds the role to give a member.
+type MemberInput struct {
+	Role access.Role `json:"role" binding:"required"`
+}
+
+// CreateInput holds the payload required to create a project.
+type CreateInput struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+	Name   string    `json:"name" binding:"required"`
+}
+
+// UpdateInput allows renaming a project.
+type UpdateInput struct {
+	Name *string `json:"name"`
+}
@@ -0,0 +1,320 @@
+package project
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"overengineeredtodo/internal/access"
+	"overengineeredtodo/internal/customfield"
+	"overengineeredtodo/internal/outbox"
+	"overengineeredtodo/internal/todo"
+	"overengineeredtodo/internal/workflow"
+)
+
+const projectColumns = `id, user_id, name, workflow, custom_fields, created_at, updated_at`
+
+// uniqueViolation is the SQLSTATE reported for duplicate keys.
+const uniqueViolation = "23505"
+
+// Repository provides Cockroach-backed persistence for projects.
+type Repository struct {
+	pool pgxPool
+}
+
+type pgxPool interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// NewRepository constructs a repository around the supplied pgx pool.
+func NewRepository(pool pgxPool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// Create inserts a project row, making its user the first owner.
+func (r *Repository) Create(ctx context.Context, input CreateInput) (Project, error) {
+	query := `
+		WITH created AS (
+			INSERT INTO projects (id, user_id, name)
+			VALUES ($1, $2, $3)
+			RETURNING ` + projectColumns + `
+		), owner AS (
+			INSERT INTO project_members (project_id, user_id, role)
+			SELECT id, user_id, 'owner' FROM created
+			RETURNING project_id
+		)
+		SELECT ` + projectColumns + `
+		FROM created
+	`
+
+	p, err := scanProject(r.pool.QueryRow(ctx, query, uuid.New(), input.UserID, strings.TrimSpace(input.Name)))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return Project{}, ErrNameTaken
+		}
+		return Project{}, fmt.Errorf("insert project: %w", err)
+	}
+
+	p.Role = access.RoleOwner
+	return p, nil
+}
+
+// Ensure returns the project with the given name the user owns, creating it when missing.
+func (r *Repository) Ensure(ctx context.Context, userID uuid.UUID, name string) (Project, error) {
+	query := `
+		WITH ensured AS (
+			INSERT INTO projects (id, user_id, name)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (user_id, name) DO UPDATE SET name = excluded.name
+			RETURNING ` + projectColumns + `
+		), owner AS (
+			INSERT INTO project_members (project_id, user_id, role)
+			SELECT id, user_id, 'owner' FROM ensured
+			ON CONFLICT (project_id, user_id) DO NOTHING
+			RETURNING project_id
+		)
+		SELECT ` + projectColumns + `
+		FROM ensured
+	`
+
+	p, err := scanProject(r.pool.QueryRow(ctx, query, uuid.New(), userID, strings.TrimSpace(name)))
+	if err != nil {
+		return Project{}, fmt.Errorf("ensure project: %w", err)
+	}
+
+	p.Role = access.RoleOwner
+	return p, nil
+}
+
+// Get fetches a project by id.
+func (r *Repository) Get(ctx context.Context, id uuid.UUID) (Project, error) {
+	query := `
+		SELECT ` + projectColumns + `
+		FROM projects
+		WHERE id = $1
+	`
+
+	p, err := scanProject(r.pool.QueryRow(ctx, query, id))
+
+	switch {
+	case err == nil:
+		return p, nil
+	case err == pgx.ErrNoRows:
+		return Project{}, ErrNotFound
+	default:
+		return Project{}, fmt.Errorf("select project: %w", err)
+	}
+}
+
+// ListByUser returns the projects the user is a member of, with their role, ordered by name.
+func (r *Repository) ListByUser(ctx context.Context, userID uuid.UUID) ([]Project, error) {
+	query := `
+		SELECT ` + projectColumns + `,
+			(SELECT m.role FROM project_members m WHERE m.project_id = projects.id AND m.user_id = $1)
+		FROM projects
+		WHERE id IN (SELECT project_id FROM project_members WHERE user_id = $1)
+		ORDER BY name ASC
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query projects: %w", err)
+	}
+	defer rows.Close()
+
+	var result []Project
+	for rows.Next() {
+		var role string
+		p, err := scanProject(rows, &role)
+		if err != nil {
+			return nil, fmt.Errorf("scan project: %w", err)
+		}
+		p.Role = access.Role(role)
+		result = append(result, p)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate projects: %w", rows.Err())
+	}
+
+	return result, nil
+}
+
+// Update applies partial updates to a project and returns the new state.
+func (r *Repository) Update(ctx context.Context, id uuid.UUID, input UpdateInput) (Project, error) {
+	if input.Name == nil {
+		return r.Get(ctx, id)
+	}
+
+	query := `
+		UPDATE projects
+		SET name = $1, updated_at = current_timestamp
+		WHERE id = $2
+		RETURNING ` + projectColumns
+
+	p, err := scanProject(r.pool.QueryRow(ctx, query, strings.TrimSpace(*input.Name), id))
+	switch {
+	case err == nil:
+		return p, nil
+	case err == pgx.ErrNoRows:
+		return Project{}, ErrNotFound
+	case isUniqueViolation(err):
+		return Project{}, ErrNameTaken
+	default:
+		return Project{}, fmt.Errorf("update project: %w", err)
+	}
+}
+
+// SetWorkflow replaces the project's workflow; nil restores the default workflow. Todos
+// whose status the new workflow lacks move to its initial status, or its first done
+// status when completed, in the same transaction.
+func (r *Repository) SetWorkflow(ctx context.Context, id uuid.UUID, custom *workflow.Workflow) (Project, error) {
+	effective := workflow.Default()
+	if custom != nil {
+		effective = *custom
+	}
+
+	keys := make([]string, len(effective.Statuses))
+	for i, s := range effective.Statuses {
+		keys[i] = s.Key
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return Project{}, fmt.Errorf("begin update project workflow: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	query := `
+		UPDATE projects
+		SET workflow = $2, updated_at = current_timestamp
+		WHERE id = $1
+		RETURNING ` + projectColumns
+
+	p, err := scanProject(tx.QueryRow(ctx, query, id, custom))
+	switch {
+	case err == pgx.ErrNoRows:
+		return Project{}, ErrNotFound
+	case err != nil:
+		return Project{}, fmt.Errorf("update project workflow: %w", err)
+	}
+
+	remap := `
+		UPDATE todos
+		SET status = CASE WHEN completed THEN $2 ELSE $3 END,
+		    updated_at = current_timestamp,
+		    version = version + 1
+		WHERE project_id = $1
+		  AND NOT (status = ANY ($4))
+		RETURNING ` + todo.ReturningColumns
+	if err := updateTodos(ctx, tx, remap, id, effective.Fallback(true), effective.Fallback(false), keys); err != nil {
+		return Project{}, fmt.Errorf("remap todo statuses: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Project{}, fmt.Errorf("commit update project workflow: %w", err)
+	}
+	return p, nil
+}
+
+// SetCustomFields replaces the project's custom fields. Values of fields that were removed
+// or changed type are dropped from its todos in the same transaction.
+func (r *Repository) SetCustomFields(ctx context.Context, id uuid.UUID, fields customfield.Schema) (Project, error) {
+	current, err := r.Get(ctx, id)
+	if err != nil {
+		return Project{}, err
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return Project{}, fmt.Errorf("begin update project custom fields: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	query := `
+		UPDATE projects
+		SET custom_fields = $2, updated_at = current_timestamp
+		WHERE id = $1
+		RETURNING ` + projectColumns
+
+	p, err := scanProject(tx.QueryRow(ctx, query, id, fields))
+	switch {
+	case err == pgx.ErrNoRows:
+		return Project{}, ErrNotFound
+	case err != nil:
+		return Project{}, fmt.Errorf("update project custom fields: %w", err)
+	}
+
+	prune := `
+		UPDATE todos
+		SET custom_fields = (
+		        SELECT COALESCE(jsonb_object_agg(f.key, f.value), '{}'::JSONB)
+		        FROM jsonb_each(todos.custom_fields) AS f
+		        WHERE f.key = ANY ($2)
+		    ),
+		    updated_at = current_timestamp,
+		    version = version + 1
+		WHERE project_id = $1
+		  AND EXISTS (
+		        SELECT 1 FROM jsonb_object_keys(todos.custom_fields) AS k
+		        WHERE NOT (k = ANY ($2))
+		    )
+		RETURNING ` + todo.ReturningColumns
+	if err := updateTodos(ctx, tx, prune, id, fields.Retained(current.CustomFields)); err != nil {
+		return Project{}, fmt.Errorf("prune todo custom fields: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Project{}, fmt.Errorf("commit update project custom fields: %w", err)
+	}
+	return p, nil
+}
+
+// updateTodos runs query, an update of todos returning todo.ReturningColumns, through tx
+// and records a TodoUpdated event for every todo it changed.
+func updateTodos(ctx context.Context, tx pgx.Tx, query string, args ...any) error {
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	events, err := todo.UpdatedEvents(rows)
+	if err != nil {
+		return err
+	}
+	return outbox.Insert(ctx, tx, events...)
+}
+
+// Delete removes a project. Its todos are kept and lose their project.
+func (r *Repository) Delete(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM projects WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete project: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// scanProject reads the projectColumns of row, followed by any extra columns.
+func scanProject(row pgx.Row, extra ...any) (Project, error) {
+	var p Project
+	err := row.Scan(append([]any{&p.ID, &p.UserID, &p.Name, &p.Workflow, &p.CustomFields, &p.CreatedAt, &p.UpdatedAt}, extra...)...)
+	if p.CustomFields == nil {
+		p.CustomFields = customfield.Schema{}
+	}
+	return p, err
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
@@ -0,0 +1,191 @@
+package project
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+
+	"overengineeredtodo/internal/customfield"
+	"overengineeredtodo/internal/outbox"
+	"overengineeredtodo/internal/todo"
+	"overengineeredtodo/internal/todo/todotest"
+	"overengineeredtodo/internal/workflow"
+)
+
+var projectRowColumns = []string{"id", "user_id", "name", "workflow", "custom_fields", "created_at", "updated_at"}
+
+// expectTodoUpdated expects a TodoUpdated event for each of the todos.
+func expectTodoUpdated(mock pgxmock.PgxPoolIface, todos ...todo.Todo) {
+	for _, t := range todos {
+		mock.ExpectExec("INSERT INTO outbox_events").
+			WithArgs(pgxmock.AnyArg(), outbox.TodoUpdated, outbox.SchemaVersion, outbox.AggregateTodo, t.ID, int64(t.Version), pgxmock.AnyArg(), pgxmock.AnyArg()).
+			WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	}
+}
+
+func TestRepositoryCreate(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	input := CreateInput{UserID: uuid.New(), Name: " Groceries "}
+	id := uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery("INSERT INTO projects").
+		WithArgs(pgxmock.AnyArg(), input.UserID, "Groceries").
+		WillReturnRows(pgxmock.NewRows(projectRowColumns).AddRow(id, input.UserID, "Groceries", nil, customfield.Schema{}, now, now))
+
+	p, err := repo.Create(context.Background(), input)
+	require.NoError(t, err)
+	require.Equal(t, id, p.ID)
+	require.Equal(t, "Groceries", p.Name)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryCreateDuplicateName(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	input := CreateInput{UserID: uuid.New(), Name: "Work"}
+
+	mock.ExpectQuery("INSERT INTO projects").
+		WithArgs(pgxmock.AnyArg(), input.UserID, "Work").
+		WillReturnError(&pgconn.PgError{Code: uniqueViolation})
+
+	_, err = repo.Create(context.Background(), input)
+	require.ErrorIs(t, err, ErrNameTaken)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryEnsure(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	userID := uuid.New()
+	existing := uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery("INSERT INTO projects .* ON CONFLICT \\(user_id, name\\) DO UPDATE").
+		WithArgs(pgxmock.AnyArg(), userID, "Inbox").
+		WillReturnRows(pgxmock.NewRows(projectRowColumns).AddRow(existing, userID, "Inbox", nil, customfield.Schema{}, now, now))
+
+	p, err := repo.Ensure(context.Background(), userID, "Inbox")
+	require.NoError(t, err)
+	require.Equal(t, existing, p.ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositorySetWorkflow(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	id := uuid.New()
+	now := time.Now()
+	custom := &workflow.Workflow{
+		Initial: "todo",
+		Statuses: []workflow.Status{
+			{Key: "todo", Name: "To do"},
+			{Key: "shipped", Name: "Shipped", Done: true},
+		},
+	}
+
+	remapped := todo.Todo{ID: uuid.New(), UserID: uuid.New(), Title: "Review", Status: "todo", Version: 4, CreatedAt: now, UpdatedAt: now}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE projects\\s+SET workflow = \\$2").
+		WithArgs(id, custom).
+		WillReturnRows(pgxmock.NewRows(projectRowColumns).AddRow(id, uuid.New(), "Release", custom, customfield.Schema{}, now, now))
+	mock.ExpectQuery("UPDATE todos\\s+SET status = CASE WHEN completed THEN \\$2 ELSE \\$3 END.* RETURNING todos.id").
+		WithArgs(id, "shipped", "todo", []string{"todo", "shipped"}).
+		WillReturnRows(todotest.Rows(remapped))
+	expectTodoUpdated(mock, remapped)
+	mock.ExpectCommit()
+
+	p, err := repo.SetWorkflow(context.Background(), id, custom)
+	require.NoError(t, err)
+	require.Equal(t, custom, p.Workflow)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositorySetCustomFields(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	id := uuid.New()
+	userID := uuid.New()
+	now := time.Now()
+	previous := customfield.Schema{
+		{Key: "points", Name: "Points", Type: customfield.TypeText},
+		{Key: "customer", Name: "Customer", Type: customfield.TypeText},
+	}
+	fields := customfield.Schema{
+		{Key: "points", Name: "Points", Type: customfield.TypeNumber},
+		{Key: "customer", Name: "Customer", Type: customfield.TypeText},
+	}
+
+	mock.ExpectQuery("SELECT id, user_id, name, workflow, custom_fields, created_at, updated_at FROM projects").
+		WithArgs(id).
+		WillReturnRows(pgxmock.NewRows(projectRowColumns).AddRow(id, userID, "Sales", nil, previous, now, now))
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE projects\\s+SET custom_fields = \\$2").
+		WithArgs(id, fields).
+		WillReturnRows(pgxmock.NewRows(projectRowColumns).AddRow(id, userID, "Sales", nil, fields, now, now))
+	mock.ExpectQuery("UPDATE todos\\s+SET custom_fields = .* RETURNING todos.id").
+		WithArgs(id, []string{"customer"}).
+		WillReturnRows(todotest.Rows())
+	mock.ExpectCommit()
+
+	p, err := repo.SetCustomFields(context.Background(), id, fields)
+	require.NoError(t, err)
+	require.Equal(t, fields, p.CustomFields)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryGetNotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	id := uuid.New()
+
+	mock.ExpectQuery("SELECT id, user_id, name, workflow, custom_fields, created_at, updated_at FROM projects").
+		WithArgs(id).
+		WillReturnError(pgx.ErrNoRows)
+
+	_, err = repo.Get(context.Background(), id)
+	require.ErrorIs(t, err, ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryDelete(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	id := uuid.New()
+
+	mock.ExpectExec("DELETE FROM projects").
+		WithArgs(id).
+		WillReturnResult(pgxmock.NewResult("DELETE", 0))
+
+	require.ErrorIs(t, repo.Delete(context.Background(), id), ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
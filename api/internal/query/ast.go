@@ -0,0 +1,72 @@
+package query
+
+import "fmt"
+
+// Node is an element of a parsed query.
+type Node interface {
+	// Column is the 1-based position of the node in the query text.
+	Column() int
+}
+
+// And matches todos matching every node. Terms separated by spaces are combined with And.
+type And struct {
+	Nodes []Node
+	Col   int
+}
+
+// Or matches todos matching any node.
+type Or struct {
+	Nodes []Node
+	Col   int
+}
+
+// Not negates a node, written as a leading "-".
+type Not struct {
+	Node Node
+	Col  int
+}
+
+// Filter compares a todo field with a value, as in "priority:>=2".
+type Filter struct {
+	Field string
+	Op    string
+	Value string
+	Col   int
+	// ValueCol is the position of the value, used in error messages.
+	ValueCol int
+}
+
+// Text matches todos whose title or description contains the phrase.
+type Text struct {
+	Value string
+	Col   int
+}
+
+func (n And) Column() int    { return n.Col }
+func (n Or) Column() int     { return n.Col }
+func (n Not) Column() int    { return n.Col }
+func (n Filter) Column() int { return n.Col }
+func (n Text) Column() int   { return n.Col }
+
+// Comparison operators of filters. OpEqual is written as a plain colon.
+const (
+	OpEqual        = ":"
+	OpLess         = "<"
+	OpLessEqual    = "<="
+	OpGreater      = ">"
+	OpGreaterEqual = ">="
+)
+
+// Error is a query error at a column of the query text.
+type Error struct {
+	Col int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("column %d: %s", e.Col, e.Msg)
+}
+
+func errorAt(col int, format string, args ...any) *Error {
+	return &Error{Col: col, Msg: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,129 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fieldSpec describes a filterable field. Ordered fields accept <, <=, > and >=.
+type fieldSpec struct {
+	name    string
+	ordered bool
+	check   func(Filter) error
+}
+
+var fields = map[string]fieldSpec{
+	"due":       {name: "due", ordered: true, check: checkDate},
+	"created":   {name: "created", ordered: true, check: checkDate},
+	"updated":   {name: "updated", ordered: true, check: checkDate},
+	"completed": {name: "completed", ordered: true, check: checkDate},
+	"priority":  {name: "priority", ordered: true, check: checkPriority},
+	"tag":       {name: "tag", check: checkAny},
+	"label":     {name: "tag", check: checkAny},
+	"status":    {name: "status", check: checkAny},
+	"project":   {name: "project", check: checkAny},
+	"is":        {name: "is", check: checkOneOf("open", "done", "completed", "overdue")},
+	"has":       {name: "has", check: checkOneOf("due", "project", "tag", "label", "description", "parent")},
+}
+
+// dateColumns maps date fields onto todo columns.
+var dateColumns = map[string]string{
+	"due":       "due_date",
+	"created":   "created_at",
+	"updated":   "updated_at",
+	"completed": "completed_at",
+}
+
+var priorityNames = map[string]int{"none": 0, "low": 1, "medium": 2, "high": 3}
+
+var relativePattern = regexp.MustCompile(`^([+-]?\d{1,4})([hdw])$`)
+
+func checkAny(Filter) error { return nil }
+
+func checkOneOf(values ...string) func(Filter) error {
+	return func(f Filter) error {
+		for _, v := range values {
+			if strings.EqualFold(f.Value, v) {
+				return nil
+			}
+		}
+		return fmt.Errorf("%s must be one of %s", f.Field, strings.Join(values, ", "))
+	}
+}
+
+func checkPriority(f Filter) error {
+	_, err := priority(f.Value)
+	return err
+}
+
+func checkDate(f Filter) error {
+	if strings.EqualFold(f.Value, "none") {
+		if f.Op != OpEqual {
+			return fmt.Errorf("%s:none cannot be compared", f.Field)
+		}
+		return nil
+	}
+	_, err := dateOperand(f.Value, time.Now(), time.UTC)
+	return err
+}
+
+func priority(value string) (int, error) {
+	if p, ok := priorityNames[strings.ToLower(value)]; ok {
+		return p, nil
+	}
+	p, err := strconv.Atoi(value)
+	if err != nil || p < 0 || p > 3 {
+		return 0, errors.New("priority must be 0-3 or none, low, medium, high")
+	}
+	return p, nil
+}
+
+// operand is the instant or day a date filter compares with.
+type operand struct {
+	// start and end delimit a day; relative operands have start == end.
+	start, end time.Time
+	relative   bool
+}
+
+// dateOperand reads today, tomorrow, yesterday, a YYYY-MM-DD date or an offset from now
+// such as 7d, -2w or 12h. Days start at midnight in loc.
+func dateOperand(value string, now time.Time, loc *time.Location) (operand, error) {
+	now = now.In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+
+	var start time.Time
+	switch strings.ToLower(value) {
+	case "today":
+		start = today
+	case "tomorrow":
+		start = today.AddDate(0, 0, 1)
+	case "yesterday":
+		start = today.AddDate(0, 0, -1)
+	default:
+		if m := relativePattern.FindStringSubmatch(strings.ToLower(value)); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			var at time.Time
+			switch m[2] {
+			case "h":
+				at = now.Add(time.Duration(n) * time.Hour)
+			case "d":
+				at = now.AddDate(0, 0, n)
+			case "w":
+				at = now.AddDate(0, 0, 7*n)
+			}
+			return operand{start: at, end: at, relative: true}, nil
+		}
+
+		d, err := time.ParseInLocation(time.DateOnly, value, loc)
+		if err != nil {
+			return operand{}, errors.New("expected a date (YYYY-MM-DD, today, tomorrow, yesterday) or an offset such as 7d, -2w or 12h")
+		}
+		start = d
+	}
+
+	return operand{start: start, end: start.AddDate(0, 0, 1)}, nil
+}
@@ -0,0 +1,290 @@
+package query
+
+import (
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenLParen
+	tokenRParen
+	tokenNot
+	tokenOr
+	tokenAnd
+)
+
+type token struct {
+	kind tokenKind
+	// text is the word with quotes removed.
+	text string
+	col  int
+	// colon is the byte offset of the first unquoted ':' in text, or -1.
+	colon    int
+	valueCol int
+}
+
+// Parse reads a query such as `due:<7d tag:work -tag:someday "quarterly report" is:open`.
+// Terms separated by spaces must all match; OR, parentheses and a leading "-" combine
+// them further. An empty query returns a nil node. Errors are of type *Error.
+func Parse(input string) (Node, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return nil, nil
+	}
+
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, errorAt(t.col, "unexpected %s", describe(t))
+	}
+	return node, nil
+}
+
+func lex(input string) ([]token, error) {
+	runes := []rune(input)
+	var tokens []token
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		col := i + 1
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, col: col})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, col: col})
+			i++
+		case r == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) && runes[i+1] != ')':
+			tokens = append(tokens, token{kind: tokenNot, col: col})
+			i++
+		default:
+			t, next, err := lexWord(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, t)
+			i = next
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, col: len(runes) + 1}), nil
+}
+
+// lexWord reads a bare or quoted word starting at runes[i]. Quotes may also enclose the
+// value of a filter, as in tag:"needs review".
+func lexWord(runes []rune, i int) (token, int, error) {
+	t := token{kind: tokenWord, col: i + 1, colon: -1}
+	startsQuoted := runes[i] == '"'
+
+	var b strings.Builder
+	quoted := false
+	for i < len(runes) && !unicode.IsSpace(runes[i]) && runes[i] != '(' && runes[i] != ')' {
+		r := runes[i]
+		if r == '"' {
+			open := i + 1
+			i++
+			for i < len(runes) && runes[i] != '"' {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				b.WriteRune(runes[i])
+				i++
+			}
+			if i >= len(runes) {
+				return token{}, 0, errorAt(open, "unterminated quote")
+			}
+			i++
+			quoted = true
+			continue
+		}
+		if r == ':' && t.colon < 0 && !quoted && !startsQuoted {
+			t.colon = b.Len()
+			t.valueCol = i + 2
+		}
+		b.WriteRune(r)
+		i++
+	}
+
+	t.text = b.String()
+	if t.colon < 0 && !quoted {
+		switch t.text {
+		case "OR":
+			t.kind = tokenOr
+		case "AND":
+			t.kind = tokenAnd
+		}
+	}
+	return t, i, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) parseOr() (Node, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	nodes := []Node{first}
+	for p.peek().kind == tokenOr {
+		p.next()
+		node, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+
+	if len(nodes) == 1 {
+		return first, nil
+	}
+	return Or{Nodes: nodes, Col: first.Column()}, nil
+}
+
+func (p *parser) parseAnd() (Node, error) {
+	var nodes []Node
+	for {
+		switch p.peek().kind {
+		case tokenEOF, tokenRParen, tokenOr:
+			if len(nodes) == 0 {
+				t := p.peek()
+				return nil, errorAt(t.col, "expected a term before %s", describe(t))
+			}
+			if len(nodes) == 1 {
+				return nodes[0], nil
+			}
+			return And{Nodes: nodes, Col: nodes[0].Column()}, nil
+		case tokenAnd:
+			if len(nodes) == 0 {
+				return nil, errorAt(p.peek().col, "expected a term before AND")
+			}
+			p.next()
+			if k := p.peek().kind; k == tokenEOF || k == tokenRParen || k == tokenOr || k == tokenAnd {
+				return nil, errorAt(p.peek().col, "expected a term after AND")
+			}
+		}
+
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+}
+
+func (p *parser) parseUnary() (Node, error) {
+	if p.peek().kind != tokenNot {
+		return p.parsePrimary()
+	}
+	t := p.next()
+	node, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	return Not{Node: node, Col: t.col}, nil
+}
+
+func (p *parser) parsePrimary() (Node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenLParen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek().kind != tokenRParen {
+			return nil, errorAt(t.col, "unclosed parenthesis")
+		}
+		p.next()
+		return node, nil
+	case tokenWord:
+		return parseTerm(t)
+	default:
+		return nil, errorAt(t.col, "unexpected %s", describe(t))
+	}
+}
+
+// parseTerm turns a word into a text search or a checked field filter.
+func parseTerm(t token) (Node, error) {
+	if t.colon < 0 {
+		return Text{Value: t.text, Col: t.col}, nil
+	}
+
+	name := strings.ToLower(t.text[:t.colon])
+	spec, ok := fields[name]
+	if !ok {
+		return nil, errorAt(t.col, "unknown field %q", name)
+	}
+
+	f := Filter{Field: spec.name, Op: OpEqual, Value: t.text[t.colon+1:], Col: t.col, ValueCol: t.valueCol}
+	for _, op := range []string{OpGreaterEqual, OpLessEqual, OpGreater, OpLess} {
+		if rest, ok := strings.CutPrefix(f.Value, op); ok {
+			f.Op, f.Value = op, rest
+			f.ValueCol += len(op)
+			break
+		}
+	}
+	// "priority:=2" is accepted as a spelling of "priority:2".
+	if rest, ok := strings.CutPrefix(f.Value, "="); ok && f.Op == OpEqual {
+		f.Value = rest
+		f.ValueCol++
+	}
+
+	if f.Value == "" {
+		return nil, errorAt(f.ValueCol, "missing value for %s", name)
+	}
+	if f.Op != OpEqual && !spec.ordered {
+		return nil, errorAt(f.ValueCol, "%s does not support %s", name, f.Op)
+	}
+	if err := spec.check(f); err != nil {
+		return nil, errorAt(f.ValueCol, "%s", err.Error())
+	}
+	return f, nil
+}
+
+func describe(t token) string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of query"
+	case tokenRParen:
+		return "')'"
+	case tokenLParen:
+		return "'('"
+	case tokenOr:
+		return "OR"
+	case tokenAnd:
+		return "AND"
+	case tokenNot:
+		return "'-'"
+	default:
+		return "'" + t.text + "'"
+	}
+}
@@ -0,0 +1,64 @@
+package query
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	node, err := Parse(`due:<7d tag:work -tag:someday priority:>=2 "quarterly report" is:open`)
+	require.NoError(t, err)
+	require.Equal(t, And{Col: 1, Nodes: []Node{
+		Filter{Field: "due", Op: OpLess, Value: "7d", Col: 1, ValueCol: 6},
+		Filter{Field: "tag", Op: OpEqual, Value: "work", Col: 9, ValueCol: 13},
+		Not{Col: 18, Node: Filter{Field: "tag", Op: OpEqual, Value: "someday", Col: 19, ValueCol: 23}},
+		Filter{Field: "priority", Op: OpGreaterEqual, Value: "2", Col: 31, ValueCol: 42},
+		Text{Value: "quarterly report", Col: 44},
+		Filter{Field: "is", Op: OpEqual, Value: "open", Col: 63, ValueCol: 66},
+	}}, node)
+}
+
+func TestParseGroupsAndQuotedValues(t *testing.T) {
+	node, err := Parse(`(label:"needs review" OR status:review) AND -due:none`)
+	require.NoError(t, err)
+	require.Equal(t, And{Col: 2, Nodes: []Node{
+		Or{Col: 2, Nodes: []Node{
+			Filter{Field: "tag", Op: OpEqual, Value: "needs review", Col: 2, ValueCol: 8},
+			Filter{Field: "status", Op: OpEqual, Value: "review", Col: 26, ValueCol: 33},
+		}},
+		Not{Col: 45, Node: Filter{Field: "due", Op: OpEqual, Value: "none", Col: 46, ValueCol: 50}},
+	}}, node)
+
+	node, err = Parse("  ")
+	require.NoError(t, err)
+	require.Nil(t, node)
+}
+
+func TestParseErrorsCarryColumns(t *testing.T) {
+	cases := map[string]struct {
+		input string
+		col   int
+	}{
+		"unknown field":      {`tag:work colour:red`, 10},
+		"bad date":           {`due:soon`, 5},
+		"bad priority":       {`priority:>urgent`, 11},
+		"unordered field":    {`tag:>work`, 6},
+		"missing value":      {`is:`, 4},
+		"bad enum":           {`is:sleeping`, 4},
+		"unterminated quote": {`tag:work "report`, 10},
+		"unclosed group":     {`(tag:a OR tag:b`, 1},
+		"stray paren":        {`tag:a)`, 6},
+		"dangling or":        {`tag:a OR`, 9},
+		"none comparison":    {`due:<none`, 6},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(tc.input)
+			var qe *Error
+			require.True(t, errors.As(err, &qe), "expected *Error, got %v", err)
+			require.Equal(t, tc.col, qe.Col, qe.Msg)
+		})
+	}
+}
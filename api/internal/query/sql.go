@@ -0,0 +1,192 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Options control the translation of a query into SQL.
+type Options struct {
+	// Now anchors relative dates; it defaults to the current time.
+	Now time.Time
+	// Location decides where days start; it defaults to UTC.
+	Location *time.Location
+	// FirstArg is the number of the first placeholder, so that the condition can follow
+	// other parameters of the statement.
+	FirstArg int
+}
+
+// SQL translates a parsed query into a parameterized condition over the todos table.
+// A nil node matches every todo.
+func SQL(node Node, opts Options) (string, []any, error) {
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	if opts.Location == nil {
+		opts.Location = time.UTC
+	}
+	if opts.FirstArg < 1 {
+		opts.FirstArg = 1
+	}
+
+	if node == nil {
+		return "TRUE", nil, nil
+	}
+
+	t := &translator{opts: opts}
+	condition, err := t.node(node)
+	if err != nil {
+		return "", nil, err
+	}
+	return condition, t.args, nil
+}
+
+type translator struct {
+	opts Options
+	args []any
+}
+
+// arg adds a parameter and returns its placeholder.
+func (t *translator) arg(value any) string {
+	t.args = append(t.args, value)
+	return fmt.Sprintf("$%d", t.opts.FirstArg+len(t.args)-1)
+}
+
+func (t *translator) node(node Node) (string, error) {
+	switch n := node.(type) {
+	case And:
+		return t.join(n.Nodes, " AND ")
+	case Or:
+		return t.join(n.Nodes, " OR ")
+	case Not:
+		inner, err := t.node(n.Node)
+		if err != nil {
+			return "", err
+		}
+		// Comparisons with NULL columns are unknown; negating them must still match.
+		return "NOT COALESCE(" + inner + ", FALSE)", nil
+	case Text:
+		pattern := t.arg("%" + escapeLike(n.Value) + "%")
+		return fmt.Sprintf("(title ILIKE %s OR description ILIKE %s)", pattern, pattern), nil
+	case Filter:
+		return t.filter(n)
+	default:
+		return "", fmt.Errorf("query: unsupported node %T", node)
+	}
+}
+
+func (t *translator) join(nodes []Node, separator string) (string, error) {
+	parts := make([]string, len(nodes))
+	for i, n := range nodes {
+		part, err := t.node(n)
+		if err != nil {
+			return "", err
+		}
+		parts[i] = part
+	}
+	return "(" + strings.Join(parts, separator) + ")", nil
+}
+
+func (t *translator) filter(f Filter) (string, error) {
+	if column, ok := dateColumns[f.Field]; ok {
+		return t.date(column, f)
+	}
+
+	switch f.Field {
+	case "priority":
+		p, err := priority(f.Value)
+		if err != nil {
+			return "", errorAt(f.ValueCol, "%s", err.Error())
+		}
+		return fmt.Sprintf("priority %s %s", sqlOp(f.Op), t.arg(p)), nil
+	case "tag":
+		return fmt.Sprintf("EXISTS (SELECT 1 FROM unnest(labels) AS l WHERE lower(l) = lower(%s))", t.arg(f.Value)), nil
+	case "status":
+		return "status = " + t.arg(f.Value), nil
+	case "project":
+		if strings.EqualFold(f.Value, "none") {
+			return "project_id IS NULL", nil
+		}
+		if id, err := uuid.Parse(f.Value); err == nil {
+			return "project_id = " + t.arg(id), nil
+		}
+		return fmt.Sprintf("project_id IN (SELECT id FROM projects WHERE projects.user_id = todos.user_id AND lower(projects.name) = lower(%s))", t.arg(f.Value)), nil
+	case "is":
+		switch strings.ToLower(f.Value) {
+		case "open":
+			return "completed = FALSE", nil
+		case "done", "completed":
+			return "completed = TRUE", nil
+		case "overdue":
+			return "(completed = FALSE AND due_date < " + t.arg(t.opts.Now) + ")", nil
+		}
+	case "has":
+		switch strings.ToLower(f.Value) {
+		case "due":
+			return "due_date IS NOT NULL", nil
+		case "project":
+			return "project_id IS NOT NULL", nil
+		case "tag", "label":
+			return "cardinality(labels) > 0", nil
+		case "description":
+			return "description != ''", nil
+		case "parent":
+			return "parent_id IS NOT NULL", nil
+		}
+	}
+
+	return "", errorAt(f.ValueCol, "unsupported filter %s:%s", f.Field, f.Value)
+}
+
+// date compares a timestamp column. Days match as a whole: due:today covers the entire
+// day and due:<today ends at midnight. A bare offset matches the span between now and
+// the offset, so due:7d is due within the next week and created:-7d the past week.
+func (t *translator) date(column string, f Filter) (string, error) {
+	if strings.EqualFold(f.Value, "none") {
+		return column + " IS NULL", nil
+	}
+
+	o, err := dateOperand(f.Value, t.opts.Now, t.opts.Location)
+	if err != nil {
+		return "", errorAt(f.ValueCol, "%s", err.Error())
+	}
+
+	if o.relative {
+		if f.Op != OpEqual {
+			return fmt.Sprintf("%s %s %s", column, f.Op, t.arg(o.start)), nil
+		}
+		from, to := t.opts.Now, o.start
+		if to.Before(from) {
+			from, to = to, from
+		}
+		return fmt.Sprintf("(%s >= %s AND %s <= %s)", column, t.arg(from), column, t.arg(to)), nil
+	}
+
+	switch f.Op {
+	case OpLess:
+		return fmt.Sprintf("%s < %s", column, t.arg(o.start)), nil
+	case OpLessEqual:
+		return fmt.Sprintf("%s < %s", column, t.arg(o.end)), nil
+	case OpGreater:
+		return fmt.Sprintf("%s >= %s", column, t.arg(o.end)), nil
+	case OpGreaterEqual:
+		return fmt.Sprintf("%s >= %s", column, t.arg(o.start)), nil
+	default:
+		return fmt.Sprintf("(%s >= %s AND %s < %s)", column, t.arg(o.start), column, t.arg(o.end)), nil
+	}
+}
+
+func sqlOp(op string) string {
+	if op == OpEqual {
+		return "="
+	}
+	return op
+}
+
+// escapeLike escapes the wildcards of LIKE patterns.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,62 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQL(t *testing.T) {
+	now := time.Date(2025, 3, 12, 15, 30, 0, 0, time.UTC)
+	today := time.Date(2025, 3, 12, 0, 0, 0, 0, time.UTC)
+
+	node, err := Parse(`due:<7d tag:work -tag:someday priority:>=high "50%_done" is:open`)
+	require.NoError(t, err)
+
+	condition, args, err := SQL(node, Options{Now: now, FirstArg: 2})
+	require.NoError(t, err)
+	require.Equal(t, "(due_date < $2"+
+		" AND EXISTS (SELECT 1 FROM unnest(labels) AS l WHERE lower(l) = lower($3))"+
+		" AND NOT COALESCE(EXISTS (SELECT 1 FROM unnest(labels) AS l WHERE lower(l) = lower($4)), FALSE)"+
+		" AND priority >= $5"+
+		" AND (title ILIKE $6 OR description ILIKE $6)"+
+		" AND completed = FALSE)", condition)
+	require.Equal(t, []any{now.AddDate(0, 0, 7), "work", "someday", 3, `%50\%\_done%`}, args)
+
+	node, err = Parse(`due:today OR created:-1w OR project:none`)
+	require.NoError(t, err)
+	condition, args, err = SQL(node, Options{Now: now})
+	require.NoError(t, err)
+	require.Equal(t, "((due_date >= $1 AND due_date < $2) OR (created_at >= $3 AND created_at <= $4) OR project_id IS NULL)", condition)
+	require.Equal(t, []any{today, today.AddDate(0, 0, 1), now.AddDate(0, 0, -7), now}, args)
+}
+
+func TestSQLDaysFollowLocation(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	now := time.Date(2025, 3, 12, 23, 30, 0, 0, time.UTC) // already the 13th in Berlin
+
+	node, err := Parse(`due:<=today`)
+	require.NoError(t, err)
+	_, args, err := SQL(node, Options{Now: now, Location: berlin})
+	require.NoError(t, err)
+	require.Equal(t, []any{time.Date(2025, 3, 14, 0, 0, 0, 0, berlin)}, args)
+}
+
+func TestSQLProjects(t *testing.T) {
+	id := uuid.New()
+	node, err := Parse("project:" + id.String() + " OR project:Work")
+	require.NoError(t, err)
+
+	condition, args, err := SQL(node, Options{})
+	require.NoError(t, err)
+	require.Equal(t, "(project_id = $1 OR project_id IN (SELECT id FROM projects WHERE projects.user_id = todos.user_id AND lower(projects.name) = lower($2)))", condition)
+	require.Equal(t, []any{id, "Work"}, args)
+
+	condition, args, err = SQL(nil, Options{})
+	require.NoError(t, err)
+	require.Equal(t, "TRUE", condition)
+	require.Empty(t, args)
+}
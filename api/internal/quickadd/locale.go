@@ -0,0 +1,184 @@
+package quickadd
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported locales.
+const (
+	LocaleEnglish = "en"
+	LocaleGerman  = "de"
+)
+
+type unit int
+
+const (
+	unitHour unit = iota
+	unitDay
+	unitWeek
+	unitMonth
+	unitYear
+)
+
+// locale is the vocabulary of one language. Phrases may span several words; all of them
+// are lower case.
+type locale struct {
+	// relativeDays maps words such as "tomorrow" onto a number of days from today.
+	relativeDays map[string]int
+	// weekdays leaves out abbreviations that double as common words, such as "sun".
+	weekdays map[string]time.Weekday
+	months   map[string]time.Month
+	// next precedes weekdays and units: "next friday", "next week".
+	next []string
+	// in precedes a duration: "in 3 days".
+	in      []string
+	numbers map[string]int
+	units   map[string]unit
+	// datePrefixes and timePrefixes may precede a date or a time: "on friday", "at 9".
+	datePrefixes []string
+	timePrefixes []string
+	// every starts a recurrence; frequencies are single-word recurrences such as "daily".
+	every       []string
+	frequencies map[string]rule
+	workday     []string
+	// ofMonth may follow the day of a monthly recurrence: "every 1st of the month".
+	ofMonth   []string
+	clockWord map[string]int
+	// oclock may follow an hour: "9 uhr".
+	oclock []string
+	// meridiem allows am/pm hours.
+	meridiem bool
+	// dayOfMonth matches a day such as "1st" or "1.", capturing the number.
+	dayOfMonth *regexp.Regexp
+	// numericDate matches a date such as 1/31 or 31.1.2027.
+	numericDate func(s string) (month time.Month, day, year int, ok bool)
+}
+
+var (
+	usDate     = regexp.MustCompile(`^(\d{1,2})/(\d{1,2})(?:/(\d{4}))?$`)
+	germanDate = regexp.MustCompile(`^(\d{1,2})\.(\d{1,2})\.(\d{4})?$`)
+)
+
+var locales = map[string]*locale{
+	LocaleEnglish: {
+		relativeDays: map[string]int{"today": 0, "tomorrow": 1, "tmr": 1, "day after tomorrow": 2, "yesterday": -1},
+		weekdays: map[string]time.Weekday{
+			"monday": time.Monday, "mon": time.Monday, "tuesday": time.Tuesday, "tue": time.Tuesday, "tues": time.Tuesday,
+			"wednesday": time.Wednesday, "wed": time.Wednesday, "thursday": time.Thursday, "thu": time.Thursday, "thurs": time.Thursday,
+			"friday": time.Friday, "fri": time.Friday, "saturday": time.Saturday, "sunday": time.Sunday,
+		},
+		months: map[string]time.Month{
+			"january": time.January, "jan": time.January, "february": time.February, "feb": time.February, "march": time.March, "mar": time.March,
+			"april": time.April, "apr": time.April, "may": time.May, "june": time.June, "jun": time.June, "july": time.July, "jul": time.July,
+			"august": time.August, "aug": time.August, "september": time.September, "sep": time.September, "sept": time.September,
+			"october": time.October, "oct": time.October, "november": time.November, "nov": time.November, "december": time.December, "dec": time.December,
+		},
+		next:    []string{"next"},
+		in:      []string{"in"},
+		numbers: map[string]int{"a": 1, "an": 1, "one": 1, "two": 2, "three": 3, "four": 4, "five": 5, "other": 2},
+		units: map[string]unit{
+			"hour": unitHour, "hours": unitHour, "day": unitDay, "days": unitDay, "week": unitWeek, "weeks": unitWeek,
+			"month": unitMonth, "months": unitMonth, "year": unitYear, "years": unitYear,
+		},
+		datePrefixes: []string{"on"},
+		timePrefixes: []string{"at", "@"},
+		every:        []string{"every", "each"},
+		frequencies: map[string]rule{
+			"daily": {freq: FreqDaily}, "weekly": {freq: FreqWeekly}, "monthly": {freq: FreqMonthly},
+			"yearly": {freq: FreqYearly}, "annually": {freq: FreqYearly}, "weekdays": {freq: FreqWeekly, byDay: workdays},
+		},
+		workday:    []string{"weekday", "workday"},
+		ofMonth:    []string{"of the month", "of month", "of each month", "of every month"},
+		clockWord:  map[string]int{"noon": 12, "midday": 12, "midnight": 0},
+		meridiem:   true,
+		dayOfMonth: regexp.MustCompile(`^(\d{1,2})(?:st|nd|rd|th)?$`),
+		numericDate: func(s string) (time.Month, int, int, bool) {
+			m := usDate.FindStringSubmatch(s)
+			if m == nil {
+				return 0, 0, 0, false
+			}
+			month, _ := strconv.Atoi(m[1])
+			day, _ := strconv.Atoi(m[2])
+			year, _ := strconv.Atoi(m[3])
+			return time.Month(month), day, year, true
+		},
+	},
+	LocaleGerman: {
+		relativeDays: map[string]int{"heute": 0, "morgen": 1, "übermorgen": 2, "gestern": -1},
+		weekdays: map[string]time.Weekday{
+			"montag": time.Monday, "dienstag": time.Tuesday, "mittwoch": time.Wednesday,
+			"donnerstag": time.Thursday, "freitag": time.Friday, "samstag": time.Saturday,
+			"sonnabend": time.Saturday, "sonntag": time.Sunday,
+		},
+		months: map[string]time.Month{
+			"januar": time.January, "jan": time.January, "jänner": time.January, "februar": time.February, "feb": time.February,
+			"märz": time.March, "mär": time.March, "maerz": time.March, "april": time.April, "apr": time.April, "mai": time.May,
+			"juni": time.June, "jun": time.June, "juli": time.July, "jul": time.July, "august": time.August, "aug": time.August,
+			"september": time.September, "sep": time.September, "sept": time.September, "oktober": time.October, "okt": time.October,
+			"november": time.November, "nov": time.November, "dezember": time.December, "dez": time.December,
+		},
+		next:    []string{"nächsten", "nächste", "nächster", "nächstes", "kommenden", "kommende", "kommendes"},
+		in:      []string{"in"},
+		numbers: map[string]int{"einem": 1, "einer": 1, "ein": 1, "eine": 1, "zwei": 2, "drei": 3, "vier": 4, "fünf": 5, "zweiten": 2, "zweite": 2},
+		units: map[string]unit{
+			"stunde": unitHour, "stunden": unitHour, "tag": unitDay, "tage": unitDay, "tagen": unitDay,
+			"woche": unitWeek, "wochen": unitWeek, "monat": unitMonth, "monate": unitMonth, "monaten": unitMonth,
+			"jahr": unitYear, "jahre": unitYear, "jahren": unitYear,
+		},
+		datePrefixes: []string{"am"},
+		timePrefixes: []string{"um", "@"},
+		every:        []string{"jeden", "jede", "jedes", "alle"},
+		frequencies: map[string]rule{
+			"täglich": {freq: FreqDaily}, "wöchentlich": {freq: FreqWeekly}, "monatlich": {freq: FreqMonthly},
+			"jährlich": {freq: FreqYearly}, "werktags": {freq: FreqWeekly, byDay: workdays},
+		},
+		workday:    []string{"werktag"},
+		ofMonth:    []string{"des monats", "im monat"},
+		clockWord:  map[string]int{"mittag": 12, "mittags": 12, "mitternacht": 0},
+		oclock:     []string{"uhr"},
+		dayOfMonth: regexp.MustCompile(`^(\d{1,2})\.?$`),
+		numericDate: func(s string) (time.Month, int, int, bool) {
+			m := germanDate.FindStringSubmatch(s)
+			if m == nil {
+				return 0, 0, 0, false
+			}
+			day, _ := strconv.Atoi(m[1])
+			month, _ := strconv.Atoi(m[2])
+			year, _ := strconv.Atoi(m[3])
+			return time.Month(month), day, year, true
+		},
+	},
+}
+
+// MatchLocale picks the supported locale for a locale tag or an Accept-Language header
+// such as "de-AT,de;q=0.9,en;q=0.8", preferring higher weights. It defaults to English.
+func MatchLocale(header string) string {
+	type candidate struct {
+		locale string
+		weight float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		weight := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if w, err := strconv.ParseFloat(q, 64); err == nil {
+				weight = w
+			}
+		}
+		primary, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		primary, _, _ = strings.Cut(primary, "_")
+		if _, ok := locales[primary]; ok && weight > 0 {
+			candidates = append(candidates, candidate{primary, weight})
+		}
+	}
+	if len(candidates) == 0 {
+		return LocaleEnglish
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].weight > candidates[j].weight })
+	return candidates[0].locale
+}
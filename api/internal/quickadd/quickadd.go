@@ -0,0 +1,489 @@
+package quickadd
+
+import (
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Options control how Parse reads a quick add text.
+type Options struct {
+	// Now anchors relative dates; it defaults to the current time.
+	Now time.Time
+	// Location is the user's time zone; it defaults to UTC.
+	Location *time.Location
+	// Locale selects the vocabulary, LocaleEnglish (the default) or LocaleGerman.
+	Locale string
+}
+
+// Result is what a quick add text describes. Fields that the text does not mention are
+// left empty.
+type Result struct {
+	Title   string     `json:"title"`
+	DueDate *time.Time `json:"due_date,omitempty"`
+	// Recurrence is an RRULE value such as FREQ=MONTHLY;BYMONTHDAY=1.
+	Recurrence string   `json:"recurrence,omitempty"`
+	Priority   *int     `json:"priority,omitempty"`
+	Labels     []string `json:"labels,omitempty"`
+	// Project is the name of the project, without the leading '#'.
+	Project string `json:"project,omitempty"`
+}
+
+// priorities maps Todoist-style markers, where p1 is the most urgent, onto todo priorities.
+var priorities = map[string]int{"!p1": 3, "!p2": 2, "!p3": 1, "!p4": 0}
+
+var (
+	isoDate   = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	clockTime = regexp.MustCompile(`^(\d{1,2})(?::(\d{2}))?(am|pm|a\.m\.|p\.m\.|uhr|h)?$`)
+	yearToken = regexp.MustCompile(`^\d{4}$`)
+)
+
+// Parse reads a text such as "Pay rent every 1st of month !p1 #finance @home tomorrow 9am".
+// Words that describe the due date, time, recurrence, priority (!p1 to !p4), labels
+// (@label) and project (#project) are taken out; the remaining words form the title.
+// Only the first date, time and recurrence count; later ones stay in the title.
+//
+// A weekday means its next occurrence, today included, and "next friday" the Friday of the
+// following week. A time without a date means its next occurrence. A recurrence without a
+// date starts at its first occurrence. Dates without a time are due at midnight.
+//
+// Parse is deterministic: the same text, options and Now always give the same result.
+func Parse(text string, opts Options) Result {
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	if opts.Location == nil {
+		opts.Location = time.UTC
+	}
+	loc, ok := locales[opts.Locale]
+	if !ok {
+		loc = locales[LocaleEnglish]
+	}
+
+	now := opts.Now.In(opts.Location)
+	p := &parser{
+		locale: loc,
+		now:    now,
+		today:  time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, opts.Location),
+	}
+	for _, field := range strings.Fields(text) {
+		p.words = append(p.words, word{raw: field, norm: strings.TrimRight(strings.ToLower(field), ",;")})
+	}
+
+	var title []string
+	for i := 0; i < len(p.words); {
+		n := p.marker(i)
+		if n == 0 && p.rule == nil {
+			n = p.recurrence(i)
+		}
+		if n == 0 && p.day == nil {
+			n = p.date(i)
+		}
+		if n == 0 && p.clock == nil {
+			n = p.time(i)
+		}
+		if n == 0 {
+			title = append(title, p.words[i].raw)
+			n = 1
+		}
+		i += n
+	}
+
+	p.result.Title = strings.Join(title, " ")
+	p.result.DueDate = p.due()
+	if p.rule != nil {
+		p.result.Recurrence = p.rule.String()
+	}
+	return p.result
+}
+
+type word struct {
+	raw string
+	// norm is the lower-case word without trailing commas.
+	norm string
+}
+
+// clock is a time of day.
+type clock struct {
+	hour, minute int
+}
+
+type parser struct {
+	locale *locale
+	words  []word
+	now    time.Time
+	today  time.Time
+
+	result Result
+	day    *time.Time
+	clock  *clock
+	// exact is set by durations in hours, which fix both the day and the time.
+	exact *time.Time
+	rule  *rule
+}
+
+// due combines the parsed day, time and recurrence into the due date.
+func (p *parser) due() *time.Time {
+	if p.exact != nil {
+		return p.exact
+	}
+	if p.day == nil && p.clock == nil && p.rule == nil {
+		return nil
+	}
+
+	at := func(day time.Time) time.Time {
+		if p.clock == nil {
+			return day
+		}
+		return time.Date(day.Year(), day.Month(), day.Day(), p.clock.hour, p.clock.minute, 0, 0, day.Location())
+	}
+
+	if p.day != nil {
+		d := at(*p.day)
+		return &d
+	}
+
+	// Without a date the todo is due at the next occurrence of the recurrence, or of the
+	// time of day, that has not passed yet.
+	r := rule{freq: FreqDaily}
+	if p.rule != nil {
+		r = *p.rule
+	}
+	d := at(r.next(p.today))
+	if p.clock != nil && !d.After(p.now) {
+		d = at(r.next(p.today.AddDate(0, 0, 1)))
+	}
+	return &d
+}
+
+// marker reads !p1 priorities, @labels and #projects.
+func (p *parser) marker(i int) int {
+	w := p.words[i].norm
+	if prio, ok := priorities[w]; ok {
+		p.result.Priority = &prio
+		return 1
+	}
+	if len(w) < 2 {
+		return 0
+	}
+	name := strings.TrimRight(p.words[i].raw[1:], ",;.!?")
+	if name == "" {
+		return 0
+	}
+	switch w[0] {
+	case '@':
+		if !slices.Contains(p.result.Labels, name) {
+			p.result.Labels = append(p.result.Labels, name)
+		}
+		return 1
+	case '#':
+		p.result.Project = name
+		return 1
+	}
+	return 0
+}
+
+// phrase returns the number of words of the longest phrase starting at word i, or 0.
+func (p *parser) phrase(i int, phrases ...string) int {
+	best := 0
+	for _, ph := range phrases {
+		parts := strings.Fields(ph)
+		if len(parts) <= best || i+len(parts) > len(p.words) {
+			continue
+		}
+		match := true
+		for j, part := range parts {
+			if p.words[i+j].norm != part {
+				match = false
+				break
+			}
+		}
+		if match {
+			best = len(parts)
+		}
+	}
+	return best
+}
+
+// lookup finds the longest phrase starting at word i among the keys of m.
+func lookup[V any](p *parser, i int, m map[string]V) (V, int) {
+	var value V
+	best := 0
+	for key, v := range m {
+		if n := p.phrase(i, key); n > best {
+			value, best = v, n
+		}
+	}
+	return value, best
+}
+
+func (p *parser) norm(i int) string {
+	if i >= len(p.words) {
+		return ""
+	}
+	return p.words[i].norm
+}
+
+// number reads a count such as "3" or "three".
+func (p *parser) number(i int) (int, bool) {
+	if n, ok := p.locale.numbers[p.norm(i)]; ok {
+		return n, true
+	}
+	n, err := strconv.Atoi(p.norm(i))
+	return n, err == nil && n > 0 && n < 1000
+}
+
+// recurrence reads "daily", "every 2 weeks", "every monday", "every weekday" and
+// "every 1st of the month", including their German counterparts.
+func (p *parser) recurrence(i int) int {
+	if r, ok := p.locale.frequencies[p.norm(i)]; ok {
+		p.rule = &r
+		return 1
+	}
+
+	n := p.phrase(i, p.locale.every...)
+	if n == 0 {
+		return 0
+	}
+	j := i + n
+
+	if wd, ok := p.locale.weekdays[p.norm(j)]; ok {
+		p.rule = &rule{freq: FreqWeekly, byDay: []time.Weekday{wd}}
+		return j + 1 - i
+	}
+	if m := p.phrase(j, p.locale.workday...); m > 0 {
+		p.rule = &rule{freq: FreqWeekly, byDay: workdays}
+		return j + m - i
+	}
+
+	if count, ok := p.number(j); ok {
+		if u, ok := p.locale.units[p.norm(j+1)]; ok && u != unitHour {
+			p.rule = &rule{freq: frequency(u), interval: count}
+			return j + 2 - i
+		}
+	}
+	if u, ok := p.locale.units[p.norm(j)]; ok && u != unitHour {
+		p.rule = &rule{freq: frequency(u)}
+		return j + 1 - i
+	}
+
+	if m := p.locale.dayOfMonth.FindStringSubmatch(p.norm(j)); m != nil {
+		day, _ := strconv.Atoi(m[1])
+		if day < 1 || day > 31 {
+			return 0
+		}
+		p.rule = &rule{freq: FreqMonthly, byMonthDay: day}
+		return j + 1 + p.phrase(j+1, p.locale.ofMonth...) - i
+	}
+	return 0
+}
+
+func frequency(u unit) string {
+	switch u {
+	case unitWeek:
+		return FreqWeekly
+	case unitMonth:
+		return FreqMonthly
+	case unitYear:
+		return FreqYearly
+	default:
+		return FreqDaily
+	}
+}
+
+// date reads relative days, weekdays, "next week", durations such as "in 3 days" and
+// calendar dates, optionally preceded by a preposition such as "on".
+func (p *parser) date(i int) int {
+	if n := p.phrase(i, p.locale.datePrefixes...); n > 0 {
+		if m := p.dateAt(i + n); m > 0 {
+			return n + m
+		}
+		return 0
+	}
+	return p.dateAt(i)
+}
+
+func (p *parser) dateAt(i int) int {
+	if days, n := lookup(p, i, p.locale.relativeDays); n > 0 {
+		return p.setDay(p.today.AddDate(0, 0, days), n)
+	}
+	if wd, ok := p.locale.weekdays[p.norm(i)]; ok {
+		return p.setDay(p.today.AddDate(0, 0, (int(wd)-int(p.today.Weekday())+7)%7), 1)
+	}
+
+	if n := p.phrase(i, p.locale.next...); n > 0 {
+		// Weeks start on Monday.
+		monday := p.today.AddDate(0, 0, 7-(int(p.today.Weekday())+6)%7)
+		if wd, ok := p.locale.weekdays[p.norm(i+n)]; ok {
+			return p.setDay(monday.AddDate(0, 0, (int(wd)+6)%7), n+1)
+		}
+		switch p.locale.units[p.norm(i+n)] {
+		case unitWeek:
+			return p.setDay(monday, n+1)
+		case unitMonth:
+			return p.setDay(time.Date(p.today.Year(), p.today.Month()+1, 1, 0, 0, 0, 0, p.today.Location()), n+1)
+		case unitYear:
+			return p.setDay(time.Date(p.today.Year()+1, time.January, 1, 0, 0, 0, 0, p.today.Location()), n+1)
+		}
+		return 0
+	}
+
+	if n := p.phrase(i, p.locale.in...); n > 0 {
+		count, ok := p.number(i + n)
+		u, isUnit := p.locale.units[p.norm(i+n+1)]
+		if !ok || !isUnit {
+			return 0
+		}
+		switch u {
+		case unitHour:
+			if p.clock != nil {
+				return 0
+			}
+			exact := p.now.Add(time.Duration(count) * time.Hour)
+			p.exact = &exact
+			p.day = &exact
+			p.clock = &clock{exact.Hour(), exact.Minute()}
+			return n + 2
+		case unitDay:
+			return p.setDay(p.today.AddDate(0, 0, count), n+2)
+		case unitWeek:
+			return p.setDay(p.today.AddDate(0, 0, 7*count), n+2)
+		case unitMonth:
+			return p.setDay(p.today.AddDate(0, count, 0), n+2)
+		default:
+			return p.setDay(p.today.AddDate(count, 0, 0), n+2)
+		}
+	}
+
+	return p.calendarDate(i)
+}
+
+// calendarDate reads 2027-01-31, the locale's numeric dates and dates with month names
+// such as "jan 31", "31st of january" or "31. januar", each with an optional year.
+func (p *parser) calendarDate(i int) int {
+	w := p.norm(i)
+	if isoDate.MatchString(w) {
+		d, err := time.ParseInLocation(time.DateOnly, w, p.today.Location())
+		if err != nil {
+			return 0
+		}
+		return p.setDay(d, 1)
+	}
+	if month, day, year, ok := p.locale.numericDate(w); ok {
+		return p.setMonthDay(year, month, day, 1)
+	}
+
+	// Month first: "jan 31", "january 31st".
+	if month, ok := p.locale.months[w]; ok {
+		if m := p.locale.dayOfMonth.FindStringSubmatch(p.norm(i + 1)); m != nil {
+			day, _ := strconv.Atoi(m[1])
+			return p.monthDayYear(i+2, month, day, 2)
+		}
+		return 0
+	}
+
+	// Day first: "31 jan", "31st of january", "31. januar".
+	if m := p.locale.dayOfMonth.FindStringSubmatch(w); m != nil {
+		j := i + 1
+		if p.locale.meridiem && p.norm(j) == "of" {
+			j++
+		}
+		if month, ok := p.locale.months[p.norm(j)]; ok {
+			day, _ := strconv.Atoi(m[1])
+			return p.monthDayYear(j+1, month, day, j+1-i)
+		}
+	}
+	return 0
+}
+
+// monthDayYear sets a date whose optional year is the word at i.
+func (p *parser) monthDayYear(i int, month time.Month, day, n int) int {
+	if yearToken.MatchString(p.norm(i)) {
+		year, _ := strconv.Atoi(p.norm(i))
+		return p.setMonthDay(year, month, day, n+1)
+	}
+	return p.setMonthDay(0, month, day, n)
+}
+
+// setMonthDay sets a calendar date. Without a year it is the next such date, today included.
+func (p *parser) setMonthDay(year int, month time.Month, day, n int) int {
+	if year != 0 {
+		d, ok := date(year, month, day, p.today.Location())
+		if !ok {
+			return 0
+		}
+		return p.setDay(d, n)
+	}
+	// February 29 may be several years ahead.
+	for y := p.today.Year(); y <= p.today.Year()+8; y++ {
+		if d, ok := date(y, month, day, p.today.Location()); ok && !d.Before(p.today) {
+			return p.setDay(d, n)
+		}
+	}
+	return 0
+}
+
+func (p *parser) setDay(d time.Time, n int) int {
+	p.day = &d
+	return n
+}
+
+// time reads times of day such as "9am", "9:30 pm", "14:00", "at 9", "noon" or "9 uhr".
+// A bare number is only a time after a preposition such as "at" or before "uhr".
+func (p *parser) time(i int) int {
+	prefix := p.phrase(i, p.locale.timePrefixes...)
+	j := i + prefix
+
+	if hour, ok := p.locale.clockWord[p.norm(j)]; ok {
+		p.clock = &clock{hour: hour}
+		return prefix + 1
+	}
+
+	m := clockTime.FindStringSubmatch(p.norm(j))
+	if m == nil {
+		return 0
+	}
+	hour, _ := strconv.Atoi(m[1])
+	minute, _ := strconv.Atoi(m[2])
+	suffix := strings.ReplaceAll(m[3], ".", "")
+	n := prefix + 1
+
+	if suffix == "" {
+		next := p.norm(j + 1)
+		switch {
+		case p.locale.meridiem && (next == "am" || next == "pm" || next == "a.m." || next == "p.m."):
+			suffix = strings.ReplaceAll(next, ".", "")
+			n++
+		case slices.Contains(p.locale.oclock, next):
+			suffix = "uhr"
+			n++
+		}
+	}
+
+	switch suffix {
+	case "am", "pm":
+		if !p.locale.meridiem || hour < 1 || hour > 12 {
+			return 0
+		}
+		hour %= 12
+		if suffix == "pm" {
+			hour += 12
+		}
+	case "uhr", "h":
+		if len(p.locale.oclock) == 0 {
+			return 0
+		}
+	default:
+		if prefix == 0 && m[2] == "" {
+			return 0
+		}
+	}
+	if hour > 23 || minute > 59 {
+		return 0
+	}
+
+	p.clock = &clock{hour: hour, minute: minute}
+	return n
+}
@@ -0,0 +1,113 @@
+package quickadd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	// A Wednesday afternoon.
+	now := time.Date(2026, time.October, 14, 15, 30, 0, 0, berlin)
+	at := func(month time.Month, day, hour, minute int) *time.Time {
+		d := time.Date(2026, month, day, hour, minute, 0, 0, berlin)
+		return &d
+	}
+	prio := func(p int) *int { return &p }
+
+	tests := []struct {
+		name   string
+		locale string
+		text   string
+		want   Result
+	}{
+		{
+			name: "full example",
+			text: "Pay rent every 1st of month !p1 #finance @home tomorrow 9am",
+			want: Result{Title: "Pay rent", DueDate: at(time.October, 15, 9, 0), Recurrence: "FREQ=MONTHLY;BYMONTHDAY=1", Priority: prio(3), Labels: []string{"home"}, Project: "finance"},
+		},
+		{name: "plain title", text: "Buy 3 apples", want: Result{Title: "Buy 3 apples"}},
+		{name: "today", text: "Call mom today", want: Result{Title: "Call mom", DueDate: at(time.October, 14, 0, 0)}},
+		{name: "weekday includes today", text: "Standup wednesday", want: Result{Title: "Standup", DueDate: at(time.October, 14, 0, 0)}},
+		{name: "weekday", text: "Report on friday at 17:00", want: Result{Title: "Report", DueDate: at(time.October, 16, 17, 0)}},
+		{name: "next weekday", text: "Review next friday", want: Result{Title: "Review", DueDate: at(time.October, 23, 0, 0)}},
+		{name: "next week", text: "Plan sprint next week", want: Result{Title: "Plan sprint", DueDate: at(time.October, 19, 0, 0)}},
+		{name: "next month", text: "Invoice next month", want: Result{Title: "Invoice", DueDate: at(time.November, 1, 0, 0)}},
+		{name: "in days", text: "Water plants in 3 days", want: Result{Title: "Water plants", DueDate: at(time.October, 17, 0, 0)}},
+		{name: "in hours", text: "Check oven in 2 hours", want: Result{Title: "Check oven", DueDate: at(time.October, 14, 17, 30)}},
+		{name: "passed time rolls over", text: "Dentist 9:30am", want: Result{Title: "Dentist", DueDate: at(time.October, 15, 9, 30)}},
+		{name: "upcoming time", text: "Dentist 4 pm", want: Result{Title: "Dentist", DueDate: at(time.October, 14, 16, 0)}},
+		{name: "bare number after at", text: "Lunch at 12", want: Result{Title: "Lunch", DueDate: at(time.October, 15, 12, 0)}},
+		{name: "noon", text: "Lunch tomorrow noon", want: Result{Title: "Lunch", DueDate: at(time.October, 15, 12, 0)}},
+		{name: "month name", text: "Birthday dec 24th", want: Result{Title: "Birthday", DueDate: at(time.December, 24, 0, 0)}},
+		{name: "passed date is next year", text: "Taxes 31st of may", want: Result{Title: "Taxes", DueDate: ptr(time.Date(2027, time.May, 31, 0, 0, 0, 0, berlin))}},
+		{name: "date with year", text: "Renew passport jan 5 2028", want: Result{Title: "Renew passport", DueDate: ptr(time.Date(2028, time.January, 5, 0, 0, 0, 0, berlin))}},
+		{name: "iso date", text: "Launch 2026-11-02", want: Result{Title: "Launch", DueDate: at(time.November, 2, 0, 0)}},
+		{name: "us numeric date", text: "Launch 11/2", want: Result{Title: "Launch", DueDate: at(time.November, 2, 0, 0)}},
+		{name: "invalid date stays in title", text: "Party feb 30", want: Result{Title: "Party feb 30"}},
+		{name: "daily", text: "Stretch daily", want: Result{Title: "Stretch", DueDate: at(time.October, 14, 0, 0), Recurrence: "FREQ=DAILY"}},
+		{name: "every weekday at time", text: "Standup every weekday at 9:15", want: Result{Title: "Standup", DueDate: at(time.October, 15, 9, 15), Recurrence: "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"}},
+		{name: "every weekday name", text: "Trash every monday", want: Result{Title: "Trash", DueDate: at(time.October, 19, 0, 0), Recurrence: "FREQ=WEEKLY;BYDAY=MO"}},
+		{name: "every other week", text: "Payroll every other week", want: Result{Title: "Payroll", DueDate: at(time.October, 14, 0, 0), Recurrence: "FREQ=WEEKLY;INTERVAL=2"}},
+		{name: "every n months", text: "Haircut every 2 months", want: Result{Title: "Haircut", DueDate: at(time.October, 14, 0, 0), Recurrence: "FREQ=MONTHLY;INTERVAL=2"}},
+		{name: "priority and labels", text: "Fix bug !p2 @work @urgent @work", want: Result{Title: "Fix bug", Priority: prio(2), Labels: []string{"work", "urgent"}}},
+		{name: "second date stays in title", text: "Move meeting from monday to friday", want: Result{Title: "Move meeting from to friday", DueDate: at(time.October, 19, 0, 0)}},
+		{name: "german words ignored in english", text: "Einkaufen morgen", want: Result{Title: "Einkaufen morgen"}},
+
+		{name: "german full example", locale: LocaleGerman, text: "Miete zahlen jeden 1. des Monats !p1 #Finanzen @zuhause morgen um 9 Uhr", want: Result{Title: "Miete zahlen", DueDate: at(time.October, 15, 9, 0), Recurrence: "FREQ=MONTHLY;BYMONTHDAY=1", Priority: prio(3), Labels: []string{"zuhause"}, Project: "Finanzen"}},
+		{name: "german day after tomorrow", locale: LocaleGerman, text: "Arzt übermorgen 14:30", want: Result{Title: "Arzt", DueDate: at(time.October, 16, 14, 30)}},
+		{name: "german weekday", locale: LocaleGerman, text: "Bericht am Freitag", want: Result{Title: "Bericht", DueDate: at(time.October, 16, 0, 0)}},
+		{name: "german next weekday", locale: LocaleGerman, text: "Review nächsten Montag", want: Result{Title: "Review", DueDate: at(time.October, 19, 0, 0)}},
+		{name: "german duration", locale: LocaleGerman, text: "Pflanzen gießen in 2 Wochen", want: Result{Title: "Pflanzen gießen", DueDate: at(time.October, 28, 0, 0)}},
+		{name: "german date", locale: LocaleGerman, text: "Geburtstag 24. Dezember", want: Result{Title: "Geburtstag", DueDate: at(time.December, 24, 0, 0)}},
+		{name: "german numeric date", locale: LocaleGerman, text: "Steuer 31.5.2027 18 Uhr", want: Result{Title: "Steuer", DueDate: ptr(time.Date(2027, time.May, 31, 18, 0, 0, 0, berlin))}},
+		{name: "german noon", locale: LocaleGerman, text: "Essen heute mittags", want: Result{Title: "Essen", DueDate: at(time.October, 14, 12, 0)}},
+		{name: "german recurrence", locale: LocaleGerman, text: "Sport alle 2 Wochen", want: Result{Title: "Sport", DueDate: at(time.October, 14, 0, 0), Recurrence: "FREQ=WEEKLY;INTERVAL=2"}},
+		{name: "german workdays", locale: LocaleGerman, text: "Standup werktags", want: Result{Title: "Standup", DueDate: at(time.October, 14, 0, 0), Recurrence: "FREQ=WEEKLY;BYDAY=MO,TU,WE,TH,FR"}},
+		{name: "german every weekday name", locale: LocaleGerman, text: "Müll jeden Dienstag", want: Result{Title: "Müll", DueDate: at(time.October, 20, 0, 0), Recurrence: "FREQ=WEEKLY;BYDAY=TU"}},
+		{name: "german 9am is not a time", locale: LocaleGerman, text: "Treffen 9 am Montag", want: Result{Title: "Treffen 9", DueDate: at(time.October, 19, 0, 0)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse(tt.text, Options{Now: now, Location: berlin, Locale: tt.locale})
+			require.Equal(t, tt.want, got)
+			require.Equal(t, got, Parse(tt.text, Options{Now: now, Location: berlin, Locale: tt.locale}))
+		})
+	}
+}
+
+func TestMatchLocale(t *testing.T) {
+	tests := map[string]string{
+		"":                        LocaleEnglish,
+		"de":                      LocaleGerman,
+		"de-AT":                   LocaleGerman,
+		"fr-FR,de;q=0.5,en;q=0.4": LocaleGerman,
+		"en-GB,en;q=0.9,de;q=0.8": LocaleEnglish,
+		"de;q=0.3, en-US;q=0.7":   LocaleEnglish,
+		"fr":                      LocaleEnglish,
+		"de_CH":                   LocaleGerman,
+	}
+	for header, want := range tests {
+		require.Equal(t, want, MatchLocale(header), header)
+	}
+}
+
+func TestValidateRecurrence(t *testing.T) {
+	valid := []string{"FREQ=DAILY", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,FR", "FREQ=MONTHLY;BYMONTHDAY=-1", "freq=yearly"}
+	for _, v := range valid {
+		require.NoError(t, ValidateRecurrence(v), v)
+	}
+
+	invalid := []string{"", "DAILY", "FREQ=HOURLY", "FREQ=DAILY;INTERVAL=0", "FREQ=WEEKLY;BYDAY=XX", "FREQ=MONTHLY;BYMONTHDAY=32", "INTERVAL=2", "FREQ=DAILY;COUNT=3"}
+	for _, v := range invalid {
+		require.ErrorIs(t, ValidateRecurrence(v), ErrInvalidRecurrence, v)
+	}
+}
+
+func ptr(t time.Time) *time.Time {
+	return &t
+}
@@ -0,0 +1,132 @@
+package quickadd
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidRecurrence indicates a recurrence rule outside the supported RRULE subset.
+var ErrInvalidRecurrence = errors.New("invalid recurrence")
+
+// Recurrence frequencies, as used in the FREQ part of a rule.
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+	FreqYearly  = "YEARLY"
+)
+
+var weekdayCodes = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+var workdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+// rule is the subset of RFC 5545 recurrence rules that quick add produces.
+type rule struct {
+	freq       string
+	interval   int
+	byDay      []time.Weekday
+	byMonthDay int
+}
+
+// String renders the rule as an RRULE value such as FREQ=MONTHLY;BYMONTHDAY=1.
+func (r rule) String() string {
+	parts := []string{"FREQ=" + r.freq}
+	if r.interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.interval))
+	}
+	if len(r.byDay) > 0 {
+		days := make([]string, len(r.byDay))
+		for i, d := range r.byDay {
+			days[i] = weekdayCodes[d]
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.byMonthDay != 0 {
+		parts = append(parts, "BYMONTHDAY="+strconv.Itoa(r.byMonthDay))
+	}
+	return strings.Join(parts, ";")
+}
+
+// next returns the first day on or after from that the rule includes.
+func (r rule) next(from time.Time) time.Time {
+	switch {
+	case len(r.byDay) > 0:
+		for i := 0; i < 7; i++ {
+			d := from.AddDate(0, 0, i)
+			for _, wd := range r.byDay {
+				if d.Weekday() == wd {
+					return d
+				}
+			}
+		}
+	case r.byMonthDay > 0:
+		for i := 0; i < 12; i++ {
+			d, ok := date(from.Year(), from.Month()+time.Month(i), r.byMonthDay, from.Location())
+			if ok && !d.Before(from) {
+				return d
+			}
+		}
+	}
+	return from
+}
+
+// ValidateRecurrence checks an RRULE value against the subset todos support: a FREQ of
+// DAILY, WEEKLY, MONTHLY or YEARLY with optional INTERVAL, BYDAY weekdays and BYMONTHDAY.
+func ValidateRecurrence(value string) error {
+	var freq bool
+	for _, part := range strings.Split(value, ";") {
+		name, v, ok := strings.Cut(part, "=")
+		if !ok {
+			return fmt.Errorf("%w: %q is not NAME=VALUE", ErrInvalidRecurrence, part)
+		}
+		switch strings.ToUpper(name) {
+		case "FREQ":
+			switch strings.ToUpper(v) {
+			case FreqDaily, FreqWeekly, FreqMonthly, FreqYearly:
+				freq = true
+			default:
+				return fmt.Errorf("%w: unsupported FREQ %q", ErrInvalidRecurrence, v)
+			}
+		case "INTERVAL":
+			if n, err := strconv.Atoi(v); err != nil || n < 1 {
+				return fmt.Errorf("%w: INTERVAL must be a positive number", ErrInvalidRecurrence)
+			}
+		case "BYDAY":
+			for _, d := range strings.Split(v, ",") {
+				if !validWeekdayCode(d) {
+					return fmt.Errorf("%w: unknown weekday %q", ErrInvalidRecurrence, d)
+				}
+			}
+		case "BYMONTHDAY":
+			if n, err := strconv.Atoi(v); err != nil || n == 0 || n < -31 || n > 31 {
+				return fmt.Errorf("%w: BYMONTHDAY must be between 1 and 31 or -31 and -1", ErrInvalidRecurrence)
+			}
+		default:
+			return fmt.Errorf("%w: unsupported part %q", ErrInvalidRecurrence, name)
+		}
+	}
+	if !freq {
+		return fmt.Errorf("%w: FREQ is required", ErrInvalidRecurrence)
+	}
+	return nil
+}
+
+func validWeekdayCode(code string) bool {
+	for _, c := range weekdayCodes {
+		if strings.EqualFold(code, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// date returns the given day at midnight, reporting false when the day does not exist
+// in that month. Months past December roll over into the following years.
+func date(year int, month time.Month, day int, loc *time.Location) (time.Time, bool) {
+	d := time.Date(year, month, day, 0, 0, 0, 0, loc)
+	normalized := time.Date(year, month, 1, 0, 0, 0, 0, loc)
+	return d, d.Month() == normalized.Month() && d.Day() == day
+}
@@ -0,0 +1,10 @@
+package smartlist
+
+import "errors"
+
+var (
+	// ErrNotFound indicates the requested smart list could not be located.
+	ErrNotFound = errors.New("smart list not found")
+	// ErrNameTaken indicates the user already has a smart list with that name.
+	ErrNameTaken = errors.New("smart list name already in use")
+)
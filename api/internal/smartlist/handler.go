@@ -0,0 +1,212 @@
+package smartlist
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"overengineeredtodo/internal/query"
+	"overengineeredtodo/internal/todo"
+)
+
+// RegisterRoutes wires the smart list HTTP handlers to a sub-router.
+func RegisterRoutes(router *gin.RouterGroup, repo *Repository, todos *todo.Repository) {
+	handler := &Handler{repo: repo, todos: todos}
+
+	router.POST("", handler.createSmartList)
+	router.GET("/:id", handler.getSmartList)
+	router.GET("", handler.listSmartLists)
+	router.PUT("/:id", handler.updateSmartList)
+	router.DELETE("/:id", handler.deleteSmartList)
+	router.GET("/:id/todos", handler.listTodos)
+}
+
+// Handler exposes HTTP endpoints for smart lists.
+type Handler struct {
+	repo  *Repository
+	todos *todo.Repository
+}
+
+func (h *Handler) createSmartList(c *gin.Context) {
+	var input CreateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.UserID == uuid.Nil || strings.TrimSpace(input.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user_id and name are required"})
+		return
+	}
+
+	if !validQuery(c, input.Query) {
+		return
+	}
+
+	l, err := h.repo.Create(c.Request.Context(), input)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusCreated, l)
+	case err == ErrNameTaken:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (h *Handler) getSmartList(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	l, err := h.repo.Get(c.Request.Context(), id)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, l)
+	case err == ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "smart list not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (h *Handler) listSmartLists(c *gin.Context) {
+	userID, err := uuid.Parse(c.Query("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+		return
+	}
+
+	lists, err := h.repo.ListByUser(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, lists)
+}
+
+func (h *Handler) updateSmartList(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var input UpdateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.Name != nil && strings.TrimSpace(*input.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name must not be empty"})
+		return
+	}
+
+	if input.Query != nil && !validQuery(c, *input.Query) {
+		return
+	}
+
+	l, err := h.repo.Update(c.Request.Context(), id, input)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, l)
+	case err == ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "smart list not found"})
+	case err == ErrNameTaken:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (h *Handler) deleteSmartList(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.repo.Delete(c.Request.Context(), id); err != nil {
+		if err == ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "smart list not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// listTodos evaluates the smart list's query against its owner's todos. Days start in the
+// tz time zone (default UTC).
+func (h *Handler) listTodos(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	opts := todo.ListOptions{Location: time.UTC}
+	if raw := c.Query("tz"); raw != "" {
+		loc, err := time.LoadLocation(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tz"})
+			return
+		}
+		opts.Location = loc
+	}
+
+	l, err := h.repo.Get(c.Request.Context(), id)
+	switch {
+	case err == nil:
+	case err == ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "smart list not found"})
+		return
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Queries are validated when saved, but the language may have changed since.
+	if opts.Query, err = query.Parse(l.Query); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	todos, err := h.todos.List(c.Request.Context(), l.UserID, opts)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, todos)
+}
+
+// validQuery parses q, writing a 422 response with the error column when it is invalid.
+func validQuery(c *gin.Context, q string) bool {
+	if strings.TrimSpace(q) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "query must not be empty"})
+		return false
+	}
+
+	_, err := query.Parse(q)
+	var qe *query.Error
+	switch {
+	case err == nil:
+		return true
+	case errors.As(err, &qe):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": qe.Error(), "column": qe.Col})
+	default:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	}
+	return false
+}
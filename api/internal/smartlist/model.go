@@ -0,0 +1,30 @@
+package smartlist
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SmartList is a saved query whose todos are computed when the list is fetched.
+type SmartList struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Name      string    `json:"name"`
+	Query     string    `json:"query"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreateInput holds the payload required to create a smart list.
+type CreateInput struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+	Name   string    `json:"name" binding:"required"`
+	Query  string    `json:"query" binding:"required"`
+}
+
+// UpdateInput allows partial updates to a smart list.
+type UpdateInput struct {
+	Name  *string `json:"name"`
+	Query *string `json:"query"`
+}
@@ -0,0 +1,170 @@
+package smartlist
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const smartListColumns = `id, user_id, name, query, created_at, updated_at`
+
+// uniqueViolation is the SQLSTATE reported for duplicate keys.
+const uniqueViolation = "23505"
+
+// Repository provides Cockroach-backed persistence for smart lists.
+type Repository struct {
+	pool pgxPool
+}
+
+type pgxPool interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// NewRepository constructs a repository around the supplied pgx pool.
+func NewRepository(pool pgxPool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// Create inserts a smart list row.
+func (r *Repository) Create(ctx context.Context, input CreateInput) (SmartList, error) {
+	query := `
+		INSERT INTO smart_lists (id, user_id, name, query)
+		VALUES ($1, $2, $3, $4)
+		RETURNING ` + smartListColumns
+
+	l, err := scanSmartList(r.pool.QueryRow(ctx, query, uuid.New(), input.UserID, strings.TrimSpace(input.Name), strings.TrimSpace(input.Query)))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return SmartList{}, ErrNameTaken
+		}
+		return SmartList{}, fmt.Errorf("insert smart list: %w", err)
+	}
+
+	return l, nil
+}
+
+// Get fetches a smart list by id.
+func (r *Repository) Get(ctx context.Context, id uuid.UUID) (SmartList, error) {
+	query := `
+		SELECT ` + smartListColumns + `
+		FROM smart_lists
+		WHERE id = $1
+	`
+
+	l, err := scanSmartList(r.pool.QueryRow(ctx, query, id))
+
+	switch {
+	case err == nil:
+		return l, nil
+	case err == pgx.ErrNoRows:
+		return SmartList{}, ErrNotFound
+	default:
+		return SmartList{}, fmt.Errorf("select smart list: %w", err)
+	}
+}
+
+// ListByUser returns the user's smart lists ordered by name.
+func (r *Repository) ListByUser(ctx context.Context, userID uuid.UUID) ([]SmartList, error) {
+	query := `
+		SELECT ` + smartListColumns + `
+		FROM smart_lists
+		WHERE user_id = $1
+		ORDER BY name ASC
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query smart lists: %w", err)
+	}
+	defer rows.Close()
+
+	var result []SmartList
+	for rows.Next() {
+		l, err := scanSmartList(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan smart list: %w", err)
+		}
+		result = append(result, l)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate smart lists: %w", rows.Err())
+	}
+
+	return result, nil
+}
+
+// Update applies partial updates to a smart list and returns the new state.
+func (r *Repository) Update(ctx context.Context, id uuid.UUID, input UpdateInput) (SmartList, error) {
+	setClauses := make([]string, 0, 2)
+	args := make([]any, 0, 3)
+	position := 1
+
+	if input.Name != nil {
+		setClauses = append(setClauses, fmt.Sprintf("name = $%d", position))
+		args = append(args, strings.TrimSpace(*input.Name))
+		position++
+	}
+
+	if input.Query != nil {
+		setClauses = append(setClauses, fmt.Sprintf("query = $%d", position))
+		args = append(args, strings.TrimSpace(*input.Query))
+		position++
+	}
+
+	if len(setClauses) == 0 {
+		return r.Get(ctx, id)
+	}
+
+	setClauses = append(setClauses, "updated_at = current_timestamp")
+	args = append(args, id)
+
+	query := fmt.Sprintf(`
+		UPDATE smart_lists
+		SET %s
+		WHERE id = $%d
+		RETURNING %s
+	`, strings.Join(setClauses, ", "), position, smartListColumns)
+
+	l, err := scanSmartList(r.pool.QueryRow(ctx, query, args...))
+	switch {
+	case err == nil:
+		return l, nil
+	case err == pgx.ErrNoRows:
+		return SmartList{}, ErrNotFound
+	case isUniqueViolation(err):
+		return SmartList{}, ErrNameTaken
+	default:
+		return SmartList{}, fmt.Errorf("update smart list: %w", err)
+	}
+}
+
+// Delete removes a smart list. Its todos are not affected.
+func (r *Repository) Delete(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM smart_lists WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete smart list: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func scanSmartList(row pgx.Row) (SmartList, error) {
+	var l SmartList
+	err := row.Scan(&l.ID, &l.UserID, &l.Name, &l.Query, &l.CreatedAt, &l.UpdatedAt)
+	return l, err
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
@@ -0,0 +1,90 @@
+package smartlist
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+)
+
+var smartListRowColumns = []string{"id", "user_id", "name", "query", "created_at", "updated_at"}
+
+func TestRepositoryCreate(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	input := CreateInput{UserID: uuid.New(), Name: " This week ", Query: " due:<7d is:open "}
+	id := uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery("INSERT INTO smart_lists").
+		WithArgs(pgxmock.AnyArg(), input.UserID, "This week", "due:<7d is:open").
+		WillReturnRows(pgxmock.NewRows(smartListRowColumns).AddRow(id, input.UserID, "This week", "due:<7d is:open", now, now))
+
+	l, err := repo.Create(context.Background(), input)
+	require.NoError(t, err)
+	require.Equal(t, id, l.ID)
+	require.Equal(t, "due:<7d is:open", l.Query)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryCreateDuplicateName(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	input := CreateInput{UserID: uuid.New(), Name: "Work", Query: "tag:work"}
+
+	mock.ExpectQuery("INSERT INTO smart_lists").
+		WithArgs(pgxmock.AnyArg(), input.UserID, "Work", "tag:work").
+		WillReturnError(&pgconn.PgError{Code: uniqueViolation})
+
+	_, err = repo.Create(context.Background(), input)
+	require.ErrorIs(t, err, ErrNameTaken)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryUpdateQuery(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	id := uuid.New()
+	q := "tag:home"
+	now := time.Now()
+
+	mock.ExpectQuery("UPDATE smart_lists SET query = \\$1, updated_at = current_timestamp WHERE id = \\$2").
+		WithArgs(q, id).
+		WillReturnRows(pgxmock.NewRows(smartListRowColumns).AddRow(id, uuid.New(), "Home", q, now, now))
+
+	l, err := repo.Update(context.Background(), id, UpdateInput{Query: &q})
+	require.NoError(t, err)
+	require.Equal(t, q, l.Query)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryGetNotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	id := uuid.New()
+
+	mock.ExpectQuery("SELECT id, user_id, name, query, created_at, updated_at FROM smart_lists").
+		WithArgs(id).
+		WillReturnError(pgx.ErrNoRows)
+
+	_, err = repo.Get(context.Background(), id)
+	require.ErrorIs(t, err, ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
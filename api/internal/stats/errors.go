@@ -0,0 +1,6 @@
+package stats
+
+import "errors"
+
+// ErrUserNotFound indicates statistics were requested for an unknown user.
+var ErrUserNotFound = errors.New("user not found")
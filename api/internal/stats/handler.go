@@ -0,0 +1,95 @@
+package stats
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	maxWindows       = 10
+	maxWindowDays    = 3650
+	maxHistogramDays = 366
+)
+
+var defaultWindows = []int{7, 30, 90}
+
+// RegisterRoutes wires the statistics endpoint onto the users router group.
+func RegisterRoutes(router *gin.RouterGroup, repo *Repository) {
+	handler := &Handler{repo: repo}
+
+	router.GET("/:id/stats", handler.getStats)
+}
+
+// Handler exposes per-user statistics.
+type Handler struct {
+	repo *Repository
+}
+
+func (h *Handler) getStats(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	opts, err := parseOptions(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	stats, err := Compute(c.Request.Context(), h.repo, userID, opts, time.Now())
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, stats)
+	case err == ErrUserNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// parseOptions reads windows (comma-separated days, default 7,30,90), days (histogram
+// length, default 30) and tz (IANA time zone, default UTC).
+func parseOptions(c *gin.Context) (Options, error) {
+	opts := Options{Windows: defaultWindows, HistogramDays: 30, Location: time.UTC}
+
+	if raw := c.Query("windows"); raw != "" {
+		parts := strings.Split(raw, ",")
+		if len(parts) > maxWindows {
+			return Options{}, errors.New("at most 10 windows are supported")
+		}
+		opts.Windows = make([]int, 0, len(parts))
+		for _, part := range parts {
+			days, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || days < 1 || days > maxWindowDays {
+				return Options{}, errors.New("windows must be comma-separated day counts between 1 and 3650")
+			}
+			opts.Windows = append(opts.Windows, days)
+		}
+	}
+
+	if raw := c.Query("days"); raw != "" {
+		days, err := strconv.Atoi(raw)
+		if err != nil || days < 1 || days > maxHistogramDays {
+			return Options{}, errors.New("days must be between 1 and 366")
+		}
+		opts.HistogramDays = days
+	}
+
+	if raw := c.Query("tz"); raw != "" {
+		loc, err := time.LoadLocation(raw)
+		if err != nil {
+			return Options{}, errors.New("invalid tz")
+		}
+		opts.Location = loc
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,45 @@
+package stats
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Stats summarises how a user is getting on with their todos.
+type Stats struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Open      int       `json:"open"`
+	Completed int       `json:"completed"`
+	Overdue   int       `json:"overdue"`
+	// CompletionRates holds one entry per requested window.
+	CompletionRates []WindowRate `json:"completion_rates"`
+	// AverageCompletionSeconds is the mean time from creation to completion, when anything was completed.
+	AverageCompletionSeconds *float64 `json:"average_completion_seconds,omitempty"`
+	Streak                   Streak   `json:"streak"`
+	// Histogram counts completions per day, oldest first, including days without any.
+	Histogram   []DayCount `json:"histogram"`
+	Timezone    string     `json:"timezone"`
+	GeneratedAt time.Time  `json:"generated_at"`
+}
+
+// WindowRate is the share of todos created within the last Days days that are completed.
+type WindowRate struct {
+	Days      int     `json:"days"`
+	Created   int     `json:"created"`
+	Completed int     `json:"completed"`
+	Rate      float64 `json:"rate"`
+}
+
+// Streak counts consecutive days with at least one completion. The current streak
+// stays alive until a full day passes without completions.
+type Streak struct {
+	Current int `json:"current"`
+	Longest int `json:"longest"`
+}
+
+// DayCount is the number of todos completed on a calendar day.
+type DayCount struct {
+	Date      string `json:"date"`
+	Completed int    `json:"completed"`
+}
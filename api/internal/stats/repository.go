@@ -0,0 +1,114 @@
+package stats
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Repository runs the aggregate queries behind the statistics.
+type Repository struct {
+	pool pgxPool
+}
+
+type pgxPool interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// NewRepository constructs a Repository backed by the supplied pgx pool.
+func NewRepository(pool pgxPool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// totals holds the counters computed in a single pass over the user's todos.
+type totals struct {
+	open       int
+	completed  int
+	overdue    int
+	avgSeconds *float64
+	windows    []WindowRate
+}
+
+// Totals counts open, completed and overdue todos, the average completion time and,
+// for every window, the todos created within it and how many of those are completed.
+func (r *Repository) Totals(ctx context.Context, userID uuid.UUID, now time.Time, windows []int) (totals, error) {
+	columns := []string{
+		"EXISTS (SELECT 1 FROM users WHERE id = $1)",
+		"count(*) FILTER (WHERE NOT completed)",
+		"count(*) FILTER (WHERE completed)",
+		"count(*) FILTER (WHERE NOT completed AND due_date < $2)",
+		"avg(extract(epoch FROM completed_at - created_at)::FLOAT8) FILTER (WHERE completed_at IS NOT NULL)",
+	}
+	args := []any{userID, now}
+	for _, days := range windows {
+		args = append(args, now.AddDate(0, 0, -days))
+		columns = append(columns,
+			fmt.Sprintf("count(*) FILTER (WHERE created_at >= $%d)", len(args)),
+			fmt.Sprintf("count(*) FILTER (WHERE created_at >= $%d AND completed)", len(args)),
+		)
+	}
+
+	query := `
+		SELECT ` + strings.Join(columns, ", ") + `
+		FROM todos
+		WHERE user_id = $1
+	`
+
+	var (
+		t      totals
+		exists bool
+	)
+	t.windows = make([]WindowRate, len(windows))
+	dest := []any{&exists, &t.open, &t.completed, &t.overdue, &t.avgSeconds}
+	for i, days := range windows {
+		t.windows[i].Days = days
+		dest = append(dest, &t.windows[i].Created, &t.windows[i].Completed)
+	}
+
+	if err := r.pool.QueryRow(ctx, query, args...).Scan(dest...); err != nil {
+		return totals{}, fmt.Errorf("select todo totals: %w", err)
+	}
+	if !exists {
+		return totals{}, ErrUserNotFound
+	}
+
+	return t, nil
+}
+
+// CompletionDays returns the number of completions per calendar day in loc, oldest first.
+func (r *Repository) CompletionDays(ctx context.Context, userID uuid.UUID, loc *time.Location) ([]dayTotal, error) {
+	query := `
+		SELECT (completed_at AT TIME ZONE $2)::DATE AS day, count(*)
+		FROM todos
+		WHERE user_id = $1
+		  AND completed_at IS NOT NULL
+		GROUP BY day
+		ORDER BY day ASC
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, loc.String())
+	if err != nil {
+		return nil, fmt.Errorf("query completion days: %w", err)
+	}
+	defer rows.Close()
+
+	var result []dayTotal
+	for rows.Next() {
+		var d dayTotal
+		if err := rows.Scan(&d.day, &d.count); err != nil {
+			return nil, fmt.Errorf("scan completion day: %w", err)
+		}
+		result = append(result, d)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate completion days: %w", rows.Err())
+	}
+
+	return result, nil
+}
@@ -0,0 +1,89 @@
+package stats
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func day(s string) time.Time {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestCompute(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	userID := uuid.New()
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+	// 23:30 UTC is already the next day in Berlin.
+	now := time.Date(2025, 3, 9, 23, 30, 0, 0, time.UTC)
+	avg := 5400.0
+
+	mock.ExpectQuery("SELECT EXISTS .* count\\(\\*\\) FILTER \\(WHERE created_at >= \\$3\\), .* FROM todos WHERE user_id = \\$1").
+		WithArgs(userID, now, now.AddDate(0, 0, -7), now.AddDate(0, 0, -30)).
+		WillReturnRows(pgxmock.NewRows([]string{"exists", "open", "completed", "overdue", "avg", "c7", "d7", "c30", "d30"}).
+			AddRow(true, 4, 6, 1, &avg, 4, 3, 10, 6))
+	mock.ExpectQuery("SELECT \\(completed_at AT TIME ZONE \\$2\\)::DATE").
+		WithArgs(userID, "Europe/Berlin").
+		WillReturnRows(pgxmock.NewRows([]string{"day", "count"}).
+			AddRow(day("2025-02-20"), 1).
+			AddRow(day("2025-02-21"), 1).
+			AddRow(day("2025-02-22"), 1).
+			AddRow(day("2025-03-08"), 1).
+			AddRow(day("2025-03-09"), 2))
+
+	stats, err := Compute(context.Background(), repo, userID, Options{Windows: []int{7, 30}, HistogramDays: 3, Location: berlin}, now)
+	require.NoError(t, err)
+
+	require.Equal(t, 4, stats.Open)
+	require.Equal(t, 6, stats.Completed)
+	require.Equal(t, 1, stats.Overdue)
+	require.Equal(t, avg, *stats.AverageCompletionSeconds)
+	require.Equal(t, []WindowRate{{Days: 7, Created: 4, Completed: 3, Rate: 0.75}, {Days: 30, Created: 10, Completed: 6, Rate: 0.6}}, stats.CompletionRates)
+
+	// Today is March 10th in Berlin; the run ending yesterday still counts.
+	require.Equal(t, Streak{Current: 2, Longest: 3}, stats.Streak)
+	require.Equal(t, []DayCount{
+		{Date: "2025-03-08", Completed: 1},
+		{Date: "2025-03-09", Completed: 2},
+		{Date: "2025-03-10", Completed: 0},
+	}, stats.Histogram)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestComputeUnknownUser(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	userID := uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT EXISTS").
+		WithArgs(userID, now).
+		WillReturnRows(pgxmock.NewRows([]string{"exists", "open", "completed", "overdue", "avg"}).
+			AddRow(false, 0, 0, 0, (*float64)(nil)))
+
+	_, err = Compute(context.Background(), repo, userID, Options{HistogramDays: 1, Location: time.UTC}, now)
+	require.ErrorIs(t, err, ErrUserNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStreaksBrokenByGap(t *testing.T) {
+	days := []dayTotal{{day: day("2025-01-01"), count: 1}, {day: day("2025-01-02"), count: 1}}
+	require.Equal(t, Streak{Current: 0, Longest: 2}, streaks(days, day("2025-01-04")))
+	require.Equal(t, Streak{}, streaks(nil, day("2025-01-04")))
+}
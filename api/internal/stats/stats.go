@@ -0,0 +1,106 @@
+package stats
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const dateLayout = "2006-01-02"
+
+// Options configures which windows and how much history are reported.
+type Options struct {
+	// Windows lists the completion-rate windows in days.
+	Windows []int
+	// HistogramDays is the number of days covered by the histogram, ending today.
+	HistogramDays int
+	// Location defines where calendar days start and end.
+	Location *time.Location
+}
+
+// dayTotal is a calendar day (midnight UTC, as returned for DATE columns) and its completions.
+type dayTotal struct {
+	day   time.Time
+	count int
+}
+
+// Compute gathers the statistics for a user as of now.
+func Compute(ctx context.Context, repo *Repository, userID uuid.UUID, opts Options, now time.Time) (Stats, error) {
+	t, err := repo.Totals(ctx, userID, now, opts.Windows)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	days, err := repo.CompletionDays(ctx, userID, opts.Location)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	for i := range t.windows {
+		if t.windows[i].Created > 0 {
+			t.windows[i].Rate = float64(t.windows[i].Completed) / float64(t.windows[i].Created)
+		}
+	}
+
+	today := civilDay(now.In(opts.Location))
+	return Stats{
+		UserID:                   userID,
+		Open:                     t.open,
+		Completed:                t.completed,
+		Overdue:                  t.overdue,
+		CompletionRates:          t.windows,
+		AverageCompletionSeconds: t.avgSeconds,
+		Streak:                   streaks(days, today),
+		Histogram:                histogram(days, today, opts.HistogramDays),
+		Timezone:                 opts.Location.String(),
+		GeneratedAt:              now.UTC(),
+	}, nil
+}
+
+// civilDay returns the calendar day of t as midnight UTC so that days compare and
+// step uniformly regardless of daylight saving changes.
+func civilDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// streaks computes the longest run of consecutive completion days and the run that
+// ends today, or yesterday when nothing has been completed yet today.
+func streaks(days []dayTotal, today time.Time) Streak {
+	var (
+		s   Streak
+		run int
+		end time.Time
+	)
+	for i, d := range days {
+		day := civilDay(d.day)
+		if i > 0 && day.Equal(end.AddDate(0, 0, 1)) {
+			run++
+		} else {
+			run = 1
+		}
+		end = day
+		s.Longest = max(s.Longest, run)
+	}
+
+	if len(days) > 0 && (end.Equal(today) || end.Equal(today.AddDate(0, 0, -1))) {
+		s.Current = run
+	}
+	return s
+}
+
+// histogram returns one entry per day for the n days ending today.
+func histogram(days []dayTotal, today time.Time, n int) []DayCount {
+	counts := make(map[time.Time]int, len(days))
+	for _, d := range days {
+		counts[civilDay(d.day)] = d.count
+	}
+
+	result := make([]DayCount, 0, n)
+	for i := n - 1; i >= 0; i-- {
+		day := today.AddDate(0, 0, -i)
+		result = append(result, DayCount{Date: day.Format(dateLayout), Completed: counts[day]})
+	}
+	return result
+}
@@ -0,0 +1,10 @@
+package stream
+
+import "errors"
+
+var (
+	// ErrUnknownEvent indicates a resume from an event that is unknown or has been pruned.
+	ErrUnknownEvent = errors.New("last event is unknown")
+	// ErrTooFarBehind indicates a resume that would replay more events than allowed.
+	ErrTooFarBehind = errors.New("too many events missed")
+)
@@ -0,0 +1,286 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"overengineeredtodo/internal/outbox"
+)
+
+const (
+	// heartbeatInterval is how often idle streams are kept alive.
+	heartbeatInterval = 15 * time.Second
+	// writeTimeout is how long a client may take to accept a write before it is dropped.
+	writeTimeout = 10 * time.Second
+	// retryMillis is the reconnection delay suggested to EventSource clients.
+	retryMillis = 3000
+	// closeTryAgain is the WebSocket close code for subscribers dropped for falling behind.
+	closeTryAgain = 1013
+)
+
+// RegisterRoutes wires the streaming HTTP handlers to the todos sub-router.
+func RegisterRoutes(router *gin.RouterGroup, hub *Hub) {
+	handler := &Handler{hub: hub, heartbeat: heartbeatInterval}
+
+	router.GET("/stream", handler.streamEvents)
+	router.GET("/ws", handler.streamWebSocket)
+}
+
+// Handler streams todo changes to clients.
+type Handler struct {
+	hub       *Hub
+	heartbeat time.Duration
+}
+
+// writer sends changes to a client in the format of its transport.
+type writer interface {
+	event(e outbox.Event) error
+	reset(reason string) error
+	heartbeat() error
+}
+
+// streamEvents streams the changes as Server-Sent Events. A Last-Event-ID header, or a
+// last_event_id query parameter, replays the changes missed since that event first.
+func (h *Handler) streamEvents(c *gin.Context) {
+	userID, lastID, ok := streamParams(c, c.GetHeader("Last-Event-ID"))
+	if !ok {
+		return
+	}
+
+	sub, missed, resetReason, err := h.resume(c.Request.Context(), userID, lastID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer h.hub.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	w := &sseWriter{w: c.Writer, rc: http.NewResponseController(c.Writer)}
+	if w.send(fmt.Sprintf("retry: %d\n\n", retryMillis)) != nil {
+		return
+	}
+	h.follow(c.Request.Context(), w, sub, missed, resetReason)
+}
+
+// streamWebSocket streams the changes over a WebSocket as text messages holding the
+// events. Browsers cannot set headers on WebSockets, so resuming takes the last_event_id
+// query parameter.
+func (h *Handler) streamWebSocket(c *gin.Context) {
+	userID, lastID, ok := streamParams(c, "")
+	if !ok {
+		return
+	}
+
+	sub, missed, resetReason, err := h.resume(c.Request.Context(), userID, lastID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer h.hub.Unsubscribe(sub)
+
+	conn, err := upgrade(c.Writer, c.Request, writeTimeout)
+	if err == errBadHandshake {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		// The connection may already be taken over, and upgrade closed it, so no response
+		// can be written anymore.
+		return
+	}
+
+	// The server no longer watches a connection it handed over; the stream ends when the
+	// client closes it or stops answering pings.
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		defer cancel()
+		if conn.conn.SetReadDeadline(time.Now().Add(2*h.heartbeat)) != nil {
+			return
+		}
+		for {
+			opcode, payload, err := conn.readFrame()
+			if err != nil {
+				return
+			}
+			if conn.conn.SetReadDeadline(time.Now().Add(2*h.heartbeat)) != nil {
+				return
+			}
+			switch opcode {
+			case opPing:
+				if conn.writeFrame(opPong, payload) != nil {
+					return
+				}
+			case opClose:
+				return
+			}
+		}
+	}()
+
+	code := uint16(closeNormal)
+	if h.follow(ctx, &wsWriter{conn: conn}, sub, missed, resetReason) {
+		code = closeTryAgain
+	}
+	cancel()
+	conn.close(code)
+}
+
+// resume subscribes the user and loads the changes missed since lastID. When those cannot
+// be replayed, it returns the reason the client has to reload instead.
+func (h *Handler) resume(ctx context.Context, userID, lastID uuid.UUID) (*Subscription, []outbox.Event, string, error) {
+	// Subscribing first means no change falls between the replay and the live changes.
+	sub := h.hub.Subscribe(userID)
+	if lastID == uuid.Nil {
+		return sub, nil, "", nil
+	}
+
+	missed, err := h.hub.Replay(ctx, userID, lastID)
+	switch {
+	case err == nil:
+		return sub, missed, "", nil
+	case err == ErrUnknownEvent || err == ErrTooFarBehind:
+		return sub, nil, err.Error(), nil
+	default:
+		h.hub.Unsubscribe(sub)
+		return nil, nil, "", err
+	}
+}
+
+// follow writes the missed changes and then the live ones until the client goes away,
+// the hub stops or the subscription is dropped for falling behind, which it reports.
+func (h *Handler) follow(ctx context.Context, w writer, sub *Subscription, missed []outbox.Event, resetReason string) bool {
+	if resetReason != "" {
+		if w.reset(resetReason) != nil {
+			return false
+		}
+	}
+	replayed := make(map[uuid.UUID]bool, len(missed))
+	for _, e := range missed {
+		if w.event(e) != nil {
+			return false
+		}
+		replayed[e.ID] = true
+	}
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-h.hub.Stopped():
+			return false
+		case <-sub.Done():
+			return sub.Dropped()
+		case e := <-sub.Events():
+			if replayed[e.ID] {
+				continue
+			}
+			if w.event(e) != nil {
+				return false
+			}
+		case <-ticker.C:
+			if w.heartbeat() != nil {
+				return false
+			}
+		}
+	}
+}
+
+// streamParams parses the user_id and optional last event query parameters, writing a 400
+// response when they are invalid. header takes precedence over the query parameter.
+func streamParams(c *gin.Context, header string) (uuid.UUID, uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.Query("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	raw := header
+	if raw == "" {
+		raw = c.Query("last_event_id")
+	}
+	if raw == "" {
+		return userID, uuid.Nil, true
+	}
+	lastID, err := uuid.Parse(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid last event id"})
+		return uuid.Nil, uuid.Nil, false
+	}
+	return userID, lastID, true
+}
+
+// sseWriter writes Server-Sent Events. Events are named after the change and carry the
+// outbox event as data; a reset event asks the client to reload its todos.
+type sseWriter struct {
+	w  io.Writer
+	rc *http.ResponseController
+}
+
+func (s *sseWriter) event(e outbox.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encode event: %w", err)
+	}
+	return s.send(fmt.Sprintf("id: %s\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data))
+}
+
+func (s *sseWriter) reset(reason string) error {
+	data, err := json.Marshal(gin.H{"reason": reason})
+	if err != nil {
+		return fmt.Errorf("encode reset: %w", err)
+	}
+	return s.send(fmt.Sprintf("event: reset\ndata: %s\n\n", data))
+}
+
+func (s *sseWriter) heartbeat() error {
+	return s.send(": heartbeat\n\n")
+}
+
+func (s *sseWriter) send(frame string) error {
+	// Writers that cannot take deadlines still stream; a stuck client then only ends
+	// with its connection.
+	s.rc.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if _, err := io.WriteString(s.w, frame); err != nil {
+		return fmt.Errorf("write event: %w", err)
+	}
+	return s.rc.Flush()
+}
+
+// wsWriter writes WebSocket messages. Messages hold the outbox event; a message of type
+// reset asks the client to reload its todos. Heartbeats are pings.
+type wsWriter struct {
+	conn *wsConn
+}
+
+func (w *wsWriter) event(e outbox.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encode event: %w", err)
+	}
+	return w.conn.writeFrame(opText, data)
+}
+
+func (w *wsWriter) reset(reason string) error {
+	data, err := json.Marshal(gin.H{"type": "reset", "reason": reason})
+	if err != nil {
+		return fmt.Errorf("encode reset: %w", err)
+	}
+	return w.conn.writeFrame(opText, data)
+}
+
+func (w *wsWriter) heartbeat() error {
+	return w.conn.writeFrame(opPing, nil)
+}
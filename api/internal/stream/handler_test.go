@@ -0,0 +1,291 @@
+package stream
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+
+	"overengineeredtodo/internal/outbox"
+)
+
+func newStreamServer(hub *Hub, heartbeat time.Duration) *httptest.Server {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := &Handler{hub: hub, heartbeat: heartbeat}
+	router.GET("/todos/stream", handler.streamEvents)
+	router.GET("/todos/ws", handler.streamWebSocket)
+	return httptest.NewServer(router)
+}
+
+// readSSE reads the next event, skipping comments, as its field lines.
+func readSSE(t *testing.T, reader *bufio.Reader) []string {
+	t.Helper()
+	var fields []string
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && len(fields) > 0:
+			return fields
+		case line == "", strings.HasPrefix(line, ":"), strings.HasPrefix(line, "retry:"):
+		default:
+			fields = append(fields, line)
+		}
+	}
+}
+
+func TestStreamEventsResumesAndFollowsChanges(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	alice := uuid.New()
+	last := todoEvent(t, outbox.TodoCreated, alice, nil)
+	missed := todoEvent(t, outbox.TodoUpdated, alice, nil)
+	live := todoEvent(t, outbox.TodoDeleted, alice, nil)
+
+	mock.ExpectQuery("FROM outbox_events WHERE id").WithArgs(last.ID).WillReturnRows(newEventRows(last))
+	mock.ExpectQuery("FROM project_members").WithArgs([]uuid.UUID{alice}).WillReturnRows(newMemberRows(nil))
+	mock.ExpectQuery("FROM outbox_events").
+		WithArgs(outbox.AggregateTodo, last.OccurredAt.Add(-lookback), uuid.Nil, pollBatch).
+		WillReturnRows(newEventRows(missed))
+
+	hub := newTestHub(mock)
+	server := newStreamServer(hub, time.Minute)
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/todos/stream?user_id="+alice.String(), nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", last.ID.String())
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	fields := readSSE(t, reader)
+	require.Equal(t, []string{"id: " + missed.ID.String(), "event: " + outbox.TodoUpdated}, fields[:2])
+
+	// A change polled again after the replay is not sent twice.
+	expectPoll(mock, missed, live)
+	mock.ExpectQuery("FROM project_members").WithArgs(pgxmock.AnyArg()).WillReturnRows(newMemberRows(nil))
+	_, err = hub.Poll(context.Background())
+	require.NoError(t, err)
+
+	fields = readSSE(t, reader)
+	require.Equal(t, []string{"id: " + live.ID.String(), "event: " + outbox.TodoDeleted}, fields[:2])
+	var sent outbox.Event
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(fields[2], "data: ")), &sent))
+	require.Equal(t, live.AggregateID, sent.AggregateID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStreamEventsResetsForUnknownLastEvent(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	lastID := uuid.New()
+	mock.ExpectQuery("FROM outbox_events WHERE id").WithArgs(lastID).WillReturnRows(newEventRows())
+
+	server := newStreamServer(newTestHub(mock), time.Minute)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/todos/stream?user_id=" + uuid.NewString() + "&last_event_id=" + lastID.String())
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	fields := readSSE(t, bufio.NewReader(resp.Body))
+	require.Equal(t, []string{"event: reset", `data: {"reason":"last event is unknown"}`}, fields)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStreamEventsSendsHeartbeats(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	server := newStreamServer(newTestHub(mock), 10*time.Millisecond)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/todos/stream?user_id=" + uuid.NewString())
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, "retry: 3000\n", line)
+	_, err = reader.ReadString('\n')
+	require.NoError(t, err)
+	line, err = reader.ReadString('\n')
+	require.NoError(t, err)
+	require.Equal(t, ": heartbeat\n", line)
+}
+
+func TestStreamEventsInvalidParams(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	server := newStreamServer(newTestHub(mock), time.Minute)
+	defer server.Close()
+
+	for _, query := range []string{"", "?user_id=nope", "?user_id=" + uuid.NewString() + "&last_event_id=nope"} {
+		resp, err := http.Get(server.URL + "/todos/stream" + query)
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode, query)
+	}
+}
+
+// writeClientFrame sends a masked frame, as clients do.
+func writeClientFrame(t *testing.T, conn net.Conn, opcode byte, payload []byte) {
+	t.Helper()
+	mask := []byte{1, 2, 3, 4}
+	frame := []byte{0x80 | opcode, 0x80 | byte(len(payload))}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	_, err := conn.Write(frame)
+	require.NoError(t, err)
+}
+
+// readServerFrame reads an unmasked frame of at most 65535 bytes.
+func readServerFrame(t *testing.T, reader *bufio.Reader) (byte, []byte) {
+	t.Helper()
+	var header [2]byte
+	_, err := io.ReadFull(reader, header[:])
+	require.NoError(t, err)
+	length := int(header[1] & 0x7F)
+	if length == 126 {
+		var ext [2]byte
+		_, err := io.ReadFull(reader, ext[:])
+		require.NoError(t, err)
+		length = int(binary.BigEndian.Uint16(ext[:]))
+	}
+	payload := make([]byte, length)
+	_, err = io.ReadFull(reader, payload)
+	require.NoError(t, err)
+	return header[0] & 0x0F, payload
+}
+
+func TestStreamWebSocket(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	alice := uuid.New()
+	live := todoEvent(t, outbox.TodoCreated, alice, nil)
+
+	hub := newTestHub(mock)
+	server := newStreamServer(hub, time.Minute)
+	defer server.Close()
+
+	conn, err := net.Dial("tcp", strings.TrimPrefix(server.URL, "http://"))
+	require.NoError(t, err)
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	key := "dGhlIHNhbXBsZSBub25jZQ=="
+	_, err = io.WriteString(conn, "GET /todos/ws?user_id="+alice.String()+" HTTP/1.1\r\n"+
+		"Host: example.com\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n"+
+		"Sec-WebSocket-Key: "+key+"\r\nSec-WebSocket-Version: 13\r\n\r\n")
+	require.NoError(t, err)
+
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	require.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", resp.Header.Get("Sec-WebSocket-Accept"))
+
+	expectPoll(mock, live)
+	mock.ExpectQuery("FROM project_members").WithArgs(pgxmock.AnyArg()).WillReturnRows(newMemberRows(nil))
+	_, err = hub.Poll(context.Background())
+	require.NoError(t, err)
+
+	opcode, payload := readServerFrame(t, reader)
+	require.Equal(t, byte(opText), opcode)
+	var sent outbox.Event
+	require.NoError(t, json.Unmarshal(payload, &sent))
+	require.Equal(t, live.ID, sent.ID)
+
+	writeClientFrame(t, conn, opPing, []byte("hi"))
+	opcode, payload = readServerFrame(t, reader)
+	require.Equal(t, byte(opPong), opcode)
+	require.Equal(t, "hi", string(payload))
+
+	writeClientFrame(t, conn, opClose, nil)
+	opcode, payload = readServerFrame(t, reader)
+	require.Equal(t, byte(opClose), opcode)
+	require.Equal(t, uint16(closeNormal), binary.BigEndian.Uint16(payload))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStreamWebSocketRejectsPlainRequests(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	server := newStreamServer(newTestHub(mock), time.Minute)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/todos/ws?user_id=" + uuid.NewString())
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+// hijackRecorder records the response and hands over conn when the connection is taken
+// over.
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	conn net.Conn
+}
+
+func (r *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return r.conn, bufio.NewReadWriter(bufio.NewReader(r.conn), bufio.NewWriter(r.conn)), nil
+}
+
+func TestStreamWebSocketFailedUpgradeWritesNoResponse(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	handler := &Handler{hub: newTestHub(mock), heartbeat: time.Minute}
+	router.GET("/todos/ws", handler.streamWebSocket)
+
+	// The handshake cannot be written to a closed connection.
+	client, taken := net.Pipe()
+	defer client.Close()
+	require.NoError(t, taken.Close())
+
+	req := httptest.NewRequest(http.MethodGet, "/todos/ws?user_id="+uuid.NewString(), nil)
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	rec := &hijackRecorder{ResponseRecorder: httptest.NewRecorder(), conn: taken}
+	router.ServeHTTP(rec, req)
+
+	require.Zero(t, rec.Body.Len(), rec.Body.String())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
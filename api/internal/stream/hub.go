@@ -0,0 +1,301 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	"overengineeredtodo/internal/outbox"
+)
+
+const (
+	// pollBatch is the number of events read per query.
+	pollBatch = 500
+	// lookback is how far before the newest event each poll reads again. Transactions can
+	// commit out of the order in which their events occurred, so events appear late.
+	lookback = 30 * time.Second
+	// subscriberBuffer is the number of events a subscriber may fall behind by before it
+	// is dropped.
+	subscriberBuffer = 256
+	// maxReplay is the number of missed events a resume replays at most.
+	maxReplay = 1000
+)
+
+// Hub follows the changes of todos in the outbox and passes them on to the subscribers
+// that may see the todos. Every replica of the todo service runs a hub reading from the
+// database, so subscribers receive the changes made through any replica.
+type Hub struct {
+	events   *outbox.Repository
+	members  *Repository
+	interval time.Duration
+	logger   *slog.Logger
+	wg       sync.WaitGroup
+	stopped  chan struct{}
+
+	mu          sync.Mutex
+	subscribers map[*Subscription]struct{}
+
+	// newest and seen belong to the polling goroutine.
+	newest time.Time
+	seen   map[uuid.UUID]time.Time
+}
+
+// NewHub constructs a Hub that polls for changes every interval.
+func NewHub(events *outbox.Repository, members *Repository, interval time.Duration, logger *slog.Logger) *Hub {
+	return &Hub{
+		events:      events,
+		members:     members,
+		interval:    interval,
+		logger:      logger,
+		stopped:     make(chan struct{}),
+		subscribers: make(map[*Subscription]struct{}),
+		newest:      time.Now(),
+		seen:        make(map[uuid.UUID]time.Time),
+	}
+}
+
+// Start polls in the background until ctx is cancelled.
+func (h *Hub) Start(ctx context.Context) {
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		defer close(h.stopped)
+
+		ticker := time.NewTicker(h.interval)
+		defer ticker.Stop()
+		for {
+			if _, err := h.Poll(ctx); err != nil && ctx.Err() == nil {
+				h.logger.Error("todo change poll failed", slog.String("error", err.Error()))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Wait blocks until a started hub has stopped.
+func (h *Hub) Wait() {
+	h.wg.Wait()
+}
+
+// Stopped is closed when a started hub has stopped, ending the streams of its subscribers.
+func (h *Hub) Stopped() <-chan struct{} {
+	return h.stopped
+}
+
+// Subscribe starts passing the changes the user may see to a new subscription.
+func (h *Hub) Subscribe(userID uuid.UUID) *Subscription {
+	s := &Subscription{UserID: userID, events: make(chan outbox.Event, subscriberBuffer), done: make(chan struct{})}
+
+	h.mu.Lock()
+	h.subscribers[s] = struct{}{}
+	h.mu.Unlock()
+	return s
+}
+
+// Unsubscribe stops the subscription.
+func (h *Hub) Unsubscribe(s *Subscription) {
+	h.mu.Lock()
+	delete(h.subscribers, s)
+	h.mu.Unlock()
+	s.stop()
+}
+
+// Poll reads the changes since the last poll, passes them on and reports how many there
+// were. Each poll reads the lookback period again and skips the events it has seen.
+func (h *Hub) Poll(ctx context.Context) (int, error) {
+	from := outbox.Cursor{OccurredAt: h.newest.Add(-lookback)}
+	var fresh []outbox.Event
+	for {
+		events, err := h.events.After(ctx, outbox.AggregateTodo, from, pollBatch)
+		if err != nil {
+			return 0, err
+		}
+		for _, e := range events {
+			from = e.Cursor()
+			if _, ok := h.seen[e.ID]; ok {
+				continue
+			}
+			h.seen[e.ID] = e.OccurredAt
+			fresh = append(fresh, e)
+			if e.OccurredAt.After(h.newest) {
+				h.newest = e.OccurredAt
+			}
+		}
+		if len(events) < pollBatch {
+			break
+		}
+	}
+
+	for id, occurredAt := range h.seen {
+		if occurredAt.Before(h.newest.Add(-2 * lookback)) {
+			delete(h.seen, id)
+		}
+	}
+
+	if len(fresh) == 0 {
+		return 0, nil
+	}
+	return len(fresh), h.broadcast(ctx, fresh)
+}
+
+// broadcast passes the events to the subscribers that may see their todos.
+func (h *Hub) broadcast(ctx context.Context, events []outbox.Event) error {
+	h.mu.Lock()
+	subscribers := make([]*Subscription, 0, len(h.subscribers))
+	for s := range h.subscribers {
+		subscribers = append(subscribers, s)
+	}
+	h.mu.Unlock()
+	if len(subscribers) == 0 {
+		return nil
+	}
+
+	userIDs := make([]uuid.UUID, len(subscribers))
+	for i, s := range subscribers {
+		userIDs[i] = s.UserID
+	}
+	projects, err := h.members.Projects(ctx, userIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range events {
+		o, ok := ownerOf(e)
+		if !ok {
+			continue
+		}
+		for _, s := range subscribers {
+			if !o.visibleTo(s.UserID, projects[s.UserID]) {
+				continue
+			}
+			if !s.send(e) {
+				h.Unsubscribe(s)
+				h.logger.Warn("dropped lagging todo stream subscriber", slog.String("user_id", s.UserID.String()))
+			}
+		}
+	}
+	return nil
+}
+
+// Replay returns the changes the user may see that occurred after the event lastID, oldest
+// first. Like Poll, it reads the lookback period before that event again, as changes that
+// occurred before it may have committed after it was sent; those may reach the subscriber
+// twice. It fails with ErrUnknownEvent when that event is not known (any longer) and with
+// ErrTooFarBehind when more than maxReplay changes were missed; the subscriber has to
+// reload its todos then.
+func (h *Hub) Replay(ctx context.Context, userID, lastID uuid.UUID) ([]outbox.Event, error) {
+	last, err := h.events.Get(ctx, lastID)
+	if err == outbox.ErrNotFound {
+		return nil, ErrUnknownEvent
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	projects, err := h.members.Projects(ctx, []uuid.UUID{userID})
+	if err != nil {
+		return nil, err
+	}
+
+	from := outbox.Cursor{OccurredAt: last.OccurredAt.Add(-lookback)}
+	seen := map[uuid.UUID]bool{last.ID: true}
+	var missed []outbox.Event
+	for {
+		events, err := h.events.After(ctx, outbox.AggregateTodo, from, pollBatch)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range events {
+			from = e.Cursor()
+			if seen[e.ID] {
+				continue
+			}
+			seen[e.ID] = true
+			if o, ok := ownerOf(e); !ok || !o.visibleTo(userID, projects[userID]) {
+				continue
+			}
+			if len(missed) == maxReplay {
+				return nil, ErrTooFarBehind
+			}
+			missed = append(missed, e)
+		}
+		if len(events) < pollBatch {
+			return missed, nil
+		}
+	}
+}
+
+// Subscription receives the changes of the todos a user may see.
+type Subscription struct {
+	UserID uuid.UUID
+
+	events  chan outbox.Event
+	done    chan struct{}
+	once    sync.Once
+	dropped atomic.Bool
+}
+
+// Events delivers the changes.
+func (s *Subscription) Events() <-chan outbox.Event {
+	return s.events
+}
+
+// Done is closed when the subscription stops, such as when it fell too far behind.
+func (s *Subscription) Done() <-chan struct{} {
+	return s.done
+}
+
+// Dropped reports whether the subscription was stopped for falling behind.
+func (s *Subscription) Dropped() bool {
+	return s.dropped.Load()
+}
+
+// send queues the event, reporting false when the subscriber has fallen too far behind.
+func (s *Subscription) send(e outbox.Event) bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+	}
+	select {
+	case s.events <- e:
+		return true
+	default:
+		s.dropped.Store(true)
+		return false
+	}
+}
+
+func (s *Subscription) stop() {
+	s.once.Do(func() { close(s.done) })
+}
+
+// owner is the part of todo payloads, including those of deleted todos, that decides who
+// may see the change.
+type owner struct {
+	UserID    uuid.UUID  `json:"user_id"`
+	ProjectID *uuid.UUID `json:"project_id"`
+}
+
+func ownerOf(e outbox.Event) (owner, bool) {
+	var o owner
+	return o, json.Unmarshal(e.Payload, &o) == nil
+}
+
+// visibleTo follows todo.VisibleTo: users see their own todos outside projects and the
+// todos of the projects they are a member of.
+func (o owner) visibleTo(userID uuid.UUID, projects map[uuid.UUID]bool) bool {
+	if o.ProjectID == nil {
+		return o.UserID == userID
+	}
+	return projects[*o.ProjectID]
+}
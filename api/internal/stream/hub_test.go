@@ -0,0 +1,222 @@
+package stream
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+
+	"overengineeredtodo/internal/outbox"
+)
+
+var eventColumns = strings.Split("id, type, schema_version, aggregate_type, aggregate_id, aggregate_version, occurred_at, payload, attempts", ", ")
+
+func newEventRows(events ...outbox.Event) *pgxmock.Rows {
+	rows := pgxmock.NewRows(eventColumns)
+	for _, e := range events {
+		rows.AddRow(e.ID, e.Type, e.SchemaVersion, e.AggregateType, e.AggregateID, e.AggregateVersion, e.OccurredAt, e.Payload, e.Attempts)
+	}
+	return rows
+}
+
+func newMemberRows(members map[uuid.UUID]uuid.UUID) *pgxmock.Rows {
+	rows := pgxmock.NewRows([]string{"user_id", "project_id"})
+	for userID, projectID := range members {
+		rows.AddRow(userID, projectID)
+	}
+	return rows
+}
+
+// todoEvent records a change of a todo of userID, in projectID when it is not nil.
+func todoEvent(t *testing.T, eventType string, userID uuid.UUID, projectID *uuid.UUID) outbox.Event {
+	t.Helper()
+	e, err := outbox.NewEvent(eventType, outbox.AggregateTodo, uuid.New(), 1, outbox.DeletedTodo{ID: uuid.New(), UserID: userID, ProjectID: projectID})
+	require.NoError(t, err)
+	return e
+}
+
+func newTestHub(mock pgxmock.PgxPoolIface) *Hub {
+	return NewHub(outbox.NewRepository(mock), NewRepository(mock), time.Minute, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func expectPoll(mock pgxmock.PgxPoolIface, events ...outbox.Event) {
+	mock.ExpectQuery("FROM outbox_events").
+		WithArgs(outbox.AggregateTodo, pgxmock.AnyArg(), pgxmock.AnyArg(), pollBatch).
+		WillReturnRows(newEventRows(events...))
+}
+
+func receive(t *testing.T, sub *Subscription) []outbox.Event {
+	t.Helper()
+	var events []outbox.Event
+	for {
+		select {
+		case e := <-sub.Events():
+			events = append(events, e)
+		default:
+			return events
+		}
+	}
+}
+
+func TestHubPollPassesChangesToSubscribersThatMaySeeThem(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	alice, bob, carol, projectID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	private := todoEvent(t, outbox.TodoCreated, alice, nil)
+	shared := todoEvent(t, outbox.TodoUpdated, carol, &projectID)
+	foreign := todoEvent(t, outbox.TodoDeleted, carol, nil)
+
+	hub := newTestHub(mock)
+	aliceSub, bobSub := hub.Subscribe(alice), hub.Subscribe(bob)
+
+	expectPoll(mock, private, shared, foreign)
+	mock.ExpectQuery("FROM project_members").
+		WithArgs(pgxmock.AnyArg()).
+		WillReturnRows(newMemberRows(map[uuid.UUID]uuid.UUID{bob: projectID}))
+
+	n, err := hub.Poll(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	require.Equal(t, []uuid.UUID{private.ID}, eventIDs(receive(t, aliceSub)))
+	require.Equal(t, []uuid.UUID{shared.ID}, eventIDs(receive(t, bobSub)))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHubPollSkipsChangesItHasSeen(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	alice := uuid.New()
+	first := todoEvent(t, outbox.TodoCreated, alice, nil)
+	late := todoEvent(t, outbox.TodoUpdated, alice, nil)
+	late.OccurredAt = first.OccurredAt.Add(-time.Second)
+
+	hub := newTestHub(mock)
+	sub := hub.Subscribe(alice)
+
+	expectPoll(mock, first)
+	mock.ExpectQuery("FROM project_members").WithArgs(pgxmock.AnyArg()).WillReturnRows(newMemberRows(nil))
+	// The second poll reads the lookback period again, finding a change committed late.
+	expectPoll(mock, late, first)
+	mock.ExpectQuery("FROM project_members").WithArgs(pgxmock.AnyArg()).WillReturnRows(newMemberRows(nil))
+	expectPoll(mock, late, first)
+
+	for _, want := range []int{1, 1, 0} {
+		n, err := hub.Poll(context.Background())
+		require.NoError(t, err)
+		require.Equal(t, want, n)
+	}
+	require.Equal(t, []uuid.UUID{first.ID, late.ID}, eventIDs(receive(t, sub)))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHubDropsLaggingSubscribers(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	alice := uuid.New()
+	events := make([]outbox.Event, subscriberBuffer+1)
+	for i := range events {
+		events[i] = todoEvent(t, outbox.TodoUpdated, alice, nil)
+	}
+
+	hub := newTestHub(mock)
+	sub := hub.Subscribe(alice)
+
+	expectPoll(mock, events...)
+	mock.ExpectQuery("FROM project_members").WithArgs(pgxmock.AnyArg()).WillReturnRows(newMemberRows(nil))
+
+	_, err = hub.Poll(context.Background())
+	require.NoError(t, err)
+	require.True(t, sub.Dropped())
+	require.Len(t, receive(t, sub), subscriberBuffer)
+	select {
+	case <-sub.Done():
+	default:
+		t.Fatal("lagging subscription was not stopped")
+	}
+	require.Empty(t, hub.subscribers)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHubReplayReturnsMissedChangesTheUserMaySee(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	alice, projectID := uuid.New(), uuid.New()
+	last := todoEvent(t, outbox.TodoCreated, alice, nil)
+	shared := todoEvent(t, outbox.TodoUpdated, uuid.New(), &projectID)
+	foreign := todoEvent(t, outbox.TodoUpdated, uuid.New(), nil)
+
+	mock.ExpectQuery("FROM outbox_events WHERE id").WithArgs(last.ID).WillReturnRows(newEventRows(last))
+	mock.ExpectQuery("FROM project_members").
+		WithArgs([]uuid.UUID{alice}).
+		WillReturnRows(newMemberRows(map[uuid.UUID]uuid.UUID{alice: projectID}))
+	mock.ExpectQuery("FROM outbox_events").
+		WithArgs(outbox.AggregateTodo, last.OccurredAt.Add(-lookback), uuid.Nil, pollBatch).
+		WillReturnRows(newEventRows(last, shared, foreign))
+
+	missed, err := newTestHub(mock).Replay(context.Background(), alice, last.ID)
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{shared.ID}, eventIDs(missed))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHubReplayReturnsLateCommits(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	alice := uuid.New()
+	last := todoEvent(t, outbox.TodoCreated, alice, nil)
+	// The late change occurred before the last event the client got, but committed after.
+	late := todoEvent(t, outbox.TodoUpdated, alice, nil)
+	late.OccurredAt = last.OccurredAt.Add(-5 * time.Second)
+	after := todoEvent(t, outbox.TodoUpdated, alice, nil)
+	after.OccurredAt = last.OccurredAt.Add(time.Second)
+
+	mock.ExpectQuery("FROM outbox_events WHERE id").WithArgs(last.ID).WillReturnRows(newEventRows(last))
+	mock.ExpectQuery("FROM project_members").
+		WithArgs([]uuid.UUID{alice}).
+		WillReturnRows(newMemberRows(nil))
+	mock.ExpectQuery("FROM outbox_events").
+		WithArgs(outbox.AggregateTodo, last.OccurredAt.Add(-lookback), uuid.Nil, pollBatch).
+		WillReturnRows(newEventRows(late, last, after))
+
+	missed, err := newTestHub(mock).Replay(context.Background(), alice, last.ID)
+	require.NoError(t, err)
+	require.Equal(t, []uuid.UUID{late.ID, after.ID}, eventIDs(missed))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestHubReplayUnknownEvent(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	lastID := uuid.New()
+	mock.ExpectQuery("FROM outbox_events WHERE id").WithArgs(lastID).WillReturnRows(newEventRows())
+
+	_, err = newTestHub(mock).Replay(context.Background(), uuid.New(), lastID)
+	require.Equal(t, ErrUnknownEvent, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func eventIDs(events []outbox.Event) []uuid.UUID {
+	ids := make([]uuid.UUID, len(events))
+	for i, e := range events {
+		ids[i] = e.ID
+	}
+	return ids
+}
@@ -0,0 +1,49 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Repository reads which todos the users following changes may see.
+type Repository struct {
+	pool pgxPool
+}
+
+type pgxPool interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+}
+
+// NewRepository constructs a repository around the supplied pgx pool.
+func NewRepository(pool pgxPool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// Projects returns the projects each of the users is a member of.
+func (r *Repository) Projects(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID]map[uuid.UUID]bool, error) {
+	rows, err := r.pool.Query(ctx, `SELECT user_id, project_id FROM project_members WHERE user_id = ANY($1)`, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("query project members: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[uuid.UUID]map[uuid.UUID]bool, len(userIDs))
+	for rows.Next() {
+		var userID, projectID uuid.UUID
+		if err := rows.Scan(&userID, &projectID); err != nil {
+			return nil, fmt.Errorf("scan project member: %w", err)
+		}
+		if result[userID] == nil {
+			result[userID] = make(map[uuid.UUID]bool)
+		}
+		result[userID][projectID] = true
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate project members: %w", rows.Err())
+	}
+	return result, nil
+}
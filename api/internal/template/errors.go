@@ -0,0 +1,10 @@
+package template
+
+import "errors"
+
+var (
+	// ErrNotFound indicates the requested template could not be located.
+	ErrNotFound = errors.New("template not found")
+	// ErrInvalid indicates template items that cannot be turned into todos.
+	ErrInvalid = errors.New("invalid template")
+)
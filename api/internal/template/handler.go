@@ -0,0 +1,224 @@
+package template
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"overengineeredtodo/internal/access"
+	"overengineeredtodo/internal/project"
+	"overengineeredtodo/internal/todo"
+)
+
+// RegisterRoutes wires the template HTTP handlers to a sub-router.
+func RegisterRoutes(router *gin.RouterGroup, repo *Repository, todos *todo.Repository, projects *project.Repository) {
+	handler := &Handler{repo: repo, todos: todos, projects: projects}
+
+	router.POST("", handler.createTemplate)
+	router.GET("/:id", handler.getTemplate)
+	router.GET("", handler.listTemplates)
+	router.PUT("/:id", handler.updateTemplate)
+	router.DELETE("/:id", handler.deleteTemplate)
+	router.POST("/:id/instantiate", handler.instantiate)
+}
+
+// Handler exposes HTTP endpoints for templates.
+type Handler struct {
+	repo     *Repository
+	todos    *todo.Repository
+	projects *project.Repository
+}
+
+// createTemplate stores a template from the supplied items or, with project_id, from the
+// todos of that project.
+func (h *Handler) createTemplate(c *gin.Context) {
+	var input CreateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.UserID == uuid.Nil || strings.TrimSpace(input.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user_id and name are required"})
+		return
+	}
+
+	if input.ProjectID != nil {
+		if len(input.Items) > 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "items and project_id are mutually exclusive"})
+			return
+		}
+
+		// Any member of the project may copy its todos into a template of their own.
+		role, err := h.projects.Role(c.Request.Context(), *input.ProjectID, input.UserID)
+		switch {
+		case err != nil:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		case role == access.RoleNone:
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "project not found"})
+			return
+		}
+
+		todos, err := h.todos.ListByProject(c.Request.Context(), input.UserID, *input.ProjectID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		input.Items = FromTodos(todos)
+	}
+
+	if err := ValidateItems(input.Items); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+
+	t, err := h.repo.Create(c.Request.Context(), input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, t)
+}
+
+func (h *Handler) getTemplate(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	t, err := h.repo.Get(c.Request.Context(), id)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, t)
+	case err == ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "template not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (h *Handler) listTemplates(c *gin.Context) {
+	userIDParam := c.Query("user_id")
+	if userIDParam == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user_id query parameter is required"})
+		return
+	}
+
+	userID, err := uuid.Parse(userIDParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+		return
+	}
+
+	templates, err := h.repo.ListByUser(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, templates)
+}
+
+func (h *Handler) updateTemplate(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var input UpdateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.Name != nil && strings.TrimSpace(*input.Name) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name must not be empty"})
+		return
+	}
+
+	if input.Items != nil {
+		if err := ValidateItems(*input.Items); err != nil {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	t, err := h.repo.Update(c.Request.Context(), id, input)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, t)
+	case err == ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "template not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (h *Handler) deleteTemplate(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.repo.Delete(c.Request.Context(), id); err != nil {
+		if err == ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "template not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// instantiate creates the template's todos, optionally inside a project.
+func (h *Handler) instantiate(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var input InstantiateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	start, err := ParseStartDate(input.StartDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	t, err := h.repo.Get(c.Request.Context(), id)
+	switch {
+	case err == nil:
+	case err == ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "template not found"})
+		return
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	todos, err := h.repo.Instantiate(c.Request.Context(), t, start, input.ProjectID)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusCreated, todos)
+	case err == todo.ErrUnknownProject:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case err == todo.ErrForbidden:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -0,0 +1,138 @@
+package template
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"overengineeredtodo/internal/todo"
+)
+
+const (
+	// maxItems bounds the todos a single instantiation creates.
+	maxItems = 500
+	// maxDepth is the deepest level of subtasks below a top-level item.
+	maxDepth = 3
+)
+
+// ValidateItems checks titles and priorities and bounds the size and nesting of the tree.
+func ValidateItems(items []Item) error {
+	count := 0
+	var walk func(items []Item, depth int) error
+	walk = func(items []Item, depth int) error {
+		if depth > maxDepth && len(items) > 0 {
+			return fmt.Errorf("%w: subtasks nest deeper than %d levels", ErrInvalid, maxDepth)
+		}
+		for _, item := range items {
+			count++
+			if strings.TrimSpace(item.Title) == "" {
+				return fmt.Errorf("%w: every item needs a title", ErrInvalid)
+			}
+			if item.Priority < todo.PriorityNone || item.Priority > todo.PriorityHigh {
+				return fmt.Errorf("%w: priority of %q must be between 0 and 3", ErrInvalid, item.Title)
+			}
+			if err := walk(item.Subtasks, depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := walk(items, 0); err != nil {
+		return err
+	}
+	if count > maxItems {
+		return fmt.Errorf("%w: more than %d items", ErrInvalid, maxItems)
+	}
+	return nil
+}
+
+// FromTodos turns todos into template items, keeping subtasks below their parents and
+// creation order. Due dates become offsets from the earliest due date; completion and
+// status are not carried over.
+func FromTodos(todos []todo.Todo) []Item {
+	todos = slices.Clone(todos)
+	slices.SortStableFunc(todos, func(a, b todo.Todo) int { return a.CreatedAt.Compare(b.CreatedAt) })
+
+	var anchor time.Time
+	present := make(map[uuid.UUID]bool, len(todos))
+	for _, t := range todos {
+		present[t.ID] = true
+		if t.DueDate != nil && (anchor.IsZero() || day(*t.DueDate).Before(anchor)) {
+			anchor = day(*t.DueDate)
+		}
+	}
+
+	children := make(map[uuid.UUID][]todo.Todo)
+	var roots []todo.Todo
+	for _, t := range todos {
+		if t.ParentID != nil && present[*t.ParentID] && *t.ParentID != t.ID {
+			children[*t.ParentID] = append(children[*t.ParentID], t)
+		} else {
+			roots = append(roots, t)
+		}
+	}
+
+	var build func(todos []todo.Todo, depth int) []Item
+	build = func(todos []todo.Todo, depth int) []Item {
+		items := make([]Item, 0, len(todos))
+		for _, t := range todos {
+			item := Item{
+				Title:       t.Title,
+				Description: t.Description,
+				Labels:      t.Labels,
+				Priority:    t.Priority,
+			}
+			if t.DueDate != nil {
+				offset := int(day(*t.DueDate).Sub(anchor).Hours() / 24)
+				item.DueOffsetDays = &offset
+			}
+			if depth < maxDepth {
+				item.Subtasks = build(children[t.ID], depth+1)
+			}
+			items = append(items, item)
+		}
+		return items
+	}
+
+	return build(roots, 0)
+}
+
+// input returns the todo to create for the item.
+func (item Item) input(userID uuid.UUID, start time.Time, projectID, parentID *uuid.UUID) todo.CreateInput {
+	input := todo.CreateInput{
+		UserID:      userID,
+		Title:       item.Title,
+		Description: item.Description,
+		ProjectID:   projectID,
+		ParentID:    parentID,
+		Labels:      item.Labels,
+		Priority:    item.Priority,
+	}
+	if item.DueOffsetDays != nil {
+		due := start.AddDate(0, 0, *item.DueOffsetDays)
+		input.DueDate = &due
+	}
+	return input
+}
+
+// ParseStartDate reads a date (midnight UTC) or an RFC 3339 timestamp.
+func ParseStartDate(value string) (time.Time, error) {
+	if d, err := time.Parse(time.DateOnly, value); err == nil {
+		return d, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, errors.New("start_date must be YYYY-MM-DD or an RFC 3339 timestamp")
+	}
+	return t, nil
+}
+
+func day(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,54 @@
+package template
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"overengineeredtodo/internal/todo"
+)
+
+func TestFromTodos(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	firstDue := time.Date(2025, 3, 10, 17, 0, 0, 0, time.UTC)
+	laterDue := time.Date(2025, 3, 13, 9, 0, 0, 0, time.UTC)
+	parent := todo.Todo{ID: uuid.New(), Title: "Prepare desk", DueDate: &laterDue, Completed: true, CreatedAt: base}
+	child := todo.Todo{ID: uuid.New(), Title: "Order chair", ParentID: &parent.ID, CreatedAt: base.Add(time.Minute)}
+	other := todo.Todo{ID: uuid.New(), Title: "Welcome mail", DueDate: &firstDue, Labels: []string{"hr"}, Priority: todo.PriorityHigh, CreatedAt: base.Add(time.Hour)}
+
+	// Listed newest first, as the todo repository returns them.
+	items := FromTodos([]todo.Todo{other, child, parent})
+	require.Len(t, items, 2)
+
+	require.Equal(t, "Prepare desk", items[0].Title)
+	require.Equal(t, 3, *items[0].DueOffsetDays)
+	require.Len(t, items[0].Subtasks, 1)
+	require.Equal(t, "Order chair", items[0].Subtasks[0].Title)
+	require.Nil(t, items[0].Subtasks[0].DueOffsetDays)
+
+	require.Equal(t, "Welcome mail", items[1].Title)
+	require.Equal(t, 0, *items[1].DueOffsetDays)
+	require.Equal(t, []string{"hr"}, items[1].Labels)
+	require.Equal(t, todo.PriorityHigh, items[1].Priority)
+	require.NoError(t, ValidateItems(items))
+}
+
+func TestValidateItems(t *testing.T) {
+	deep := []Item{{Title: "1", Subtasks: []Item{{Title: "2", Subtasks: []Item{{Title: "3", Subtasks: []Item{{Title: "4", Subtasks: []Item{{Title: "5"}}}}}}}}}}
+
+	require.ErrorIs(t, ValidateItems([]Item{{Title: " "}}), ErrInvalid)
+	require.ErrorIs(t, ValidateItems([]Item{{Title: "x", Priority: 4}}), ErrInvalid)
+	require.ErrorIs(t, ValidateItems(deep), ErrInvalid)
+	require.NoError(t, ValidateItems(deep[0].Subtasks))
+}
+
+func TestParseStartDate(t *testing.T) {
+	d, err := ParseStartDate("2025-06-02")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC), d)
+
+	_, err = ParseStartDate("next monday")
+	require.Error(t, err)
+}
@@ -0,0 +1,53 @@
+package template
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Template captures a reusable set of todos, such as an onboarding checklist.
+type Template struct {
+	ID          uuid.UUID `json:"id"`
+	UserID      uuid.UUID `json:"user_id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	Items       []Item    `json:"items"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Item is the blueprint of a todo. DueOffsetDays places its due date relative to the
+// start date of an instantiation; items without it get no due date.
+type Item struct {
+	Title         string   `json:"title"`
+	Description   string   `json:"description,omitempty"`
+	DueOffsetDays *int     `json:"due_offset_days,omitempty"`
+	Labels        []string `json:"labels,omitempty"`
+	Priority      int      `json:"priority,omitempty"`
+	Subtasks      []Item   `json:"subtasks,omitempty"`
+}
+
+// CreateInput holds the payload required to create a template.
+type CreateInput struct {
+	UserID      uuid.UUID `json:"user_id" binding:"required"`
+	Name        string    `json:"name" binding:"required"`
+	Description string    `json:"description"`
+	Items       []Item    `json:"items"`
+	// ProjectID saves the todos of an existing project as the template's items.
+	ProjectID *uuid.UUID `json:"project_id"`
+}
+
+// UpdateInput allows partial updates to a template.
+type UpdateInput struct {
+	Name        *string `json:"name"`
+	Description *string `json:"description"`
+	Items       *[]Item `json:"items"`
+}
+
+// InstantiateInput holds the payload for creating todos from a template. StartDate is a
+// date (YYYY-MM-DD, midnight UTC) or an RFC 3339 timestamp.
+type InstantiateInput struct {
+	StartDate string     `json:"start_date" binding:"required"`
+	ProjectID *uuid.UUID `json:"project_id"`
+}
@@ -0,0 +1,225 @@
+package template
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"overengineeredtodo/internal/todo"
+)
+
+const templateColumns = `id, user_id, name, description, items, created_at, updated_at`
+
+// Repository provides Cockroach-backed persistence for templates.
+type Repository struct {
+	pool pgxPool
+}
+
+type pgxPool interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// NewRepository constructs a repository around the supplied pgx pool.
+func NewRepository(pool pgxPool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// Create inserts a template row.
+func (r *Repository) Create(ctx context.Context, input CreateInput) (Template, error) {
+	query := `
+		INSERT INTO templates (id, user_id, name, description, items)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING ` + templateColumns
+
+	items := input.Items
+	if items == nil {
+		items = []Item{}
+	}
+
+	t, err := scanTemplate(r.pool.QueryRow(ctx, query, uuid.New(), input.UserID, strings.TrimSpace(input.Name), input.Description, items))
+	if err != nil {
+		return Template{}, fmt.Errorf("insert template: %w", err)
+	}
+
+	return t, nil
+}
+
+// Get fetches a template by id.
+func (r *Repository) Get(ctx context.Context, id uuid.UUID) (Template, error) {
+	query := `
+		SELECT ` + templateColumns + `
+		FROM templates
+		WHERE id = $1
+	`
+
+	t, err := scanTemplate(r.pool.QueryRow(ctx, query, id))
+
+	switch {
+	case err == nil:
+		return t, nil
+	case err == pgx.ErrNoRows:
+		return Template{}, ErrNotFound
+	default:
+		return Template{}, fmt.Errorf("select template: %w", err)
+	}
+}
+
+// ListByUser returns the user's templates ordered by name.
+func (r *Repository) ListByUser(ctx context.Context, userID uuid.UUID) ([]Template, error) {
+	query := `
+		SELECT ` + templateColumns + `
+		FROM templates
+		WHERE user_id = $1
+		ORDER BY name ASC
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query templates: %w", err)
+	}
+	defer rows.Close()
+
+	var result []Template
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan template: %w", err)
+		}
+		result = append(result, t)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate templates: %w", rows.Err())
+	}
+
+	return result, nil
+}
+
+// Update applies partial updates to a template and returns the new state.
+func (r *Repository) Update(ctx context.Context, id uuid.UUID, input UpdateInput) (Template, error) {
+	setClauses := make([]string, 0, 3)
+	args := make([]any, 0, 4)
+	position := 1
+
+	if input.Name != nil {
+		setClauses = append(setClauses, fmt.Sprintf("name = $%d", position))
+		args = append(args, strings.TrimSpace(*input.Name))
+		position++
+	}
+
+	if input.Description != nil {
+		setClauses = append(setClauses, fmt.Sprintf("description = $%d", position))
+		args = append(args, *input.Description)
+		position++
+	}
+
+	if input.Items != nil {
+		items := *input.Items
+		if items == nil {
+			items = []Item{}
+		}
+		setClauses = append(setClauses, fmt.Sprintf("items = $%d", position))
+		args = append(args, items)
+		position++
+	}
+
+	if len(setClauses) == 0 {
+		return r.Get(ctx, id)
+	}
+
+	setClauses = append(setClauses, "updated_at = current_timestamp")
+	args = append(args, id)
+
+	query := fmt.Sprintf(`
+		UPDATE templates
+		SET %s
+		WHERE id = $%d
+		RETURNING %s
+	`, strings.Join(setClauses, ", "), position, templateColumns)
+
+	t, err := scanTemplate(r.pool.QueryRow(ctx, query, args...))
+	switch {
+	case err == nil:
+		return t, nil
+	case err == pgx.ErrNoRows:
+		return Template{}, ErrNotFound
+	default:
+		return Template{}, fmt.Errorf("update template: %w", err)
+	}
+}
+
+// Delete removes a template. Todos created from it are kept.
+func (r *Repository) Delete(ctx context.Context, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM templates WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("delete template: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Instantiate creates the template's todos for its owner in one transaction, one batch
+// per level of subtasks, with due dates counted from start. The todos are returned
+// parents first.
+func (r *Repository) Instantiate(ctx context.Context, t Template, start time.Time, projectID *uuid.UUID) ([]todo.Todo, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin instantiate: %w", err)
+	}
+	// Rolling back a committed transaction is a no-op.
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	todos := todo.NewRepository(tx)
+	created := make([]todo.Todo, 0, len(t.Items))
+
+	level, parents := t.Items, make([]*uuid.UUID, len(t.Items))
+	for len(level) > 0 {
+		inputs := make([]todo.CreateInput, len(level))
+		for i, item := range level {
+			inputs[i] = item.input(t.UserID, start, projectID, parents[i])
+		}
+
+		batch, err := todos.CreateBatch(ctx, inputs)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, batch...)
+
+		var (
+			next        []Item
+			nextParents []*uuid.UUID
+		)
+		for i, item := range level {
+			for _, sub := range item.Subtasks {
+				next = append(next, sub)
+				nextParents = append(nextParents, &batch[i].ID)
+			}
+		}
+		level, parents = next, nextParents
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit instantiate: %w", err)
+	}
+
+	return created, nil
+}
+
+func scanTemplate(row pgx.Row) (Template, error) {
+	var t Template
+	err := row.Scan(&t.ID, &t.UserID, &t.Name, &t.Description, &t.Items, &t.CreatedAt, &t.UpdatedAt)
+	if t.Items == nil {
+		t.Items = []Item{}
+	}
+	return t, err
+}
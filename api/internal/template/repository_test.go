@@ -0,0 +1,154 @@
+package template
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+
+	"overengineeredtodo/internal/customfield"
+	"overengineeredtodo/internal/todo/todotest"
+	"overengineeredtodo/internal/workflow"
+)
+
+func TestRepositoryCreate(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	input := CreateInput{UserID: uuid.New(), Name: " Onboarding "}
+	id := uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery("INSERT INTO templates").
+		WithArgs(pgxmock.AnyArg(), input.UserID, "Onboarding", "", []Item{}).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "name", "description", "items", "created_at", "updated_at"}).
+			AddRow(id, input.UserID, "Onboarding", "", []Item{}, now, now))
+
+	tmpl, err := repo.Create(context.Background(), input)
+	require.NoError(t, err)
+	require.Equal(t, id, tmpl.ID)
+	require.Empty(t, tmpl.Items)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryGetNotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	id := uuid.New()
+
+	mock.ExpectQuery("SELECT id, user_id, name, description, items, created_at, updated_at FROM templates").
+		WithArgs(id).
+		WillReturnError(pgx.ErrNoRows)
+
+	_, err = repo.Get(context.Background(), id)
+	require.ErrorIs(t, err, ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryInstantiate(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	userID := uuid.New()
+	start := time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC)
+	now := time.Now()
+	week := 7
+
+	tmpl := Template{
+		ID:     uuid.New(),
+		UserID: userID,
+		Name:   "Onboarding",
+		Items: []Item{
+			{Title: "Set up laptop", Subtasks: []Item{{Title: "Install VPN"}}},
+			{Title: "First review", DueOffsetDays: &week, Labels: []string{"hr"}},
+		},
+	}
+
+	// Make the ids generated by CreateBatch predictable. Each batch draws the ids of its
+	// todos and then those of its events.
+	seed := make([]byte, 16*6)
+	for i := 0; i < 6; i++ {
+		binary.BigEndian.PutUint32(seed[i*16:], uint32(i+1))
+	}
+	uuid.SetRand(bytes.NewReader(seed))
+	defer uuid.SetRand(nil)
+
+	drawn := make([]uuid.UUID, 6)
+	expected := bytes.NewReader(seed)
+	for i := range drawn {
+		drawn[i], err = uuid.NewRandomFromReader(expected)
+		require.NoError(t, err)
+	}
+	ids := []uuid.UUID{drawn[0], drawn[1], drawn[4]}
+	due := start.AddDate(0, 0, 7)
+
+	mock.ExpectBegin()
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO todos").
+		WithArgs(
+			ids[0], userID, "Set up laptop", "", (*time.Time)(nil), false, (*string)(nil), (*uuid.UUID)(nil), []string{}, 0,
+			(*string)(nil), (*string)(nil), (*time.Time)(nil), workflow.StatusBacklog, customfield.Values{}, (*uuid.UUID)(nil), "", (*uuid.UUID)(nil), (*int)(nil), (*time.Time)(nil),
+			ids[1], userID, "First review", "", &due, false, (*string)(nil), (*uuid.UUID)(nil), []string{"hr"}, 0,
+			(*string)(nil), (*string)(nil), (*time.Time)(nil), workflow.StatusBacklog, customfield.Values{}, (*uuid.UUID)(nil), "", (*uuid.UUID)(nil), (*int)(nil), (*time.Time)(nil),
+		).
+		WillReturnRows(pgxmock.NewRows(todotest.Columns).
+			AddRow(ids[0], userID, "Set up laptop", "", nil, false, now, now, nil, 1, nil, []string{}, 0, nil, workflow.StatusBacklog, customfield.Values{}, nil, "", nil, nil, nil, nil).
+			AddRow(ids[1], userID, "First review", "", &due, false, now, now, nil, 1, nil, []string{"hr"}, 0, nil, workflow.StatusBacklog, customfield.Values{}, nil, "", nil, nil, nil, nil))
+	mock.ExpectExec("INSERT INTO outbox_events").WithArgs(drawn[2], "TodoCreated", 1, "todo", ids[0], int64(1), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectExec("INSERT INTO outbox_events").WithArgs(drawn[3], "TodoCreated", 1, "todo", ids[1], int64(1), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO todos").
+		WithArgs(
+			ids[2], userID, "Install VPN", "", (*time.Time)(nil), false, (*string)(nil), (*uuid.UUID)(nil), []string{}, 0,
+			(*string)(nil), (*string)(nil), (*time.Time)(nil), workflow.StatusBacklog, customfield.Values{}, &ids[0], "", (*uuid.UUID)(nil), (*int)(nil), (*time.Time)(nil),
+		).
+		WillReturnRows(pgxmock.NewRows(todotest.Columns).
+			AddRow(ids[2], userID, "Install VPN", "", nil, false, now, now, nil, 1, nil, []string{}, 0, nil, workflow.StatusBacklog, customfield.Values{}, &ids[0], "", (*uuid.UUID)(nil), (*int)(nil), (*time.Time)(nil), (*time.Time)(nil)))
+	mock.ExpectExec("INSERT INTO outbox_events").WithArgs(drawn[5], "TodoCreated", 1, "todo", ids[2], int64(1), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+	mock.ExpectCommit()
+
+	todos, err := repo.Instantiate(context.Background(), tmpl, start, nil)
+	require.NoError(t, err)
+	require.Len(t, todos, 3)
+	require.Equal(t, due, *todos[1].DueDate)
+	require.Equal(t, ids[0], *todos[2].ParentID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryInstantiateRollsBackOnError(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	projectID := uuid.New()
+	tmpl := Template{ID: uuid.New(), UserID: uuid.New(), Items: []Item{{Title: "Only"}}}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT workflow, custom_fields FROM projects").
+		WithArgs(projectID).
+		WillReturnError(pgx.ErrNoRows)
+	mock.ExpectRollback()
+
+	_, err = repo.Instantiate(context.Background(), tmpl, time.Now(), &projectID)
+	require.Error(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,18 @@
+package timeentry
+
+import "errors"
+
+var (
+	// ErrNotFound indicates the requested time entry could not be located.
+	ErrNotFound = errors.New("time entry not found")
+	// ErrTimerRunning indicates the user already has a running timer.
+	ErrTimerRunning = errors.New("a timer is already running")
+	// ErrNoTimer indicates the user has no running timer to stop.
+	ErrNoTimer = errors.New("no running timer")
+	// ErrNotOwner indicates a user tried to change someone else's time entry.
+	ErrNotOwner = errors.New("only the user who tracked the time may change it")
+	// ErrInvalidRange indicates an entry that ends before it starts or has no length.
+	ErrInvalidRange = errors.New("time entry must end after it starts")
+	// ErrInvalidGroup indicates an unknown way of grouping totals.
+	ErrInvalidGroup = errors.New("group_by must be todo, project, user or day")
+)
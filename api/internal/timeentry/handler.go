@@ -0,0 +1,328 @@
+package timeentry
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+
+	"overengineeredtodo/internal/access"
+	"overengineeredtodo/internal/todo"
+)
+
+// RegisterTodoRoutes wires the time entries of a todo to a sub-router of the todo, whose
+// path holds the todo's :id.
+func RegisterTodoRoutes(router *gin.RouterGroup, repo *Repository, todos *todo.Repository) {
+	handler := &Handler{repo: repo, todos: todos}
+
+	router.GET("", handler.listEntries)
+	router.POST("", handler.createEntry)
+	router.POST("/start", handler.startTimer)
+	router.POST("/stop", handler.stopTimer)
+	router.DELETE("/:entry_id", handler.deleteEntry)
+}
+
+// RegisterRoutes wires the reports across todos onto the time entries router group.
+func RegisterRoutes(router *gin.RouterGroup, repo *Repository, todos *todo.Repository) {
+	handler := &Handler{repo: repo, todos: todos}
+
+	router.GET("/running", handler.getRunning)
+	router.GET("/totals", handler.getTotals)
+	router.GET("/timesheet", handler.exportTimesheet)
+}
+
+// Handler exposes HTTP endpoints for time tracking.
+type Handler struct {
+	repo  *Repository
+	todos *todo.Repository
+}
+
+func (h *Handler) listEntries(c *gin.Context) {
+	userID, ok := queryUserID(c)
+	if !ok {
+		return
+	}
+	todoID, ok := h.todoID(c, userID)
+	if !ok {
+		return
+	}
+
+	entries, err := h.repo.ListByTodo(c.Request.Context(), todoID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, entries)
+}
+
+// createEntry records time input.UserID spent on the todo without a timer.
+func (h *Handler) createEntry(c *gin.Context) {
+	var input CreateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	todoID, ok := h.todoID(c, input.UserID)
+	if !ok {
+		return
+	}
+
+	entry, err := h.repo.Create(c.Request.Context(), todoID, input)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusCreated, entry)
+	case err == ErrInvalidRange:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// startTimer starts a timer for input.UserID. A timer already running, on any todo, is
+// reported with 409 together with the running entry.
+func (h *Handler) startTimer(c *gin.Context) {
+	var input StartInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	todoID, ok := h.todoID(c, input.UserID)
+	if !ok {
+		return
+	}
+
+	entry, err := h.repo.Start(c.Request.Context(), todoID, input)
+	if err == ErrTimerRunning {
+		running, runningErr := h.repo.Running(c.Request.Context(), input.UserID)
+		if runningErr == nil {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "running": running})
+			return
+		}
+	}
+
+	switch {
+	case err == nil:
+		c.JSON(http.StatusCreated, entry)
+	case err == ErrTimerRunning:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (h *Handler) stopTimer(c *gin.Context) {
+	var input StopInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	todoID, ok := h.todoID(c, input.UserID)
+	if !ok {
+		return
+	}
+
+	entry, err := h.repo.Stop(c.Request.Context(), todoID, input.UserID)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, entry)
+	case err == ErrNoTimer:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// deleteEntry removes an entry; users may only delete the time they tracked themselves.
+func (h *Handler) deleteEntry(c *gin.Context) {
+	userID, ok := queryUserID(c)
+	if !ok {
+		return
+	}
+	todoID, ok := h.todoID(c, userID)
+	if !ok {
+		return
+	}
+	id, err := uuid.Parse(c.Param("entry_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := h.repo.Get(c.Request.Context(), todoID, id)
+	if err == nil && entry.UserID != userID {
+		err = ErrNotOwner
+	}
+	if err == nil {
+		err = h.repo.Delete(c.Request.Context(), todoID, id)
+	}
+
+	switch {
+	case err == nil:
+		c.Status(http.StatusNoContent)
+	case err == ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err == ErrNotOwner:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (h *Handler) getRunning(c *gin.Context) {
+	userID, ok := queryUserID(c)
+	if !ok {
+		return
+	}
+
+	entry, err := h.repo.Running(c.Request.Context(), userID)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, entry)
+	case err == ErrNoTimer:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// getTotals adds up tracked time per group_by (todo, project, user or day; default todo).
+func (h *Handler) getTotals(c *gin.Context) {
+	filter, loc, ok := parseFilter(c)
+	if !ok {
+		return
+	}
+
+	summary, err := h.repo.Totals(c.Request.Context(), filter, GroupBy(c.DefaultQuery("group_by", string(GroupByTodo))), loc)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, summary)
+	case err == ErrInvalidGroup:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (h *Handler) exportTimesheet(c *gin.Context) {
+	filter, loc, ok := parseFilter(c)
+	if !ok {
+		return
+	}
+
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="timesheet.csv"`)
+	c.Status(http.StatusOK)
+
+	// Rows are written as they are read, so failures after the first byte can only be logged.
+	writer := NewTimesheetWriter(c.Writer, loc)
+	if err := h.repo.StreamTimesheet(c.Request.Context(), filter, writer.Write); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	if err := writer.Close(); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// todoID parses the todo of the request path and checks that the user may see it. Any
+// role on the todo's project is enough to track time on it.
+func (h *Handler) todoID(c *gin.Context, userID uuid.UUID) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return uuid.Nil, false
+	}
+
+	_, err = h.todos.Authorize(c.Request.Context(), id, userID, access.RoleViewer)
+	switch {
+	case err == nil:
+		return id, true
+	case err == todo.ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "todo not found"})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+	return uuid.Nil, false
+}
+
+// parseFilter reads user_id, the optional project_id, todo_id and member (a user id or
+// "me"), tz (IANA time zone, default UTC) and from and to. The bounds are RFC 3339 times
+// or dates in tz; a date given as to includes that whole day.
+func parseFilter(c *gin.Context) (Filter, *time.Location, bool) {
+	userID, ok := queryUserID(c)
+	if !ok {
+		return Filter{}, nil, false
+	}
+	filter := Filter{UserID: userID}
+
+	loc := time.UTC
+	if raw := c.Query("tz"); raw != "" {
+		var err error
+		if loc, err = time.LoadLocation(raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tz"})
+			return Filter{}, nil, false
+		}
+	}
+
+	for _, param := range []struct {
+		name string
+		dest **uuid.UUID
+	}{{"project_id", &filter.ProjectID}, {"todo_id", &filter.TodoID}, {"member", &filter.MemberID}} {
+		raw := c.Query(param.name)
+		switch {
+		case raw == "":
+			continue
+		case param.name == "member" && raw == "me":
+			*param.dest = &filter.UserID
+			continue
+		}
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + param.name})
+			return Filter{}, nil, false
+		}
+		*param.dest = &id
+	}
+
+	var err error
+	if filter.From, err = parseBound(c.Query("from"), loc, false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from"})
+		return Filter{}, nil, false
+	}
+	if filter.To, err = parseBound(c.Query("to"), loc, true); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to"})
+		return Filter{}, nil, false
+	}
+	return filter, loc, true
+}
+
+// parseBound reads an RFC 3339 time or a date in loc. A date taken as an upper bound
+// stands for the end of that day.
+func parseBound(raw string, loc *time.Location, upper bool) (*time.Time, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, raw); err == nil {
+		return &t, nil
+	}
+	day, err := time.ParseInLocation(time.DateOnly, raw, loc)
+	if err != nil {
+		return nil, errors.New("invalid time")
+	}
+	if upper {
+		day = day.AddDate(0, 0, 1)
+	}
+	return &day, nil
+}
+
+func queryUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.Query("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
@@ -0,0 +1,78 @@
+package timeentry
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+
+	"overengineeredtodo/internal/todo"
+	"overengineeredtodo/internal/todo/todotest"
+)
+
+func TestStartTimerWhileRunning(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	todoID, otherTodoID, userID, runningID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	started := time.Now().Add(-time.Hour)
+
+	mock.ExpectQuery("SELECT .* FROM todos WHERE id = \\$1").
+		WithArgs(todoID, userID).
+		WillReturnRows(todotest.OwnRow(todoID, userID))
+	mock.ExpectQuery("INSERT INTO time_entries").
+		WithArgs(pgxmock.AnyArg(), todoID, userID, "").
+		WillReturnError(&pgconn.PgError{Code: uniqueViolation})
+	mock.ExpectQuery("SELECT .* FROM time_entries WHERE user_id = \\$1 AND ended_at IS NULL").
+		WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows(entryRowColumns).AddRow(runningID, otherTodoID, userID, started, (*time.Time)(nil), "", started))
+
+	engine := gin.New()
+	RegisterTodoRoutes(engine.Group("/todos/:id/time-entries"), NewRepository(mock), todo.NewRepository(mock))
+
+	req := httptest.NewRequest(http.MethodPost, "/todos/"+todoID.String()+"/time-entries/start", strings.NewReader(`{"user_id":"`+userID.String()+`"}`))
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusConflict, rec.Code)
+	var body struct {
+		Running Entry `json:"running"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Equal(t, runningID, body.Running.ID)
+	require.Equal(t, otherTodoID, body.Running.TodoID)
+	require.True(t, body.Running.Running())
+	require.GreaterOrEqual(t, body.Running.Seconds, int64(3600))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestParseBound(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	from, err := parseBound("2026-03-01", berlin, false)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, berlin), *from)
+
+	to, err := parseBound("2026-03-31", berlin, true)
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2026, 4, 1, 0, 0, 0, 0, berlin), *to)
+
+	exact, err := parseBound("2026-03-01T08:00:00Z", berlin, true)
+	require.NoError(t, err)
+	require.True(t, exact.Equal(time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC)))
+
+	_, err = parseBound("March", berlin, false)
+	require.Error(t, err)
+}
@@ -0,0 +1,96 @@
+package timeentry
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Entry is time a user spent on a todo. A running timer has no EndedAt.
+type Entry struct {
+	ID        uuid.UUID  `json:"id"`
+	TodoID    uuid.UUID  `json:"todo_id"`
+	UserID    uuid.UUID  `json:"user_id"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	Note      string     `json:"note,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+	// Seconds is the length of the entry; running timers count up to now.
+	Seconds int64 `json:"seconds"`
+}
+
+// Running reports whether the entry is a timer that has not been stopped.
+func (e Entry) Running() bool {
+	return e.EndedAt == nil
+}
+
+// StartInput starts a timer on a todo.
+type StartInput struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+	Note   string    `json:"note"`
+}
+
+// StopInput stops the user's timer on a todo.
+type StopInput struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+}
+
+// CreateInput records time spent without a timer. The entry ends at EndedAt or, when
+// that is left out, Minutes after StartedAt.
+type CreateInput struct {
+	UserID    uuid.UUID  `json:"user_id" binding:"required"`
+	StartedAt time.Time  `json:"started_at" binding:"required"`
+	EndedAt   *time.Time `json:"ended_at"`
+	Minutes   int        `json:"minutes" binding:"min=0"`
+	Note      string     `json:"note"`
+}
+
+// GroupBy names how Totals adds up time entries.
+type GroupBy string
+
+const (
+	GroupByTodo    GroupBy = "todo"
+	GroupByProject GroupBy = "project"
+	GroupByUser    GroupBy = "user"
+	GroupByDay     GroupBy = "day"
+)
+
+// Filter selects the time entries of the todos a user may see.
+type Filter struct {
+	// UserID is the user asking; only entries on todos they can see are included.
+	UserID uuid.UUID
+	// From and To bound the start of the entries to [From, To).
+	From *time.Time
+	To   *time.Time
+	// ProjectID, TodoID and MemberID keep the entries of one project, todo or user.
+	ProjectID *uuid.UUID
+	TodoID    *uuid.UUID
+	MemberID  *uuid.UUID
+}
+
+// Total is the time tracked for one group. Only the fields identifying the group are set.
+type Total struct {
+	TodoID    *uuid.UUID `json:"todo_id,omitempty"`
+	ProjectID *uuid.UUID `json:"project_id,omitempty"`
+	UserID    *uuid.UUID `json:"user_id,omitempty"`
+	// Day is a date in the requested time zone, formatted as YYYY-MM-DD.
+	Day string `json:"day,omitempty"`
+	// Name is the todo's title or the project's name.
+	Name    string `json:"name,omitempty"`
+	Seconds int64  `json:"seconds"`
+}
+
+// Summary lists the totals of a Filter together with their sum.
+type Summary struct {
+	GroupBy      GroupBy `json:"group_by"`
+	Totals       []Total `json:"totals"`
+	TotalSeconds int64   `json:"total_seconds"`
+}
+
+// TimesheetRow is an entry together with the todo and project it was tracked on.
+type TimesheetRow struct {
+	Entry
+	TodoTitle   string
+	ProjectID   *uuid.UUID
+	ProjectName string
+}
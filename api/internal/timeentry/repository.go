@@ -0,0 +1,343 @@
+package timeentry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"overengineeredtodo/internal/todo"
+)
+
+const entryColumns = `id, todo_id, user_id, started_at, ended_at, note, created_at`
+
+// uniqueViolation is the SQLSTATE reported when the running timer index rejects a row.
+const uniqueViolation = "23505"
+
+// trackedSeconds sums the length of the entries aliased e, counting running timers up to now.
+const trackedSeconds = `sum(extract(epoch FROM COALESCE(e.ended_at, now()) - e.started_at))::INT8`
+
+// Repository provides Cockroach-backed persistence for time entries.
+type Repository struct {
+	pool pgxPool
+}
+
+type pgxPool interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// NewRepository constructs a repository around the supplied pgx pool.
+func NewRepository(pool pgxPool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// Start runs a timer for the user on the todo. Users run at most one timer at a time, so
+// starting another fails with ErrTimerRunning.
+func (r *Repository) Start(ctx context.Context, todoID uuid.UUID, input StartInput) (Entry, error) {
+	query := `
+		INSERT INTO time_entries (id, todo_id, user_id, started_at, note)
+		VALUES ($1, $2, $3, now(), $4)
+		RETURNING ` + entryColumns
+
+	e, err := scanEntry(r.pool.QueryRow(ctx, query, uuid.New(), todoID, input.UserID, strings.TrimSpace(input.Note)))
+	switch {
+	case err == nil:
+		return e, nil
+	case isUniqueViolation(err):
+		return Entry{}, ErrTimerRunning
+	default:
+		return Entry{}, fmt.Errorf("insert time entry: %w", err)
+	}
+}
+
+// Stop ends the user's running timer on the todo.
+func (r *Repository) Stop(ctx context.Context, todoID, userID uuid.UUID) (Entry, error) {
+	query := `
+		UPDATE time_entries
+		SET ended_at = greatest(now(), started_at)
+		WHERE todo_id = $1 AND user_id = $2 AND ended_at IS NULL
+		RETURNING ` + entryColumns
+
+	e, err := scanEntry(r.pool.QueryRow(ctx, query, todoID, userID))
+	switch {
+	case err == nil:
+		return e, nil
+	case err == pgx.ErrNoRows:
+		return Entry{}, ErrNoTimer
+	default:
+		return Entry{}, fmt.Errorf("stop time entry: %w", err)
+	}
+}
+
+// Running returns the user's running timer, on whichever todo it is.
+func (r *Repository) Running(ctx context.Context, userID uuid.UUID) (Entry, error) {
+	query := `
+		SELECT ` + entryColumns + `
+		FROM time_entries
+		WHERE user_id = $1 AND ended_at IS NULL
+	`
+
+	e, err := scanEntry(r.pool.QueryRow(ctx, query, userID))
+	switch {
+	case err == nil:
+		return e, nil
+	case err == pgx.ErrNoRows:
+		return Entry{}, ErrNoTimer
+	default:
+		return Entry{}, fmt.Errorf("select running time entry: %w", err)
+	}
+}
+
+// Create records time the user spent on the todo without a timer.
+func (r *Repository) Create(ctx context.Context, todoID uuid.UUID, input CreateInput) (Entry, error) {
+	endedAt := input.StartedAt.Add(time.Duration(input.Minutes) * time.Minute)
+	if input.EndedAt != nil {
+		endedAt = *input.EndedAt
+	}
+	if !endedAt.After(input.StartedAt) {
+		return Entry{}, ErrInvalidRange
+	}
+
+	query := `
+		INSERT INTO time_entries (id, todo_id, user_id, started_at, ended_at, note)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + entryColumns
+
+	e, err := scanEntry(r.pool.QueryRow(ctx, query, uuid.New(), todoID, input.UserID, input.StartedAt, endedAt, strings.TrimSpace(input.Note)))
+	if err != nil {
+		return Entry{}, fmt.Errorf("insert time entry: %w", err)
+	}
+	return e, nil
+}
+
+// Get fetches a time entry of the todo.
+func (r *Repository) Get(ctx context.Context, todoID, id uuid.UUID) (Entry, error) {
+	query := `
+		SELECT ` + entryColumns + `
+		FROM time_entries
+		WHERE id = $1 AND todo_id = $2
+	`
+
+	e, err := scanEntry(r.pool.QueryRow(ctx, query, id, todoID))
+	switch {
+	case err == nil:
+		return e, nil
+	case err == pgx.ErrNoRows:
+		return Entry{}, ErrNotFound
+	default:
+		return Entry{}, fmt.Errorf("select time entry: %w", err)
+	}
+}
+
+// ListByTodo returns the time entries of a todo, oldest first.
+func (r *Repository) ListByTodo(ctx context.Context, todoID uuid.UUID) ([]Entry, error) {
+	query := `
+		SELECT ` + entryColumns + `
+		FROM time_entries
+		WHERE todo_id = $1
+		ORDER BY started_at ASC, id ASC
+	`
+
+	rows, err := r.pool.Query(ctx, query, todoID)
+	if err != nil {
+		return nil, fmt.Errorf("query time entries: %w", err)
+	}
+	defer rows.Close()
+
+	result := []Entry{}
+	for rows.Next() {
+		e, err := scanEntry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan time entry: %w", err)
+		}
+		result = append(result, e)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate time entries: %w", rows.Err())
+	}
+
+	return result, nil
+}
+
+// Delete removes a time entry of the todo.
+func (r *Repository) Delete(ctx context.Context, todoID, id uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM time_entries WHERE id = $1 AND todo_id = $2`, id, todoID)
+	if err != nil {
+		return fmt.Errorf("delete time entry: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Totals adds up the time of the entries selected by filter per todo, project, user or
+// day. Days are taken in loc; entries of todos outside projects are totalled under no
+// project.
+func (r *Repository) Totals(ctx context.Context, filter Filter, groupBy GroupBy, loc *time.Location) (Summary, error) {
+	where, args := filter.conditions()
+
+	var key string
+	switch groupBy {
+	case GroupByTodo:
+		key = "e.todo_id, t.title"
+	case GroupByProject:
+		key = "t.project_id, COALESCE(p.name, '')"
+	case GroupByUser:
+		key = "e.user_id, ''"
+	case GroupByDay:
+		args = append(args, loc.String())
+		key = fmt.Sprintf("timezone($%d, e.started_at)::DATE, ''", len(args))
+	default:
+		return Summary{}, ErrInvalidGroup
+	}
+
+	query := `
+		SELECT ` + key + `, ` + trackedSeconds + `
+		FROM time_entries e
+		JOIN todos t ON t.id = e.todo_id
+		LEFT JOIN projects p ON p.id = t.project_id
+		WHERE ` + where + `
+		GROUP BY 1, 2
+		ORDER BY 1, 2
+	`
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return Summary{}, fmt.Errorf("query time totals: %w", err)
+	}
+	defer rows.Close()
+
+	summary := Summary{GroupBy: groupBy, Totals: []Total{}}
+	for rows.Next() {
+		var total Total
+		var id *uuid.UUID
+		var day time.Time
+		var keyDest any = &id
+		if groupBy == GroupByDay {
+			keyDest = &day
+		}
+		if err := rows.Scan(keyDest, &total.Name, &total.Seconds); err != nil {
+			return Summary{}, fmt.Errorf("scan time total: %w", err)
+		}
+
+		switch groupBy {
+		case GroupByTodo:
+			total.TodoID = id
+		case GroupByProject:
+			total.ProjectID = id
+		case GroupByUser:
+			total.UserID = id
+		case GroupByDay:
+			total.Day = day.Format(time.DateOnly)
+		}
+		summary.Totals = append(summary.Totals, total)
+		summary.TotalSeconds += total.Seconds
+	}
+
+	if rows.Err() != nil {
+		return Summary{}, fmt.Errorf("iterate time totals: %w", rows.Err())
+	}
+	return summary, nil
+}
+
+// StreamTimesheet calls fn with each entry selected by filter, in the order they started.
+func (r *Repository) StreamTimesheet(ctx context.Context, filter Filter, fn func(TimesheetRow) error) error {
+	where, args := filter.conditions()
+
+	query := `
+		SELECT e.id, e.todo_id, e.user_id, e.started_at, e.ended_at, e.note, e.created_at,
+			t.title, t.project_id, COALESCE(p.name, '')
+		FROM time_entries e
+		JOIN todos t ON t.id = e.todo_id
+		LEFT JOIN projects p ON p.id = t.project_id
+		WHERE ` + where + `
+		ORDER BY e.started_at ASC, e.id ASC
+	`
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("query timesheet: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row TimesheetRow
+		fields := append(entryFields(&row.Entry), &row.TodoTitle, &row.ProjectID, &row.ProjectName)
+		if err := rows.Scan(fields...); err != nil {
+			return fmt.Errorf("scan timesheet row: %w", err)
+		}
+		row.Entry = withSeconds(row.Entry, time.Now())
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+
+	if rows.Err() != nil {
+		return fmt.Errorf("iterate timesheet: %w", rows.Err())
+	}
+	return nil
+}
+
+// conditions returns the WHERE clause selecting the filter's entries, aliased e, and its
+// arguments.
+func (f Filter) conditions() (string, []any) {
+	args := []any{f.UserID}
+	conditions := []string{"e.todo_id IN (SELECT id FROM todos WHERE " + todo.VisibleTo("$1") + ")"}
+
+	if f.From != nil {
+		args = append(args, *f.From)
+		conditions = append(conditions, fmt.Sprintf("e.started_at >= $%d", len(args)))
+	}
+	if f.To != nil {
+		args = append(args, *f.To)
+		conditions = append(conditions, fmt.Sprintf("e.started_at < $%d", len(args)))
+	}
+	if f.ProjectID != nil {
+		args = append(args, *f.ProjectID)
+		conditions = append(conditions, fmt.Sprintf("t.project_id = $%d", len(args)))
+	}
+	if f.TodoID != nil {
+		args = append(args, *f.TodoID)
+		conditions = append(conditions, fmt.Sprintf("e.todo_id = $%d", len(args)))
+	}
+	if f.MemberID != nil {
+		args = append(args, *f.MemberID)
+		conditions = append(conditions, fmt.Sprintf("e.user_id = $%d", len(args)))
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+func scanEntry(row pgx.Row) (Entry, error) {
+	var e Entry
+	err := row.Scan(entryFields(&e)...)
+	return withSeconds(e, time.Now()), err
+}
+
+func entryFields(e *Entry) []any {
+	return []any{&e.ID, &e.TodoID, &e.UserID, &e.StartedAt, &e.EndedAt, &e.Note, &e.CreatedAt}
+}
+
+// withSeconds sets the length of the entry, counting a running timer up to now.
+func withSeconds(e Entry, now time.Time) Entry {
+	end := now
+	if e.EndedAt != nil {
+		end = *e.EndedAt
+	}
+	e.Seconds = max(int64(end.Sub(e.StartedAt)/time.Second), 0)
+	return e
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolation
+}
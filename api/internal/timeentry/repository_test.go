@@ -0,0 +1,129 @@
+package timeentry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+)
+
+var entryRowColumns = []string{"id", "todo_id", "user_id", "started_at", "ended_at", "note", "created_at"}
+
+func TestRepositoryStartWhileRunning(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	todoID, userID := uuid.New(), uuid.New()
+	mock.ExpectQuery("INSERT INTO time_entries \\(id, todo_id, user_id, started_at, note\\) VALUES \\(\\$1, \\$2, \\$3, now\\(\\), \\$4\\)").
+		WithArgs(pgxmock.AnyArg(), todoID, userID, "Design").
+		WillReturnError(&pgconn.PgError{Code: uniqueViolation})
+
+	_, err = NewRepository(mock).Start(context.Background(), todoID, StartInput{UserID: userID, Note: " Design "})
+	require.ErrorIs(t, err, ErrTimerRunning)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryStopWithoutTimer(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	todoID, userID := uuid.New(), uuid.New()
+	mock.ExpectQuery("UPDATE time_entries SET ended_at = greatest\\(now\\(\\), started_at\\) WHERE todo_id = \\$1 AND user_id = \\$2 AND ended_at IS NULL").
+		WithArgs(todoID, userID).
+		WillReturnError(pgx.ErrNoRows)
+
+	_, err = NewRepository(mock).Stop(context.Background(), todoID, userID)
+	require.ErrorIs(t, err, ErrNoTimer)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryCreateFromMinutes(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	todoID, userID := uuid.New(), uuid.New()
+	started := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	ended := started.Add(90 * time.Minute)
+	mock.ExpectQuery("INSERT INTO time_entries \\(id, todo_id, user_id, started_at, ended_at, note\\)").
+		WithArgs(pgxmock.AnyArg(), todoID, userID, started, ended, "Call").
+		WillReturnRows(pgxmock.NewRows(entryRowColumns).AddRow(uuid.New(), todoID, userID, started, &ended, "Call", time.Now()))
+
+	entry, err := NewRepository(mock).Create(context.Background(), todoID, CreateInput{UserID: userID, StartedAt: started, Minutes: 90, Note: "Call"})
+	require.NoError(t, err)
+	require.Equal(t, int64(5400), entry.Seconds)
+	require.False(t, entry.Running())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryCreateRejectsEmptyRange(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	started := time.Now()
+	_, err = NewRepository(mock).Create(context.Background(), uuid.New(), CreateInput{UserID: uuid.New(), StartedAt: started, EndedAt: &started})
+	require.ErrorIs(t, err, ErrInvalidRange)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryTotalsByProject(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	userID, projectID := uuid.New(), uuid.New()
+	from := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+
+	mock.ExpectQuery("SELECT t.project_id, COALESCE\\(p.name, ''\\), sum\\(.*\\)::INT8 FROM time_entries e .* WHERE e.todo_id IN \\(SELECT id FROM todos WHERE .*\\) AND e.started_at >= \\$2 AND e.started_at < \\$3 AND e.user_id = \\$4 GROUP BY 1, 2").
+		WithArgs(userID, from, to, userID).
+		WillReturnRows(pgxmock.NewRows([]string{"project_id", "name", "seconds"}).
+			AddRow(&projectID, "Client A", int64(7200)).
+			AddRow((*uuid.UUID)(nil), "", int64(600)))
+
+	summary, err := NewRepository(mock).Totals(context.Background(), Filter{UserID: userID, From: &from, To: &to, MemberID: &userID}, GroupByProject, time.UTC)
+	require.NoError(t, err)
+	require.Equal(t, int64(7800), summary.TotalSeconds)
+	require.Len(t, summary.Totals, 2)
+	require.Equal(t, projectID, *summary.Totals[0].ProjectID)
+	require.Equal(t, "Client A", summary.Totals[0].Name)
+	require.Nil(t, summary.Totals[1].ProjectID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryTotalsByDay(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	mock.ExpectQuery("SELECT timezone\\(\\$2, e.started_at\\)::DATE, ''").
+		WithArgs(userID, "Europe/Berlin").
+		WillReturnRows(pgxmock.NewRows([]string{"day", "name", "seconds"}).
+			AddRow(time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC), "", int64(3600)))
+
+	summary, err := NewRepository(mock).Totals(context.Background(), Filter{UserID: userID}, GroupByDay, berlin)
+	require.NoError(t, err)
+	require.Equal(t, "2026-03-02", summary.Totals[0].Day)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryTotalsRejectsUnknownGroup(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	_, err = NewRepository(mock).Totals(context.Background(), Filter{UserID: uuid.New()}, "label", time.UTC)
+	require.ErrorIs(t, err, ErrInvalidGroup)
+}
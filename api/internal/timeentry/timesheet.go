@@ -0,0 +1,69 @@
+package timeentry
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+var timesheetColumns = []string{"date", "started_at", "ended_at", "hours", "user_id", "project_id", "project", "todo_id", "todo", "note"}
+
+// TimesheetWriter writes time entries as CSV, one line per entry. Dates and times are
+// given in the writer's time zone and hours are rounded to two decimals.
+type TimesheetWriter struct {
+	w           *csv.Writer
+	loc         *time.Location
+	wroteHeader bool
+}
+
+// NewTimesheetWriter returns a writer of timesheet lines to w.
+func NewTimesheetWriter(w io.Writer, loc *time.Location) *TimesheetWriter {
+	return &TimesheetWriter{w: csv.NewWriter(w), loc: loc}
+}
+
+// Write adds a line for the entry. Running timers have no end and count up to now.
+func (tw *TimesheetWriter) Write(row TimesheetRow) error {
+	if err := tw.writeHeader(); err != nil {
+		return err
+	}
+
+	ended, projectID := "", ""
+	if row.EndedAt != nil {
+		ended = row.EndedAt.In(tw.loc).Format(time.RFC3339)
+	}
+	if row.ProjectID != nil {
+		projectID = row.ProjectID.String()
+	}
+
+	started := row.StartedAt.In(tw.loc)
+	return tw.w.Write([]string{
+		started.Format(time.DateOnly),
+		started.Format(time.RFC3339),
+		ended,
+		strconv.FormatFloat(float64(row.Seconds)/3600, 'f', 2, 64),
+		row.UserID.String(),
+		projectID,
+		row.ProjectName,
+		row.TodoID.String(),
+		row.TodoTitle,
+		row.Note,
+	})
+}
+
+// Close writes the header of an empty timesheet and flushes the output.
+func (tw *TimesheetWriter) Close() error {
+	if err := tw.writeHeader(); err != nil {
+		return err
+	}
+	tw.w.Flush()
+	return tw.w.Error()
+}
+
+func (tw *TimesheetWriter) writeHeader() error {
+	if tw.wroteHeader {
+		return nil
+	}
+	tw.wroteHeader = true
+	return tw.w.Write(timesheetColumns)
+}
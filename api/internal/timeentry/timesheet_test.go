@@ -0,0 +1,43 @@
+package timeentry
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimesheetWriter(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	started := time.Date(2026, 3, 2, 23, 30, 0, 0, time.UTC)
+	ended := started.Add(45 * time.Minute)
+	projectID := uuid.New()
+	row := TimesheetRow{
+		Entry:       withSeconds(Entry{ID: uuid.New(), TodoID: uuid.New(), UserID: uuid.New(), StartedAt: started, EndedAt: &ended, Note: "Review, round 2"}, time.Now()),
+		TodoTitle:   "Landing page",
+		ProjectID:   &projectID,
+		ProjectName: "Client A",
+	}
+
+	var out strings.Builder
+	w := NewTimesheetWriter(&out, berlin)
+	require.NoError(t, w.Write(row))
+	require.NoError(t, w.Close())
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	require.Equal(t, "date,started_at,ended_at,hours,user_id,project_id,project,todo_id,todo,note", lines[0])
+	require.Equal(t, "2026-03-03,2026-03-03T00:30:00+01:00,2026-03-03T01:15:00+01:00,0.75,"+
+		row.UserID.String()+","+projectID.String()+",Client A,"+row.TodoID.String()+",Landing page,\"Review, round 2\"", lines[1])
+}
+
+func TestTimesheetWriterEmpty(t *testing.T) {
+	var out strings.Builder
+	w := NewTimesheetWriter(&out, time.UTC)
+	require.NoError(t, w.Close())
+	require.Equal(t, "date,started_at,ended_at,hours,user_id,project_id,project,todo_id,todo,note\n", out.String())
+}
@@ -0,0 +1,91 @@
+package todo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"overengineeredtodo/internal/access"
+)
+
+// VisibleTo is the condition selecting the todos a user may see: their own todos outside
+// projects and all todos of the projects they are a member of. param is the placeholder
+// holding the user's id.
+func VisibleTo(param string) string {
+	return "((project_id IS NULL AND user_id = " + param + ") OR project_id IN (SELECT project_id FROM project_members WHERE user_id = " + param + "))"
+}
+
+// tombstoneVisibleTo is the condition selecting the tombstones of todos the user owned or
+// could see through a project they are still a member of.
+func tombstoneVisibleTo(param string) string {
+	return "(user_id = " + param + " OR project_id IN (SELECT project_id FROM project_members WHERE user_id = " + param + "))"
+}
+
+// Authorize fetches a todo the user holds at least the required role for. A todo outside
+// projects is owned by its user; one in a project grants the user's role in that project.
+// Todos the user cannot see fail with ErrNotFound, so their existence does not leak, and
+// todos the user may see but not change fail with ErrForbidden.
+func (r *Repository) Authorize(ctx context.Context, id, userID uuid.UUID, required access.Role) (Todo, error) {
+	query := `
+		SELECT ` + todoColumns + `,
+			(SELECT m.role FROM project_members m WHERE m.project_id = todos.project_id AND m.user_id = $2)
+		FROM todos
+		WHERE id = $1
+	`
+
+	var t Todo
+	var memberRole *string
+	err := r.pool.QueryRow(ctx, query, id, userID).Scan(append(todoFields(&t), &memberRole)...)
+	switch {
+	case err == pgx.ErrNoRows:
+		return Todo{}, ErrNotFound
+	case err != nil:
+		return Todo{}, fmt.Errorf("select todo: %w", err)
+	}
+
+	role := access.RoleNone
+	switch {
+	case t.ProjectID != nil && memberRole != nil:
+		role = access.Role(*memberRole)
+	case t.ProjectID == nil && t.UserID == userID:
+		role = access.RoleOwner
+	}
+
+	switch {
+	case role == access.RoleNone:
+		return Todo{}, ErrNotFound
+	case !role.Allows(required):
+		return Todo{}, ErrForbidden
+	}
+	return normalize(t), nil
+}
+
+// ProjectRole returns the user's role in a project, or RoleNone when they are not a member.
+func (r *Repository) ProjectRole(ctx context.Context, projectID, userID uuid.UUID) (access.Role, error) {
+	var role string
+	err := r.pool.QueryRow(ctx, `SELECT role FROM project_members WHERE project_id = $1 AND user_id = $2`, projectID, userID).Scan(&role)
+	switch {
+	case err == pgx.ErrNoRows:
+		return access.RoleNone, nil
+	case err != nil:
+		return access.RoleNone, fmt.Errorf("select project role: %w", err)
+	}
+	return access.Role(role), nil
+}
+
+// CheckProjectWrite makes sure the user may add todos to the project, or move todos into
+// it. Projects the user is no member of are reported as unknown.
+func (r *Repository) CheckProjectWrite(ctx context.Context, projectID, userID uuid.UUID) error {
+	role, err := r.ProjectRole(ctx, projectID, userID)
+	switch {
+	case err != nil:
+		return err
+	case role == access.RoleNone:
+		return ErrUnknownProject
+	case !role.Allows(access.RoleEditor):
+		return ErrForbidden
+	}
+	return nil
+}
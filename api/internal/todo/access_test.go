@@ -0,0 +1,82 @@
+package todo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+
+	"overengineeredtodo/internal/access"
+)
+
+func TestRepositoryAuthorize(t *testing.T) {
+	userID, projectID := uuid.New(), uuid.New()
+	now := time.Now()
+	own := Todo{ID: uuid.New(), UserID: userID, Title: "Own", CreatedAt: now, UpdatedAt: now}
+	foreign := Todo{ID: uuid.New(), UserID: uuid.New(), Title: "Foreign", CreatedAt: now, UpdatedAt: now}
+	shared := Todo{ID: uuid.New(), UserID: uuid.New(), Title: "Shared", ProjectID: &projectID, CreatedAt: now, UpdatedAt: now}
+	viewer, editor := "viewer", "editor"
+
+	tests := []struct {
+		name     string
+		todo     Todo
+		role     *string
+		required access.Role
+		wantErr  error
+	}{
+		{name: "own todo", todo: own, required: access.RoleOwner},
+		{name: "foreign personal todo", todo: foreign, role: &editor, required: access.RoleViewer, wantErr: ErrNotFound},
+		{name: "shared todo of non-member", todo: shared, required: access.RoleViewer, wantErr: ErrNotFound},
+		{name: "viewer reads", todo: shared, role: &viewer, required: access.RoleViewer},
+		{name: "viewer edits", todo: shared, role: &viewer, required: access.RoleEditor, wantErr: ErrForbidden},
+		{name: "editor edits", todo: shared, role: &editor, required: access.RoleEditor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock, err := pgxmock.NewPool()
+			require.NoError(t, err)
+			defer mock.Close()
+
+			mock.ExpectQuery("SELECT .*, \\(SELECT m.role FROM project_members m .*\\) FROM todos WHERE id = \\$1").
+				WithArgs(tt.todo.ID, userID).
+				WillReturnRows(authorizeRows(tt.todo, tt.role))
+
+			got, err := NewRepository(mock).Authorize(context.Background(), tt.todo.ID, userID, tt.required)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.todo.ID, got.ID)
+			}
+			require.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestRepositoryCheckProjectWrite(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	userID, projectID := uuid.New(), uuid.New()
+
+	mock.ExpectQuery("SELECT role FROM project_members WHERE project_id = \\$1 AND user_id = \\$2").
+		WithArgs(projectID, userID).
+		WillReturnRows(pgxmock.NewRows([]string{"role"}).AddRow("viewer"))
+	mock.ExpectQuery("SELECT role FROM project_members").
+		WithArgs(projectID, userID).
+		WillReturnRows(pgxmock.NewRows([]string{"role"}))
+	mock.ExpectQuery("SELECT role FROM project_members").
+		WithArgs(projectID, userID).
+		WillReturnRows(pgxmock.NewRows([]string{"role"}).AddRow("editor"))
+
+	require.ErrorIs(t, repo.CheckProjectWrite(context.Background(), projectID, userID), ErrForbidden)
+	require.ErrorIs(t, repo.CheckProjectWrite(context.Background(), projectID, userID), ErrUnknownProject)
+	require.NoError(t, repo.CheckProjectWrite(context.Background(), projectID, userID))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
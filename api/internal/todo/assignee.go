@@ -0,0 +1,30 @@
+package todo
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+
+	"overengineeredtodo/internal/access"
+)
+
+// checkAssignee makes sure the assignee can see a todo of owner in the project: outside
+// projects only the owner can, inside a project every member.
+func (r *Repository) checkAssignee(ctx context.Context, ownerID uuid.UUID, projectID *uuid.UUID, assigneeID uuid.UUID) error {
+	if projectID == nil {
+		if assigneeID != ownerID {
+			return ErrUnknownAssignee
+		}
+		return nil
+	}
+
+	role, err := r.ProjectRole(ctx, *projectID, assigneeID)
+	switch {
+	case err != nil:
+		return err
+	case role == access.RoleNone:
+		return ErrUnknownAssignee
+	default:
+		return nil
+	}
+}
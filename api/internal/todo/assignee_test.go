@@ -0,0 +1,96 @@
+package todo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+
+	"overengineeredtodo/internal/notification"
+	"overengineeredtodo/internal/outbox"
+	"overengineeredtodo/internal/workflow"
+)
+
+func TestRepositoryCreateRejectsForeignAssigneeOutsideProjects(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	assigneeID := uuid.New()
+	_, err = NewRepository(mock).Create(context.Background(), CreateInput{UserID: uuid.New(), Title: "Call", AssigneeID: &assigneeID})
+	require.ErrorIs(t, err, ErrUnknownAssignee)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryUpdateAssignsAndNotifies(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	ownerID, assigneeID, projectID := uuid.New(), uuid.New(), uuid.New()
+	now := time.Now()
+	current := Todo{ID: uuid.New(), UserID: ownerID, Title: "Plan trip", ProjectID: &projectID, Status: workflow.StatusBacklog, CreatedAt: now, UpdatedAt: now, Version: 1}
+	assigned := current
+	assigned.AssigneeID = &assigneeID
+
+	mock.ExpectQuery("SELECT .* FROM todos WHERE id = \\$1").
+		WithArgs(current.ID).
+		WillReturnRows(newTodoRows(current))
+	mock.ExpectQuery("SELECT role FROM project_members WHERE project_id = \\$1 AND user_id = \\$2").
+		WithArgs(projectID, assigneeID).
+		WillReturnRows(pgxmock.NewRows([]string{"role"}).AddRow("viewer"))
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE todos SET assignee_id = \\$1").
+		WithArgs(assigneeID, current.ID).
+		WillReturnRows(newTodoRows(assigned))
+	expectEvent(mock, outbox.TodoUpdated, current.ID, 1)
+	mock.ExpectExec("INSERT INTO notifications").
+		WithArgs(pgxmock.AnyArg(), assigneeID, notification.KindAssignment, current.ID, (*uuid.UUID)(nil), ownerID).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+
+	updated, err := repo.Update(context.Background(), current.ID, UpdateInput{AssigneeID: &assigneeID, ActorID: ownerID})
+	require.NoError(t, err)
+	require.Equal(t, assigneeID, *updated.AssigneeID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryUpdateRejectsNonMemberAssignee(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	assigneeID, projectID := uuid.New(), uuid.New()
+	current := Todo{ID: uuid.New(), UserID: uuid.New(), Title: "Plan trip", ProjectID: &projectID, Status: workflow.StatusBacklog}
+
+	mock.ExpectQuery("SELECT .* FROM todos WHERE id = \\$1").
+		WithArgs(current.ID).
+		WillReturnRows(newTodoRows(current))
+	mock.ExpectQuery("SELECT role FROM project_members").
+		WithArgs(projectID, assigneeID).
+		WillReturnRows(pgxmock.NewRows([]string{"role"}))
+
+	_, err = repo.Update(context.Background(), current.ID, UpdateInput{AssigneeID: &assigneeID, ActorID: current.UserID})
+	require.ErrorIs(t, err, ErrUnknownAssignee)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryListByAssignee(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	mock.ExpectQuery("WHERE \\(\\(project_id IS NULL AND user_id = \\$1\\) .*\\) AND assignee_id = \\$2 AND "+notHiddenPattern+" ORDER BY created_at DESC").
+		WithArgs(userID, userID).
+		WillReturnRows(newTodoRows())
+
+	_, err = NewRepository(mock).List(context.Background(), userID, ListOptions{AssigneeID: &userID})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
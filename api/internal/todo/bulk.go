@@ -0,0 +1,396 @@
+package todo
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Formats understood by the bulk export and import endpoints.
+const (
+	FormatCSV    = "csv"
+	FormatJSON   = "json"
+	FormatNDJSON = "ndjson"
+	FormatICal   = "ics"
+)
+
+// importBatchSize bounds the number of rows written by a single multi-row INSERT.
+const importBatchSize = 500
+
+var (
+	exportColumns = []string{"id", "title", "description", "due_date", "completed", "created_at", "updated_at"}
+	importFields  = []string{"title", "description", "due_date", "completed"}
+)
+
+// recordWriter streams todos in one of the bulk formats.
+type recordWriter interface {
+	Write(t Todo) error
+	Close() error
+}
+
+func newRecordWriter(w io.Writer, format string) (recordWriter, error) {
+	switch format {
+	case FormatCSV:
+		return &csvRecordWriter{w: csv.NewWriter(w)}, nil
+	case FormatJSON:
+		return &jsonRecordWriter{w: w}, nil
+	case FormatNDJSON:
+		return &ndjsonRecordWriter{enc: json.NewEncoder(w)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported format %q", format)
+	}
+}
+
+type csvRecordWriter struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+func (cw *csvRecordWriter) Write(t Todo) error {
+	if !cw.wroteHeader {
+		if err := cw.w.Write(exportColumns); err != nil {
+			return err
+		}
+		cw.wroteHeader = true
+	}
+
+	due := ""
+	if t.DueDate != nil {
+		due = t.DueDate.UTC().Format(time.RFC3339)
+	}
+
+	return cw.w.Write([]string{
+		t.ID.String(),
+		t.Title,
+		t.Description,
+		due,
+		strconv.FormatBool(t.Completed),
+		t.CreatedAt.UTC().Format(time.RFC3339),
+		t.UpdatedAt.UTC().Format(time.RFC3339),
+	})
+}
+
+func (cw *csvRecordWriter) Close() error {
+	if !cw.wroteHeader {
+		if err := cw.w.Write(exportColumns); err != nil {
+			return err
+		}
+	}
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+type jsonRecordWriter struct {
+	w       io.Writer
+	started bool
+}
+
+func (jw *jsonRecordWriter) Write(t Todo) error {
+	prefix := ","
+	if !jw.started {
+		prefix = "["
+		jw.started = true
+	}
+
+	data, err := json.Marshal(t)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(jw.w, prefix+string(data))
+	return err
+}
+
+func (jw *jsonRecordWriter) Close() error {
+	if !jw.started {
+		_, err := io.WriteString(jw.w, "[]")
+		return err
+	}
+	_, err := io.WriteString(jw.w, "]")
+	return err
+}
+
+type ndjsonRecordWriter struct {
+	enc *json.Encoder
+}
+
+func (nw *ndjsonRecordWriter) Write(t Todo) error {
+	return nw.enc.Encode(t)
+}
+
+func (nw *ndjsonRecordWriter) Close() error {
+	return nil
+}
+
+// Mapping tells the importer which source column (CSV) or key (JSON) holds each
+// todo field. Fields that are not mapped are read from a column of the same name.
+type Mapping map[string]string
+
+func (m Mapping) validate() error {
+	for field := range m {
+		known := false
+		for _, f := range importFields {
+			if f == field {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return fmt.Errorf("%w: cannot map unknown field %q", ErrInvalidImport, field)
+		}
+	}
+	return nil
+}
+
+func (m Mapping) source(field string) string {
+	if src, ok := m[field]; ok {
+		return strings.ToLower(strings.TrimSpace(src))
+	}
+	return field
+}
+
+// recordReader yields source records keyed by lower-cased column name. It returns
+// io.EOF once the input is exhausted.
+type recordReader interface {
+	Next() (row int, record map[string]string, err error)
+}
+
+func newRecordReader(r io.Reader, format string) (recordReader, error) {
+	switch format {
+	case FormatCSV:
+		cr := csv.NewReader(r)
+		cr.FieldsPerRecord = -1
+		cr.TrimLeadingSpace = true
+
+		header, err := cr.Read()
+		if err != nil {
+			return nil, fmt.Errorf("%w: read csv header: %v", ErrInvalidImport, err)
+		}
+		for i := range header {
+			header[i] = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(header[i], "\ufeff")))
+		}
+		return &csvRecordReader{r: cr, header: header, row: 1}, nil
+	case FormatJSON:
+		dec := json.NewDecoder(r)
+		tok, err := dec.Token()
+		if err != nil || tok != json.Delim('[') {
+			return nil, fmt.Errorf("%w: expected a JSON array", ErrInvalidImport)
+		}
+		return &jsonRecordReader{dec: dec, array: true}, nil
+	case FormatNDJSON:
+		return &jsonRecordReader{dec: json.NewDecoder(r)}, nil
+	default:
+		return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidImport, format)
+	}
+}
+
+type csvRecordReader struct {
+	r      *csv.Reader
+	header []string
+	row    int
+}
+
+func (cr *csvRecordReader) Next() (int, map[string]string, error) {
+	values, err := cr.r.Read()
+	if err == io.EOF {
+		return 0, nil, io.EOF
+	}
+	cr.row++
+	if err != nil {
+		return cr.row, nil, fmt.Errorf("%w: row %d: %v", ErrInvalidImport, cr.row, err)
+	}
+
+	record := make(map[string]string, len(cr.header))
+	for i, name := range cr.header {
+		if i < len(values) {
+			record[name] = values[i]
+		}
+	}
+	return cr.row, record, nil
+}
+
+type jsonRecordReader struct {
+	dec   *json.Decoder
+	array bool
+	row   int
+}
+
+func (jr *jsonRecordReader) Next() (int, map[string]string, error) {
+	if jr.array && !jr.dec.More() {
+		return 0, nil, io.EOF
+	}
+
+	var raw map[string]any
+	if err := jr.dec.Decode(&raw); err != nil {
+		if err == io.EOF && !jr.array {
+			return 0, nil, io.EOF
+		}
+		return jr.row + 1, nil, fmt.Errorf("%w: record %d: %v", ErrInvalidImport, jr.row+1, err)
+	}
+	jr.row++
+
+	record := make(map[string]string, len(raw))
+	for key, value := range raw {
+		switch v := value.(type) {
+		case nil:
+			record[strings.ToLower(key)] = ""
+		case string:
+			record[strings.ToLower(key)] = v
+		default:
+			record[strings.ToLower(key)] = fmt.Sprint(v)
+		}
+	}
+	return jr.row, record, nil
+}
+
+// recordToInput validates a source record and converts it into a CreateInput.
+func recordToInput(record map[string]string, mapping Mapping, userID uuid.UUID) (CreateInput, error) {
+	input := CreateInput{
+		UserID:      userID,
+		Title:       strings.TrimSpace(record[mapping.source("title")]),
+		Description: record[mapping.source("description")],
+	}
+	if input.Title == "" {
+		return input, errors.New("title is required")
+	}
+
+	if raw := strings.TrimSpace(record[mapping.source("due_date")]); raw != "" {
+		due, err := parseImportDate(raw)
+		if err != nil {
+			return input, fmt.Errorf("invalid due_date %q", raw)
+		}
+		input.DueDate = &due
+	}
+
+	if raw := strings.TrimSpace(record[mapping.source("completed")]); raw != "" {
+		completed, err := parseImportBool(raw)
+		if err != nil {
+			return input, fmt.Errorf("invalid completed %q", raw)
+		}
+		input.Completed = completed
+	}
+
+	return input, nil
+}
+
+func parseImportDate(raw string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.Parse(layout, raw); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, errors.New("unrecognised date")
+}
+
+func parseImportBool(raw string) (bool, error) {
+	switch strings.ToLower(raw) {
+	case "yes", "y", "x", "done":
+		return true, nil
+	case "no", "n", "open":
+		return false, nil
+	}
+	return strconv.ParseBool(raw)
+}
+
+// ImportRecords creates a todo for every valid record. Invalid rows are reported as
+// skipped with their row number; with dryRun nothing is written. Rows are inserted
+// in batches of importBatchSize within one transaction, so when the import fails
+// nothing is created and the report only lists the rows skipped so far.
+func ImportRecords(ctx context.Context, repo *Repository, userID uuid.UUID, reader recordReader, mapping Mapping, dryRun bool) (ImportReport, error) {
+	report := ImportReport{
+		Created: []ImportEntry{},
+		Updated: []ImportEntry{},
+		Skipped: []ImportEntry{},
+		DryRun:  dryRun,
+	}
+
+	if err := mapping.validate(); err != nil {
+		return report, err
+	}
+
+	fail := func(err error) (ImportReport, error) {
+		report.Created = []ImportEntry{}
+		return report, err
+	}
+
+	var tx pgx.Tx
+	if !dryRun {
+		var err error
+		if tx, err = repo.pool.Begin(ctx); err != nil {
+			return report, fmt.Errorf("begin import: %w", err)
+		}
+		// Rolling back a committed transaction is a no-op.
+		defer func() { _ = tx.Rollback(ctx) }()
+		repo = NewRepository(tx)
+	}
+
+	var (
+		batch []CreateInput
+		rows  []int
+	)
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if !dryRun {
+			created, err := repo.CreateBatch(ctx, batch)
+			if err != nil {
+				return err
+			}
+			for i, t := range created {
+				report.Created = append(report.Created, ImportEntry{Row: rows[i], TodoID: &t.ID, Title: t.Title})
+			}
+		} else {
+			for i, input := range batch {
+				report.Created = append(report.Created, ImportEntry{Row: rows[i], Title: input.Title})
+			}
+		}
+		batch, rows = batch[:0], rows[:0]
+		return nil
+	}
+
+	for {
+		row, record, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fail(err)
+		}
+
+		input, err := recordToInput(record, mapping, userID)
+		if err != nil {
+			report.Skipped = append(report.Skipped, ImportEntry{Row: row, Title: input.Title, Reason: err.Error()})
+			continue
+		}
+
+		batch = append(batch, input)
+		rows = append(rows, row)
+		if len(batch) == importBatchSize {
+			if err := flush(); err != nil {
+				return fail(err)
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return fail(err)
+	}
+
+	if tx != nil {
+		if err := tx.Commit(ctx); err != nil {
+			return fail(fmt.Errorf("commit import: %w", err))
+		}
+	}
+
+	return report, nil
+}
@@ -0,0 +1,190 @@
+package todo
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+
+	"overengineeredtodo/internal/customfield"
+	"overengineeredtodo/internal/outbox"
+	"overengineeredtodo/internal/workflow"
+)
+
+func TestRecordWriters(t *testing.T) {
+	due := time.Date(2025, 6, 1, 9, 0, 0, 0, time.UTC)
+	created := time.Date(2025, 5, 1, 9, 0, 0, 0, time.UTC)
+	todos := []Todo{
+		{ID: uuid.MustParse("00000000-0000-0000-0000-000000000001"), Title: "A, with comma", DueDate: &due, CreatedAt: created, UpdatedAt: created},
+		{ID: uuid.MustParse("00000000-0000-0000-0000-000000000002"), Title: "B", Completed: true, CreatedAt: created, UpdatedAt: created},
+	}
+
+	render := func(format string, todos []Todo) string {
+		var buf bytes.Buffer
+		w, err := newRecordWriter(&buf, format)
+		require.NoError(t, err)
+		for _, todo := range todos {
+			require.NoError(t, w.Write(todo))
+		}
+		require.NoError(t, w.Close())
+		return buf.String()
+	}
+
+	csvOut := render(FormatCSV, todos)
+	require.Equal(t, "id,title,description,due_date,completed,created_at,updated_at\n"+
+		"00000000-0000-0000-0000-000000000001,\"A, with comma\",,2025-06-01T09:00:00Z,false,2025-05-01T09:00:00Z,2025-05-01T09:00:00Z\n"+
+		"00000000-0000-0000-0000-000000000002,B,,,true,2025-05-01T09:00:00Z,2025-05-01T09:00:00Z\n", csvOut)
+
+	jsonOut := render(FormatJSON, todos)
+	require.True(t, strings.HasPrefix(jsonOut, `[{"id":"00000000-0000-0000-0000-000000000001"`))
+	require.True(t, strings.HasSuffix(jsonOut, "}]"))
+	require.Equal(t, "[]", render(FormatJSON, nil))
+
+	ndjsonOut := render(FormatNDJSON, todos)
+	require.Len(t, strings.Split(strings.TrimSpace(ndjsonOut), "\n"), 2)
+
+	_, err := newRecordWriter(&bytes.Buffer{}, "xml")
+	require.Error(t, err)
+}
+
+func TestImportRecordsDryRunReportsRowErrors(t *testing.T) {
+	input := "Task,Notes,Deadline,Done\n" +
+		"Write spec,first draft,2025-07-01,no\n" +
+		",missing title,,\n" +
+		"Review,,next week,\n" +
+		"Ship,,2025-07-03T10:00:00Z,yes\n"
+
+	reader, err := newRecordReader(strings.NewReader(input), FormatCSV)
+	require.NoError(t, err)
+
+	mapping := Mapping{"title": "Task", "description": "Notes", "due_date": "Deadline", "completed": "Done"}
+	report, err := ImportRecords(context.Background(), nil, uuid.New(), reader, mapping, true)
+	require.NoError(t, err)
+	require.True(t, report.DryRun)
+
+	require.Len(t, report.Created, 2)
+	require.Equal(t, 2, report.Created[0].Row)
+	require.Equal(t, "Write spec", report.Created[0].Title)
+	require.Nil(t, report.Created[0].TodoID)
+	require.Equal(t, 5, report.Created[1].Row)
+
+	require.Len(t, report.Skipped, 2)
+	require.Equal(t, ImportEntry{Row: 3, Reason: "title is required"}, report.Skipped[0])
+	require.Equal(t, 4, report.Skipped[1].Row)
+	require.Contains(t, report.Skipped[1].Reason, "invalid due_date")
+}
+
+func TestImportRecordsRejectsUnknownMapping(t *testing.T) {
+	reader, err := newRecordReader(strings.NewReader("[]"), FormatJSON)
+	require.NoError(t, err)
+
+	_, err = ImportRecords(context.Background(), nil, uuid.New(), reader, Mapping{"owner": "x"}, true)
+	require.ErrorIs(t, err, ErrInvalidImport)
+}
+
+func TestImportRecordsInsertsInBatches(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	userID := uuid.New()
+	now := time.Now()
+	total := importBatchSize + 1
+
+	// Make the ids generated by CreateBatch predictable. Each batch draws the ids of its
+	// todos and then those of its events.
+	seed := make([]byte, 2*16*total)
+	for i := 0; i < 2*total; i++ {
+		binary.BigEndian.PutUint32(seed[i*16:], uint32(i+1))
+	}
+	uuid.SetRand(bytes.NewReader(seed))
+	defer uuid.SetRand(nil)
+
+	drawn := make([]uuid.UUID, 2*total)
+	expected := bytes.NewReader(seed)
+	for i := range drawn {
+		drawn[i], err = uuid.NewRandomFromReader(expected)
+		require.NoError(t, err)
+	}
+	ids := append(drawn[:importBatchSize:importBatchSize], drawn[2*importBatchSize])
+
+	var input strings.Builder
+	for i := 0; i < total; i++ {
+		input.WriteString(`{"title":"task","completed":true}` + "\n")
+	}
+
+	reader, err := newRecordReader(strings.NewReader(input.String()), FormatNDJSON)
+	require.NoError(t, err)
+
+	// The first batch comes back in reverse order to check that results are matched by id.
+	firstBatch := newTodoRows()
+	firstArgs := make([]any, 0, importBatchSize*20)
+	for i := importBatchSize - 1; i >= 0; i-- {
+		firstBatch.AddRow(ids[i], userID, "task", "", nil, true, now, now, nil, 1, nil, []string{}, 0, &now, workflow.StatusDone, customfield.Values{}, nil, "", nil, nil, nil, nil)
+	}
+	for i := 0; i < importBatchSize; i++ {
+		firstArgs = append(firstArgs, ids[i], userID, "task", "", (*time.Time)(nil), true, (*string)(nil), (*uuid.UUID)(nil), []string{}, 0, (*string)(nil), (*string)(nil), pgxmock.AnyArg(), workflow.StatusDone, customfield.Values{}, (*uuid.UUID)(nil), "", (*uuid.UUID)(nil), (*int)(nil), (*time.Time)(nil))
+	}
+
+	// The batches are savepoints of the import's transaction.
+	mock.ExpectBegin()
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO todos .* VALUES \\(\\$1, .*, \\$20\\), \\(\\$21").
+		WithArgs(firstArgs...).
+		WillReturnRows(firstBatch)
+	for i := 0; i < importBatchSize; i++ {
+		expectEvent(mock, outbox.TodoCreated, ids[i], 1)
+	}
+	mock.ExpectCommit()
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO todos .* VALUES \\(\\$1, .*, \\$20\\) RETURNING").
+		WithArgs(ids[importBatchSize], userID, "task", "", (*time.Time)(nil), true, (*string)(nil), (*uuid.UUID)(nil), []string{}, 0, (*string)(nil), (*string)(nil), pgxmock.AnyArg(), workflow.StatusDone, customfield.Values{}, (*uuid.UUID)(nil), "", (*uuid.UUID)(nil), (*int)(nil), (*time.Time)(nil)).
+		WillReturnRows(newTodoRows(Todo{ID: ids[importBatchSize], UserID: userID, Title: "task", Completed: true, CreatedAt: now, UpdatedAt: now}))
+	expectEvent(mock, outbox.TodoCreated, ids[importBatchSize], 0)
+	mock.ExpectCommit()
+	mock.ExpectCommit()
+
+	report, err := ImportRecords(context.Background(), repo, userID, reader, nil, false)
+	require.NoError(t, err)
+	require.Len(t, report.Created, total)
+	for i, entry := range report.Created {
+		require.Equal(t, i+1, entry.Row)
+		require.Equal(t, ids[i], *entry.TodoID, "row %d", i)
+	}
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestImportRecordsCreatesNothingWhenABatchFails(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	reader, err := newRecordReader(strings.NewReader(`{"title":"task"}`+"\n"+`{"completed":true}`+"\n"), FormatNDJSON)
+	require.NoError(t, err)
+
+	args := make([]any, 20)
+	for i := range args {
+		args[i] = pgxmock.AnyArg()
+	}
+	mock.ExpectBegin()
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO todos").
+		WithArgs(args...).
+		WillReturnError(errors.New("connection reset"))
+	mock.ExpectRollback()
+	mock.ExpectRollback()
+
+	report, err := ImportRecords(context.Background(), NewRepository(mock), uuid.New(), reader, nil, false)
+	require.ErrorContains(t, err, "connection reset")
+	require.Empty(t, report.Created)
+	require.Len(t, report.Skipped, 1)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
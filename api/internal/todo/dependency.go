@@ -0,0 +1,211 @@
+package todo
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// AddDependency records that todoID cannot start before blockerID is completed. Both
+// todos must belong to the same user or to the same project, and userID must be able to
+// see the blocker; otherwise it fails with ErrUnknownBlocker. Adding an existing
+// dependency is a no-op; one that would close a cycle fails with ErrDependencyCycle.
+func (r *Repository) AddDependency(ctx context.Context, todoID, blockerID, userID uuid.UUID) error {
+	if todoID == blockerID {
+		return ErrDependencyCycle
+	}
+
+	// The cycle check and the insert share a serializable transaction, so two concurrent
+	// requests cannot each add one half of a cycle.
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var owner, blockerOwner uuid.UUID
+	var project, blockerProject *uuid.UUID
+	if err := tx.QueryRow(ctx, `SELECT user_id, project_id FROM todos WHERE id = $1`, todoID).Scan(&owner, &project); err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrNotFound
+		}
+		return fmt.Errorf("get todo: %w", err)
+	}
+	err = tx.QueryRow(ctx, `SELECT user_id, project_id FROM todos WHERE id = $1 AND `+VisibleTo("$2"), blockerID, userID).
+		Scan(&blockerOwner, &blockerProject)
+	related := blockerOwner == owner || (project != nil && blockerProject != nil && *project == *blockerProject)
+	switch {
+	case err == pgx.ErrNoRows || (err == nil && !related):
+		return ErrUnknownBlocker
+	case err != nil:
+		return fmt.Errorf("get blocker: %w", err)
+	}
+
+	// A cycle forms when the blocker already waits, directly or transitively, for the todo.
+	var cycle bool
+	err = tx.QueryRow(ctx, `
+		WITH RECURSIVE upstream (id) AS (
+			SELECT blocker_id FROM todo_dependencies WHERE todo_id = $1
+			UNION
+			SELECT d.blocker_id FROM todo_dependencies d JOIN upstream u ON d.todo_id = u.id
+		)
+		SELECT EXISTS (SELECT 1 FROM upstream WHERE id = $2)`, blockerID, todoID).Scan(&cycle)
+	if err != nil {
+		return fmt.Errorf("check dependency cycle: %w", err)
+	}
+	if cycle {
+		return ErrDependencyCycle
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO todo_dependencies (todo_id, blocker_id)
+		VALUES ($1, $2)
+		ON CONFLICT (todo_id, blocker_id) DO NOTHING`, todoID, blockerID); err != nil {
+		return fmt.Errorf("insert dependency: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit dependency: %w", err)
+	}
+	return nil
+}
+
+// RemoveDependency deletes the dependency of todoID on blockerID.
+func (r *Repository) RemoveDependency(ctx context.Context, todoID, blockerID uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM todo_dependencies WHERE todo_id = $1 AND blocker_id = $2`, todoID, blockerID)
+	if err != nil {
+		return fmt.Errorf("delete dependency: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNoDependency
+	}
+	return nil
+}
+
+// WithBlockers fills in BlockedBy and Blocked of the todos.
+func (r *Repository) WithBlockers(ctx context.Context, todos []Todo) error {
+	if len(todos) == 0 {
+		return nil
+	}
+
+	index := make(map[uuid.UUID]int, len(todos))
+	ids := make([]uuid.UUID, len(todos))
+	for i, t := range todos {
+		index[t.ID] = i
+		ids[i] = t.ID
+		todos[i].BlockedBy, todos[i].Blocked = nil, false
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT d.todo_id, d.blocker_id, b.completed
+		FROM todo_dependencies d
+		JOIN todos b ON b.id = d.blocker_id
+		WHERE d.todo_id = ANY($1)
+		ORDER BY d.created_at, d.blocker_id`, ids)
+	if err != nil {
+		return fmt.Errorf("query dependencies: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var todoID, blockerID uuid.UUID
+		var completed bool
+		if err := rows.Scan(&todoID, &blockerID, &completed); err != nil {
+			return fmt.Errorf("scan dependency: %w", err)
+		}
+		t := &todos[index[todoID]]
+		t.BlockedBy = append(t.BlockedBy, blockerID)
+		t.Blocked = t.Blocked || !completed
+	}
+
+	if rows.Err() != nil {
+		return fmt.Errorf("iterate dependencies: %w", rows.Err())
+	}
+	return nil
+}
+
+// Plan orders open todos so that every todo comes after the todos it depends on.
+type Plan struct {
+	Steps []PlanStep `json:"steps"`
+}
+
+// PlanStep is a todo in a plan. Todos of the same level do not depend on each other and
+// can be worked on in parallel; level 0 holds the todos that can start right away.
+type PlanStep struct {
+	Level int  `json:"level"`
+	Todo  Todo `json:"todo"`
+}
+
+// NewPlan sorts the open todos topologically by their BlockedBy dependencies. Within a
+// level, todos are ordered by priority, then due date and creation time. Dependencies on
+// todos outside the list, or on completed todos, do not hold todos back.
+func NewPlan(todos []Todo) (Plan, error) {
+	open := make(map[uuid.UUID]Todo)
+	for _, t := range todos {
+		if !t.Completed {
+			open[t.ID] = t
+		}
+	}
+
+	waiting := make(map[uuid.UUID]int, len(open))
+	dependents := make(map[uuid.UUID][]uuid.UUID)
+	for id, t := range open {
+		for _, blocker := range t.BlockedBy {
+			if _, ok := open[blocker]; ok {
+				waiting[id]++
+				dependents[blocker] = append(dependents[blocker], id)
+			}
+		}
+	}
+
+	var ready []Todo
+	for id, t := range open {
+		if waiting[id] == 0 {
+			ready = append(ready, t)
+		}
+	}
+
+	plan := Plan{Steps: make([]PlanStep, 0, len(open))}
+	for level := 0; len(ready) > 0; level++ {
+		slices.SortFunc(ready, comparePlanOrder)
+		var next []Todo
+		for _, t := range ready {
+			plan.Steps = append(plan.Steps, PlanStep{Level: level, Todo: t})
+			for _, dependent := range dependents[t.ID] {
+				waiting[dependent]--
+				if waiting[dependent] == 0 {
+					next = append(next, open[dependent])
+				}
+			}
+		}
+		ready = next
+	}
+
+	if len(plan.Steps) != len(open) {
+		return Plan{}, ErrDependencyCycle
+	}
+	return plan, nil
+}
+
+// comparePlanOrder puts urgent todos first: higher priority, then earlier due dates
+// (todos without one last), then older todos.
+func comparePlanOrder(a, b Todo) int {
+	if a.Priority != b.Priority {
+		return b.Priority - a.Priority
+	}
+	switch {
+	case a.DueDate != nil && b.DueDate != nil && !a.DueDate.Equal(*b.DueDate):
+		return a.DueDate.Compare(*b.DueDate)
+	case a.DueDate != nil && b.DueDate == nil:
+		return -1
+	case a.DueDate == nil && b.DueDate != nil:
+		return 1
+	}
+	if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+		return c
+	}
+	return slices.Compare(a.ID[:], b.ID[:])
+}
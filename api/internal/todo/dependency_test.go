@@ -0,0 +1,133 @@
+package todo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepositoryAddDependency(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	userID, todoID, blockerID := uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT user_id, project_id FROM todos WHERE id = \\$1").WithArgs(todoID).
+		WillReturnRows(pgxmock.NewRows([]string{"user_id", "project_id"}).AddRow(userID, (*uuid.UUID)(nil)))
+	mock.ExpectQuery("SELECT user_id, project_id FROM todos WHERE id = \\$1 AND \\(\\(project_id IS NULL AND user_id = \\$2\\)").WithArgs(blockerID, userID).
+		WillReturnRows(pgxmock.NewRows([]string{"user_id", "project_id"}).AddRow(userID, (*uuid.UUID)(nil)))
+	mock.ExpectQuery("WITH RECURSIVE upstream").WithArgs(blockerID, todoID).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectExec("INSERT INTO todo_dependencies").WithArgs(todoID, blockerID).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.AddDependency(context.Background(), todoID, blockerID, userID))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryAddDependencyRejectsCycle(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	userID, todoID, blockerID := uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT user_id, project_id FROM todos").WithArgs(todoID).
+		WillReturnRows(pgxmock.NewRows([]string{"user_id", "project_id"}).AddRow(userID, (*uuid.UUID)(nil)))
+	mock.ExpectQuery("SELECT user_id, project_id FROM todos").WithArgs(blockerID, userID).
+		WillReturnRows(pgxmock.NewRows([]string{"user_id", "project_id"}).AddRow(userID, (*uuid.UUID)(nil)))
+	mock.ExpectQuery("WITH RECURSIVE upstream").WithArgs(blockerID, todoID).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	require.ErrorIs(t, repo.AddDependency(context.Background(), todoID, blockerID, userID), ErrDependencyCycle)
+	require.ErrorIs(t, repo.AddDependency(context.Background(), todoID, todoID, userID), ErrDependencyCycle)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryAddDependencyRejectsForeignBlocker(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	userID, todoID, blockerID := uuid.New(), uuid.New(), uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT user_id, project_id FROM todos").WithArgs(todoID).
+		WillReturnRows(pgxmock.NewRows([]string{"user_id", "project_id"}).AddRow(userID, (*uuid.UUID)(nil)))
+	mock.ExpectQuery("SELECT user_id, project_id FROM todos").WithArgs(blockerID, userID).
+		WillReturnError(pgx.ErrNoRows)
+	mock.ExpectRollback()
+
+	require.ErrorIs(t, repo.AddDependency(context.Background(), todoID, blockerID, userID), ErrUnknownBlocker)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryWithBlockers(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	a, b, done, open := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	todos := []Todo{{ID: a}, {ID: b}}
+
+	mock.ExpectQuery("SELECT d.todo_id, d.blocker_id, b.completed FROM todo_dependencies d").
+		WithArgs([]uuid.UUID{a, b}).
+		WillReturnRows(pgxmock.NewRows([]string{"todo_id", "blocker_id", "completed"}).
+			AddRow(a, done, true).
+			AddRow(b, done, true).
+			AddRow(b, open, false))
+
+	require.NoError(t, repo.WithBlockers(context.Background(), todos))
+	require.Equal(t, []uuid.UUID{done}, todos[0].BlockedBy)
+	require.False(t, todos[0].Blocked)
+	require.Equal(t, []uuid.UUID{done, open}, todos[1].BlockedBy)
+	require.True(t, todos[1].Blocked)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNewPlan(t *testing.T) {
+	now := time.Now()
+	due := now.Add(24 * time.Hour)
+	design := Todo{ID: uuid.New(), Title: "Design", CreatedAt: now}
+	specs := Todo{ID: uuid.New(), Title: "Specs", Priority: PriorityHigh, CreatedAt: now}
+	build := Todo{ID: uuid.New(), Title: "Build", BlockedBy: []uuid.UUID{design.ID, specs.ID}, CreatedAt: now}
+	docs := Todo{ID: uuid.New(), Title: "Docs", BlockedBy: []uuid.UUID{design.ID}, DueDate: &due, CreatedAt: now}
+	ship := Todo{ID: uuid.New(), Title: "Ship", BlockedBy: []uuid.UUID{build.ID, docs.ID, uuid.New()}, CreatedAt: now}
+	done := Todo{ID: uuid.New(), Title: "Kickoff", Completed: true, CreatedAt: now}
+	design.BlockedBy = []uuid.UUID{done.ID}
+
+	plan, err := NewPlan([]Todo{ship, build, docs, design, specs, done})
+	require.NoError(t, err)
+
+	var titles []string
+	var levels []int
+	for _, step := range plan.Steps {
+		titles = append(titles, step.Todo.Title)
+		levels = append(levels, step.Level)
+	}
+	require.Equal(t, []string{"Specs", "Design", "Docs", "Build", "Ship"}, titles)
+	require.Equal(t, []int{0, 0, 1, 1, 2}, levels)
+}
+
+func TestNewPlanDetectsCycle(t *testing.T) {
+	a := Todo{ID: uuid.New()}
+	b := Todo{ID: uuid.New(), BlockedBy: []uuid.UUID{a.ID}}
+	a.BlockedBy = []uuid.UUID{b.ID}
+
+	_, err := NewPlan([]Todo{a, b})
+	require.ErrorIs(t, err, ErrDependencyCycle)
+}
@@ -0,0 +1,48 @@
+package todo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// WithEffort fills in TrackedMinutes of the todos from their time entries. Running timers
+// count up to now.
+func (r *Repository) WithEffort(ctx context.Context, todos []Todo) error {
+	if len(todos) == 0 {
+		return nil
+	}
+
+	index := make(map[uuid.UUID]int, len(todos))
+	ids := make([]uuid.UUID, len(todos))
+	for i, t := range todos {
+		index[t.ID] = i
+		ids[i] = t.ID
+		todos[i].TrackedMinutes = 0
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT todo_id, sum(extract(epoch FROM COALESCE(ended_at, now()) - started_at))::INT8
+		FROM time_entries
+		WHERE todo_id = ANY($1)
+		GROUP BY todo_id`, ids)
+	if err != nil {
+		return fmt.Errorf("query tracked time: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var todoID uuid.UUID
+		var seconds int64
+		if err := rows.Scan(&todoID, &seconds); err != nil {
+			return fmt.Errorf("scan tracked time: %w", err)
+		}
+		todos[index[todoID]].TrackedMinutes = int(seconds / 60)
+	}
+
+	if rows.Err() != nil {
+		return fmt.Errorf("iterate tracked time: %w", rows.Err())
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package todo
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithEffort(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	estimate := 60
+	todos := []Todo{{ID: uuid.New(), EstimateMinutes: &estimate}, {ID: uuid.New(), TrackedMinutes: 5}}
+	mock.ExpectQuery("SELECT todo_id, sum\\(.*\\)::INT8 FROM time_entries WHERE todo_id = ANY\\(\\$1\\) GROUP BY todo_id").
+		WithArgs([]uuid.UUID{todos[0].ID, todos[1].ID}).
+		WillReturnRows(pgxmock.NewRows([]string{"todo_id", "seconds"}).AddRow(todos[0].ID, int64(4530)))
+
+	require.NoError(t, NewRepository(mock).WithEffort(context.Background(), todos))
+	require.Equal(t, 75, todos[0].TrackedMinutes)
+	require.Equal(t, 0, todos[1].TrackedMinutes)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
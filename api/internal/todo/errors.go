@@ -2,5 +2,36 @@ package todo
 
 import "errors"
 
-// ErrNotFound indicates the requested todo could not be located.
-var ErrNotFound = errors.New("todo not found")
+var (
+	// ErrNotFound indicates the requested todo could not be located.
+	ErrNotFound = errors.New("todo not found")
+	// ErrInvalidImport indicates an uploaded import file could not be read.
+	ErrInvalidImport = errors.New("invalid import")
+	// ErrUnknownStatus indicates a status that is not part of the todo's workflow.
+	ErrUnknownStatus = errors.New("unknown status")
+	// ErrInvalidTransition indicates the workflow does not allow the requested status change.
+	ErrInvalidTransition = errors.New("invalid status transition")
+	// ErrUnknownUser indicates a todo for a user that does not exist.
+	ErrUnknownUser = errors.New("user not found")
+	// ErrUnknownProject indicates the todo refers to a project that does not exist.
+	ErrUnknownProject = errors.New("project not found")
+	// ErrUnknownParent indicates a subtask refers to a parent todo that does not exist or belongs to another user.
+	ErrUnknownParent = errors.New("parent todo not found")
+	// ErrEmptySearch indicates a search query without any word to look for.
+	ErrEmptySearch = errors.New("search query must contain a word")
+	// ErrUnknownBlocker indicates a dependency on a todo that does not exist, that the user cannot see or that is unrelated to the dependent todo.
+	ErrUnknownBlocker = errors.New("blocking todo not found")
+	// ErrDependencyCycle indicates a dependency that would make a todo wait for itself.
+	ErrDependencyCycle = errors.New("dependency would create a cycle")
+	// ErrNoDependency indicates the todo does not depend on the given todo.
+	ErrNoDependency = errors.New("dependency not found")
+	// ErrBlocked indicates a todo cannot be completed while todos it depends on are open.
+	ErrBlocked = errors.New("todo is blocked by open todos")
+	// ErrUnknownAssignee indicates an assignee who cannot see the todo.
+	ErrUnknownAssignee = errors.New("assignee cannot access the todo")
+	// ErrForbidden indicates the user may see the todo or project but their role does not
+	// allow the change.
+	ErrForbidden = errors.New("your role does not allow this change")
+	// ErrConflict indicates the todo changed while an update was being applied, or no longer has the version the caller expected.
+	ErrConflict = errors.New("todo was modified concurrently")
+)
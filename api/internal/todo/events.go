@@ -0,0 +1,104 @@
+package todo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"overengineeredtodo/internal/notification"
+	"overengineeredtodo/internal/outbox"
+)
+
+// write runs query, an insert or update returning todoColumns, in a transaction that also
+// records an event of eventType for the resulting todo. When assignedBy is set, the todo's
+// assignee is notified that that user assigned it to them. Scan errors are returned
+// unwrapped so that callers can tell a missing row.
+func (r *Repository) write(ctx context.Context, eventType string, assignedBy *uuid.UUID, query string, args ...any) (Todo, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return Todo{}, fmt.Errorf("begin todo change: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	t, err := scanTodo(tx.QueryRow(ctx, query, args...))
+	if err != nil {
+		return Todo{}, err
+	}
+
+	if err := recordEvents(ctx, tx, eventType, t); err != nil {
+		return Todo{}, err
+	}
+
+	if assignedBy != nil && t.AssigneeID != nil {
+		if err := notification.Insert(ctx, tx, notification.Notification{
+			UserID:  *t.AssigneeID,
+			Kind:    notification.KindAssignment,
+			TodoID:  t.ID,
+			ActorID: *assignedBy,
+		}); err != nil {
+			return Todo{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return Todo{}, fmt.Errorf("commit todo change: %w", err)
+	}
+	return t, nil
+}
+
+// recordEvents writes an event of eventType for each of the todos through db.
+func recordEvents(ctx context.Context, db outbox.Execer, eventType string, todos ...Todo) error {
+	events, err := newEvents(eventType, todos...)
+	if err != nil {
+		return err
+	}
+	return outbox.Insert(ctx, db, events...)
+}
+
+func newEvents(eventType string, todos ...Todo) ([]outbox.Event, error) {
+	events := make([]outbox.Event, len(todos))
+	for i, t := range todos {
+		e, err := outbox.NewEvent(eventType, outbox.AggregateTodo, t.ID, int64(t.Version), t)
+		if err != nil {
+			return nil, err
+		}
+		events[i] = e
+	}
+	return events, nil
+}
+
+// ReturningColumns lists the todo columns UpdatedEvents reads, qualified with the table so
+// that statements of other packages joining further tables can return them.
+var ReturningColumns = "todos." + strings.ReplaceAll(todoColumns, ", ", ", todos.")
+
+// UpdatedEvents builds a TodoUpdated event for each todo rows returns in ReturningColumns
+// order. Other packages that change todos in bulk record the events with it. It closes
+// rows.
+func UpdatedEvents(rows pgx.Rows) ([]outbox.Event, error) {
+	todos, err := collectTodos(rows)
+	if err != nil {
+		return nil, err
+	}
+	return newEvents(outbox.TodoUpdated, todos...)
+}
+
+// collectTodos reads the todos rows returns in todoColumns order and closes rows.
+func collectTodos(rows pgx.Rows) ([]Todo, error) {
+	defer rows.Close()
+
+	var todos []Todo
+	for rows.Next() {
+		t, err := scanTodo(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan todo: %w", err)
+		}
+		todos = append(todos, t)
+	}
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate todos: %w", rows.Err())
+	}
+	return todos, nil
+}
@@ -0,0 +1,83 @@
+package todo
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+
+	"overengineeredtodo/internal/outbox"
+)
+
+// expectEvent expects an outbox event of eventType for the todo at version.
+func expectEvent(mock pgxmock.PgxPoolIface, eventType string, id uuid.UUID, version int64) {
+	mock.ExpectExec("INSERT INTO outbox_events").
+		WithArgs(pgxmock.AnyArg(), eventType, outbox.SchemaVersion, outbox.AggregateTodo, id, version, pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+}
+
+func TestRecordEventsCarriesTodo(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	todo := Todo{ID: uuid.New(), UserID: uuid.New(), Title: "Title", Version: 3, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	var payload json.RawMessage
+
+	mock.ExpectExec("INSERT INTO outbox_events").
+		WithArgs(pgxmock.AnyArg(), outbox.TodoCompleted, outbox.SchemaVersion, outbox.AggregateTodo, todo.ID, int64(3), pgxmock.AnyArg(), capture(&payload)).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	require.NoError(t, recordEvents(context.Background(), mock, outbox.TodoCompleted, todo))
+
+	var decoded Todo
+	require.NoError(t, json.Unmarshal(payload, &decoded))
+	require.Equal(t, todo.ID, decoded.ID)
+	require.Equal(t, "Title", decoded.Title)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryDeleteRecordsNextVersion(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	id := uuid.New()
+	userID := uuid.New()
+	var payload json.RawMessage
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("WITH deleted AS").
+		WithArgs(id).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "project_id", "version"}).AddRow(id, userID, (*uuid.UUID)(nil), int64(7)))
+	mock.ExpectExec("INSERT INTO outbox_events").
+		WithArgs(pgxmock.AnyArg(), outbox.TodoDeleted, outbox.SchemaVersion, outbox.AggregateTodo, id, int64(8), pgxmock.AnyArg(), capture(&payload)).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, repo.Delete(context.Background(), id))
+	require.JSONEq(t, `{"id":"`+id.String()+`","user_id":"`+userID.String()+`"}`, string(payload))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+// captured is an argument matcher that keeps the JSON it is given.
+type captured struct {
+	dest *json.RawMessage
+}
+
+func capture(dest *json.RawMessage) captured {
+	return captured{dest: dest}
+}
+
+func (c captured) Match(v any) bool {
+	raw, ok := v.(json.RawMessage)
+	if ok {
+		*c.dest = raw
+	}
+	return ok
+}
@@ -0,0 +1,100 @@
+package todo
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"overengineeredtodo/internal/customfield"
+	"overengineeredtodo/internal/query"
+)
+
+// ListOptions narrows and orders the todos returned by List.
+type ListOptions struct {
+	ProjectID *uuid.UUID
+	// AssigneeID keeps the todos assigned to that user.
+	AssigneeID *uuid.UUID
+	// IncludeHidden also lists todos that are deferred or snoozed.
+	IncludeHidden bool
+	// Fields keeps todos whose custom field values contain these values; for multi-select
+	// fields every listed option must be selected.
+	Fields customfield.Values
+	// SortField orders by a custom field, todos without a value last, instead of newest first.
+	SortField  *customfield.Field
+	Descending bool
+	// Query keeps todos matching a parsed query; Location decides where its days start.
+	Query    query.Node
+	Location *time.Location
+}
+
+// Fields returns the custom fields declared by the project.
+func (r *Repository) Fields(ctx context.Context, projectID uuid.UUID) (customfield.Schema, error) {
+	s, err := r.settings(ctx, &projectID)
+	return s.fields, err
+}
+
+// List returns the todos the user can see that match opts.
+func (r *Repository) List(ctx context.Context, userID uuid.UUID, opts ListOptions) ([]Todo, error) {
+	conditions := []string{VisibleTo("$1")}
+	args := []any{userID}
+
+	if opts.ProjectID != nil {
+		args = append(args, *opts.ProjectID)
+		conditions = append(conditions, fmt.Sprintf("project_id = $%d", len(args)))
+	}
+	if opts.AssigneeID != nil {
+		args = append(args, *opts.AssigneeID)
+		conditions = append(conditions, fmt.Sprintf("assignee_id = $%d", len(args)))
+	}
+	if !opts.IncludeHidden {
+		conditions = append(conditions, notHidden)
+	}
+	if len(opts.Fields) > 0 {
+		args = append(args, opts.Fields)
+		conditions = append(conditions, fmt.Sprintf("custom_fields @> $%d", len(args)))
+	}
+
+	if opts.Query != nil {
+		condition, queryArgs, err := query.SQL(opts.Query, query.Options{Location: opts.Location, FirstArg: len(args) + 1})
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, condition)
+		args = append(args, queryArgs...)
+	}
+
+	orderBy := "created_at DESC"
+	if opts.SortField != nil {
+		args = append(args, opts.SortField.Key)
+		value := fmt.Sprintf("custom_fields->>$%d", len(args))
+		if opts.SortField.Type == customfield.TypeNumber {
+			value = "(" + value + ")::DECIMAL"
+		}
+		direction := "ASC"
+		if opts.Descending {
+			direction = "DESC"
+		}
+		orderBy = value + " " + direction + " NULLS LAST, created_at DESC"
+	}
+
+	query := `
+		SELECT ` + todoColumns + `
+		FROM todos
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY ` + orderBy
+
+	return r.list(ctx, query, args...)
+}
+
+// withCustomFields checks the custom field values of a new todo against the project's fields.
+func withCustomFields(fields customfield.Schema, input CreateInput) (CreateInput, error) {
+	values, err := fields.Check(input.CustomFields)
+	if err != nil {
+		return input, err
+	}
+	input.CustomFields = customfield.Merge(nil, values)
+	return input, nil
+}
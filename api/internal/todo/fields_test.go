@@ -0,0 +1,131 @@
+package todo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+
+	"overengineeredtodo/internal/customfield"
+	"overengineeredtodo/internal/outbox"
+	"overengineeredtodo/internal/query"
+	"overengineeredtodo/internal/workflow"
+)
+
+var testFields = customfield.Schema{
+	{Key: "points", Name: "Story points", Type: customfield.TypeNumber},
+	{Key: "customer", Name: "Customer", Type: customfield.TypeText},
+}
+
+func settingsRows(fields customfield.Schema) *pgxmock.Rows {
+	return pgxmock.NewRows([]string{"workflow", "custom_fields"}).AddRow((*workflow.Workflow)(nil), fields)
+}
+
+func TestRepositoryCreateRejectsUnknownCustomField(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	projectID := uuid.New()
+
+	mock.ExpectQuery("SELECT workflow, custom_fields FROM projects").
+		WithArgs(projectID).
+		WillReturnRows(settingsRows(testFields))
+
+	_, err = repo.Create(context.Background(), CreateInput{
+		UserID:       uuid.New(),
+		Title:        "Title",
+		ProjectID:    &projectID,
+		CustomFields: customfield.Values{"estimate": "2d"},
+	})
+	require.ErrorIs(t, err, customfield.ErrInvalidValue)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryUpdateMergesCustomFields(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	id := uuid.New()
+	projectID := uuid.New()
+	now := time.Now()
+	current := Todo{
+		ID: id, UserID: uuid.New(), Title: "Title", ProjectID: &projectID, Status: workflow.StatusBacklog, Version: 3,
+		CustomFields: customfield.Values{"points": 3.0, "customer": "ACME"}, CreatedAt: now, UpdatedAt: now,
+	}
+	merged := customfield.Values{"points": 5.0}
+	updated := current
+	updated.CustomFields = merged
+
+	mock.ExpectQuery("SELECT .* FROM todos WHERE id = \\$1").
+		WithArgs(id).
+		WillReturnRows(newTodoRows(current))
+	mock.ExpectQuery("SELECT workflow, custom_fields FROM projects").
+		WithArgs(projectID).
+		WillReturnRows(settingsRows(testFields))
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE todos SET custom_fields = \\$1, .* WHERE id = \\$2 AND version = \\$3").
+		WithArgs(merged, id, 3).
+		WillReturnRows(newTodoRows(updated))
+	expectEvent(mock, outbox.TodoUpdated, id, 3)
+	mock.ExpectCommit()
+
+	result, err := repo.Update(context.Background(), id, UpdateInput{
+		CustomFields: customfield.Values{"points": 5.0, "customer": nil},
+	})
+	require.NoError(t, err)
+	require.Equal(t, merged, result.CustomFields)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryListFiltersAndSortsByCustomField(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	userID := uuid.New()
+	projectID := uuid.New()
+	filter := customfield.Values{"customer": "ACME"}
+
+	mock.ExpectQuery("WHERE \\(\\(project_id IS NULL AND user_id = \\$1\\) OR project_id IN \\(SELECT project_id FROM project_members WHERE user_id = \\$1\\)\\) AND project_id = \\$2 AND "+notHiddenPattern+" AND custom_fields @> \\$3 "+
+		"ORDER BY \\(custom_fields->>\\$4\\)::DECIMAL DESC NULLS LAST, created_at DESC").
+		WithArgs(userID, projectID, filter, "points").
+		WillReturnRows(newTodoRows(Todo{ID: uuid.New(), UserID: userID, Title: "Title"}))
+
+	todos, err := repo.List(context.Background(), userID, ListOptions{
+		ProjectID:  &projectID,
+		Fields:     filter,
+		SortField:  &testFields[0],
+		Descending: true,
+	})
+	require.NoError(t, err)
+	require.Len(t, todos, 1)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryListWithQuery(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	userID := uuid.New()
+	node, err := query.Parse("tag:work priority:>=2")
+	require.NoError(t, err)
+
+	mock.ExpectQuery("WHERE \\(\\(project_id IS NULL AND user_id = \\$1\\) OR project_id IN \\(SELECT project_id FROM project_members WHERE user_id = \\$1\\)\\) AND "+notHiddenPattern+" AND \\(EXISTS \\(SELECT 1 FROM unnest\\(labels\\) AS l WHERE lower\\(l\\) = lower\\(\\$2\\)\\) AND priority >= \\$3\\) ORDER BY created_at DESC").
+		WithArgs(userID, "work", 2).
+		WillReturnRows(newTodoRows(Todo{ID: uuid.New(), UserID: userID, Title: "Title"}))
+
+	todos, err := repo.List(context.Background(), userID, ListOptions{Query: node})
+	require.NoError(t, err)
+	require.Len(t, todos, 1)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,457 @@
+package todo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"overengineeredtodo/internal/access"
+	"overengineeredtodo/internal/customfield"
+	"overengineeredtodo/internal/query"
+	"overengineeredtodo/internal/quickadd"
+	todoappv1 "overengineeredtodo/proto/todoapp/v1"
+)
+
+// RegisterGRPC serves the todos as todoappv1.TodoService.
+func RegisterGRPC(server grpc.ServiceRegistrar, repo *Repository, users Users, logger *slog.Logger) {
+	todoappv1.RegisterTodoServiceServer(server, &GRPCServer{repo: repo, users: users, logger: logger})
+}
+
+// GRPCServer exposes the todo resource over gRPC, mirroring Handler.
+type GRPCServer struct {
+	todoappv1.UnimplementedTodoServiceServer
+	repo   *Repository
+	users  Users
+	logger *slog.Logger
+}
+
+// CreateTodo creates a todo, from a quick_add description when one is given.
+func (s *GRPCServer) CreateTodo(ctx context.Context, req *todoappv1.CreateTodoRequest) (*todoappv1.Todo, error) {
+	userID, err := parseID("user_id", req.GetUserId())
+	if err != nil {
+		return nil, err
+	}
+	if req.GetPriority() < PriorityNone || req.GetPriority() > PriorityHigh {
+		return nil, status.Error(codes.InvalidArgument, "priority must be between 0 and 3")
+	}
+	if req.EstimateMinutes != nil && req.GetEstimateMinutes() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "estimate_minutes must not be negative")
+	}
+
+	input := CreateInput{
+		UserID:      userID,
+		Title:       req.GetTitle(),
+		Description: req.GetDescription(),
+		DueDate:     optionalTime(req.GetDueDate()),
+		StartDate:   optionalTime(req.GetStartDate()),
+		Completed:   req.GetCompleted(),
+		Status:      req.GetStatus(),
+		Labels:      req.GetLabels(),
+		Priority:    int(req.GetPriority()),
+		Recurrence:  req.GetRecurrence(),
+		QuickAdd:    req.GetQuickAdd(),
+	}
+	if req.EstimateMinutes != nil {
+		input.EstimateMinutes = ptrTo(int(req.GetEstimateMinutes()))
+	}
+	if req.GetCustomFields() != nil {
+		input.CustomFields = req.GetCustomFields().AsMap()
+	}
+	if input.ProjectID, err = parseOptionalID("project_id", req.ProjectId); err != nil {
+		return nil, err
+	}
+	if input.ParentID, err = parseOptionalID("parent_id", req.ParentId); err != nil {
+		return nil, err
+	}
+	if input.AssigneeID, err = parseOptionalID("assignee_id", req.AssigneeId); err != nil {
+		return nil, err
+	}
+
+	if err := checkUser(ctx, s.users, s.logger, userID); err != nil {
+		return nil, grpcError(err)
+	}
+
+	if input.QuickAdd != "" {
+		opts := quickadd.Options{Location: time.UTC, Locale: quickadd.MatchLocale(req.GetLocale())}
+		if req.GetTz() != "" {
+			if opts.Location, err = time.LoadLocation(req.GetTz()); err != nil {
+				return nil, status.Error(codes.InvalidArgument, "invalid tz")
+			}
+		}
+		if input, _, err = s.repo.ApplyQuickAdd(ctx, input, opts); err != nil {
+			return nil, grpcError(err)
+		}
+	}
+
+	if strings.TrimSpace(input.Title) == "" {
+		return nil, status.Error(codes.InvalidArgument, "title is required")
+	}
+
+	t, err := s.repo.Create(ctx, input)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return s.todo(ctx, t)
+}
+
+// GetTodo fetches a todo.
+func (s *GRPCServer) GetTodo(ctx context.Context, req *todoappv1.GetTodoRequest) (*todoappv1.Todo, error) {
+	t, _, err := s.authorize(ctx, req.GetId(), req.GetUserId(), access.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+	return s.todo(ctx, t)
+}
+
+// ListTodos streams the todos the user can see that match the request.
+func (s *GRPCServer) ListTodos(req *todoappv1.ListTodosRequest, stream grpc.ServerStreamingServer[todoappv1.Todo]) error {
+	userID, err := parseID("user_id", req.GetUserId())
+	if err != nil {
+		return err
+	}
+
+	opts := ListOptions{IncludeHidden: req.GetIncludeHidden()}
+	if opts.ProjectID, err = parseOptionalID("project_id", req.ProjectId); err != nil {
+		return err
+	}
+	switch raw := req.GetAssignee(); raw {
+	case "":
+	case "me":
+		opts.AssigneeID = &userID
+	default:
+		assigneeID, err := uuid.Parse(raw)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, "assignee must be me or a user id")
+		}
+		opts.AssigneeID = &assigneeID
+	}
+	if req.GetQ() != "" {
+		if opts.Query, err = query.Parse(req.GetQ()); err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	if req.GetTz() != "" {
+		if opts.Location, err = time.LoadLocation(req.GetTz()); err != nil {
+			return status.Error(codes.InvalidArgument, "invalid tz")
+		}
+	}
+
+	todos, err := s.repo.List(stream.Context(), userID, opts)
+	if err == nil {
+		err = s.repo.WithBlockers(stream.Context(), todos)
+	}
+	if err == nil {
+		err = s.repo.WithEffort(stream.Context(), todos)
+	}
+	if err != nil {
+		return grpcError(err)
+	}
+
+	for _, t := range todos {
+		msg, err := toProto(t)
+		if err != nil {
+			return grpcError(err)
+		}
+		if err := stream.Send(msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateTodo changes the fields set in the request.
+func (s *GRPCServer) UpdateTodo(ctx context.Context, req *todoappv1.UpdateTodoRequest) (*todoappv1.Todo, error) {
+	current, userID, err := s.authorize(ctx, req.GetId(), req.GetUserId(), access.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	input, err := updateInput(req)
+	if err != nil {
+		return nil, err
+	}
+	if input.ProjectID != nil {
+		if err := s.repo.CheckProjectWrite(ctx, *input.ProjectID, userID); err != nil {
+			return nil, grpcError(err)
+		}
+	}
+	input.ActorID = userID
+
+	t, err := s.repo.Update(ctx, current.ID, input)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return s.todo(ctx, t)
+}
+
+// CompleteTodo marks a todo as complete.
+func (s *GRPCServer) CompleteTodo(ctx context.Context, req *todoappv1.CompleteTodoRequest) (*todoappv1.Todo, error) {
+	current, _, err := s.authorize(ctx, req.GetId(), req.GetUserId(), access.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	if !req.GetForce() {
+		todos := []Todo{current}
+		if err := s.repo.WithBlockers(ctx, todos); err != nil {
+			return nil, grpcError(err)
+		}
+		if todos[0].Blocked {
+			return nil, status.Errorf(codes.FailedPrecondition, "%s: %s", ErrBlocked, joinIDs(todos[0].BlockedBy))
+		}
+	}
+
+	t, err := s.repo.Update(ctx, current.ID, UpdateInput{Completed: ptrTo(true)})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return s.todo(ctx, t)
+}
+
+// DeleteTodo deletes a todo.
+func (s *GRPCServer) DeleteTodo(ctx context.Context, req *todoappv1.DeleteTodoRequest) (*todoappv1.DeleteTodoResponse, error) {
+	t, _, err := s.authorize(ctx, req.GetId(), req.GetUserId(), access.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Delete(ctx, t.ID); err != nil {
+		return nil, grpcError(err)
+	}
+	return &todoappv1.DeleteTodoResponse{}, nil
+}
+
+// authorize loads the todo on behalf of the user, who must hold at least the required role.
+func (s *GRPCServer) authorize(ctx context.Context, rawID, rawUserID string, required access.Role) (Todo, uuid.UUID, error) {
+	id, err := parseID("id", rawID)
+	if err != nil {
+		return Todo{}, uuid.Nil, err
+	}
+	userID, err := parseID("user_id", rawUserID)
+	if err != nil {
+		return Todo{}, uuid.Nil, err
+	}
+
+	t, err := s.repo.Authorize(ctx, id, userID, required)
+	if err != nil {
+		return Todo{}, uuid.Nil, grpcError(err)
+	}
+	return t, userID, nil
+}
+
+// todo converts the todo together with its blockers and tracked time.
+func (s *GRPCServer) todo(ctx context.Context, t Todo) (*todoappv1.Todo, error) {
+	todos := []Todo{t}
+	err := s.repo.WithBlockers(ctx, todos)
+	if err == nil {
+		err = s.repo.WithEffort(ctx, todos)
+	}
+	if err != nil {
+		return nil, grpcError(err)
+	}
+
+	msg, err := toProto(todos[0])
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return msg, nil
+}
+
+// updateInput converts the request, applying the checks the REST binding applies.
+func updateInput(req *todoappv1.UpdateTodoRequest) (UpdateInput, error) {
+	if req.GetClearDueDate() && req.GetDueDate() != nil {
+		return UpdateInput{}, status.Error(codes.InvalidArgument, "due_date and clear_due_date are mutually exclusive")
+	}
+	if req.GetClearProject() && req.ProjectId != nil {
+		return UpdateInput{}, status.Error(codes.InvalidArgument, "project_id and clear_project are mutually exclusive")
+	}
+	if req.GetClearAssignee() && req.AssigneeId != nil {
+		return UpdateInput{}, status.Error(codes.InvalidArgument, "assignee_id and clear_assignee are mutually exclusive")
+	}
+	if req.GetClearEstimate() && req.EstimateMinutes != nil {
+		return UpdateInput{}, status.Error(codes.InvalidArgument, "estimate_minutes and clear_estimate are mutually exclusive")
+	}
+	if req.GetClearStartDate() && req.GetStartDate() != nil {
+		return UpdateInput{}, status.Error(codes.InvalidArgument, "start_date and clear_start_date are mutually exclusive")
+	}
+	if req.Priority != nil && (req.GetPriority() < PriorityNone || req.GetPriority() > PriorityHigh) {
+		return UpdateInput{}, status.Error(codes.InvalidArgument, "priority must be between 0 and 3")
+	}
+	if req.EstimateMinutes != nil && req.GetEstimateMinutes() < 0 {
+		return UpdateInput{}, status.Error(codes.InvalidArgument, "estimate_minutes must not be negative")
+	}
+
+	input := UpdateInput{
+		Title:          req.Title,
+		Description:    req.Description,
+		DueDate:        optionalTime(req.GetDueDate()),
+		ClearDueDate:   req.GetClearDueDate(),
+		StartDate:      optionalTime(req.GetStartDate()),
+		ClearStartDate: req.GetClearStartDate(),
+		Completed:      req.Completed,
+		Status:         req.Status,
+		ClearProject:   req.GetClearProject(),
+		Recurrence:     req.Recurrence,
+		ClearAssignee:  req.GetClearAssignee(),
+		ClearEstimate:  req.GetClearEstimate(),
+	}
+	if req.GetLabels() != nil {
+		input.Labels = ptrTo(req.GetLabels().GetValues())
+	}
+	if req.Priority != nil {
+		input.Priority = ptrTo(int(req.GetPriority()))
+	}
+	if req.EstimateMinutes != nil {
+		input.EstimateMinutes = ptrTo(int(req.GetEstimateMinutes()))
+	}
+	if req.GetCustomFields() != nil {
+		input.CustomFields = req.GetCustomFields().AsMap()
+	}
+
+	var err error
+	if input.ProjectID, err = parseOptionalID("project_id", req.ProjectId); err != nil {
+		return UpdateInput{}, err
+	}
+	if input.AssigneeID, err = parseOptionalID("assignee_id", req.AssigneeId); err != nil {
+		return UpdateInput{}, err
+	}
+	return input, nil
+}
+
+func toProto(t Todo) (*todoappv1.Todo, error) {
+	msg := &todoappv1.Todo{
+		Id:             t.ID.String(),
+		UserId:         t.UserID.String(),
+		Title:          t.Title,
+		Description:    t.Description,
+		DueDate:        optionalTimestamp(t.DueDate),
+		StartDate:      optionalTimestamp(t.StartDate),
+		SnoozedUntil:   optionalTimestamp(t.SnoozedUntil),
+		Completed:      t.Completed,
+		Status:         t.Status,
+		CreatedAt:      timestamppb.New(t.CreatedAt),
+		UpdatedAt:      timestamppb.New(t.UpdatedAt),
+		IcalUid:        t.ICalUID,
+		Version:        int64(t.Version),
+		ProjectId:      optionalString(t.ProjectID),
+		ParentId:       optionalString(t.ParentID),
+		AssigneeId:     optionalString(t.AssigneeID),
+		TrackedMinutes: int32(t.TrackedMinutes),
+		Labels:         t.Labels,
+		Priority:       int32(t.Priority),
+		CompletedAt:    optionalTimestamp(t.CompletedAt),
+		Recurrence:     t.Recurrence,
+		BlockedBy:      make([]string, len(t.BlockedBy)),
+		Blocked:        t.Blocked,
+	}
+	if t.EstimateMinutes != nil {
+		msg.EstimateMinutes = ptrTo(int32(*t.EstimateMinutes))
+	}
+	for i, id := range t.BlockedBy {
+		msg.BlockedBy[i] = id.String()
+	}
+
+	// Values take the same shape as in the REST API.
+	fields, err := customFieldsStruct(t.CustomFields)
+	if err != nil {
+		return nil, err
+	}
+	msg.CustomFields = fields
+	return msg, nil
+}
+
+func customFieldsStruct(values customfield.Values) (*structpb.Struct, error) {
+	fields := &structpb.Struct{}
+	if values == nil {
+		return fields, nil
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return nil, fmt.Errorf("encode custom fields: %w", err)
+	}
+	if err := protojson.Unmarshal(data, fields); err != nil {
+		return nil, fmt.Errorf("convert custom fields: %w", err)
+	}
+	return fields, nil
+}
+
+// grpcError maps repository errors onto gRPC status codes, following respondWriteError:
+// what REST answers with 400 or 422 is INVALID_ARGUMENT, 403 PERMISSION_DENIED, 404
+// NOT_FOUND and a concurrent change ABORTED. Completing a blocked todo, also a 409 in
+// REST, is FAILED_PRECONDITION.
+func grpcError(err error) error {
+	switch {
+	case err == ErrNotFound:
+		return status.Error(codes.NotFound, "todo not found")
+	case err == ErrForbidden:
+		return status.Error(codes.PermissionDenied, err.Error())
+	case err == ErrConflict:
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, ErrUnknownStatus), errors.Is(err, ErrInvalidTransition), err == ErrUnknownUser, err == ErrUnknownProject, err == ErrUnknownParent, err == ErrUnknownAssignee,
+		err == ErrUnknownBlocker, err == ErrDependencyCycle,
+		errors.Is(err, customfield.ErrInvalidValue), errors.Is(err, quickadd.ErrInvalidRecurrence):
+		return status.Error(codes.InvalidArgument, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func parseID(field, raw string) (uuid.UUID, error) {
+	id, err := uuid.Parse(raw)
+	if err != nil || id == uuid.Nil {
+		return uuid.Nil, status.Errorf(codes.InvalidArgument, "invalid %s", field)
+	}
+	return id, nil
+}
+
+func parseOptionalID(field string, raw *string) (*uuid.UUID, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	id, err := parseID(field, *raw)
+	if err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+func optionalTime(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	return ptrTo(ts.AsTime())
+}
+
+func optionalTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func optionalString(id *uuid.UUID) *string {
+	if id == nil {
+		return nil
+	}
+	return ptrTo(id.String())
+}
+
+func joinIDs(ids []uuid.UUID) string {
+	parts := make([]string, len(ids))
+	for i, id := range ids {
+		parts[i] = id.String()
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,204 @@
+package todo
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"overengineeredtodo/internal/customfield"
+	"overengineeredtodo/internal/outbox"
+	"overengineeredtodo/pkg/grpcserver"
+	todoappv1 "overengineeredtodo/proto/todoapp/v1"
+)
+
+// newGRPCClient serves the todos from mock over an in-memory connection.
+func newGRPCClient(t *testing.T, mock pgxmock.PgxPoolIface, users Users) todoappv1.TodoServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	server := grpcserver.New()
+	RegisterGRPC(server, NewRepository(mock), users, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = server.Serve(ctx, lis, time.Second)
+	}()
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+		cancel()
+		<-done
+	})
+	return todoappv1.NewTodoServiceClient(conn)
+}
+
+// expectDetails expects the queries that fill in blockers and tracked time, returning the
+// blocker for the first todo when it is not nil.
+func expectDetails(mock pgxmock.PgxPoolIface, todoID uuid.UUID, blocker *uuid.UUID) {
+	dependencies := pgxmock.NewRows([]string{"todo_id", "blocker_id", "completed"})
+	if blocker != nil {
+		dependencies.AddRow(todoID, *blocker, false)
+	}
+	mock.ExpectQuery("FROM todo_dependencies").WithArgs(pgxmock.AnyArg()).WillReturnRows(dependencies)
+	mock.ExpectQuery("FROM time_entries").
+		WithArgs(pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"todo_id", "seconds"}).AddRow(todoID, int64(1800)))
+}
+
+func TestGRPCCreateTodo(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	userID, id, blocker := uuid.New(), uuid.New(), uuid.New()
+	now := time.Now().UTC()
+	created := Todo{ID: id, UserID: userID, Title: "Title", Priority: PriorityHigh, Labels: []string{"home"}, CustomFields: customfield.Values{"size": "L"}, CreatedAt: now, UpdatedAt: now}
+
+	args := make([]any, 20)
+	for i := range args {
+		args[i] = pgxmock.AnyArg()
+	}
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO todos").WithArgs(args...).WillReturnRows(newTodoRows(created))
+	expectEvent(mock, outbox.TodoCreated, id, 0)
+	mock.ExpectCommit()
+	expectDetails(mock, id, &blocker)
+
+	client := newGRPCClient(t, mock, &fakeUsers{})
+	todo, err := client.CreateTodo(context.Background(), &todoappv1.CreateTodoRequest{UserId: userID.String(), Title: "Title", Priority: PriorityHigh, Labels: []string{"home"}})
+	require.NoError(t, err)
+	require.Equal(t, id.String(), todo.GetId())
+	require.Equal(t, int32(PriorityHigh), todo.GetPriority())
+	require.Equal(t, []string{"home"}, todo.GetLabels())
+	require.Equal(t, "L", todo.GetCustomFields().AsMap()["size"])
+	require.Equal(t, []string{blocker.String()}, todo.GetBlockedBy())
+	require.True(t, todo.GetBlocked())
+	require.Equal(t, int32(30), todo.GetTrackedMinutes())
+	require.Nil(t, todo.DueDate)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGRPCCreateTodoRejectsInvalidInput(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	ghost := uuid.New()
+	client := newGRPCClient(t, mock, &fakeUsers{missing: map[uuid.UUID]bool{ghost: true}})
+	for _, req := range []*todoappv1.CreateTodoRequest{
+		{UserId: "nope", Title: "Title"},
+		{UserId: uuid.NewString()},
+		{UserId: uuid.NewString(), Title: "Title", Priority: 4},
+		{UserId: ghost.String(), Title: "Title"},
+	} {
+		_, err := client.CreateTodo(context.Background(), req)
+		require.Equal(t, codes.InvalidArgument, status.Code(err), req.String())
+	}
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGRPCGetTodoNotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	id, userID := uuid.New(), uuid.New()
+	mock.ExpectQuery("SELECT .* FROM todos WHERE id = \\$1").WithArgs(id, userID).WillReturnError(pgx.ErrNoRows)
+
+	client := newGRPCClient(t, mock, &fakeUsers{})
+	_, err = client.GetTodo(context.Background(), &todoappv1.GetTodoRequest{Id: id.String(), UserId: userID.String()})
+	require.Equal(t, codes.NotFound, status.Code(err))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGRPCCompleteBlockedTodo(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	userID, blocker := uuid.New(), uuid.New()
+	todo := Todo{ID: uuid.New(), UserID: userID, Title: "Title"}
+	mock.ExpectQuery("SELECT .* FROM todos WHERE id = \\$1").WithArgs(todo.ID, userID).WillReturnRows(authorizeRows(todo, nil))
+	mock.ExpectQuery("FROM todo_dependencies").
+		WithArgs(pgxmock.AnyArg()).
+		WillReturnRows(pgxmock.NewRows([]string{"todo_id", "blocker_id", "completed"}).AddRow(todo.ID, blocker, false))
+
+	client := newGRPCClient(t, mock, &fakeUsers{})
+	_, err = client.CompleteTodo(context.Background(), &todoappv1.CompleteTodoRequest{Id: todo.ID.String(), UserId: userID.String()})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	require.Contains(t, status.Convert(err).Message(), blocker.String())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGRPCUpdateTodoRejectsConflictingFields(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	todo := Todo{ID: uuid.New(), UserID: userID, Title: "Title"}
+	projectID, assigneeID, estimate := uuid.NewString(), uuid.NewString(), int32(30)
+	requests := []*todoappv1.UpdateTodoRequest{
+		{ProjectId: &projectID, ClearProject: true},
+		{AssigneeId: &assigneeID, ClearAssignee: true},
+		{EstimateMinutes: &estimate, ClearEstimate: true},
+		{StartDate: timestamppb.Now(), ClearStartDate: true},
+	}
+
+	client := newGRPCClient(t, mock, &fakeUsers{})
+	for _, req := range requests {
+		mock.ExpectQuery("SELECT .* FROM todos WHERE id = \\$1").WithArgs(todo.ID, userID).WillReturnRows(authorizeRows(todo, nil))
+		req.Id, req.UserId = todo.ID.String(), userID.String()
+		_, err = client.UpdateTodo(context.Background(), req)
+		require.Equal(t, codes.InvalidArgument, status.Code(err), req.String())
+	}
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGRPCListTodosStreams(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	now := time.Now()
+	first := Todo{ID: uuid.New(), UserID: userID, Title: "A", CreatedAt: now, UpdatedAt: now}
+	second := Todo{ID: uuid.New(), UserID: userID, Title: "B", CreatedAt: now.Add(-time.Hour), UpdatedAt: now}
+	mock.ExpectQuery("FROM todos").WithArgs(userID).WillReturnRows(newTodoRows(first, second))
+	expectDetails(mock, first.ID, nil)
+
+	client := newGRPCClient(t, mock, &fakeUsers{})
+	stream, err := client.ListTodos(context.Background(), &todoappv1.ListTodosRequest{UserId: userID.String()})
+	require.NoError(t, err)
+
+	var titles []string
+	for {
+		todo, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		titles = append(titles, todo.GetTitle())
+	}
+	require.Equal(t, []string{"A", "B"}, titles)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
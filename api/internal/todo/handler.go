@@ -1,15 +1,37 @@
 package todo
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+
+	"overengineeredtodo/internal/access"
+	"overengineeredtodo/internal/customfield"
+	"overengineeredtodo/internal/ical"
+	"overengineeredtodo/internal/query"
+	"overengineeredtodo/internal/quickadd"
 )
 
+const maxBulkImportBytes = 32 << 20
+
+var exportContentTypes = map[string]string{
+	FormatCSV:    "text/csv; charset=utf-8",
+	FormatJSON:   "application/json; charset=utf-8",
+	FormatNDJSON: "application/x-ndjson",
+}
+
 // RegisterRoutes wires the todo HTTP handlers to a sub-router.
-func RegisterRoutes(router *gin.RouterGroup, repo *Repository) {
-	handler := &Handler{repo: repo}
+func RegisterRoutes(router *gin.RouterGroup, repo *Repository, users Users, logger *slog.Logger) {
+	handler := &Handler{repo: repo, users: users, logger: logger}
 
 	router.POST("", handler.createTodo)
 	router.GET("/:id", handler.getTodo)
@@ -17,11 +39,24 @@ func RegisterRoutes(router *gin.RouterGroup, repo *Repository) {
 	router.PUT("/:id", handler.updateTodo)
 	router.DELETE("/:id", handler.deleteTodo)
 	router.PATCH("/:id/complete", handler.markComplete)
+	router.POST("/:id/snooze", handler.snoozeTodo)
+	router.DELETE("/:id/snooze", handler.wakeTodo)
+	router.GET("/board", handler.getBoard)
+	router.GET("/search", handler.searchTodos)
+	router.GET("/quick-add", handler.previewQuickAdd)
+	router.GET("/plan", handler.getPlan)
+	router.POST("/:id/dependencies", handler.addDependency)
+	router.DELETE("/:id/dependencies", handler.removeDependency)
+	router.GET("/export.ics", handler.exportICal)
+	router.GET("/export", handler.exportTodos)
+	router.POST("/import", handler.importTodos)
 }
 
 // Handler exposes HTTP endpoints for todos.
 type Handler struct {
-	repo *Repository
+	repo   *Repository
+	users  Users
+	logger *slog.Logger
 }
 
 func (h *Handler) createTodo(c *gin.Context) {
@@ -36,9 +71,31 @@ func (h *Handler) createTodo(c *gin.Context) {
 		return
 	}
 
+	if err := checkUser(c.Request.Context(), h.users, h.logger, input.UserID); err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	if input.QuickAdd != "" {
+		opts, ok := quickAddOptions(c)
+		if !ok {
+			return
+		}
+		var err error
+		if input, _, err = h.repo.ApplyQuickAdd(c.Request.Context(), input, opts); err != nil {
+			respondWriteError(c, err)
+			return
+		}
+	}
+
+	if strings.TrimSpace(input.Title) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "title is required"})
+		return
+	}
+
 	t, err := h.repo.Create(c.Request.Context(), input)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondWriteError(c, err)
 		return
 	}
 
@@ -46,33 +103,454 @@ func (h *Handler) createTodo(c *gin.Context) {
 }
 
 func (h *Handler) getTodo(c *gin.Context) {
-	id, err := uuid.Parse(c.Param("id"))
+	t, _, ok := h.authorize(c, access.RoleViewer)
+	if !ok {
+		return
+	}
+
+	h.respondTodo(c, http.StatusOK, t)
+}
+
+// listTodos lists the user's todos, leaving out deferred and snoozed ones unless
+// include_hidden=true. assignee=me, or a user id, keeps the todos assigned to that user.
+// q filters with the query language, with days starting in the tz time zone. Within a
+// project, field[key]=value filters on custom field values and sort=field[key] with
+// order=asc|desc sorts by one.
+func (h *Handler) listTodos(c *gin.Context) {
+	userID, ok := userIDQuery(c)
+	if !ok {
+		return
+	}
+
+	var opts ListOptions
+	if raw := c.Query("project_id"); raw != "" {
+		projectID, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project_id"})
+			return
+		}
+		opts.ProjectID = &projectID
+	}
+
+	opts.IncludeHidden = c.Query("include_hidden") == "true"
+
+	switch raw := c.Query("assignee"); raw {
+	case "":
+	case "me":
+		opts.AssigneeID = &userID
+	default:
+		assigneeID, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "assignee must be me or a user id"})
+			return
+		}
+		opts.AssigneeID = &assigneeID
+	}
+
+	if raw := c.Query("q"); raw != "" {
+		node, err := query.Parse(raw)
+		if err != nil {
+			respondQueryError(c, http.StatusBadRequest, err)
+			return
+		}
+		opts.Query = node
+	}
+
+	if raw := c.Query("tz"); raw != "" {
+		loc, err := time.LoadLocation(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tz"})
+			return
+		}
+		opts.Location = loc
+	}
+
+	filters := c.QueryMap("field")
+	sortKey, sortByField := fieldKey(c.Query("sort"))
+	if c.Query("sort") != "" && !sortByField {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "sort must be field[key]"})
+		return
+	}
+
+	if len(filters) > 0 || sortByField {
+		if opts.ProjectID == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "custom field filters and sorting require project_id"})
+			return
+		}
+		schema, err := h.repo.Fields(c.Request.Context(), *opts.ProjectID)
+		if err != nil {
+			respondWriteError(c, err)
+			return
+		}
+
+		opts.Fields = make(customfield.Values, len(filters))
+		for key, raw := range filters {
+			f, ok := schema.Field(key)
+			if !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unknown custom field %q", key)})
+				return
+			}
+			value, err := f.Parse(raw)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if value != nil {
+				opts.Fields[key] = value
+			}
+		}
+
+		if sortByField {
+			f, ok := schema.Field(sortKey)
+			if !ok || f.Type == customfield.TypeMultiSelect {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("cannot sort by custom field %q", sortKey)})
+				return
+			}
+			opts.SortField = &f
+		}
+	}
+
+	switch c.DefaultQuery("order", "asc") {
+	case "asc":
+	case "desc":
+		opts.Descending = true
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "order must be asc or desc"})
+		return
+	}
+
+	todos, err := h.repo.List(c.Request.Context(), userID, opts)
+	if err == nil {
+		err = h.repo.WithBlockers(c.Request.Context(), todos)
+	}
+	if err == nil {
+		err = h.repo.WithEffort(c.Request.Context(), todos)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, todos)
+}
+
+// fieldKey extracts key from a "field[key]" query value.
+func fieldKey(value string) (string, bool) {
+	key, ok := strings.CutPrefix(value, "field[")
+	if !ok {
+		return "", false
+	}
+	key, ok = strings.CutSuffix(key, "]")
+	return key, ok && key != ""
+}
+
+func (h *Handler) updateTodo(c *gin.Context) {
+	current, userID, ok := h.authorize(c, access.RoleEditor)
+	if !ok {
+		return
+	}
+
+	var input UpdateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if input.ClearDueDate && input.DueDate != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "due_date and clear_due_date are mutually exclusive"})
+		return
+	}
+
+	if input.ClearProject && input.ProjectID != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "project_id and clear_project are mutually exclusive"})
+		return
+	}
+
+	if input.ClearAssignee && input.AssigneeID != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "assignee_id and clear_assignee are mutually exclusive"})
+		return
+	}
+
+	if input.ClearEstimate && input.EstimateMinutes != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "estimate_minutes and clear_estimate are mutually exclusive"})
+		return
+	}
+
+	if input.ClearStartDate && input.StartDate != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "start_date and clear_start_date are mutually exclusive"})
+		return
+	}
+
+	if input.ProjectID != nil {
+		if err := h.repo.CheckProjectWrite(c.Request.Context(), *input.ProjectID, userID); err != nil {
+			respondWriteError(c, err)
+			return
+		}
+	}
+	input.ActorID = userID
+
+	t, err := h.repo.Update(c.Request.Context(), current.ID, input)
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	h.respondTodo(c, http.StatusOK, t)
+}
+
+func (h *Handler) deleteTodo(c *gin.Context) {
+	t, _, ok := h.authorize(c, access.RoleEditor)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.Delete(c.Request.Context(), t.ID); err != nil {
+		if err == ErrNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": "todo not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *Handler) markComplete(c *gin.Context) {
+	current, _, ok := h.authorize(c, access.RoleEditor)
+	if !ok {
+		return
+	}
+
+	// Todos waiting for open todos cannot be completed unless force=true.
+	if c.Query("force") != "true" {
+		todos := []Todo{current}
+		if err := h.repo.WithBlockers(c.Request.Context(), todos); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		if todos[0].Blocked {
+			c.JSON(http.StatusConflict, gin.H{"error": ErrBlocked.Error(), "blocked_by": todos[0].BlockedBy})
+			return
+		}
+	}
+
+	input := UpdateInput{
+		Completed: ptrTo(true),
+	}
+
+	t, err := h.repo.Update(c.Request.Context(), current.ID, input)
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	h.respondTodo(c, http.StatusOK, t)
+}
+
+// snoozeTodo hides the todo from default listings and due reminders until the input's
+// time or for its duration.
+func (h *Handler) snoozeTodo(c *gin.Context) {
+	current, _, ok := h.authorize(c, access.RoleEditor)
+	if !ok {
+		return
+	}
+
+	var input SnoozeInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	until, err := input.End(time.Now())
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	t, err := h.repo.Snooze(c.Request.Context(), current.ID, &until)
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	h.respondTodo(c, http.StatusOK, t)
+}
+
+// wakeTodo ends a snooze early.
+func (h *Handler) wakeTodo(c *gin.Context) {
+	current, _, ok := h.authorize(c, access.RoleEditor)
+	if !ok {
+		return
+	}
+
+	t, err := h.repo.Snooze(c.Request.Context(), current.ID, nil)
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	h.respondTodo(c, http.StatusOK, t)
+}
+
+// addDependency makes the todo wait for the todo given as blocker_id.
+func (h *Handler) addDependency(c *gin.Context) {
+	current, userID, ok := h.authorize(c, access.RoleEditor)
+	if !ok {
+		return
+	}
+	id := current.ID
+
+	var input struct {
+		BlockerID uuid.UUID `json:"blocker_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.repo.AddDependency(c.Request.Context(), id, input.BlockerID, userID); err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
 	t, err := h.repo.Get(c.Request.Context(), id)
-	switch {
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+	h.respondTodo(c, http.StatusCreated, t)
+}
+
+// removeDependency deletes the todo's dependency on the blocker_id query parameter.
+func (h *Handler) removeDependency(c *gin.Context) {
+	current, _, ok := h.authorize(c, access.RoleEditor)
+	if !ok {
+		return
+	}
+	blockerID, err := uuid.Parse(c.Query("blocker_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid blocker_id"})
+		return
+	}
+
+	switch err := h.repo.RemoveDependency(c.Request.Context(), current.ID, blockerID); {
 	case err == nil:
-		c.JSON(http.StatusOK, t)
-	case err == ErrNotFound:
-		c.JSON(http.StatusNotFound, gin.H{"error": "todo not found"})
+		c.Status(http.StatusNoContent)
+	case err == ErrNoDependency:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 	default:
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 	}
 }
 
-func (h *Handler) listTodos(c *gin.Context) {
-	userIDParam := c.Query("user_id")
-	if userIDParam == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "user_id query parameter is required"})
+// getPlan orders the open todos of a project so that dependencies come first.
+func (h *Handler) getPlan(c *gin.Context) {
+	userID, ok := userIDQuery(c)
+	if !ok {
+		return
+	}
+	projectID, err := uuid.Parse(c.Query("project_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "project_id is required"})
 		return
 	}
 
-	userID, err := uuid.Parse(userIDParam)
+	todos, err := h.repo.ListByProject(c.Request.Context(), userID, projectID)
+	if err == nil {
+		err = h.repo.WithBlockers(c.Request.Context(), todos)
+	}
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	plan, err := NewPlan(todos)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// getBoard groups the user's todos by status. With project_id the board follows that
+// project's workflow, otherwise the default workflow.
+func (h *Handler) getBoard(c *gin.Context) {
+	userID, ok := userIDQuery(c)
+	if !ok {
+		return
+	}
+
+	var projectID *uuid.UUID
+	if raw := c.Query("project_id"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project_id"})
+			return
+		}
+		projectID = &id
+	}
+
+	wf, err := h.repo.Workflow(c.Request.Context(), projectID)
+	if err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	var todos []Todo
+	if projectID != nil {
+		todos, err = h.repo.ListByProject(c.Request.Context(), userID, *projectID)
+	} else {
+		todos, err = h.repo.ListByUser(c.Request.Context(), userID)
+	}
+	if err == nil {
+		err = h.repo.WithBlockers(c.Request.Context(), todos)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, NewBoard(wf, todos))
+}
+
+func (h *Handler) searchTodos(c *gin.Context) {
+	userID, ok := userIDQuery(c)
+	if !ok {
+		return
+	}
+
+	limit := DefaultSearchLimit
+	if raw := c.Query("limit"); raw != "" {
+		if parsed, err := strconv.Atoi(raw); err == nil && parsed > 0 {
+			limit = min(parsed, MaxSearchLimit)
+		}
+	}
+
+	results, err := h.repo.Search(c.Request.Context(), userID, c.Query("q"), limit)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, results)
+	case err == ErrEmptySearch:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// previewQuickAdd shows how a quick add text would be read, without creating a todo.
+func (h *Handler) previewQuickAdd(c *gin.Context) {
+	opts, ok := quickAddOptions(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, quickadd.Parse(c.Query("text"), opts))
+}
+
+func (h *Handler) exportICal(c *gin.Context) {
+	userID, ok := userIDQuery(c)
+	if !ok {
 		return
 	}
 
@@ -82,79 +560,251 @@ func (h *Handler) listTodos(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, todos)
+	c.Header("Content-Type", "text/calendar; charset=utf-8")
+	c.Header("Content-Disposition", `attachment; filename="todos.ics"`)
+	c.Status(http.StatusOK)
+	if err := ical.Encode(c.Writer, NewCalendar(todos)); err != nil {
+		_ = c.Error(err)
+	}
 }
 
-func (h *Handler) updateTodo(c *gin.Context) {
-	id, err := uuid.Parse(c.Param("id"))
+func (h *Handler) exportTodos(c *gin.Context) {
+	userID, ok := userIDQuery(c)
+	if !ok {
+		return
+	}
+
+	format := strings.ToLower(c.DefaultQuery("format", FormatJSON))
+	contentType, ok := exportContentTypes[format]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv, json or ndjson"})
+		return
+	}
+
+	writer, err := newRecordWriter(c.Writer, format)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	var input UpdateInput
-	if err := c.ShouldBindJSON(&input); err != nil {
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", `attachment; filename="todos.`+format+`"`)
+	c.Status(http.StatusOK)
+
+	// Rows are written as they are read, so failures after the first byte can only be logged.
+	if err := h.repo.StreamByUser(c.Request.Context(), userID, writer.Write); err != nil {
+		_ = c.Error(err)
+		return
+	}
+	if err := writer.Close(); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// importTodos accepts iCalendar, CSV, JSON and NDJSON uploads. The format comes from the
+// format query parameter, the uploaded file name or the content type, defaulting to iCalendar.
+func (h *Handler) importTodos(c *gin.Context) {
+	userID, ok := userIDQuery(c)
+	if !ok {
+		return
+	}
+
+	if err := checkUser(c.Request.Context(), h.users, h.logger, userID); err != nil {
+		respondWriteError(c, err)
+		return
+	}
+
+	body, filename, err := uploadBody(c, maxBulkImportBytes)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	defer body.Close()
 
-	if input.ClearDueDate && input.DueDate != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "due_date and clear_due_date are mutually exclusive"})
+	format := importFormat(c, filename)
+	if format == FormatICal {
+		h.importICal(c, userID, body)
+		return
+	}
+
+	reader, err := newRecordReader(body, format)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	t, err := h.repo.Update(c.Request.Context(), id, input)
+	dryRun, _ := strconv.ParseBool(c.Query("dry_run"))
+	report, err := ImportRecords(c.Request.Context(), h.repo, userID, reader, c.QueryMap("map"), dryRun)
 	switch {
 	case err == nil:
-		c.JSON(http.StatusOK, t)
-	case err == ErrNotFound:
-		c.JSON(http.StatusNotFound, gin.H{"error": "todo not found"})
+		c.JSON(http.StatusOK, report)
+	case errors.Is(err, ErrInvalidImport):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "report": report})
+	case err == ErrUnknownUser:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
 	default:
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "report": report})
 	}
 }
 
-func (h *Handler) deleteTodo(c *gin.Context) {
-	id, err := uuid.Parse(c.Param("id"))
+func (h *Handler) importICal(c *gin.Context, userID uuid.UUID, body io.Reader) {
+	cal, err := ical.Decode(body)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
+	if cal.Name != ical.ComponentCalendar {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "expected a VCALENDAR"})
+		return
+	}
 
-	if err := h.repo.Delete(c.Request.Context(), id); err != nil {
-		if err == ErrNotFound {
-			c.JSON(http.StatusNotFound, gin.H{"error": "todo not found"})
-			return
-		}
+	report, err := ImportCalendar(c.Request.Context(), h.repo, userID, cal)
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	c.Status(http.StatusNoContent)
+	c.JSON(http.StatusOK, report)
 }
 
-func (h *Handler) markComplete(c *gin.Context) {
+func importFormat(c *gin.Context, filename string) string {
+	if format := c.Query("format"); format != "" {
+		return strings.ToLower(format)
+	}
+
+	if ext := strings.ToLower(path.Ext(filename)); ext != "" {
+		return strings.TrimPrefix(ext, ".")
+	}
+
+	switch c.ContentType() {
+	case "text/csv":
+		return FormatCSV
+	case "application/json":
+		return FormatJSON
+	case "application/x-ndjson", "application/ndjson":
+		return FormatNDJSON
+	default:
+		return FormatICal
+	}
+}
+
+// authorize loads the todo of the request path on behalf of the user_id query parameter,
+// who must hold at least the required role. It writes the error response on failure.
+func (h *Handler) authorize(c *gin.Context, required access.Role) (Todo, uuid.UUID, bool) {
 	id, err := uuid.Parse(c.Param("id"))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
+		return Todo{}, uuid.Nil, false
+	}
+	userID, ok := userIDQuery(c)
+	if !ok {
+		return Todo{}, uuid.Nil, false
 	}
 
-	input := UpdateInput{
-		Completed: ptrTo(true),
+	t, err := h.repo.Authorize(c.Request.Context(), id, userID, required)
+	if err != nil {
+		respondWriteError(c, err)
+		return Todo{}, uuid.Nil, false
+	}
+	return t, userID, true
+}
+
+// respondTodo writes the todo together with its blockers and tracked time.
+func (h *Handler) respondTodo(c *gin.Context, status int, t Todo) {
+	todos := []Todo{t}
+	err := h.repo.WithBlockers(c.Request.Context(), todos)
+	if err == nil {
+		err = h.repo.WithEffort(c.Request.Context(), todos)
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
+	c.JSON(status, todos[0])
+}
 
-	t, err := h.repo.Update(c.Request.Context(), id, input)
+// respondWriteError maps errors of creating or updating a todo onto HTTP responses.
+func respondWriteError(c *gin.Context, err error) {
 	switch {
-	case err == nil:
-		c.JSON(http.StatusOK, t)
 	case err == ErrNotFound:
 		c.JSON(http.StatusNotFound, gin.H{"error": "todo not found"})
+	case err == ErrForbidden:
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case err == ErrConflict:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrUnknownStatus), errors.Is(err, ErrInvalidTransition), err == ErrUnknownUser, err == ErrUnknownProject, err == ErrUnknownParent, err == ErrUnknownAssignee,
+		err == ErrUnknownBlocker, err == ErrDependencyCycle,
+		errors.Is(err, customfield.ErrInvalidValue), errors.Is(err, quickadd.ErrInvalidRecurrence):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
 	default:
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 	}
 }
 
+// respondQueryError reports a query error together with the column it refers to.
+func respondQueryError(c *gin.Context, status int, err error) {
+	var qe *query.Error
+	if errors.As(err, &qe) {
+		c.JSON(status, gin.H{"error": qe.Error(), "column": qe.Col})
+		return
+	}
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
+// quickAddOptions reads the user's time zone from the tz query parameter (default UTC)
+// and the locale from the locale parameter or the Accept-Language header.
+func quickAddOptions(c *gin.Context) (quickadd.Options, bool) {
+	opts := quickadd.Options{Location: time.UTC}
+	if raw := c.Query("tz"); raw != "" {
+		loc, err := time.LoadLocation(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid tz"})
+			return opts, false
+		}
+		opts.Location = loc
+	}
+
+	opts.Locale = quickadd.MatchLocale(c.GetHeader("Accept-Language"))
+	if raw := c.Query("locale"); raw != "" {
+		opts.Locale = quickadd.MatchLocale(raw)
+	}
+	return opts, true
+}
+
+// userIDQuery parses the mandatory user_id query parameter, writing a 400 response when it is invalid.
+func userIDQuery(c *gin.Context) (uuid.UUID, bool) {
+	userIDParam := c.Query("user_id")
+	if userIDParam == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "user_id query parameter is required"})
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(userIDParam)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
+
+// uploadBody returns the uploaded file of a multipart request (field "file") and its name,
+// or the raw request body.
+func uploadBody(c *gin.Context, limit int64) (io.ReadCloser, string, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		header, err := c.FormFile("file")
+		if err != nil {
+			return nil, "", err
+		}
+		file, err := header.Open()
+		return file, header.Filename, err
+	}
+
+	return c.Request.Body, "", nil
+}
+
 func ptrTo[T any](v T) *T {
 	return &v
 }
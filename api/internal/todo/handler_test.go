@@ -0,0 +1,108 @@
+package todo
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestRouter serves the todo routes from mock under /todos.
+func newTestRouter(mock pgxmock.PgxPoolIface) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	RegisterRoutes(engine.Group("/todos"), NewRepository(mock), &fakeUsers{}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	return engine
+}
+
+func TestAddDependencyRejectsBlockerHiddenFromCaller(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	// The blocker is a personal todo of the shared todo's owner, so the two are related,
+	// but the project editor adding the dependency cannot see it.
+	ownerID, editorID, projectID, blockerID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	now := time.Now()
+	shared := Todo{ID: uuid.New(), UserID: ownerID, Title: "Shared", ProjectID: &projectID, CreatedAt: now, UpdatedAt: now}
+	editor := "editor"
+
+	mock.ExpectQuery("SELECT .* FROM todos WHERE id = \\$1").
+		WithArgs(shared.ID, editorID).
+		WillReturnRows(authorizeRows(shared, &editor))
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT user_id, project_id FROM todos WHERE id = \\$1$").WithArgs(shared.ID).
+		WillReturnRows(pgxmock.NewRows([]string{"user_id", "project_id"}).AddRow(ownerID, &projectID))
+	mock.ExpectQuery("SELECT user_id, project_id FROM todos WHERE id = \\$1 AND").WithArgs(blockerID, editorID).
+		WillReturnError(pgx.ErrNoRows)
+	mock.ExpectRollback()
+
+	req := httptest.NewRequest(http.MethodPost, "/todos/"+shared.ID.String()+"/dependencies?user_id="+editorID.String(),
+		strings.NewReader(`{"blocker_id":"`+blockerID.String()+`"}`))
+	rec := httptest.NewRecorder()
+	newTestRouter(mock).ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	var body struct {
+		Error string `json:"error"`
+	}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+	require.Equal(t, ErrUnknownBlocker.Error(), body.Error)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateTodoRejectsConflictingFields(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	todo := Todo{ID: uuid.New(), UserID: userID, Title: "Title"}
+	router := newTestRouter(mock)
+
+	for _, body := range []string{
+		`{"due_date": "2026-01-02T00:00:00Z", "clear_due_date": true}`,
+		`{"project_id": "` + uuid.NewString() + `", "clear_project": true}`,
+		`{"assignee_id": "` + uuid.NewString() + `", "clear_assignee": true}`,
+		`{"estimate_minutes": 30, "clear_estimate": true}`,
+		`{"start_date": "2026-01-02T00:00:00Z", "clear_start_date": true}`,
+	} {
+		mock.ExpectQuery("SELECT .* FROM todos WHERE id = \\$1").WithArgs(todo.ID, userID).WillReturnRows(authorizeRows(todo, nil))
+
+		req := httptest.NewRequest(http.MethodPut, "/todos/"+todo.ID.String()+"?user_id="+userID.String(), strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		require.Equal(t, http.StatusBadRequest, rec.Code, body)
+		require.Contains(t, rec.Body.String(), "mutually exclusive", body)
+	}
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestImportTodosRejectsUnknownUser(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	RegisterRoutes(engine.Group("/todos"), NewRepository(mock), &fakeUsers{missing: map[uuid.UUID]bool{userID: true}}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	req := httptest.NewRequest(http.MethodPost, "/todos/import?format=ndjson&user_id="+userID.String(), strings.NewReader(`{"title":"task"}`+"\n"))
+	rec := httptest.NewRecorder()
+	engine.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	require.Contains(t, rec.Body.String(), ErrUnknownUser.Error())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
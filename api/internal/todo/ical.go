@@ -0,0 +1,383 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"overengineeredtodo/internal/access"
+	"overengineeredtodo/internal/ical"
+	"overengineeredtodo/internal/quickadd"
+)
+
+// ICalProdID identifies this service as the producer of exported calendars.
+const ICalProdID = "-//Overengineered ToDo//Todo Service//EN"
+
+// ImportReport summarises the outcome of a bulk import.
+type ImportReport struct {
+	Created []ImportEntry `json:"created"`
+	Updated []ImportEntry `json:"updated"`
+	Skipped []ImportEntry `json:"skipped"`
+	DryRun  bool          `json:"dry_run,omitempty"`
+}
+
+// ImportEntry describes what happened to a single imported item.
+type ImportEntry struct {
+	Row    int        `json:"row,omitempty"`
+	UID    string     `json:"uid,omitempty"`
+	TodoID *uuid.UUID `json:"todo_id,omitempty"`
+	Title  string     `json:"title,omitempty"`
+	Reason string     `json:"reason,omitempty"`
+}
+
+// UID returns the iCalendar UID of the todo: the imported UID when present,
+// otherwise the todo id.
+func (t Todo) UID() string {
+	if t.ICalUID != nil && *t.ICalUID != "" {
+		return *t.ICalUID
+	}
+	return t.ID.String()
+}
+
+// ToVTODO serialises a todo as an RFC 5545 VTODO component.
+func ToVTODO(t Todo) *ical.Component {
+	c := ical.NewComponent(ical.ComponentTodo)
+	c.SetText("UID", t.UID())
+	c.SetDateTime("DTSTAMP", t.UpdatedAt)
+	c.SetDateTime("CREATED", t.CreatedAt)
+	c.SetDateTime("LAST-MODIFIED", t.UpdatedAt)
+	c.SetText("SUMMARY", t.Title)
+	if t.Description != "" {
+		c.SetText("DESCRIPTION", t.Description)
+	}
+	if t.StartDate != nil {
+		c.SetDateTime("DTSTART", *t.StartDate)
+	}
+	if t.DueDate != nil {
+		c.SetDateTime("DUE", *t.DueDate)
+	}
+	if len(t.Labels) > 0 {
+		escaped := make([]string, len(t.Labels))
+		for i, label := range t.Labels {
+			escaped[i] = ical.EscapeText(label)
+		}
+		c.Set("CATEGORIES", strings.Join(escaped, ","))
+	}
+	if t.Priority != PriorityNone {
+		c.Set("PRIORITY", strconv.Itoa(icalPriority(t.Priority)))
+	}
+	if t.Recurrence != "" {
+		c.Set("RRULE", t.Recurrence)
+	}
+	if t.Completed {
+		c.Set("STATUS", "COMPLETED")
+		completedAt := t.UpdatedAt
+		if t.CompletedAt != nil {
+			completedAt = *t.CompletedAt
+		}
+		c.SetDateTime("COMPLETED", completedAt)
+		c.Set("PERCENT-COMPLETE", "100")
+	} else {
+		c.Set("STATUS", "NEEDS-ACTION")
+	}
+	return c
+}
+
+// ToVEVENT renders a todo with a due date as a point-in-time VEVENT so it shows
+// up in calendar views that ignore tasks.
+func ToVEVENT(t Todo) *ical.Component {
+	c := ical.NewComponent(ical.ComponentEvent)
+	c.SetText("UID", t.UID())
+	c.SetDateTime("DTSTAMP", t.UpdatedAt)
+	c.SetDateTime("CREATED", t.CreatedAt)
+	c.SetDateTime("LAST-MODIFIED", t.UpdatedAt)
+	c.SetText("SUMMARY", t.Title)
+	if t.Description != "" {
+		c.SetText("DESCRIPTION", t.Description)
+	}
+	if t.DueDate != nil {
+		c.SetDateTime("DTSTART", *t.DueDate)
+	}
+	c.Set("TRANSP", "TRANSPARENT")
+	if t.Completed {
+		c.Set("STATUS", "CANCELLED")
+	} else {
+		c.Set("STATUS", "CONFIRMED")
+	}
+	return c
+}
+
+// icalPriority maps a todo priority onto the RFC 5545 scale, where 1 is the
+// highest priority, 5 medium and 9 the lowest.
+func icalPriority(priority int) int {
+	switch priority {
+	case PriorityHigh:
+		return 1
+	case PriorityMedium:
+		return 5
+	case PriorityLow:
+		return 9
+	default:
+		return 0
+	}
+}
+
+// fromICalPriority is the inverse of icalPriority, following the ranges of RFC 5545 section 3.8.1.9.
+func fromICalPriority(value int) int {
+	switch {
+	case value >= 1 && value <= 4:
+		return PriorityHigh
+	case value == 5:
+		return PriorityMedium
+	case value >= 6 && value <= 9:
+		return PriorityLow
+	default:
+		return PriorityNone
+	}
+}
+
+// NewCalendar wraps the todos in a VCALENDAR.
+func NewCalendar(todos []Todo) *ical.Component {
+	cal := ical.NewCalendar(ICalProdID)
+	for _, t := range todos {
+		cal.Append(ToVTODO(t))
+	}
+	return cal
+}
+
+// ICalTodo holds the todo fields read from a VTODO component.
+type ICalTodo struct {
+	UID         string
+	Title       string
+	Description string
+	DueDate     *time.Time
+	StartDate   *time.Time
+	Completed   bool
+	CompletedAt *time.Time
+	Labels      []string
+	Priority    int
+	Recurrence  string
+}
+
+// FromVTODO extracts the todo fields from a VTODO component.
+func FromVTODO(c *ical.Component) (ICalTodo, error) {
+	item := ICalTodo{
+		UID:         strings.TrimSpace(c.Text("UID")),
+		Title:       strings.TrimSpace(c.Text("SUMMARY")),
+		Description: c.Text("DESCRIPTION"),
+	}
+
+	if item.UID == "" {
+		return item, errors.New("missing UID")
+	}
+	if item.Title == "" {
+		return item, errors.New("missing SUMMARY")
+	}
+
+	if due, ok := c.Get("DUE"); ok {
+		parsed, err := ical.ParseDateTime(due, time.UTC)
+		if err != nil {
+			return item, fmt.Errorf("invalid DUE: %w", err)
+		}
+		item.DueDate = &parsed
+	}
+	if start, ok := c.Get("DTSTART"); ok {
+		parsed, err := ical.ParseDateTime(start, time.UTC)
+		if err != nil {
+			return item, fmt.Errorf("invalid DTSTART: %w", err)
+		}
+		item.StartDate = &parsed
+	}
+
+	for _, p := range c.Properties {
+		if p.Name == "CATEGORIES" {
+			item.Labels = append(item.Labels, ical.SplitText(p.Value)...)
+		}
+	}
+	item.Labels = NormalizeLabels(item.Labels)
+
+	if p, ok := c.Get("PRIORITY"); ok {
+		value, err := strconv.Atoi(strings.TrimSpace(p.Value))
+		if err != nil {
+			return item, fmt.Errorf("invalid PRIORITY: %w", err)
+		}
+		item.Priority = fromICalPriority(value)
+	}
+
+	// Rules outside the supported subset, such as ones with COUNT or UNTIL, are dropped
+	// rather than failing the whole item.
+	if rrule, ok := c.Get("RRULE"); ok && quickadd.ValidateRecurrence(rrule.Value) == nil {
+		item.Recurrence = rrule.Value
+	}
+
+	// STATUS wins over a stale COMPLETED timestamp left behind by clients that reopen tasks.
+	if status, ok := c.Get("STATUS"); ok {
+		item.Completed = strings.EqualFold(status.Value, "COMPLETED")
+	} else {
+		_, item.Completed = c.Get("COMPLETED")
+	}
+	if completed, ok := c.Get("COMPLETED"); ok && item.Completed {
+		if at, err := ical.ParseDateTime(completed, time.UTC); err == nil {
+			item.CompletedAt = &at
+		}
+	}
+
+	return item, nil
+}
+
+// changes returns the update needed to bring existing in line with the item.
+func (item ICalTodo) changes(existing Todo) UpdateInput {
+	var input UpdateInput
+	if item.Title != existing.Title {
+		input.Title = ptrTo(item.Title)
+	}
+	if item.Description != existing.Description {
+		input.Description = ptrTo(item.Description)
+	}
+	if item.Completed != existing.Completed {
+		input.Completed = ptrTo(item.Completed)
+	}
+	switch {
+	case item.DueDate == nil && existing.DueDate != nil:
+		input.ClearDueDate = true
+	case item.DueDate != nil && (existing.DueDate == nil || !item.DueDate.Equal(*existing.DueDate)):
+		input.DueDate = item.DueDate
+	}
+	switch {
+	case item.StartDate == nil && existing.StartDate != nil:
+		input.ClearStartDate = true
+	case item.StartDate != nil && (existing.StartDate == nil || !item.StartDate.Equal(*existing.StartDate)):
+		input.StartDate = item.StartDate
+	}
+	if !slices.Equal(item.Labels, existing.Labels) {
+		input.Labels = ptrTo(item.Labels)
+	}
+	if item.Priority != existing.Priority {
+		input.Priority = ptrTo(item.Priority)
+	}
+	if item.Recurrence != existing.Recurrence {
+		input.Recurrence = ptrTo(item.Recurrence)
+	}
+	return input
+}
+
+// UpsertResult tells callers of UpsertICal what happened to the todo.
+type UpsertResult int
+
+const (
+	UpsertCreated UpsertResult = iota
+	UpsertUpdated
+	UpsertUnchanged
+)
+
+// UpsertICal creates or updates the todo identified by item.UID among those the user can
+// see. Changing a todo of a shared project requires the editor role. With ifVersion, only
+// a todo still at that version is changed; otherwise it fails with ErrConflict.
+func UpsertICal(ctx context.Context, repo *Repository, userID uuid.UUID, item ICalTodo, ifVersion *int) (Todo, UpsertResult, error) {
+	existing, err := repo.FindByUID(ctx, userID, item.UID)
+	switch {
+	case err == ErrNotFound && ifVersion != nil:
+		return Todo{}, 0, ErrConflict
+	case err == ErrNotFound:
+		created, err := repo.Create(ctx, CreateInput{
+			UserID:      userID,
+			Title:       item.Title,
+			Description: item.Description,
+			DueDate:     item.DueDate,
+			StartDate:   item.StartDate,
+			Completed:   item.Completed,
+			CompletedAt: item.CompletedAt,
+			Labels:      item.Labels,
+			Priority:    item.Priority,
+			Recurrence:  item.Recurrence,
+			ICalUID:     ptrTo(item.UID),
+		})
+		return created, UpsertCreated, err
+	case err != nil:
+		return Todo{}, 0, err
+	}
+
+	if ifVersion != nil && existing.Version != *ifVersion {
+		return Todo{}, 0, ErrConflict
+	}
+	input := item.changes(existing)
+	if input.IsEmpty() {
+		return existing, UpsertUnchanged, nil
+	}
+	input.IfVersion = ifVersion
+	// Todos of shared projects are found too; only editors may change them.
+	if _, err := repo.Authorize(ctx, existing.ID, userID, access.RoleEditor); err != nil {
+		return Todo{}, 0, err
+	}
+
+	updated, err := repo.Update(ctx, existing.ID, input)
+	return updated, UpsertUpdated, err
+}
+
+// ImportCalendar upserts every VTODO of cal into the user's todos, matching by UID.
+// Components that cannot be mapped are reported as skipped rather than failing the import.
+// The import runs in one transaction, so when it fails no todo is changed.
+func ImportCalendar(ctx context.Context, repo *Repository, userID uuid.UUID, cal *ical.Component) (ImportReport, error) {
+	report := ImportReport{
+		Created: []ImportEntry{},
+		Updated: []ImportEntry{},
+		Skipped: []ImportEntry{},
+	}
+
+	tx, err := repo.pool.Begin(ctx)
+	if err != nil {
+		return ImportReport{}, fmt.Errorf("begin import: %w", err)
+	}
+	// Rolling back a committed transaction is a no-op.
+	defer func() { _ = tx.Rollback(ctx) }()
+	repo = NewRepository(tx)
+
+	for _, comp := range cal.Components {
+		if comp.Name != ical.ComponentTodo {
+			if comp.Name != "VTIMEZONE" {
+				report.Skipped = append(report.Skipped, ImportEntry{
+					UID:    comp.Text("UID"),
+					Reason: "unsupported component " + comp.Name,
+				})
+			}
+			continue
+		}
+
+		item, err := FromVTODO(comp)
+		if err != nil {
+			report.Skipped = append(report.Skipped, ImportEntry{UID: item.UID, Title: item.Title, Reason: err.Error()})
+			continue
+		}
+
+		t, result, err := UpsertICal(ctx, repo, userID, item, nil)
+		if errors.Is(err, ErrInvalidTransition) || err == ErrConflict || err == ErrForbidden {
+			report.Skipped = append(report.Skipped, ImportEntry{UID: item.UID, Title: item.Title, Reason: err.Error()})
+			continue
+		}
+		if err != nil {
+			return ImportReport{}, err
+		}
+
+		entry := ImportEntry{UID: item.UID, TodoID: &t.ID, Title: t.Title}
+		switch result {
+		case UpsertCreated:
+			report.Created = append(report.Created, entry)
+		case UpsertUpdated:
+			report.Updated = append(report.Updated, entry)
+		default:
+			entry.Reason = "unchanged"
+			report.Skipped = append(report.Skipped, entry)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return ImportReport{}, fmt.Errorf("commit import: %w", err)
+	}
+	return report, nil
+}
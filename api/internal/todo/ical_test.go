@@ -0,0 +1,160 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+
+	"overengineeredtodo/internal/customfield"
+	"overengineeredtodo/internal/ical"
+	"overengineeredtodo/internal/outbox"
+	"overengineeredtodo/internal/workflow"
+)
+
+func TestToVTODO(t *testing.T) {
+	due := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	start := time.Date(2025, 4, 25, 9, 0, 0, 0, time.UTC)
+	now := time.Date(2025, 4, 1, 8, 0, 0, 0, time.UTC)
+	todo := Todo{
+		ID:          uuid.New(),
+		Title:       "Pay rent",
+		Description: "Landlord, flat 3",
+		DueDate:     &due,
+		StartDate:   &start,
+		Completed:   true,
+		Labels:      []string{"home", "bills, monthly"},
+		Priority:    PriorityHigh,
+		Recurrence:  "FREQ=MONTHLY;BYMONTHDAY=1",
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+
+	c := ToVTODO(todo)
+	require.Equal(t, todo.ID.String(), c.Text("UID"))
+	require.Equal(t, "Pay rent", c.Text("SUMMARY"))
+	require.Equal(t, "Landlord, flat 3", c.Text("DESCRIPTION"))
+	require.Equal(t, "20250501T120000Z", c.Text("DUE"))
+	require.Equal(t, "20250425T090000Z", c.Text("DTSTART"))
+	require.Equal(t, "COMPLETED", c.Text("STATUS"))
+	require.Equal(t, "20250401T080000Z", c.Text("COMPLETED"))
+	require.Equal(t, "1", c.Text("PRIORITY"))
+
+	item, err := FromVTODO(c)
+	require.NoError(t, err)
+	require.Equal(t, todo.Labels, item.Labels)
+	require.Equal(t, PriorityHigh, item.Priority)
+	require.Equal(t, todo.Recurrence, item.Recurrence)
+	require.Equal(t, start, *item.StartDate)
+
+	imported := "external-uid@example.com"
+	todo.ICalUID = &imported
+	require.Equal(t, imported, ToVTODO(todo).Text("UID"))
+}
+
+func TestImportCalendar(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	userID := uuid.New()
+	existingID := uuid.New()
+	unchangedID := uuid.New()
+	now := time.Now()
+
+	input := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" +
+		"BEGIN:VTODO\r\nUID:new-1\r\nSUMMARY:New task\r\nSTATUS:NEEDS-ACTION\r\nEND:VTODO\r\n" +
+		"BEGIN:VTODO\r\nUID:" + existingID.String() + "\r\nSUMMARY:Renamed\r\nSTATUS:COMPLETED\r\nEND:VTODO\r\n" +
+		"BEGIN:VTODO\r\nUID:same\r\nSUMMARY:Same\r\nEND:VTODO\r\n" +
+		"BEGIN:VTODO\r\nUID:no-summary\r\nEND:VTODO\r\n" +
+		"BEGIN:VEVENT\r\nUID:event\r\nEND:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	cal, err := ical.Decode(strings.NewReader(input))
+	require.NoError(t, err)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .* FROM todos WHERE \\(\\(project_id IS NULL AND user_id = \\$1\\) .* AND \\(ical_uid = \\$2 OR id = \\$3\\) ORDER BY user_id = \\$1 DESC").
+		WithArgs(userID, "new-1", uuid.Nil).
+		WillReturnError(pgx.ErrNoRows)
+	createdID := uuid.New()
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO todos").
+		WithArgs(pgxmock.AnyArg(), userID, "New task", "", (*time.Time)(nil), false, ptrTo("new-1"), (*uuid.UUID)(nil), []string{}, 0, (*string)(nil), (*string)(nil), (*time.Time)(nil), workflow.StatusBacklog, customfield.Values{}, (*uuid.UUID)(nil), "", (*uuid.UUID)(nil), (*int)(nil), (*time.Time)(nil)).
+		WillReturnRows(newTodoRows(Todo{ID: createdID, UserID: userID, Title: "New task", CreatedAt: now, UpdatedAt: now}))
+	expectEvent(mock, outbox.TodoCreated, createdID, 0)
+	mock.ExpectCommit()
+
+	mock.ExpectQuery("SELECT .* FROM todos WHERE \\(\\(project_id IS NULL AND user_id = \\$1\\)").
+		WithArgs(userID, existingID.String(), existingID).
+		WillReturnRows(newTodoRows(Todo{ID: existingID, UserID: userID, Title: "Old", Status: workflow.StatusInProgress, CreatedAt: now, UpdatedAt: now}))
+	mock.ExpectQuery("SELECT .*, \\(SELECT m.role FROM project_members m .*\\) FROM todos WHERE id = \\$1").
+		WithArgs(existingID, userID).
+		WillReturnRows(authorizeRows(Todo{ID: existingID, UserID: userID, Title: "Old", Status: workflow.StatusInProgress, CreatedAt: now, UpdatedAt: now}, nil))
+	mock.ExpectQuery("SELECT .* FROM todos WHERE id = \\$1").
+		WithArgs(existingID).
+		WillReturnRows(newTodoRows(Todo{ID: existingID, UserID: userID, Title: "Old", Status: workflow.StatusInProgress, CreatedAt: now, UpdatedAt: now}))
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE todos SET").
+		WithArgs(workflow.StatusDone, "Renamed", true, existingID, workflow.StatusInProgress).
+		WillReturnRows(newTodoRows(Todo{ID: existingID, UserID: userID, Title: "Renamed", Completed: true, CreatedAt: now, UpdatedAt: now}))
+	expectEvent(mock, outbox.TodoCompleted, existingID, 0)
+	mock.ExpectCommit()
+
+	mock.ExpectQuery("SELECT .* FROM todos WHERE \\(\\(project_id IS NULL AND user_id = \\$1\\)").
+		WithArgs(userID, "same", uuid.Nil).
+		WillReturnRows(newTodoRows(Todo{ID: unchangedID, UserID: userID, Title: "Same", CreatedAt: now, UpdatedAt: now}))
+	mock.ExpectCommit()
+
+	report, err := ImportCalendar(context.Background(), repo, userID, cal)
+	require.NoError(t, err)
+	require.Len(t, report.Created, 1)
+	require.Equal(t, "new-1", report.Created[0].UID)
+	require.Len(t, report.Updated, 1)
+	require.Equal(t, existingID, *report.Updated[0].TodoID)
+	require.Len(t, report.Skipped, 3)
+	require.Equal(t, "unchanged", report.Skipped[0].Reason)
+	require.Equal(t, "missing SUMMARY", report.Skipped[1].Reason)
+	require.Equal(t, "unsupported component VEVENT", report.Skipped[2].Reason)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestImportCalendarRollsBackOnFailure(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	userID, createdID := uuid.New(), uuid.New()
+	now := time.Now()
+	cal, err := ical.Decode(strings.NewReader("BEGIN:VCALENDAR\r\nVERSION:2.0\r\n" +
+		"BEGIN:VTODO\r\nUID:first\r\nSUMMARY:First\r\nEND:VTODO\r\n" +
+		"BEGIN:VTODO\r\nUID:second\r\nSUMMARY:Second\r\nEND:VTODO\r\n" +
+		"END:VCALENDAR\r\n"))
+	require.NoError(t, err)
+
+	args := make([]any, 20)
+	for i := range args {
+		args[i] = pgxmock.AnyArg()
+	}
+	mock.ExpectBegin()
+	mock.ExpectQuery("SELECT .* FROM todos WHERE").WithArgs(userID, "first", uuid.Nil).WillReturnError(pgx.ErrNoRows)
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO todos").WithArgs(args...).
+		WillReturnRows(newTodoRows(Todo{ID: createdID, UserID: userID, Title: "First", CreatedAt: now, UpdatedAt: now}))
+	expectEvent(mock, outbox.TodoCreated, createdID, 0)
+	mock.ExpectCommit()
+	mock.ExpectQuery("SELECT .* FROM todos WHERE").WithArgs(userID, "second", uuid.Nil).WillReturnError(errors.New("connection reset"))
+	// The todo created for the first VTODO goes with the rest of the import.
+	mock.ExpectRollback()
+
+	_, err = ImportCalendar(context.Background(), NewRepository(mock), userID, cal)
+	require.Error(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+
+	"overengineeredtodo/internal/customfield"
 )
 
 // Todo represents a task owned by a user.
@@ -13,24 +15,146 @@ type Todo struct {
 	Title       string     `json:"title"`
 	Description string     `json:"description,omitempty"`
 	DueDate     *time.Time `json:"due_date,omitempty"`
-	Completed   bool       `json:"completed"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at"`
+	// StartDate defers the todo: it stays out of default listings until then.
+	StartDate *time.Time `json:"start_date,omitempty"`
+	// SnoozedUntil hides the todo from default listings and due reminders until then.
+	SnoozedUntil *time.Time `json:"snoozed_until,omitempty"`
+	Completed    bool       `json:"completed"`
+	Status       string     `json:"status"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	ICalUID      *string    `json:"ical_uid,omitempty"`
+	Version      int        `json:"version"`
+	ProjectID    *uuid.UUID `json:"project_id,omitempty"`
+	// ParentID is set on subtasks.
+	ParentID *uuid.UUID `json:"parent_id,omitempty"`
+	// AssigneeID is the user expected to do the todo, who may differ from its owner.
+	AssigneeID *uuid.UUID `json:"assignee_id,omitempty"`
+	// EstimateMinutes is the expected effort; TrackedMinutes is the time logged so far,
+	// including running timers, and is filled in by WithEffort.
+	EstimateMinutes *int       `json:"estimate_minutes,omitempty"`
+	TrackedMinutes  int        `json:"tracked_minutes"`
+	Labels          []string   `json:"labels"`
+	Priority        int        `json:"priority"`
+	CompletedAt     *time.Time `json:"completed_at,omitempty"`
+	// Recurrence is an RFC 5545 RRULE value such as FREQ=WEEKLY;BYDAY=MO, or empty.
+	Recurrence string `json:"recurrence,omitempty"`
+	// CustomFields holds the values of the project's custom fields.
+	CustomFields customfield.Values `json:"custom_fields"`
+	// BlockedBy lists the todos this todo depends on; Blocked is set while any of them is
+	// open. Both are filled in by WithBlockers.
+	BlockedBy []uuid.UUID `json:"blocked_by,omitempty"`
+	Blocked   bool        `json:"blocked"`
+}
+
+// Priorities range from PriorityNone to PriorityHigh.
+const (
+	PriorityNone = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+)
+
+// ExternalRef identifies the record a todo was imported from in another tool,
+// so that importing the same export again updates instead of duplicating.
+type ExternalRef struct {
+	Source string
+	ID     string
+}
+
+// Tombstone records a deleted todo so that sync clients can learn about the removal.
+type Tombstone struct {
+	ID        uuid.UUID `json:"id"`
+	UserID    uuid.UUID `json:"user_id"`
+	UID       string    `json:"uid"`
+	DeletedAt time.Time `json:"deleted_at"`
 }
 
 // CreateInput holds the payload required to create a todo.
 type CreateInput struct {
-	UserID      uuid.UUID  `json:"user_id" binding:"required"`
-	Title       string     `json:"title" binding:"required"`
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+	// Title may be left out when QuickAdd provides one.
+	Title       string     `json:"title"`
 	Description string     `json:"description"`
 	DueDate     *time.Time `json:"due_date"`
+	// StartDate hides the todo from default listings until then.
+	StartDate *time.Time `json:"start_date"`
+	Completed bool       `json:"completed"`
+	// Status defaults to the workflow's initial status, or its first done status for
+	// completed todos. When set, it decides whether the todo is completed.
+	Status    string     `json:"status"`
+	ProjectID *uuid.UUID `json:"project_id"`
+	// ParentID makes the todo a subtask of another todo of the same user.
+	ParentID *uuid.UUID `json:"parent_id"`
+	// AssigneeID must name a user who can see the todo: its owner or, in a project, a member.
+	AssigneeID      *uuid.UUID `json:"assignee_id"`
+	EstimateMinutes *int       `json:"estimate_minutes" binding:"omitempty,min=0"`
+	Labels          []string   `json:"labels"`
+	Priority        int        `json:"priority" binding:"min=0,max=3"`
+	// CustomFields must match the custom fields declared by the project.
+	CustomFields customfield.Values `json:"custom_fields"`
+	// Recurrence is an RRULE value within the subset accepted by quickadd.ValidateRecurrence.
+	Recurrence string `json:"recurrence"`
+	// QuickAdd is a natural-language description of the todo, such as
+	// "Pay rent every 1st of month !p1 #finance tomorrow 9am". See ApplyQuickAdd.
+	QuickAdd string `json:"quick_add"`
+	// ICalUID is set by calendar imports; it is not accepted from API clients.
+	ICalUID *string `json:"-"`
+	// External is set by third-party importers; it is not accepted from API clients.
+	External *ExternalRef `json:"-"`
+	// CompletedAt is the completion time known to imports. Completed todos created
+	// without it count as completed at creation.
+	CompletedAt *time.Time `json:"-"`
 }
 
 // UpdateInput allows partial updates to a todo.
 type UpdateInput struct {
-	Title        *string     `json:"title"`
-	Description  *string     `json:"description"`
-	DueDate      *time.Time  `json:"due_date"`
-	Completed    *bool       `json:"completed"`
-	ClearDueDate bool        `json:"clear_due_date"`
+	Title          *string    `json:"title"`
+	Description    *string    `json:"description"`
+	DueDate        *time.Time `json:"due_date"`
+	Completed      *bool      `json:"completed"`
+	Status         *string    `json:"status"`
+	ClearDueDate   bool       `json:"clear_due_date"`
+	StartDate      *time.Time `json:"start_date"`
+	ClearStartDate bool       `json:"clear_start_date"`
+	ProjectID      *uuid.UUID `json:"project_id"`
+	ClearProject   bool       `json:"clear_project"`
+	Labels         *[]string  `json:"labels"`
+	Priority       *int       `json:"priority" binding:"omitempty,min=0,max=3"`
+	// CustomFields is merged into the todo's values; null removes a value.
+	CustomFields customfield.Values `json:"custom_fields"`
+	// Recurrence replaces the recurrence rule; an empty string removes it.
+	Recurrence      *string    `json:"recurrence"`
+	AssigneeID      *uuid.UUID `json:"assignee_id"`
+	ClearAssignee   bool       `json:"clear_assignee"`
+	EstimateMinutes *int       `json:"estimate_minutes" binding:"omitempty,min=0"`
+	ClearEstimate   bool       `json:"clear_estimate"`
+	// ActorID is the user making the change, who is not notified of their own assignments.
+	// It is set by the handler.
+	ActorID uuid.UUID `json:"-"`
+	// IfVersion makes the update fail with ErrConflict unless the todo is still at this
+	// version. It is set by CalDAV's If-Match; API clients cannot send it.
+	IfVersion *int `json:"-"`
+}
+
+// IsEmpty reports whether the input leaves the todo unchanged.
+func (input UpdateInput) IsEmpty() bool {
+	return input.Title == nil &&
+		input.Description == nil &&
+		input.DueDate == nil &&
+		input.Completed == nil &&
+		input.Status == nil &&
+		!input.ClearDueDate &&
+		input.StartDate == nil &&
+		!input.ClearStartDate &&
+		input.ProjectID == nil &&
+		!input.ClearProject &&
+		input.Labels == nil &&
+		input.Priority == nil &&
+		input.CustomFields == nil &&
+		input.Recurrence == nil &&
+		input.AssigneeID == nil &&
+		!input.ClearAssignee &&
+		input.EstimateMinutes == nil &&
+		!input.ClearEstimate
 }
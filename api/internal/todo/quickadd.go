@@ -0,0 +1,61 @@
+package todo
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"overengineeredtodo/internal/quickadd"
+)
+
+// ApplyQuickAdd parses input.QuickAdd and fills in the fields it describes. Fields set
+// explicitly in the input win; labels are added to the explicit ones. A #project refers
+// to one of the user's projects by name, ignoring case.
+func (r *Repository) ApplyQuickAdd(ctx context.Context, input CreateInput, opts quickadd.Options) (CreateInput, quickadd.Result, error) {
+	result := quickadd.Parse(input.QuickAdd, opts)
+
+	if input.Title == "" {
+		input.Title = result.Title
+	}
+	if input.DueDate == nil {
+		input.DueDate = result.DueDate
+	}
+	if input.Recurrence == "" {
+		input.Recurrence = result.Recurrence
+	}
+	if input.Priority == PriorityNone && result.Priority != nil {
+		input.Priority = *result.Priority
+	}
+	input.Labels = NormalizeLabels(slices.Concat(input.Labels, result.Labels))
+
+	if input.ProjectID == nil && result.Project != "" {
+		id, err := r.projectByName(ctx, input.UserID, result.Project)
+		if err != nil {
+			return input, result, err
+		}
+		input.ProjectID = &id
+	}
+
+	return input, result, nil
+}
+
+// projectByName finds a project of the user with the given name, preferring an exact
+// match over one that differs in case and the user's own projects over shared ones.
+func (r *Repository) projectByName(ctx context.Context, userID uuid.UUID, name string) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := r.pool.QueryRow(ctx, `
+		SELECT id FROM projects
+		WHERE id IN (SELECT project_id FROM project_members WHERE user_id = $1) AND lower(name) = lower($2)
+		ORDER BY name = $2 DESC, user_id = $1 DESC
+		LIMIT 1`, userID, name).Scan(&id)
+	switch {
+	case err == pgx.ErrNoRows:
+		return uuid.Nil, ErrUnknownProject
+	case err != nil:
+		return uuid.Nil, fmt.Errorf("find project: %w", err)
+	}
+	return id, nil
+}
@@ -0,0 +1,78 @@
+package todo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+
+	"overengineeredtodo/internal/quickadd"
+)
+
+func TestApplyQuickAdd(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	userID := uuid.New()
+	projectID := uuid.New()
+	now := time.Date(2026, time.October, 14, 15, 30, 0, 0, time.UTC)
+
+	mock.ExpectQuery("SELECT id FROM projects WHERE id IN \\(SELECT project_id FROM project_members WHERE user_id = \\$1\\) AND lower\\(name\\) = lower\\(\\$2\\)").
+		WithArgs(userID, "finance").
+		WillReturnRows(pgxmock.NewRows([]string{"id"}).AddRow(projectID))
+
+	input, result, err := repo.ApplyQuickAdd(context.Background(), CreateInput{
+		UserID:   userID,
+		Labels:   []string{"bills"},
+		QuickAdd: "Pay rent every 1st of month !p1 #finance @home tomorrow 9am",
+	}, quickadd.Options{Now: now})
+	require.NoError(t, err)
+	require.Equal(t, "Pay rent", input.Title)
+	require.Equal(t, time.Date(2026, time.October, 15, 9, 0, 0, 0, time.UTC), *input.DueDate)
+	require.Equal(t, "FREQ=MONTHLY;BYMONTHDAY=1", input.Recurrence)
+	require.Equal(t, PriorityHigh, input.Priority)
+	require.Equal(t, []string{"bills", "home"}, input.Labels)
+	require.Equal(t, projectID, *input.ProjectID)
+	require.Equal(t, "finance", result.Project)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestApplyQuickAddKeepsExplicitFields(t *testing.T) {
+	repo := NewRepository(nil)
+	projectID := uuid.New()
+
+	input, _, err := repo.ApplyQuickAdd(context.Background(), CreateInput{
+		UserID:    uuid.New(),
+		Title:     "Explicit",
+		Priority:  PriorityLow,
+		ProjectID: &projectID,
+		QuickAdd:  "Something else !p1 #finance",
+	}, quickadd.Options{})
+	require.NoError(t, err)
+	require.Equal(t, "Explicit", input.Title)
+	require.Equal(t, PriorityLow, input.Priority)
+	require.Equal(t, projectID, *input.ProjectID)
+}
+
+func TestApplyQuickAddUnknownProject(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	userID := uuid.New()
+
+	mock.ExpectQuery("SELECT id FROM projects").
+		WithArgs(userID, "nowhere").
+		WillReturnError(pgx.ErrNoRows)
+
+	_, _, err = repo.ApplyQuickAdd(context.Background(), CreateInput{UserID: userID, QuickAdd: "Task #nowhere"}, quickadd.Options{})
+	require.ErrorIs(t, err, ErrUnknownProject)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -9,8 +9,20 @@ import (
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+
+	"overengineeredtodo/internal/access"
+	"overengineeredtodo/internal/customfield"
+	"overengineeredtodo/internal/outbox"
+	"overengineeredtodo/internal/quickadd"
+	"overengineeredtodo/internal/workflow"
 )
 
+// todoColumns lists the columns scanned by scanTodo, in order.
+const todoColumns = `id, user_id, title, description, due_date, completed, created_at, updated_at, ical_uid, version, project_id, labels, priority, completed_at, status, custom_fields, parent_id, recurrence, assignee_id, estimate_minutes, start_date, snoozed_until`
+
+// insertColumns lists the columns written by Create and CreateBatch, in insertArgs order.
+const insertColumns = `id, user_id, title, description, due_date, completed, ical_uid, project_id, labels, priority, external_source, external_id, completed_at, status, custom_fields, parent_id, recurrence, assignee_id, estimate_minutes, start_date`
+
 // Repository provides Cockroach-backed persistence for todos.
 type Repository struct {
 	pool pgxPool
@@ -20,6 +32,7 @@ type pgxPool interface {
 	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
 	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
 }
 
 // NewRepository constructs a repository around the supplied pgx pool.
@@ -30,55 +43,76 @@ func NewRepository(pool pgxPool) *Repository {
 // Create inserts a todo row.
 func (r *Repository) Create(ctx context.Context, input CreateInput) (Todo, error) {
 	query := `
-		INSERT INTO todos (id, user_id, title, description, due_date)
-		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id, user_id, title, description, due_date, completed, created_at, updated_at
-	`
+		INSERT INTO todos (` + insertColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
+		RETURNING ` + todoColumns
 
-	id := uuid.New()
-	var t Todo
+	if input.Recurrence != "" {
+		if err := quickadd.ValidateRecurrence(input.Recurrence); err != nil {
+			return Todo{}, err
+		}
+	}
 
-	if err := r.pool.QueryRow(ctx, query,
-		id,
-		input.UserID,
-		input.Title,
-		input.Description,
-		input.DueDate,
-	).Scan(
-		&t.ID,
-		&t.UserID,
-		&t.Title,
-		&t.Description,
-		&t.DueDate,
-		&t.Completed,
-		&t.CreatedAt,
-		&t.UpdatedAt,
-	); err != nil {
+	settings, err := r.settings(ctx, input.ProjectID)
+	if err != nil {
+		return Todo{}, err
+	}
+	if input, err = withStatus(settings.workflow, input); err != nil {
+		return Todo{}, err
+	}
+	if input, err = withCustomFields(settings.fields, input); err != nil {
+		return Todo{}, err
+	}
+	if input.ProjectID != nil {
+		if err := r.CheckProjectWrite(ctx, *input.ProjectID, input.UserID); err != nil {
+			return Todo{}, err
+		}
+	}
+	if input.ParentID != nil {
+		if err := r.checkParent(ctx, input.UserID, *input.ParentID); err != nil {
+			return Todo{}, err
+		}
+	}
+	if input.AssigneeID != nil {
+		if err := r.checkAssignee(ctx, input.UserID, input.ProjectID, *input.AssigneeID); err != nil {
+			return Todo{}, err
+		}
+	}
+
+	var assignedBy *uuid.UUID
+	if input.AssigneeID != nil && *input.AssigneeID != input.UserID {
+		assignedBy = &input.UserID
+	}
+	t, err := r.write(ctx, outbox.TodoCreated, assignedBy, query, insertArgs(uuid.New(), input)...)
+	if err != nil {
 		return Todo{}, fmt.Errorf("insert todo: %w", err)
 	}
 
 	return t, nil
 }
 
+// checkParent makes sure a new subtask's parent is a todo the user may edit.
+func (r *Repository) checkParent(ctx context.Context, userID, parentID uuid.UUID) error {
+	_, err := r.Authorize(ctx, parentID, userID, access.RoleEditor)
+	switch {
+	case err == ErrNotFound || err == ErrForbidden:
+		return ErrUnknownParent
+	case err != nil:
+		return fmt.Errorf("select parent todo: %w", err)
+	default:
+		return nil
+	}
+}
+
 // Get fetches a todo by id.
 func (r *Repository) Get(ctx context.Context, id uuid.UUID) (Todo, error) {
 	query := `
-		SELECT id, user_id, title, description, due_date, completed, created_at, updated_at
+		SELECT ` + todoColumns + `
 		FROM todos
 		WHERE id = $1
 	`
 
-	var t Todo
-	err := r.pool.QueryRow(ctx, query, id).Scan(
-		&t.ID,
-		&t.UserID,
-		&t.Title,
-		&t.Description,
-		&t.DueDate,
-		&t.Completed,
-		&t.CreatedAt,
-		&t.UpdatedAt,
-	)
+	t, err := scanTodo(r.pool.QueryRow(ctx, query, id))
 
 	switch {
 	case err == nil:
@@ -90,34 +124,106 @@ func (r *Repository) Get(ctx context.Context, id uuid.UUID) (Todo, error) {
 	}
 }
 
-// ListByUser returns todos scoped to a user.
-func (r *Repository) ListByUser(ctx context.Context, userID uuid.UUID) ([]Todo, error) {
+// FindByUID looks up a todo the user can see by its iCalendar UID, preferring the user's
+// own todos. Todos that were not imported are exported with their id as UID, so both are
+// matched.
+func (r *Repository) FindByUID(ctx context.Context, userID uuid.UUID, uid string) (Todo, error) {
 	query := `
-		SELECT id, user_id, title, description, due_date, completed, created_at, updated_at
+		SELECT ` + todoColumns + `
+		FROM todos
+		WHERE ` + VisibleTo("$1") + `
+		  AND (ical_uid = $2 OR id = $3)
+		ORDER BY user_id = $1 DESC
+		LIMIT 1
+	`
+
+	id, err := uuid.Parse(uid)
+	if err != nil {
+		id = uuid.Nil
+	}
+
+	t, err := scanTodo(r.pool.QueryRow(ctx, query, userID, uid, id))
+
+	switch {
+	case err == nil:
+		return t, nil
+	case err == pgx.ErrNoRows:
+		return Todo{}, ErrNotFound
+	default:
+		return Todo{}, fmt.Errorf("select todo by uid: %w", err)
+	}
+}
+
+// ListExternal returns the user's todos imported from source, keyed by their id in that source.
+func (r *Repository) ListExternal(ctx context.Context, userID uuid.UUID, source string) (map[string]Todo, error) {
+	query := `
+		SELECT external_id, ` + todoColumns + `
 		FROM todos
 		WHERE user_id = $1
+		  AND external_source = $2
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, source)
+	if err != nil {
+		return nil, fmt.Errorf("query imported todos: %w", err)
+	}
+	defer rows.Close()
+
+	result := make(map[string]Todo)
+	for rows.Next() {
+		var (
+			externalID string
+			t          Todo
+		)
+		if err := rows.Scan(append([]any{&externalID}, todoFields(&t)...)...); err != nil {
+			return nil, fmt.Errorf("scan imported todo: %w", err)
+		}
+		result[externalID] = normalize(t)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate imported todos: %w", rows.Err())
+	}
+
+	return result, nil
+}
+
+// ListByUser returns the todos the user can see: their own and those of shared projects.
+func (r *Repository) ListByUser(ctx context.Context, userID uuid.UUID) ([]Todo, error) {
+	query := `
+		SELECT ` + todoColumns + `
+		FROM todos
+		WHERE ` + VisibleTo("$1") + `
 		ORDER BY created_at DESC
 	`
 
-	rows, err := r.pool.Query(ctx, query, userID)
+	return r.list(ctx, query, userID)
+}
+
+// ListByProject returns the todos of a project the user is a member of.
+func (r *Repository) ListByProject(ctx context.Context, userID, projectID uuid.UUID) ([]Todo, error) {
+	query := `
+		SELECT ` + todoColumns + `
+		FROM todos
+		WHERE ` + VisibleTo("$1") + `
+		  AND project_id = $2
+		ORDER BY created_at DESC
+	`
+
+	return r.list(ctx, query, userID, projectID)
+}
+
+func (r *Repository) list(ctx context.Context, query string, args ...any) ([]Todo, error) {
+	rows, err := r.pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("query todos: %w", err)
 	}
 	defer rows.Close()
 
-	var result []Todo
+	result := []Todo{}
 	for rows.Next() {
-		var t Todo
-		if err := rows.Scan(
-			&t.ID,
-			&t.UserID,
-			&t.Title,
-			&t.Description,
-			&t.DueDate,
-			&t.Completed,
-			&t.CreatedAt,
-			&t.UpdatedAt,
-		); err != nil {
+		t, err := scanTodo(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scan todo: %w", err)
 		}
 		result = append(result, t)
@@ -130,12 +236,214 @@ func (r *Repository) ListByUser(ctx context.Context, userID uuid.UUID) ([]Todo,
 	return result, nil
 }
 
-// Update applies partial updates to a todo and returns the new state.
+// StreamByUser calls fn for each todo the user can see without buffering the result set.
+// Iteration stops at the first error returned by fn.
+func (r *Repository) StreamByUser(ctx context.Context, userID uuid.UUID, fn func(Todo) error) error {
+	query := `
+		SELECT ` + todoColumns + `
+		FROM todos
+		WHERE ` + VisibleTo("$1") + `
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("query todos: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		t, err := scanTodo(rows)
+		if err != nil {
+			return fmt.Errorf("scan todo: %w", err)
+		}
+		if err := fn(t); err != nil {
+			return err
+		}
+	}
+
+	if rows.Err() != nil {
+		return fmt.Errorf("iterate todos: %w", rows.Err())
+	}
+
+	return nil
+}
+
+// CreateBatch inserts several todos with a single multi-row statement. The result
+// follows the order of inputs. Unlike Create it does not check parent todos, which
+// callers create themselves.
+func (r *Repository) CreateBatch(ctx context.Context, inputs []CreateInput) ([]Todo, error) {
+	if len(inputs) == 0 {
+		return nil, nil
+	}
+
+	const columnsPerRow = 20
+	values := make([]string, 0, len(inputs))
+	args := make([]any, 0, len(inputs)*columnsPerRow)
+	order := make(map[uuid.UUID]int, len(inputs))
+	projects := make(map[uuid.UUID]projectSettings)
+
+	for i, input := range inputs {
+		settings := projectSettings{workflow: workflow.Default()}
+		if input.ProjectID != nil {
+			var ok bool
+			if settings, ok = projects[*input.ProjectID]; !ok {
+				var err error
+				if settings, err = r.settings(ctx, input.ProjectID); err != nil {
+					return nil, err
+				}
+				if err := r.CheckProjectWrite(ctx, *input.ProjectID, input.UserID); err != nil {
+					return nil, err
+				}
+				projects[*input.ProjectID] = settings
+			}
+		}
+
+		input, err := withStatus(settings.workflow, input)
+		if err != nil {
+			return nil, err
+		}
+		if input, err = withCustomFields(settings.fields, input); err != nil {
+			return nil, err
+		}
+
+		id := uuid.New()
+		order[id] = i
+
+		base := i * columnsPerRow
+		placeholders := make([]string, columnsPerRow)
+		for j := range placeholders {
+			placeholders[j] = fmt.Sprintf("$%d", base+j+1)
+		}
+		values = append(values, "("+strings.Join(placeholders, ", ")+")")
+		args = append(args, insertArgs(id, input)...)
+	}
+
+	query := `
+		INSERT INTO todos (` + insertColumns + `)
+		VALUES ` + strings.Join(values, ", ") + `
+		RETURNING ` + todoColumns
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("begin insert todos: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	result, err := insertBatch(ctx, tx, query, args, order)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordEvents(ctx, tx, outbox.TodoCreated, result...); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("commit insert todos: %w", err)
+	}
+	return result, nil
+}
+
+// insertBatch runs the insert of CreateBatch and returns the todos in the order of their
+// inputs, whose positions order maps the new ids to.
+func insertBatch(ctx context.Context, tx pgx.Tx, query string, args []any, order map[uuid.UUID]int) ([]Todo, error) {
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("insert todos: %w", err)
+	}
+	defer rows.Close()
+
+	result := make([]Todo, len(order))
+	for rows.Next() {
+		t, err := scanTodo(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan inserted todo: %w", err)
+		}
+		i, ok := order[t.ID]
+		if !ok {
+			return nil, fmt.Errorf("insert todos: unexpected id %s returned", t.ID)
+		}
+		result[i] = t
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("insert todos: %w", rows.Err())
+	}
+
+	return result, nil
+}
+
+// Update applies partial updates to a todo and returns the new state. Status changes,
+// completion changes and project moves are checked against the workflow of the todo's
+// (new) project; completed follows the resulting status. Custom field values are checked
+// against the project's custom fields, and values the new project does not declare are
+// dropped when the todo moves.
 func (r *Repository) Update(ctx context.Context, id uuid.UUID, input UpdateInput) (Todo, error) {
+	if input.Recurrence != nil && *input.Recurrence != "" {
+		if err := quickadd.ValidateRecurrence(*input.Recurrence); err != nil {
+			return Todo{}, err
+		}
+	}
+
 	setClauses := make([]string, 0, 5)
 	args := make([]any, 0, 5)
 	position := 1
 
+	var (
+		expectedStatus  *string
+		expectedVersion *int
+		current         Todo
+	)
+	if input.Status != nil || input.Completed != nil || input.ProjectID != nil || input.ClearProject || input.CustomFields != nil || input.AssigneeID != nil {
+		var err error
+		if current, err = r.Get(ctx, id); err != nil {
+			return Todo{}, err
+		}
+	}
+	if input.Status != nil || input.Completed != nil || input.ProjectID != nil || input.ClearProject || input.CustomFields != nil {
+		projectID := targetProject(current, input)
+		moved := !sameProject(projectID, current.ProjectID)
+		settings, err := r.settings(ctx, projectID)
+		if err != nil {
+			return Todo{}, err
+		}
+
+		status, err := nextStatus(settings.workflow, current, input, moved)
+		if err != nil {
+			return Todo{}, err
+		}
+
+		input.Completed = nil
+		if status.key != current.Status {
+			setClauses = append(setClauses, fmt.Sprintf("status = $%d", position))
+			args = append(args, status.key)
+			position++
+			expectedStatus = &current.Status
+		}
+		if status.done != current.Completed {
+			input.Completed = &status.done
+		}
+
+		if input.CustomFields != nil || moved {
+			patch, err := settings.fields.Check(input.CustomFields)
+			if err != nil {
+				return Todo{}, err
+			}
+			setClauses = append(setClauses, fmt.Sprintf("custom_fields = $%d", position))
+			args = append(args, customfield.Merge(settings.fields.Filter(current.CustomFields), patch))
+			position++
+			// Values are merged in Go, so a concurrent edit must not be overwritten.
+			expectedVersion = &current.Version
+		}
+	}
+
+	if input.IfVersion != nil {
+		if expectedVersion != nil && *expectedVersion != *input.IfVersion {
+			return Todo{}, ErrConflict
+		}
+		expectedVersion = input.IfVersion
+	}
+
 	if input.Title != nil {
 		setClauses = append(setClauses, fmt.Sprintf("title = $%d", position))
 		args = append(args, *input.Title)
@@ -149,7 +457,10 @@ func (r *Repository) Update(ctx context.Context, id uuid.UUID, input UpdateInput
 	}
 
 	if input.Completed != nil {
-		setClauses = append(setClauses, fmt.Sprintf("completed = $%d", position))
+		setClauses = append(setClauses,
+			fmt.Sprintf("completed = $%d", position),
+			fmt.Sprintf("completed_at = CASE WHEN $%d THEN COALESCE(completed_at, current_timestamp) END", position),
+		)
 		args = append(args, *input.Completed)
 		position++
 	}
@@ -162,32 +473,117 @@ func (r *Repository) Update(ctx context.Context, id uuid.UUID, input UpdateInput
 		position++
 	}
 
+	if input.ClearStartDate {
+		setClauses = append(setClauses, "start_date = NULL")
+	} else if input.StartDate != nil {
+		setClauses = append(setClauses, fmt.Sprintf("start_date = $%d", position))
+		args = append(args, *input.StartDate)
+		position++
+	}
+
+	if input.ClearProject {
+		setClauses = append(setClauses, "project_id = NULL")
+	} else if input.ProjectID != nil {
+		setClauses = append(setClauses, fmt.Sprintf("project_id = $%d", position))
+		args = append(args, *input.ProjectID)
+		position++
+	}
+
+	if input.Labels != nil {
+		setClauses = append(setClauses, fmt.Sprintf("labels = $%d", position))
+		args = append(args, NormalizeLabels(*input.Labels))
+		position++
+	}
+
+	if input.Priority != nil {
+		setClauses = append(setClauses, fmt.Sprintf("priority = $%d", position))
+		args = append(args, *input.Priority)
+		position++
+	}
+
+	if input.Recurrence != nil {
+		setClauses = append(setClauses, fmt.Sprintf("recurrence = $%d", position))
+		args = append(args, *input.Recurrence)
+		position++
+	}
+
+	notify := false
+	switch {
+	case input.ClearAssignee:
+		setClauses = append(setClauses, "assignee_id = NULL")
+	case input.AssigneeID != nil:
+		if err := r.checkAssignee(ctx, current.UserID, targetProject(current, input), *input.AssigneeID); err != nil {
+			return Todo{}, err
+		}
+		setClauses = append(setClauses, fmt.Sprintf("assignee_id = $%d", position))
+		args = append(args, *input.AssigneeID)
+		position++
+		notify = *input.AssigneeID != input.ActorID && (current.AssigneeID == nil || *current.AssigneeID != *input.AssigneeID)
+	case (input.ProjectID != nil || input.ClearProject) && current.AssigneeID != nil:
+		// Assignees who cannot follow the todo into its new project are dropped.
+		err := r.checkAssignee(ctx, current.UserID, targetProject(current, input), *current.AssigneeID)
+		switch {
+		case err == ErrUnknownAssignee:
+			setClauses = append(setClauses, "assignee_id = NULL")
+		case err != nil:
+			return Todo{}, err
+		}
+	}
+
+	if input.ClearEstimate {
+		setClauses = append(setClauses, "estimate_minutes = NULL")
+	} else if input.EstimateMinutes != nil {
+		setClauses = append(setClauses, fmt.Sprintf("estimate_minutes = $%d", position))
+		args = append(args, *input.EstimateMinutes)
+		position++
+	}
+
 	if len(setClauses) == 0 {
-		return r.Get(ctx, id)
+		t, err := r.Get(ctx, id)
+		if err == nil && input.IfVersion != nil && t.Version != *input.IfVersion {
+			return Todo{}, ErrConflict
+		}
+		return t, err
 	}
 
-	setClauses = append(setClauses, "updated_at = current_timestamp")
+	setClauses = append(setClauses, "updated_at = current_timestamp", "version = version + 1")
 	args = append(args, id)
+	where := fmt.Sprintf("id = $%d", position)
+
+	// Guard status changes against concurrent moves so that transitions are checked
+	// against the status they start from.
+	if expectedStatus != nil {
+		position++
+		args = append(args, *expectedStatus)
+		where += fmt.Sprintf(" AND status = $%d", position)
+	}
+	if expectedVersion != nil {
+		position++
+		args = append(args, *expectedVersion)
+		where += fmt.Sprintf(" AND version = $%d", position)
+	}
 
 	query := fmt.Sprintf(`
 		UPDATE todos
 		SET %s
-		WHERE id = $%d
-		RETURNING id, user_id, title, description, due_date, completed, created_at, updated_at
-	`, strings.Join(setClauses, ", "), position)
+		WHERE %s
+		RETURNING %s
+	`, strings.Join(setClauses, ", "), where, todoColumns)
 
-	var t Todo
-	if err := r.pool.QueryRow(ctx, query, args...).Scan(
-		&t.ID,
-		&t.UserID,
-		&t.Title,
-		&t.Description,
-		&t.DueDate,
-		&t.Completed,
-		&t.CreatedAt,
-		&t.UpdatedAt,
-	); err != nil {
+	eventType := outbox.TodoUpdated
+	if input.Completed != nil && *input.Completed {
+		eventType = outbox.TodoCompleted
+	}
+	var assignedBy *uuid.UUID
+	if notify {
+		assignedBy = &input.ActorID
+	}
+	t, err := r.write(ctx, eventType, assignedBy, query, args...)
+	if err != nil {
 		if err == pgx.ErrNoRows {
+			if expectedStatus != nil || expectedVersion != nil {
+				return Todo{}, ErrConflict
+			}
 			return Todo{}, ErrNotFound
 		}
 		return Todo{}, fmt.Errorf("update todo: %w", err)
@@ -196,28 +592,134 @@ func (r *Repository) Update(ctx context.Context, id uuid.UUID, input UpdateInput
 	return t, nil
 }
 
-// Delete removes a todo.
+// Delete removes a todo and leaves a tombstone behind for sync clients.
 func (r *Repository) Delete(ctx context.Context, id uuid.UUID) error {
-	tag, err := r.pool.Exec(ctx, `DELETE FROM todos WHERE id = $1`, id)
+	return r.delete(ctx, id, nil)
+}
+
+// DeleteVersion removes a todo like Delete, but only while it is still at version. A todo
+// changed or removed in the meantime fails with ErrConflict.
+func (r *Repository) DeleteVersion(ctx context.Context, id uuid.UUID, version int) error {
+	return r.delete(ctx, id, &version)
+}
+
+func (r *Repository) delete(ctx context.Context, id uuid.UUID, version *int) error {
+	where, args := "id = $1", []any{id}
+	if version != nil {
+		where += " AND version = $2"
+		args = append(args, *version)
+	}
+	query := `
+		WITH deleted AS (
+			DELETE FROM todos WHERE ` + where + `
+			RETURNING id, user_id, project_id, ical_uid, version
+		), tombstone AS (
+			UPSERT INTO todo_tombstones (id, user_id, project_id, uid, deleted_at)
+			SELECT id, user_id, project_id, COALESCE(ical_uid, id::STRING), now()
+			FROM deleted
+			RETURNING id
+		)
+		SELECT id, user_id, project_id, version FROM deleted
+	`
+
+	tx, err := r.pool.Begin(ctx)
 	if err != nil {
-		return fmt.Errorf("delete todo: %w", err)
+		return fmt.Errorf("begin delete todo: %w", err)
 	}
-	if tag.RowsAffected() == 0 {
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var deleted outbox.DeletedTodo
+	var current int64
+	err = tx.QueryRow(ctx, query, args...).Scan(&deleted.ID, &deleted.UserID, &deleted.ProjectID, &current)
+	switch {
+	case err == pgx.ErrNoRows && version != nil:
+		return ErrConflict
+	case err == pgx.ErrNoRows:
 		return ErrNotFound
+	case err != nil:
+		return fmt.Errorf("delete todo: %w", err)
+	}
+
+	// The deletion counts as one more change of the todo.
+	event, err := outbox.NewEvent(outbox.TodoDeleted, outbox.AggregateTodo, deleted.ID, current+1, deleted)
+	if err != nil {
+		return err
+	}
+	if err := outbox.Insert(ctx, tx, event); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit delete todo: %w", err)
 	}
 	return nil
 }
 
+// ListTombstonesSince returns the todos deleted after the supplied instant that the user
+// owned or could see through a project.
+func (r *Repository) ListTombstonesSince(ctx context.Context, userID uuid.UUID, since time.Time) ([]Tombstone, error) {
+	query := `
+		SELECT id, user_id, uid, deleted_at
+		FROM todo_tombstones
+		WHERE ` + tombstoneVisibleTo("$1") + `
+		  AND deleted_at > $2
+		ORDER BY deleted_at ASC
+	`
+
+	rows, err := r.pool.Query(ctx, query, userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("query tombstones: %w", err)
+	}
+	defer rows.Close()
+
+	var result []Tombstone
+	for rows.Next() {
+		var ts Tombstone
+		if err := rows.Scan(&ts.ID, &ts.UserID, &ts.UID, &ts.DeletedAt); err != nil {
+			return nil, fmt.Errorf("scan tombstone: %w", err)
+		}
+		result = append(result, ts)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate tombstones: %w", rows.Err())
+	}
+
+	return result, nil
+}
+
+// LatestChange returns the most recent modification or deletion time across the user's todos.
+// The zero time is returned when the user never had any todos.
+func (r *Repository) LatestChange(ctx context.Context, userID uuid.UUID) (time.Time, error) {
+	query := `
+		SELECT greatest(
+			COALESCE((SELECT max(updated_at) FROM todos WHERE ` + VisibleTo("$1") + `), 'epoch'::TIMESTAMPTZ),
+			COALESCE((SELECT max(deleted_at) FROM todo_tombstones WHERE ` + tombstoneVisibleTo("$1") + `), 'epoch'::TIMESTAMPTZ)
+		)
+	`
+
+	var latest time.Time
+	if err := r.pool.QueryRow(ctx, query, userID).Scan(&latest); err != nil {
+		return time.Time{}, fmt.Errorf("select latest change: %w", err)
+	}
+	if latest.Unix() == 0 {
+		return time.Time{}, nil
+	}
+
+	return latest, nil
+}
+
 // ListDueWithin returns incomplete todos that are due within the provided window.
 func (r *Repository) ListDueWithin(ctx context.Context, window time.Duration) ([]Todo, error) {
 	target := time.Now().Add(window)
 
 	query := `
-		SELECT id, user_id, title, description, due_date, completed, created_at, updated_at
+		SELECT ` + todoColumns + `
 		FROM todos
 		WHERE completed = FALSE
 		  AND due_date IS NOT NULL
 		  AND due_date <= $1
+		  AND (snoozed_until IS NULL OR snoozed_until <= now())
 		ORDER BY due_date ASC
 	`
 
@@ -229,17 +731,8 @@ func (r *Repository) ListDueWithin(ctx context.Context, window time.Duration) ([
 
 	var result []Todo
 	for rows.Next() {
-		var t Todo
-		if err := rows.Scan(
-			&t.ID,
-			&t.UserID,
-			&t.Title,
-			&t.Description,
-			&t.DueDate,
-			&t.Completed,
-			&t.CreatedAt,
-			&t.UpdatedAt,
-		); err != nil {
+		t, err := scanTodo(rows)
+		if err != nil {
 			return nil, fmt.Errorf("scan due todo: %w", err)
 		}
 		result = append(result, t)
@@ -251,3 +744,103 @@ func (r *Repository) ListDueWithin(ctx context.Context, window time.Duration) ([
 
 	return result, nil
 }
+
+// scanTodo reads a row selected with todoColumns.
+func scanTodo(row pgx.Row) (Todo, error) {
+	var t Todo
+	err := row.Scan(todoFields(&t)...)
+	return normalize(t), err
+}
+
+// todoFields returns scan destinations for todoColumns.
+func todoFields(t *Todo) []any {
+	return []any{
+		&t.ID,
+		&t.UserID,
+		&t.Title,
+		&t.Description,
+		&t.DueDate,
+		&t.Completed,
+		&t.CreatedAt,
+		&t.UpdatedAt,
+		&t.ICalUID,
+		&t.Version,
+		&t.ProjectID,
+		&t.Labels,
+		&t.Priority,
+		&t.CompletedAt,
+		&t.Status,
+		&t.CustomFields,
+		&t.ParentID,
+		&t.Recurrence,
+		&t.AssigneeID,
+		&t.EstimateMinutes,
+		&t.StartDate,
+		&t.SnoozedUntil,
+	}
+}
+
+func normalize(t Todo) Todo {
+	if t.Labels == nil {
+		t.Labels = []string{}
+	}
+	if t.CustomFields == nil {
+		t.CustomFields = customfield.Values{}
+	}
+	return t
+}
+
+// insertArgs returns the values for insertColumns.
+func insertArgs(id uuid.UUID, input CreateInput) []any {
+	var source, externalID *string
+	if input.External != nil {
+		source, externalID = &input.External.Source, &input.External.ID
+	}
+
+	completedAt := input.CompletedAt
+	if !input.Completed {
+		completedAt = nil
+	} else if completedAt == nil {
+		now := time.Now().UTC()
+		completedAt = &now
+	}
+
+	return []any{
+		id,
+		input.UserID,
+		input.Title,
+		input.Description,
+		input.DueDate,
+		input.Completed,
+		input.ICalUID,
+		input.ProjectID,
+		NormalizeLabels(input.Labels),
+		input.Priority,
+		source,
+		externalID,
+		completedAt,
+		input.Status,
+		customfield.Merge(nil, input.CustomFields),
+		input.ParentID,
+		input.Recurrence,
+		input.AssigneeID,
+		input.EstimateMinutes,
+		input.StartDate,
+	}
+}
+
+// NormalizeLabels trims labels and drops case-insensitive duplicates. It never returns nil so that
+// the NOT NULL labels column is satisfied.
+func NormalizeLabels(labels []string) []string {
+	result := make([]string, 0, len(labels))
+	seen := make(map[string]bool, len(labels))
+	for _, label := range labels {
+		label = strings.TrimSpace(label)
+		if label == "" || seen[strings.ToLower(label)] {
+			continue
+		}
+		seen[strings.ToLower(label)] = true
+		result = append(result, label)
+	}
+	return result
+}
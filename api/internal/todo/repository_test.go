@@ -2,6 +2,7 @@ package todo
 
 import (
 	"context"
+	"strings"
 	"testing"
 	"time"
 
@@ -9,8 +10,27 @@ import (
 	"github.com/jackc/pgx/v5"
 	pgxmock "github.com/pashagolub/pgxmock/v3"
 	"github.com/stretchr/testify/require"
+
+	"overengineeredtodo/internal/customfield"
+	"overengineeredtodo/internal/outbox"
+	"overengineeredtodo/internal/workflow"
 )
 
+// newTodoRows builds mock rows in todoColumns order.
+func newTodoRows(todos ...Todo) *pgxmock.Rows {
+	rows := pgxmock.NewRows(strings.Split(todoColumns, ", "))
+	for _, t := range todos {
+		rows.AddRow(t.ID, t.UserID, t.Title, t.Description, t.DueDate, t.Completed, t.CreatedAt, t.UpdatedAt, t.ICalUID, t.Version, t.ProjectID, t.Labels, t.Priority, t.CompletedAt, t.Status, t.CustomFields, t.ParentID, t.Recurrence, t.AssigneeID, t.EstimateMinutes, t.StartDate, t.SnoozedUntil)
+	}
+	return rows
+}
+
+// authorizeRows builds the mock row Authorize reads: the todo and the user's project role.
+func authorizeRows(t Todo, role *string) *pgxmock.Rows {
+	return pgxmock.NewRows(append(strings.Split(todoColumns, ", "), "role")).
+		AddRow(t.ID, t.UserID, t.Title, t.Description, t.DueDate, t.Completed, t.CreatedAt, t.UpdatedAt, t.ICalUID, t.Version, t.ProjectID, t.Labels, t.Priority, t.CompletedAt, t.Status, t.CustomFields, t.ParentID, t.Recurrence, t.AssigneeID, t.EstimateMinutes, t.StartDate, t.SnoozedUntil, role)
+}
+
 func TestRepositoryCreate(t *testing.T) {
 	mock, err := pgxmock.NewPool()
 	require.NoError(t, err)
@@ -25,13 +45,17 @@ func TestRepositoryCreate(t *testing.T) {
 	returnedID := uuid.New()
 	now := time.Now()
 
-	rows := pgxmock.NewRows([]string{
-		"id", "user_id", "title", "description", "due_date", "completed", "created_at", "updated_at",
-	}).AddRow(returnedID, input.UserID, input.Title, input.Description, nil, false, now, now)
+	rows := newTodoRows(Todo{
+		ID: returnedID, UserID: input.UserID, Title: input.Title, Description: input.Description, CreatedAt: now, UpdatedAt: now,
+	})
 
+	mock.ExpectBegin()
 	mock.ExpectQuery("INSERT INTO todos").
-		WithArgs(pgxmock.AnyArg(), input.UserID, input.Title, input.Description, input.DueDate).
+		WithArgs(pgxmock.AnyArg(), input.UserID, input.Title, input.Description, input.DueDate, input.Completed, input.ICalUID,
+			input.ProjectID, []string{}, input.Priority, (*string)(nil), (*string)(nil), (*time.Time)(nil), workflow.StatusBacklog, customfield.Values{}, (*uuid.UUID)(nil), "", (*uuid.UUID)(nil), (*int)(nil), (*time.Time)(nil)).
 		WillReturnRows(rows)
+	expectEvent(mock, outbox.TodoCreated, returnedID, 0)
+	mock.ExpectCommit()
 
 	todo, err := repo.Create(context.Background(), input)
 	require.NoError(t, err)
@@ -53,9 +77,7 @@ func TestRepositoryGet(t *testing.T) {
 	userID := uuid.New()
 	now := time.Now()
 
-	rows := pgxmock.NewRows([]string{
-		"id", "user_id", "title", "description", "due_date", "completed", "created_at", "updated_at",
-	}).AddRow(id, userID, "Title", "Desc", nil, false, now, now)
+	rows := newTodoRows(Todo{ID: id, UserID: userID, Title: "Title", Description: "Desc", CreatedAt: now, UpdatedAt: now})
 
 	mock.ExpectQuery("SELECT id, user_id, title, description, due_date, completed, created_at, updated_at").
 		WithArgs(id).
@@ -94,12 +116,12 @@ func TestRepositoryListByUser(t *testing.T) {
 	userID := uuid.New()
 	now := time.Now()
 
-	rows := pgxmock.NewRows([]string{
-		"id", "user_id", "title", "description", "due_date", "completed", "created_at", "updated_at",
-	}).AddRow(uuid.New(), userID, "A", "desc", nil, false, now, now).
-		AddRow(uuid.New(), userID, "B", "desc", nil, true, now.Add(-time.Hour), now.Add(-time.Hour))
+	rows := newTodoRows(
+		Todo{ID: uuid.New(), UserID: userID, Title: "A", Description: "desc", CreatedAt: now, UpdatedAt: now},
+		Todo{ID: uuid.New(), UserID: userID, Title: "B", Description: "desc", Completed: true, CreatedAt: now.Add(-time.Hour), UpdatedAt: now.Add(-time.Hour)},
+	)
 
-	mock.ExpectQuery("SELECT id, user_id, title, description, due_date, completed, created_at, updated_at FROM todos").
+	mock.ExpectQuery("SELECT id, user_id, title, description, due_date, completed, created_at, updated_at, ical_uid, version, project_id, labels, priority, completed_at, status, custom_fields, parent_id, recurrence, assignee_id, estimate_minutes, start_date, snoozed_until FROM todos").
 		WithArgs(userID).
 		WillReturnRows(rows)
 
@@ -120,13 +142,19 @@ func TestRepositoryUpdate(t *testing.T) {
 	completed := true
 	now := time.Now()
 
-	rows := pgxmock.NewRows([]string{
-		"id", "user_id", "title", "description", "due_date", "completed", "created_at", "updated_at",
-	}).AddRow(id, uuid.New(), title, "Desc", nil, completed, now, now)
+	userID := uuid.New()
+	current := newTodoRows(Todo{ID: id, UserID: userID, Title: "Old", Description: "Desc", Status: workflow.StatusInProgress, CreatedAt: now, UpdatedAt: now})
+	rows := newTodoRows(Todo{ID: id, UserID: userID, Title: title, Description: "Desc", Completed: completed, Status: workflow.StatusDone, CreatedAt: now, UpdatedAt: now})
 
-	mock.ExpectQuery("UPDATE todos SET").
-		WithArgs(title, completed, id).
+	mock.ExpectQuery("SELECT .* FROM todos WHERE id = \\$1").
+		WithArgs(id).
+		WillReturnRows(current)
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE todos SET status = \\$1, title = \\$2").
+		WithArgs(workflow.StatusDone, title, completed, id, workflow.StatusInProgress).
 		WillReturnRows(rows)
+	expectEvent(mock, outbox.TodoCompleted, id, 0)
+	mock.ExpectCommit()
 
 	updated, err := repo.Update(context.Background(), id, UpdateInput{
 		Title:     &title,
@@ -135,6 +163,50 @@ func TestRepositoryUpdate(t *testing.T) {
 	require.NoError(t, err)
 	require.Equal(t, title, updated.Title)
 	require.Equal(t, completed, updated.Completed)
+	require.Equal(t, workflow.StatusDone, updated.Status)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryUpdateInvalidTransition(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	id := uuid.New()
+	status := workflow.StatusReview
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT .* FROM todos WHERE id = \\$1").
+		WithArgs(id).
+		WillReturnRows(newTodoRows(Todo{ID: id, UserID: uuid.New(), Title: "Title", Status: workflow.StatusBacklog, CreatedAt: now, UpdatedAt: now}))
+
+	_, err = repo.Update(context.Background(), id, UpdateInput{Status: &status})
+	require.ErrorIs(t, err, ErrInvalidTransition)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryUpdateStatusConflict(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	id := uuid.New()
+	status := workflow.StatusInProgress
+	now := time.Now()
+
+	mock.ExpectQuery("SELECT .* FROM todos WHERE id = \\$1").
+		WithArgs(id).
+		WillReturnRows(newTodoRows(Todo{ID: id, UserID: uuid.New(), Title: "Title", Status: workflow.StatusBacklog, CreatedAt: now, UpdatedAt: now}))
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE todos SET status = \\$1").
+		WithArgs(workflow.StatusInProgress, id, workflow.StatusBacklog).
+		WillReturnError(pgx.ErrNoRows)
+	mock.ExpectRollback()
+
+	_, err = repo.Update(context.Background(), id, UpdateInput{Status: &status})
+	require.ErrorIs(t, err, ErrConflict)
 	require.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -148,13 +220,14 @@ func TestRepositoryUpdateClearDueDate(t *testing.T) {
 	desc := "Updated description"
 	now := time.Now()
 
-	rows := pgxmock.NewRows([]string{
-		"id", "user_id", "title", "description", "due_date", "completed", "created_at", "updated_at",
-	}).AddRow(id, uuid.New(), "Title", desc, nil, false, now, now)
+	rows := newTodoRows(Todo{ID: id, UserID: uuid.New(), Title: "Title", Description: desc, CreatedAt: now, UpdatedAt: now})
 
+	mock.ExpectBegin()
 	mock.ExpectQuery("UPDATE todos SET").
 		WithArgs(desc, id).
 		WillReturnRows(rows)
+	expectEvent(mock, outbox.TodoUpdated, id, 0)
+	mock.ExpectCommit()
 
 	updated, err := repo.Update(context.Background(), id, UpdateInput{
 		Description:  &desc,
@@ -174,9 +247,7 @@ func TestRepositoryUpdateNoFieldsFallsBackToGet(t *testing.T) {
 	id := uuid.New()
 	now := time.Now()
 
-	rows := pgxmock.NewRows([]string{
-		"id", "user_id", "title", "description", "due_date", "completed", "created_at", "updated_at",
-	}).AddRow(id, uuid.New(), "Title", "Desc", nil, false, now, now)
+	rows := newTodoRows(Todo{ID: id, UserID: uuid.New(), Title: "Title", Description: "Desc", CreatedAt: now, UpdatedAt: now})
 
 	mock.ExpectQuery("SELECT id, user_id, title, description, due_date, completed, created_at, updated_at").
 		WithArgs(id).
@@ -196,9 +267,12 @@ func TestRepositoryDelete(t *testing.T) {
 	repo := NewRepository(mock)
 	id := uuid.New()
 
-	mock.ExpectExec("DELETE FROM todos").
+	mock.ExpectBegin()
+	mock.ExpectQuery("WITH deleted AS \\(\\s+DELETE FROM todos").
 		WithArgs(id).
-		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+		WillReturnRows(pgxmock.NewRows([]string{"id", "user_id", "project_id", "version"}).AddRow(id, uuid.New(), (*uuid.UUID)(nil), int64(2)))
+	expectEvent(mock, outbox.TodoDeleted, id, 3)
+	mock.ExpectCommit()
 
 	err = repo.Delete(context.Background(), id)
 	require.NoError(t, err)
@@ -213,9 +287,11 @@ func TestRepositoryDeleteNotFound(t *testing.T) {
 	repo := NewRepository(mock)
 	id := uuid.New()
 
-	mock.ExpectExec("DELETE FROM todos").
+	mock.ExpectBegin()
+	mock.ExpectQuery("WITH deleted AS \\(\\s+DELETE FROM todos").
 		WithArgs(id).
-		WillReturnResult(pgxmock.NewResult("DELETE", 0))
+		WillReturnError(pgx.ErrNoRows)
+	mock.ExpectRollback()
 
 	err = repo.Delete(context.Background(), id)
 	require.ErrorIs(t, err, ErrNotFound)
@@ -230,13 +306,11 @@ func TestRepositoryListDueWithin(t *testing.T) {
 	repo := NewRepository(mock)
 	now := time.Now()
 
-	rows := pgxmock.NewRows([]string{
-		"id", "user_id", "title", "description", "due_date", "completed", "created_at", "updated_at",
-	})
 	due := now.Add(30 * time.Minute)
-	rows.AddRow(uuid.New(), uuid.New(), "Due soon", "desc", &due, false, now, now)
+	rows := newTodoRows(Todo{ID: uuid.New(), UserID: uuid.New(), Title: "Due soon", Description: "desc", DueDate: &due, CreatedAt: now, UpdatedAt: now})
 
-	mock.ExpectQuery("SELECT id, user_id, title, description, due_date, completed, created_at, updated_at FROM todos WHERE completed = FALSE").
+	mock.ExpectQuery("SELECT id, user_id, title, description, due_date, completed, created_at, updated_at, ical_uid, version, project_id, labels, priority, completed_at, status, custom_fields, parent_id, recurrence, assignee_id, estimate_minutes, start_date, snoozed_until FROM todos WHERE completed = FALSE " +
+		"AND due_date IS NOT NULL AND due_date <= \\$1 AND \\(snoozed_until IS NULL OR snoozed_until <= now\\(\\)\\) ORDER BY due_date ASC").
 		WithArgs(pgxmock.AnyArg()).
 		WillReturnRows(rows)
 
@@ -253,7 +327,7 @@ func TestRepositoryListDueWithinError(t *testing.T) {
 
 	repo := NewRepository(mock)
 
-	mock.ExpectQuery("SELECT id, user_id, title, description, due_date, completed, created_at, updated_at FROM todos WHERE completed = FALSE").
+	mock.ExpectQuery("SELECT id, user_id, title, description, due_date, completed, created_at, updated_at, ical_uid, version, project_id, labels, priority, completed_at, status, custom_fields, parent_id, recurrence, assignee_id, estimate_minutes, start_date, snoozed_until FROM todos WHERE completed = FALSE").
 		WithArgs(pgxmock.AnyArg()).
 		WillReturnError(pgx.ErrNoRows)
 
@@ -261,3 +335,61 @@ func TestRepositoryListDueWithinError(t *testing.T) {
 	require.Error(t, err)
 	require.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestRepositoryListTombstonesSince(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	userID := uuid.New()
+	since := time.Now().Add(-time.Hour)
+
+	rows := pgxmock.NewRows([]string{"id", "user_id", "uid", "deleted_at"}).
+		AddRow(uuid.New(), userID, "uid-1", since.Add(time.Minute))
+
+	mock.ExpectQuery("SELECT id, user_id, uid, deleted_at FROM todo_tombstones").
+		WithArgs(userID, since).
+		WillReturnRows(rows)
+
+	result, err := repo.ListTombstonesSince(context.Background(), userID, since)
+	require.NoError(t, err)
+	require.Len(t, result, 1)
+	require.Equal(t, "uid-1", result[0].UID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryLatestChangeWithoutTodos(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	userID := uuid.New()
+
+	mock.ExpectQuery("SELECT greatest").
+		WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"greatest"}).AddRow(time.Unix(0, 0)))
+
+	latest, err := repo.LatestChange(context.Background(), userID)
+	require.NoError(t, err)
+	require.True(t, latest.IsZero())
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryCreateRejectsForeignParent(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	parentID, userID := uuid.New(), uuid.New()
+
+	mock.ExpectQuery("SELECT .* FROM todos WHERE id = \\$1").
+		WithArgs(parentID, userID).
+		WillReturnRows(authorizeRows(Todo{ID: parentID, UserID: uuid.New()}, nil))
+
+	_, err = repo.Create(context.Background(), CreateInput{UserID: userID, Title: "Subtask", ParentID: &parentID})
+	require.ErrorIs(t, err, ErrUnknownParent)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,180 @@
+package todo
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultSearchLimit and MaxSearchLimit bound the number of search results.
+	DefaultSearchLimit = 20
+	MaxSearchLimit     = 100
+
+	// snippetWords is the length of a description snippet; snippetLead is the number of
+	// words kept before the first match.
+	snippetWords = 24
+	snippetLead  = 6
+)
+
+// SearchResult is a todo matching a search, with its rank and highlighted snippets.
+type SearchResult struct {
+	Todo       Todo       `json:"todo"`
+	Rank       float64    `json:"rank"`
+	Highlights Highlights `json:"highlights"`
+}
+
+// Highlights are HTML-escaped excerpts with matching words wrapped in <mark> tags. The
+// description excerpt is empty when only the title matched.
+type Highlights struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+}
+
+// Search returns the todos the user can see whose title or description contain all words of q,
+// best matches first. Every word also matches as a prefix, so "rep" finds "report".
+func (r *Repository) Search(ctx context.Context, userID uuid.UUID, q string, limit int) ([]SearchResult, error) {
+	terms := searchTerms(q)
+	if len(terms) == 0 {
+		return nil, ErrEmptySearch
+	}
+	if limit <= 0 || limit > MaxSearchLimit {
+		limit = DefaultSearchLimit
+	}
+
+	// The substring match keeps results for fragments inside words, which the full-text
+	// index does not see; such results rank below any full-text match.
+	query := `
+		SELECT ` + todoColumns + `, ts_rank(search_vector, to_tsquery('english', $2)) AS rank
+		FROM todos
+		WHERE ` + VisibleTo("$1") + `
+			AND (search_vector @@ to_tsquery('english', $2) OR title ILIKE $3 OR description ILIKE $3)
+		ORDER BY rank DESC, updated_at DESC
+		LIMIT $4`
+
+	rows, err := r.pool.Query(ctx, query, userID, tsQuery(terms), "%"+escapeLike(strings.TrimSpace(q))+"%", limit)
+	if err != nil {
+		return nil, fmt.Errorf("search todos: %w", err)
+	}
+	defer rows.Close()
+
+	result := []SearchResult{}
+	for rows.Next() {
+		var res SearchResult
+		if err := rows.Scan(append(todoFields(&res.Todo), &res.Rank)...); err != nil {
+			return nil, fmt.Errorf("scan todo: %w", err)
+		}
+		res.Todo = normalize(res.Todo)
+		res.Highlights = Highlights{
+			Title:       highlight(res.Todo.Title, terms, 0),
+			Description: highlight(res.Todo.Description, terms, snippetWords),
+		}
+		result = append(result, res)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate todos: %w", rows.Err())
+	}
+
+	return result, nil
+}
+
+// searchTerms splits q into lower-case words. Punctuation separates words, which also
+// keeps tsquery operators out of the terms.
+func searchTerms(q string) []string {
+	return strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// tsQuery requires every term, each as a prefix.
+func tsQuery(terms []string) string {
+	parts := make([]string, len(terms))
+	for i, t := range terms {
+		parts[i] = t + ":*"
+	}
+	return strings.Join(parts, " & ")
+}
+
+// word is the rune range of a word within a text.
+type word struct {
+	start, end int
+}
+
+// highlight escapes text and marks the words starting with one of terms. With a positive
+// maxWords the result is cut to that many words around the first match, with ellipses
+// where text was left out; text without a match then yields "".
+func highlight(text string, terms []string, maxWords int) string {
+	runes := []rune(text)
+
+	var words []word
+	for i := 0; i < len(runes); {
+		if !unicode.IsLetter(runes[i]) && !unicode.IsDigit(runes[i]) {
+			i++
+			continue
+		}
+		start := i
+		for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+			i++
+		}
+		words = append(words, word{start, i})
+	}
+
+	matches := make([]bool, len(words))
+	first := -1
+	for i, w := range words {
+		lower := strings.ToLower(string(runes[w.start:w.end]))
+		for _, t := range terms {
+			if strings.HasPrefix(lower, t) {
+				matches[i] = true
+				if first < 0 {
+					first = i
+				}
+				break
+			}
+		}
+	}
+
+	from, to := 0, len(words)
+	if maxWords > 0 {
+		if first < 0 {
+			return ""
+		}
+		// Start a few words before the match, but keep the window full near the end.
+		from = max(0, min(first-min(snippetLead, maxWords-1), len(words)-maxWords))
+		to = min(len(words), from+maxWords)
+	}
+
+	var b strings.Builder
+	pos := 0
+	if from > 0 {
+		b.WriteString("…")
+		pos = words[from].start
+	}
+	for i := from; i < to; i++ {
+		w := words[i]
+		b.WriteString(html.EscapeString(string(runes[pos:w.start])))
+		if matches[i] {
+			b.WriteString("<mark>" + html.EscapeString(string(runes[w.start:w.end])) + "</mark>")
+		} else {
+			b.WriteString(html.EscapeString(string(runes[w.start:w.end])))
+		}
+		pos = w.end
+	}
+	if to < len(words) {
+		b.WriteString("…")
+	} else {
+		b.WriteString(html.EscapeString(string(runes[pos:])))
+	}
+
+	return b.String()
+}
+
+// escapeLike escapes the wildcards of LIKE patterns.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,69 @@
+package todo
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepositorySearch(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	userID := uuid.New()
+	now := time.Now()
+
+	td := Todo{ID: uuid.New(), UserID: userID, Title: "Quarterly report", Description: "Send the <draft> report to finance", CreatedAt: now, UpdatedAt: now}
+	rows := pgxmock.NewRows(append(strings.Split(todoColumns, ", "), "rank")).
+		AddRow(td.ID, td.UserID, td.Title, td.Description, td.DueDate, td.Completed, td.CreatedAt, td.UpdatedAt, td.ICalUID, td.Version, td.ProjectID, td.Labels, td.Priority, td.CompletedAt, td.Status, td.CustomFields, td.ParentID, td.Recurrence, td.AssigneeID, td.EstimateMinutes, td.StartDate, td.SnoozedUntil, 0.6)
+
+	mock.ExpectQuery("search_vector @@ to_tsquery\\('english', \\$2\\) OR title ILIKE \\$3 OR description ILIKE \\$3\\)").
+		WithArgs(userID, "quarterly:* & rep:*", "%Quarterly REP%", DefaultSearchLimit).
+		WillReturnRows(rows)
+
+	results, err := repo.Search(context.Background(), userID, " Quarterly REP", 0)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.InDelta(t, 0.6, results[0].Rank, 0.001)
+	require.Equal(t, "<mark>Quarterly</mark> <mark>report</mark>", results[0].Highlights.Title)
+	require.Equal(t, "Send the &lt;draft&gt; <mark>report</mark> to finance", results[0].Highlights.Description)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositorySearchRejectsEmptyQuery(t *testing.T) {
+	repo := NewRepository(nil)
+
+	_, err := repo.Search(context.Background(), uuid.New(), " & :* ", 10)
+	require.ErrorIs(t, err, ErrEmptySearch)
+}
+
+func TestHighlight(t *testing.T) {
+	long := "one two three four five six seven eight nine ten match eleven twelve"
+	tests := []struct {
+		name     string
+		text     string
+		terms    []string
+		maxWords int
+		want     string
+	}{
+		{"prefix", "Reporting done.", []string{"rep"}, 0, "<mark>Reporting</mark> done."},
+		{"no match keeps title", "Buy milk", []string{"rep"}, 0, "Buy milk"},
+		{"no match drops snippet", "Buy milk", []string{"rep"}, 5, ""},
+		{"window", long, []string{"match"}, 4, "…eight nine ten <mark>match</mark>…"},
+		{"window at end", long, []string{"twelve"}, 4, "…ten match eleven <mark>twelve</mark>"},
+		{"case and unicode", "Straße fegen", []string{"straße"}, 0, "<mark>Straße</mark> fegen"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, highlight(tt.text, tt.terms, tt.maxWords))
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"overengineeredtodo/internal/outbox"
+)
+
+// notHidden is the condition leaving out todos that are deferred to a later start date or
+// snoozed. Both reappear on their own once the time has passed.
+const notHidden = "(start_date IS NULL OR start_date <= now()) AND (snoozed_until IS NULL OR snoozed_until <= now())"
+
+// ErrInvalidSnooze indicates a snooze that does not end in the future.
+var ErrInvalidSnooze = errors.New("snooze must end in the future")
+
+// SnoozeInput tells how long to snooze a todo: until a time or for a duration such as
+// "90m", "3h" or "2d".
+type SnoozeInput struct {
+	Until    *time.Time `json:"until"`
+	Duration string     `json:"duration"`
+}
+
+// End returns when a snooze starting at now ends.
+func (input SnoozeInput) End(now time.Time) (time.Time, error) {
+	var end time.Time
+	switch {
+	case input.Until != nil && input.Duration != "":
+		return time.Time{}, errors.New("give either until or duration")
+	case input.Until != nil:
+		end = *input.Until
+	case input.Duration != "":
+		d, err := parseSnoozeDuration(input.Duration)
+		if err != nil {
+			return time.Time{}, err
+		}
+		end = now.Add(d)
+	default:
+		return time.Time{}, errors.New("until or duration is required")
+	}
+
+	if !end.After(now) {
+		return time.Time{}, ErrInvalidSnooze
+	}
+	return end, nil
+}
+
+// parseSnoozeDuration reads Go durations and, as snoozes are often counted in days,
+// whole days such as "2d".
+func parseSnoozeDuration(raw string) (time.Duration, error) {
+	raw = strings.TrimSpace(raw)
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", raw)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q", raw)
+	}
+	return d, nil
+}
+
+// Snooze hides the todo from default listings and due reminders until the given time. A
+// nil until wakes the todo up again.
+func (r *Repository) Snooze(ctx context.Context, id uuid.UUID, until *time.Time) (Todo, error) {
+	query := `
+		UPDATE todos
+		SET snoozed_until = $2, updated_at = current_timestamp, version = version + 1
+		WHERE id = $1
+		RETURNING ` + todoColumns
+
+	t, err := r.write(ctx, outbox.TodoUpdated, nil, query, id, until)
+	switch {
+	case err == nil:
+		return t, nil
+	case err == pgx.ErrNoRows:
+		return Todo{}, ErrNotFound
+	default:
+		return Todo{}, fmt.Errorf("snooze todo: %w", err)
+	}
+}
@@ -0,0 +1,104 @@
+package todo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+
+	"overengineeredtodo/internal/outbox"
+)
+
+// notHiddenPattern matches the condition List adds to leave out deferred and snoozed todos.
+const notHiddenPattern = "\\(start_date IS NULL OR start_date <= now\\(\\)\\) AND \\(snoozed_until IS NULL OR snoozed_until <= now\\(\\)\\)"
+
+func TestSnoozeInputEnd(t *testing.T) {
+	now := time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC)
+	later := now.Add(time.Hour)
+
+	end, err := SnoozeInput{Duration: "2d"}.End(now)
+	require.NoError(t, err)
+	require.Equal(t, now.AddDate(0, 0, 2), end)
+
+	end, err = SnoozeInput{Duration: "90m"}.End(now)
+	require.NoError(t, err)
+	require.Equal(t, now.Add(90*time.Minute), end)
+
+	end, err = SnoozeInput{Until: &later}.End(now)
+	require.NoError(t, err)
+	require.Equal(t, later, end)
+
+	earlier := now.Add(-time.Minute)
+	_, err = SnoozeInput{Until: &earlier}.End(now)
+	require.ErrorIs(t, err, ErrInvalidSnooze)
+
+	_, err = SnoozeInput{Duration: "-1h"}.End(now)
+	require.ErrorIs(t, err, ErrInvalidSnooze)
+
+	_, err = SnoozeInput{Duration: "soon"}.End(now)
+	require.Error(t, err)
+
+	_, err = SnoozeInput{Until: &later, Duration: "1h"}.End(now)
+	require.Error(t, err)
+
+	_, err = SnoozeInput{}.End(now)
+	require.Error(t, err)
+}
+
+func TestRepositorySnooze(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	now := time.Now()
+	until := now.Add(24 * time.Hour)
+	snoozed := Todo{ID: uuid.New(), UserID: uuid.New(), Title: "Renew passport", SnoozedUntil: &until, Version: 2, CreatedAt: now, UpdatedAt: now}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE todos SET snoozed_until = \\$2, updated_at = current_timestamp, version = version \\+ 1 WHERE id = \\$1").
+		WithArgs(snoozed.ID, &until).
+		WillReturnRows(newTodoRows(snoozed))
+	expectEvent(mock, outbox.TodoUpdated, snoozed.ID, 2)
+	mock.ExpectCommit()
+
+	result, err := NewRepository(mock).Snooze(context.Background(), snoozed.ID, &until)
+	require.NoError(t, err)
+	require.Equal(t, until, *result.SnoozedUntil)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositorySnoozeNotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	id := uuid.New()
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE todos SET snoozed_until").
+		WithArgs(id, (*time.Time)(nil)).
+		WillReturnError(pgx.ErrNoRows)
+	mock.ExpectRollback()
+
+	_, err = NewRepository(mock).Snooze(context.Background(), id, nil)
+	require.ErrorIs(t, err, ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryListIncludeHidden(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	mock.ExpectQuery("WHERE user_id = \\$1\\)\\) ORDER BY created_at DESC").
+		WithArgs(userID).
+		WillReturnRows(newTodoRows())
+
+	_, err = NewRepository(mock).List(context.Background(), userID, ListOptions{IncludeHidden: true})
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,173 @@
+package todo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+
+	"overengineeredtodo/internal/customfield"
+	"overengineeredtodo/internal/workflow"
+)
+
+// projectSettings is the per-project configuration todos are checked against.
+type projectSettings struct {
+	workflow workflow.Workflow
+	fields   customfield.Schema
+}
+
+// settings loads the workflow and custom fields of the project. Todos outside projects
+// follow the default workflow and have no custom fields.
+func (r *Repository) settings(ctx context.Context, projectID *uuid.UUID) (projectSettings, error) {
+	if projectID == nil {
+		return projectSettings{workflow: workflow.Default()}, nil
+	}
+
+	var (
+		custom *workflow.Workflow
+		fields customfield.Schema
+	)
+	err := r.pool.QueryRow(ctx, `SELECT workflow, custom_fields FROM projects WHERE id = $1`, *projectID).Scan(&custom, &fields)
+	switch {
+	case err == pgx.ErrNoRows:
+		return projectSettings{}, ErrUnknownProject
+	case err != nil:
+		return projectSettings{}, fmt.Errorf("select project settings: %w", err)
+	case custom == nil:
+		return projectSettings{workflow: workflow.Default(), fields: fields}, nil
+	default:
+		return projectSettings{workflow: *custom, fields: fields}, nil
+	}
+}
+
+// Workflow returns the workflow governing todos of the project: its custom workflow,
+// or the default one for projects without a custom workflow and todos outside projects.
+func (r *Repository) Workflow(ctx context.Context, projectID *uuid.UUID) (workflow.Workflow, error) {
+	s, err := r.settings(ctx, projectID)
+	return s.workflow, err
+}
+
+// withStatus fills in the status of a new todo and derives completed from it.
+func withStatus(wf workflow.Workflow, input CreateInput) (CreateInput, error) {
+	if input.Status == "" {
+		input.Status = wf.Fallback(input.Completed)
+		return input, nil
+	}
+	if !wf.Has(input.Status) {
+		return input, fmt.Errorf("%w %q", ErrUnknownStatus, input.Status)
+	}
+	input.Completed = wf.IsDone(input.Status)
+	return input, nil
+}
+
+// statusChange is the status a todo ends up in after an update.
+type statusChange struct {
+	key  string
+	done bool
+}
+
+// nextStatus works out the status after applying input to current under wf, the workflow
+// of the todo's (new) project. An explicit status must be reachable from the current one;
+// a completed flag moves the todo along the first allowed transition into a done or an
+// open status. A todo that moved to a project whose workflow lacks its status is reset to
+// that workflow's initial (or done) status.
+func nextStatus(wf workflow.Workflow, current Todo, input UpdateInput, moved bool) (statusChange, error) {
+	from := current.Status
+	to := from
+	switch {
+	case input.Status != nil:
+		to = *input.Status
+		if !wf.Has(to) {
+			return statusChange{}, fmt.Errorf("%w %q", ErrUnknownStatus, to)
+		}
+		if input.Completed != nil && *input.Completed != wf.IsDone(to) {
+			return statusChange{}, fmt.Errorf("%w: completed does not match status %q", ErrInvalidTransition, to)
+		}
+		if !moved && !wf.CanTransition(from, to) {
+			return statusChange{}, fmt.Errorf("%w from %q to %q", ErrInvalidTransition, from, to)
+		}
+	case moved && !wf.Has(from):
+		completed := current.Completed
+		if input.Completed != nil {
+			completed = *input.Completed
+		}
+		to = wf.Fallback(completed)
+	case input.Completed != nil && *input.Completed != current.Completed:
+		var ok bool
+		if *input.Completed {
+			to, ok = wf.Complete(from)
+		} else {
+			to, ok = wf.Reopen(from)
+		}
+		if !ok {
+			return statusChange{}, fmt.Errorf("%w: %q has no transition to a %s status", ErrInvalidTransition, from, openOrDone(*input.Completed))
+		}
+	}
+
+	return statusChange{key: to, done: wf.IsDone(to)}, nil
+}
+
+// targetProject returns the project the todo belongs to after applying input.
+func targetProject(current Todo, input UpdateInput) *uuid.UUID {
+	switch {
+	case input.ClearProject:
+		return nil
+	case input.ProjectID != nil:
+		return input.ProjectID
+	default:
+		return current.ProjectID
+	}
+}
+
+func sameProject(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func openOrDone(done bool) string {
+	if done {
+		return "done"
+	}
+	return "open"
+}
+
+// Board is a kanban view of todos grouped by workflow status.
+type Board struct {
+	Workflow workflow.Workflow `json:"workflow"`
+	Columns  []BoardColumn     `json:"columns"`
+}
+
+// BoardColumn holds the todos in one status.
+type BoardColumn struct {
+	Status workflow.Status `json:"status"`
+	Todos  []Todo          `json:"todos"`
+}
+
+// NewBoard groups todos into one column per workflow status, in workflow order. Todos in
+// statuses the workflow no longer knows get trailing columns of their own.
+func NewBoard(wf workflow.Workflow, todos []Todo) Board {
+	board := Board{Workflow: wf, Columns: make([]BoardColumn, 0, len(wf.Statuses))}
+	index := make(map[string]int, len(wf.Statuses))
+	for i, s := range wf.Statuses {
+		index[s.Key] = i
+		board.Columns = append(board.Columns, BoardColumn{Status: s, Todos: []Todo{}})
+	}
+
+	for _, t := range todos {
+		i, ok := index[t.Status]
+		if !ok {
+			i = len(board.Columns)
+			index[t.Status] = i
+			board.Columns = append(board.Columns, BoardColumn{
+				Status: workflow.Status{Key: t.Status, Name: t.Status, Done: t.Completed},
+				Todos:  []Todo{},
+			})
+		}
+		board.Columns[i].Todos = append(board.Columns[i].Todos, t)
+	}
+
+	return board
+}
@@ -0,0 +1,44 @@
+package todo
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"overengineeredtodo/internal/workflow"
+)
+
+func TestNewBoard(t *testing.T) {
+	wf := workflow.Default()
+	todos := []Todo{
+		{ID: uuid.New(), Title: "A", Status: workflow.StatusInProgress},
+		{ID: uuid.New(), Title: "B", Status: workflow.StatusDone, Completed: true},
+		{ID: uuid.New(), Title: "C", Status: "blocked"},
+		{ID: uuid.New(), Title: "D", Status: workflow.StatusInProgress},
+	}
+
+	board := NewBoard(wf, todos)
+	require.Len(t, board.Columns, len(wf.Statuses)+1)
+	require.Equal(t, workflow.StatusBacklog, board.Columns[0].Status.Key)
+	require.Empty(t, board.Columns[0].Todos)
+	require.Len(t, board.Columns[1].Todos, 2)
+	require.Len(t, board.Columns[3].Todos, 1)
+	require.Equal(t, "blocked", board.Columns[4].Status.Key)
+	require.Equal(t, "C", board.Columns[4].Todos[0].Title)
+}
+
+func TestWithStatus(t *testing.T) {
+	wf := workflow.Default()
+
+	input, err := withStatus(wf, CreateInput{Completed: true})
+	require.NoError(t, err)
+	require.Equal(t, workflow.StatusDone, input.Status)
+
+	input, err = withStatus(wf, CreateInput{Status: workflow.StatusReview})
+	require.NoError(t, err)
+	require.False(t, input.Completed)
+
+	_, err = withStatus(wf, CreateInput{Status: "shipped"})
+	require.ErrorIs(t, err, ErrUnknownStatus)
+}
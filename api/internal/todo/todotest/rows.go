@@ -0,0 +1,57 @@
+// Package todotest provides mock rows of todos for the tests of packages that read todos
+// through a todo.Repository.
+package todotest
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+
+	"overengineeredtodo/internal/customfield"
+	"overengineeredtodo/internal/todo"
+	"overengineeredtodo/internal/workflow"
+)
+
+// Columns are the todo columns in the order the repository scans them.
+var Columns = strings.Split(strings.ReplaceAll(todo.ReturningColumns, "todos.", ""), ", ")
+
+// Rows builds mock rows holding the todos.
+func Rows(todos ...todo.Todo) *pgxmock.Rows {
+	rows := pgxmock.NewRows(Columns)
+	for _, t := range todos {
+		rows.AddRow(values(t)...)
+	}
+	return rows
+}
+
+// AuthorizeRows builds the row todo.Repository.Authorize reads: the todo and the caller's
+// project role, nil for the todo's owner.
+func AuthorizeRows(t todo.Todo, role *string) *pgxmock.Rows {
+	return pgxmock.NewRows(slices.Concat(Columns, []string{"role"})).AddRow(append(values(t), role)...)
+}
+
+// OwnRow is the row todo.Repository.Authorize reads for a personal todo of userID.
+func OwnRow(id, userID uuid.UUID) *pgxmock.Rows {
+	now := time.Now()
+	return AuthorizeRows(todo.Todo{
+		ID:           id,
+		UserID:       userID,
+		Title:        "Todo",
+		Version:      1,
+		Labels:       []string{},
+		Status:       workflow.StatusBacklog,
+		CustomFields: customfield.Values{},
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}, nil)
+}
+
+func values(t todo.Todo) []any {
+	return []any{
+		t.ID, t.UserID, t.Title, t.Description, t.DueDate, t.Completed, t.CreatedAt, t.UpdatedAt, t.ICalUID, t.Version, t.ProjectID,
+		t.Labels, t.Priority, t.CompletedAt, t.Status, t.CustomFields, t.ParentID, t.Recurrence, t.AssigneeID, t.EstimateMinutes, t.StartDate, t.SnoozedUntil,
+	}
+}
@@ -0,0 +1,165 @@
+package todo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"overengineeredtodo/internal/outbox"
+)
+
+// userCheckTimeout bounds asking the user service about the owner of a new todo, so that
+// creating todos does not stall while the service is down.
+const userCheckTimeout = 500 * time.Millisecond
+
+// Users tells whether users exist. The user service's client satisfies it.
+type Users interface {
+	// Exists reports whether the user exists; an error means it could not be told.
+	Exists(ctx context.Context, id uuid.UUID) (bool, error)
+	// Forget drops what is remembered about a user that was deleted.
+	Forget(id uuid.UUID)
+}
+
+// checkUser fails with ErrUnknownUser when the user service does not know the user. When
+// the service cannot tell, the check is skipped and logged, so that todos can still be
+// written while it is down.
+func checkUser(ctx context.Context, users Users, logger *slog.Logger, id uuid.UUID) error {
+	ctx, cancel := context.WithTimeout(ctx, userCheckTimeout)
+	defer cancel()
+
+	exists, err := users.Exists(ctx, id)
+	switch {
+	case err != nil:
+		logger.Warn("user service unavailable, skipping the user check",
+			slog.String("user_id", id.String()), slog.String("error", err.Error()))
+	case !exists:
+		return ErrUnknownUser
+	}
+	return nil
+}
+
+// PurgeUser takes care of the todos of a deleted user, recording an event for each, and
+// reports how many todos changed. The user's todos in projects owned by someone else move
+// to their assignee or the project's owner, todos assigned to the user become unassigned
+// and the user's other todos are deleted.
+func (r *Repository) PurgeUser(ctx context.Context, userID uuid.UUID) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin purge user: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	// iCalendar UIDs and import keys are unique per user; ones the new owner already uses
+	// are dropped so the todos keep their ids instead.
+	heir := `COALESCE(NULLIF(todos.assignee_id, $1), p.user_id)`
+	rows, err := tx.Query(ctx, `
+		UPDATE todos
+		SET user_id = `+heir+`,
+		    ical_uid = CASE
+		        WHEN EXISTS (SELECT 1 FROM todos o WHERE o.user_id = `+heir+` AND o.ical_uid = todos.ical_uid)
+		        THEN NULL
+		        ELSE todos.ical_uid
+		    END,
+		    external_id = CASE
+		        WHEN EXISTS (
+		            SELECT 1 FROM todos o
+		            WHERE o.user_id = `+heir+` AND o.external_source = todos.external_source AND o.external_id = todos.external_id
+		        )
+		        THEN NULL
+		        ELSE todos.external_id
+		    END,
+		    updated_at = current_timestamp,
+		    version = todos.version + 1
+		FROM projects p
+		WHERE p.id = todos.project_id AND todos.user_id = $1 AND p.user_id <> $1
+		RETURNING `+ReturningColumns, userID)
+	if err != nil {
+		return 0, fmt.Errorf("reassign todos: %w", err)
+	}
+	reassigned, err := collectTodos(rows)
+	if err != nil {
+		return 0, err
+	}
+	if err := recordEvents(ctx, tx, outbox.TodoUpdated, reassigned...); err != nil {
+		return 0, err
+	}
+
+	rows, err = tx.Query(ctx, `
+		UPDATE todos
+		SET assignee_id = NULL, updated_at = current_timestamp, version = version + 1
+		WHERE assignee_id = $1 AND user_id <> $1
+		RETURNING `+todoColumns, userID)
+	if err != nil {
+		return 0, fmt.Errorf("unassign todos: %w", err)
+	}
+	unassigned, err := collectTodos(rows)
+	if err != nil {
+		return 0, err
+	}
+	if err := recordEvents(ctx, tx, outbox.TodoUpdated, unassigned...); err != nil {
+		return 0, err
+	}
+
+	rows, err = tx.Query(ctx, `DELETE FROM todos WHERE user_id = $1 RETURNING id, project_id, version`, userID)
+	if err != nil {
+		return 0, fmt.Errorf("delete todos: %w", err)
+	}
+	var deleted []outbox.Event
+	for rows.Next() {
+		gone := outbox.DeletedTodo{UserID: userID}
+		var version int64
+		if err := rows.Scan(&gone.ID, &gone.ProjectID, &version); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scan deleted todo: %w", err)
+		}
+		event, err := outbox.NewEvent(outbox.TodoDeleted, outbox.AggregateTodo, gone.ID, version+1, gone)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		deleted = append(deleted, event)
+	}
+	rows.Close()
+	if rows.Err() != nil {
+		return 0, fmt.Errorf("iterate deleted todos: %w", rows.Err())
+	}
+	if err := outbox.Insert(ctx, tx, deleted...); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("commit purge user: %w", err)
+	}
+	return len(reassigned) + len(unassigned) + len(deleted), nil
+}
+
+// UserSink is an outbox.Sink that purges the todos of deleted users and forgets them.
+type UserSink struct {
+	repo  *Repository
+	users Users
+}
+
+// NewUserSink constructs a UserSink.
+func NewUserSink(repo *Repository, users Users) *UserSink {
+	return &UserSink{repo: repo, users: users}
+}
+
+// Publish reacts to UserDeleted events and ignores all others. Purging is idempotent, so
+// events delivered twice do no harm.
+func (s *UserSink) Publish(ctx context.Context, e outbox.Event) error {
+	if e.Type != outbox.UserDeleted {
+		return nil
+	}
+
+	var deleted outbox.DeletedUser
+	if err := json.Unmarshal(e.Payload, &deleted); err != nil {
+		return fmt.Errorf("decode %s payload: %w", e.Type, err)
+	}
+	s.users.Forget(deleted.ID)
+	_, err := s.repo.PurgeUser(ctx, deleted.ID)
+	return err
+}
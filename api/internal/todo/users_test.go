@@ -0,0 +1,114 @@
+package todo
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+
+	"overengineeredtodo/internal/outbox"
+)
+
+type fakeUsers struct {
+	missing   map[uuid.UUID]bool
+	forgotten []uuid.UUID
+}
+
+func (f *fakeUsers) Exists(_ context.Context, id uuid.UUID) (bool, error) {
+	return !f.missing[id], nil
+}
+
+func (f *fakeUsers) Forget(id uuid.UUID) {
+	f.forgotten = append(f.forgotten, id)
+}
+
+// stalledUsers stands in for a user service that does not answer.
+type stalledUsers struct{}
+
+func (stalledUsers) Exists(ctx context.Context, _ uuid.UUID) (bool, error) {
+	<-ctx.Done()
+	return false, ctx.Err()
+}
+
+func (stalledUsers) Forget(uuid.UUID) {}
+
+func TestCheckUser(t *testing.T) {
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&logs, nil))
+	missing := uuid.New()
+
+	require.NoError(t, checkUser(context.Background(), &fakeUsers{}, logger, uuid.New()))
+	require.Equal(t, ErrUnknownUser, checkUser(context.Background(), &fakeUsers{missing: map[uuid.UUID]bool{missing: true}}, logger, missing))
+	require.Empty(t, logs.String())
+
+	// A user service that does not answer in time is skipped, and the fallback logged.
+	start := time.Now()
+	require.NoError(t, checkUser(context.Background(), stalledUsers{}, logger, missing))
+	require.Less(t, time.Since(start), 2*userCheckTimeout)
+	require.Contains(t, logs.String(), "user service unavailable")
+	require.Contains(t, logs.String(), missing.String())
+}
+
+func TestRepositoryPurgeUser(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	reassigned := Todo{ID: uuid.New(), UserID: uuid.New(), Title: "Handed over", Version: 3, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	unassigned := Todo{ID: uuid.New(), UserID: uuid.New(), Title: "Shared", Version: 4, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+	owned := uuid.New()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE todos\\s+SET user_id = COALESCE\\(NULLIF\\(todos.assignee_id, \\$1\\), p.user_id\\).* RETURNING todos.id, todos.user_id").
+		WithArgs(userID).
+		WillReturnRows(newTodoRows(reassigned))
+	expectEvent(mock, outbox.TodoUpdated, reassigned.ID, 3)
+	mock.ExpectQuery("UPDATE todos SET assignee_id = NULL").
+		WithArgs(userID).
+		WillReturnRows(newTodoRows(unassigned))
+	expectEvent(mock, outbox.TodoUpdated, unassigned.ID, 4)
+	mock.ExpectQuery("DELETE FROM todos WHERE user_id = \\$1").
+		WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "project_id", "version"}).AddRow(owned, (*uuid.UUID)(nil), int64(2)))
+	expectEvent(mock, outbox.TodoDeleted, owned, 3)
+	mock.ExpectCommit()
+
+	n, err := NewRepository(mock).PurgeUser(context.Background(), userID)
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUserSinkPurgesDeletedUsers(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	userID := uuid.New()
+	users := &fakeUsers{}
+	sink := NewUserSink(NewRepository(mock), users)
+
+	created, err := outbox.NewEvent(outbox.UserCreated, outbox.AggregateUser, userID, 1, map[string]string{"id": userID.String()})
+	require.NoError(t, err)
+	require.NoError(t, sink.Publish(context.Background(), created))
+
+	mock.ExpectBegin()
+	mock.ExpectQuery("UPDATE todos\\s+SET user_id").WithArgs(userID).WillReturnRows(newTodoRows())
+	mock.ExpectQuery("UPDATE todos SET assignee_id = NULL").WithArgs(userID).WillReturnRows(newTodoRows())
+	mock.ExpectQuery("DELETE FROM todos WHERE user_id = \\$1").
+		WithArgs(userID).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "project_id", "version"}))
+	mock.ExpectCommit()
+
+	deleted, err := outbox.NewEvent(outbox.UserDeleted, outbox.AggregateUser, userID, 2, outbox.DeletedUser{ID: userID})
+	require.NoError(t, err)
+	require.NoError(t, sink.Publish(context.Background(), deleted))
+	require.Equal(t, []uuid.UUID{userID}, users.forgotten)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,128 @@
+package user
+
+import (
+	"context"
+	"net/mail"
+	"strings"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	todoappv1 "overengineeredtodo/proto/todoapp/v1"
+)
+
+// defaultListLimit is the number of users listed when the request does not say.
+const defaultListLimit = 100
+
+// RegisterGRPC serves the users as todoappv1.UserService.
+func RegisterGRPC(server grpc.ServiceRegistrar, repo *Repository) {
+	todoappv1.RegisterUserServiceServer(server, &GRPCServer{repo: repo})
+}
+
+// GRPCServer exposes the user resource over gRPC, mirroring Handler.
+type GRPCServer struct {
+	todoappv1.UnimplementedUserServiceServer
+	repo *Repository
+}
+
+// CreateUser creates a user.
+func (s *GRPCServer) CreateUser(ctx context.Context, req *todoappv1.CreateUserRequest) (*todoappv1.User, error) {
+	if strings.TrimSpace(req.GetName()) == "" {
+		return nil, status.Error(codes.InvalidArgument, "name is required")
+	}
+	if address, err := mail.ParseAddress(req.GetEmail()); err != nil || address.Address != req.GetEmail() {
+		return nil, status.Error(codes.InvalidArgument, "invalid email")
+	}
+
+	u, err := s.repo.Create(ctx, CreateUserInput{Name: req.GetName(), Email: req.GetEmail()})
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return toProto(u), nil
+}
+
+// GetUser fetches a user.
+func (s *GRPCServer) GetUser(ctx context.Context, req *todoappv1.GetUserRequest) (*todoappv1.User, error) {
+	id, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid id")
+	}
+
+	u, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return toProto(u), nil
+}
+
+// ListUsers streams the newest users, up to the request's limit.
+func (s *GRPCServer) ListUsers(req *todoappv1.ListUsersRequest, stream grpc.ServerStreamingServer[todoappv1.User]) error {
+	limit := defaultListLimit
+	if req.GetLimit() > 0 {
+		limit = int(req.GetLimit())
+	}
+
+	users, err := s.repo.List(stream.Context(), limit)
+	if err != nil {
+		return grpcError(err)
+	}
+	for _, u := range users {
+		if err := stream.Send(toProto(u)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LookupUsers resolves handles, emails or names as used in @mentions.
+func (s *GRPCServer) LookupUsers(ctx context.Context, req *todoappv1.LookupUsersRequest) (*todoappv1.LookupUsersResponse, error) {
+	if len(req.GetHandles()) == 0 || len(req.GetHandles()) > maxLookupHandles {
+		return nil, status.Error(codes.InvalidArgument, "between 1 and 50 handles are required")
+	}
+
+	users, err := s.repo.Lookup(ctx, req.GetHandles())
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	resp := &todoappv1.LookupUsersResponse{Users: make(map[string]*todoappv1.User, len(users))}
+	for handle, u := range users {
+		resp.Users[handle] = toProto(u)
+	}
+	return resp, nil
+}
+
+// DeleteUser deletes a user along with the todos they still own.
+func (s *GRPCServer) DeleteUser(ctx context.Context, req *todoappv1.DeleteUserRequest) (*todoappv1.DeleteUserResponse, error) {
+	id, err := uuid.Parse(req.GetId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid id")
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return nil, grpcError(err)
+	}
+	return &todoappv1.DeleteUserResponse{}, nil
+}
+
+func toProto(u User) *todoappv1.User {
+	return &todoappv1.User{
+		Id:        u.ID.String(),
+		Name:      u.Name,
+		Email:     u.Email,
+		CreatedAt: timestamppb.New(u.CreatedAt),
+	}
+}
+
+// grpcError maps repository errors onto gRPC status codes, as the handlers map them onto
+// HTTP responses.
+func grpcError(err error) error {
+	switch {
+	case err == ErrNotFound:
+		return status.Error(codes.NotFound, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
@@ -0,0 +1,156 @@
+package user
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"overengineeredtodo/pkg/grpcserver"
+	todoappv1 "overengineeredtodo/proto/todoapp/v1"
+)
+
+// newGRPCConn serves the users from mock over an in-memory connection.
+func newGRPCConn(t *testing.T, mock pgxmock.PgxPoolIface) *grpc.ClientConn {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	server := grpcserver.New()
+	RegisterGRPC(server, NewRepository(mock))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = server.Serve(ctx, lis, time.Second)
+	}()
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		conn.Close()
+		cancel()
+		<-done
+	})
+	return conn
+}
+
+func TestGRPCCreateUser(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	id, createdAt := uuid.New(), time.Now().UTC()
+	mock.ExpectBegin()
+	mock.ExpectQuery("INSERT INTO users").
+		WithArgs(pgxmock.AnyArg(), "Alice", "alice@example.com").
+		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "email", "created_at"}).AddRow(id, "Alice", "alice@example.com", createdAt))
+	mock.ExpectExec("INSERT INTO outbox_events").
+		WithArgs(pgxmock.AnyArg(), "UserCreated", 1, "user", id, int64(1), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
+
+	client := todoappv1.NewUserServiceClient(newGRPCConn(t, mock))
+	u, err := client.CreateUser(context.Background(), &todoappv1.CreateUserRequest{Name: "Alice", Email: "alice@example.com"})
+	require.NoError(t, err)
+	require.Equal(t, id.String(), u.GetId())
+	require.True(t, createdAt.Equal(u.GetCreatedAt().AsTime()))
+
+	_, err = client.CreateUser(context.Background(), &todoappv1.CreateUserRequest{Name: "Bob", Email: "not an email"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGRPCGetUserNotFound(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	id := uuid.New()
+	mock.ExpectQuery("SELECT id, name, email, created_at FROM users").WithArgs(id).WillReturnError(pgx.ErrNoRows)
+
+	client := todoappv1.NewUserServiceClient(newGRPCConn(t, mock))
+	_, err = client.GetUser(context.Background(), &todoappv1.GetUserRequest{Id: id.String()})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = client.GetUser(context.Background(), &todoappv1.GetUserRequest{Id: "nope"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGRPCListUsersStreams(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	rows := pgxmock.NewRows([]string{"id", "name", "email", "created_at"})
+	for _, name := range []string{"Alice", "Bob"} {
+		rows.AddRow(uuid.New(), name, name+"@example.com", time.Now())
+	}
+	mock.ExpectQuery("SELECT id, name, email, created_at FROM users").WithArgs(defaultListLimit).WillReturnRows(rows)
+
+	client := todoappv1.NewUserServiceClient(newGRPCConn(t, mock))
+	stream, err := client.ListUsers(context.Background(), &todoappv1.ListUsersRequest{})
+	require.NoError(t, err)
+
+	var names []string
+	for {
+		u, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		names = append(names, u.GetName())
+	}
+	require.Equal(t, []string{"Alice", "Bob"}, names)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGRPCLookupUsersValidatesHandles(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	client := todoappv1.NewUserServiceClient(newGRPCConn(t, mock))
+	_, err = client.LookupUsers(context.Background(), &todoappv1.LookupUsersRequest{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPCHealthAndReflection(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	conn := newGRPCConn(t, mock)
+	health, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{Service: todoappv1.UserService_ServiceDesc.ServiceName})
+	require.NoError(t, err)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, health.GetStatus())
+
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, stream.Send(&reflectionpb.ServerReflectionRequest{
+		MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{},
+	}))
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	var services []string
+	for _, s := range resp.GetListServicesResponse().GetService() {
+		services = append(services, s.GetName())
+	}
+	require.Contains(t, services, todoappv1.UserService_ServiceDesc.ServiceName)
+	require.NoError(t, stream.CloseSend())
+}
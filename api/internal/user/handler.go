@@ -8,11 +8,15 @@ import (
 	"github.com/google/uuid"
 )
 
+// maxLookupHandles bounds the handles resolved by one lookup.
+const maxLookupHandles = 50
+
 // RegisterRoutes wires the user HTTP handlers onto the supplied router group.
 func RegisterRoutes(router *gin.RouterGroup, repo *Repository) {
 	handler := &Handler{repo: repo}
 
 	router.POST("", handler.createUser)
+	router.GET("/lookup", handler.lookupUsers)
 	router.GET("/:id", handler.getUser)
 	router.GET("", handler.listUsers)
 	router.DELETE("/:id", handler.deleteUser)
@@ -74,6 +78,23 @@ func (h *Handler) listUsers(c *gin.Context) {
 	c.JSON(http.StatusOK, users)
 }
 
+// lookupUsers resolves the handle parameters, emails or names as used in @mentions.
+func (h *Handler) lookupUsers(c *gin.Context) {
+	handles := c.QueryArray("handle")
+	if len(handles) == 0 || len(handles) > maxLookupHandles {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "between 1 and 50 handle parameters are required"})
+		return
+	}
+
+	users, err := h.repo.Lookup(c.Request.Context(), handles)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"users": users})
+}
+
 func (h *Handler) deleteUser(c *gin.Context) {
 	id, err := parseUUIDParam(c.Param("id"))
 	if err != nil {
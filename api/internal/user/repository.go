@@ -3,11 +3,14 @@ package user
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+
+	"overengineeredtodo/internal/outbox"
 )
 
 // Repository provides database persistence for users.
@@ -19,6 +22,7 @@ type pgxPool interface {
 	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
 	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
 }
 
 // NewRepository constructs a Repository backed by the supplied pgx pool.
@@ -34,15 +38,32 @@ func (r *Repository) Create(ctx context.Context, input CreateUserInput) (User, e
 		RETURNING id, name, email, created_at
 	`
 
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return User{}, fmt.Errorf("begin create user: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
 	id := uuid.New()
 	var u User
 
-	if err := r.pool.QueryRow(ctx, query, id, input.Name, input.Email).Scan(
+	if err := tx.QueryRow(ctx, query, id, input.Name, input.Email).Scan(
 		&u.ID, &u.Name, &u.Email, &u.CreatedAt,
 	); err != nil {
 		return User{}, fmt.Errorf("insert user: %w", err)
 	}
 
+	event, err := outbox.NewEvent(outbox.UserCreated, outbox.AggregateUser, u.ID, 1, u)
+	if err != nil {
+		return User{}, err
+	}
+	if err := outbox.Insert(ctx, tx, event); err != nil {
+		return User{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return User{}, fmt.Errorf("commit create user: %w", err)
+	}
 	return u, nil
 }
 
@@ -84,7 +105,7 @@ func (r *Repository) List(ctx context.Context, limit int) ([]User, error) {
 	}
 	defer rows.Close()
 
-	var result []User
+	result := []User{}
 	for rows.Next() {
 		var u User
 		if err := rows.Scan(&u.ID, &u.Name, &u.Email, &u.CreatedAt); err != nil {
@@ -100,15 +121,130 @@ func (r *Repository) List(ctx context.Context, limit int) ([]User, error) {
 	return result, nil
 }
 
+// Lookup resolves handles, which are either an email address or a name, to users. Both
+// compare case-insensitively and names also without spaces, so "alicesmith" names Alice
+// Smith. Handles that match no user or several users are left out of the result, which
+// is keyed by the lower-cased handle.
+func (r *Repository) Lookup(ctx context.Context, handles []string) (map[string]User, error) {
+	keys := make([]string, 0, len(handles))
+	for _, h := range handles {
+		keys = append(keys, strings.ToLower(strings.TrimSpace(h)))
+	}
+
+	query := `
+		SELECT id, name, email, created_at, lower(email), lower(replace(name, ' ', ''))
+		FROM users
+		WHERE lower(email) = ANY($1) OR lower(replace(name, ' ', '')) = ANY($1)
+	`
+
+	rows, err := r.pool.Query(ctx, query, keys)
+	if err != nil {
+		return nil, fmt.Errorf("query users: %w", err)
+	}
+	defer rows.Close()
+
+	matches := make(map[string][]User)
+	for rows.Next() {
+		var u User
+		var email, name string
+		if err := rows.Scan(&u.ID, &u.Name, &u.Email, &u.CreatedAt, &email, &name); err != nil {
+			return nil, fmt.Errorf("scan user: %w", err)
+		}
+		matches[email] = append(matches[email], u)
+		if name != email {
+			matches[name] = append(matches[name], u)
+		}
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate users: %w", rows.Err())
+	}
+
+	result := make(map[string]User)
+	for _, key := range keys {
+		if users := matches[key]; len(users) == 1 {
+			result[key] = users[0]
+		}
+	}
+	return result, nil
+}
+
 // Delete removes a user record. Returns ErrNotFound when the row is absent.
+//
+// Shared projects survive their owner: projects the user is the last owner of pass to the
+// longest-standing remaining member, preferring editors over viewers. The todo service
+// takes care of the user's todos when the UserDeleted event reaches it. Everything else
+// of the user is removed along with the row.
 func (r *Repository) Delete(ctx context.Context, id uuid.UUID) error {
-	tag, err := r.pool.Exec(ctx, `DELETE FROM users WHERE id = $1`, id)
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin delete user: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	promote := `
+		UPDATE project_members
+		SET role = 'owner'
+		WHERE (project_id, user_id) IN (
+			SELECT DISTINCT ON (m.project_id) m.project_id, m.user_id
+			FROM project_members m
+			WHERE m.user_id <> $1
+			  AND m.project_id IN (SELECT project_id FROM project_members WHERE user_id = $1 AND role = 'owner')
+			  AND NOT EXISTS (
+			        SELECT 1 FROM project_members o
+			        WHERE o.project_id = m.project_id AND o.role = 'owner' AND o.user_id <> $1
+			    )
+			ORDER BY m.project_id, m.role = 'editor' DESC, m.created_at ASC
+		)
+	`
+	if _, err := tx.Exec(ctx, promote, id); err != nil {
+		return fmt.Errorf("promote project owners: %w", err)
+	}
+
+	// Project names are unique per owner, so a name the successor already uses gets a suffix.
+	transfer := `
+		WITH successors AS (
+			SELECT DISTINCT ON (m.project_id) m.project_id, m.user_id
+			FROM project_members m
+			JOIN projects p ON p.id = m.project_id
+			WHERE p.user_id = $1 AND m.role = 'owner' AND m.user_id <> $1
+			ORDER BY m.project_id, m.created_at ASC
+		)
+		UPDATE projects
+		SET user_id = s.user_id,
+		    name = CASE
+		        WHEN EXISTS (SELECT 1 FROM projects o WHERE o.user_id = s.user_id AND o.name = projects.name)
+		        THEN projects.name || ' (transferred)'
+		        ELSE projects.name
+		    END,
+		    updated_at = current_timestamp
+		FROM successors s
+		WHERE projects.id = s.project_id
+	`
+	if _, err := tx.Exec(ctx, transfer, id); err != nil {
+		return fmt.Errorf("transfer projects: %w", err)
+	}
+
+	tag, err := tx.Exec(ctx, `DELETE FROM users WHERE id = $1`, id)
 	if err != nil {
 		return fmt.Errorf("delete user: %w", err)
 	}
 	if tag.RowsAffected() == 0 {
 		return ErrNotFound
 	}
+
+	// Users change only by being created and deleted, so deletion is their second version.
+	event, err := outbox.NewEvent(outbox.UserDeleted, outbox.AggregateUser, id, 2, outbox.DeletedUser{ID: id})
+	if err != nil {
+		return err
+	}
+	if err := outbox.Insert(ctx, tx, event); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit delete user: %w", err)
+	}
 	return nil
 }
 
@@ -25,9 +25,14 @@ func TestRepositoryCreate(t *testing.T) {
 	rows := pgxmock.NewRows([]string{"id", "name", "email", "created_at"}).
 		AddRow(returnedID, input.Name, input.Email, createdAt)
 
+	mock.ExpectBegin()
 	mock.ExpectQuery("INSERT INTO users").
 		WithArgs(pgxmock.AnyArg(), input.Name, input.Email).
 		WillReturnRows(rows)
+	mock.ExpectExec("INSERT INTO outbox_events").
+		WithArgs(pgxmock.AnyArg(), "UserCreated", 1, "user", returnedID, int64(1), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
 
 	u, err := repo.Create(context.Background(), input)
 	require.NoError(t, err)
@@ -108,9 +113,20 @@ func TestRepositoryDelete(t *testing.T) {
 	repo := NewRepository(mock)
 	id := uuid.New()
 
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE project_members\\s+SET role = 'owner'").
+		WithArgs(id).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectExec("UPDATE projects\\s+SET user_id = s.user_id").
+		WithArgs(id).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
 	mock.ExpectExec("DELETE FROM users").
 		WithArgs(id).
 		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+	mock.ExpectExec("INSERT INTO outbox_events").
+		WithArgs(pgxmock.AnyArg(), "UserDeleted", 1, "user", id, int64(2), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mock.ExpectCommit()
 
 	err = repo.Delete(context.Background(), id)
 	require.NoError(t, err)
@@ -125,9 +141,13 @@ func TestRepositoryDeleteNotFound(t *testing.T) {
 	repo := NewRepository(mock)
 	id := uuid.New()
 
+	mock.ExpectBegin()
+	mock.ExpectExec("UPDATE project_members").WithArgs(id).WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	mock.ExpectExec("UPDATE projects").WithArgs(id).WillReturnResult(pgxmock.NewResult("UPDATE", 0))
 	mock.ExpectExec("DELETE FROM users").
 		WithArgs(id).
 		WillReturnResult(pgxmock.NewResult("DELETE", 0))
+	mock.ExpectRollback()
 
 	err = repo.Delete(context.Background(), id)
 	require.ErrorIs(t, err, ErrNotFound)
@@ -159,11 +179,38 @@ func TestRepositoryCreateErrorPropagates(t *testing.T) {
 	repo := NewRepository(mock)
 	input := CreateUserInput{Name: "Alice", Email: "alice@example.com"}
 
+	mock.ExpectBegin()
 	mock.ExpectQuery("INSERT INTO users").
 		WithArgs(pgxmock.AnyArg(), input.Name, input.Email).
 		WillReturnError(errors.New("boom"))
+	mock.ExpectRollback()
 
 	_, err = repo.Create(context.Background(), input)
 	require.Error(t, err)
 	require.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestRepositoryLookup(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	alice := User{ID: uuid.New(), Name: "Alice Smith", Email: "alice@example.com", CreatedAt: time.Now()}
+	bob1 := User{ID: uuid.New(), Name: "Bob", Email: "bob@example.com", CreatedAt: time.Now()}
+	bob2 := User{ID: uuid.New(), Name: "bob", Email: "robert@example.com", CreatedAt: time.Now()}
+
+	mock.ExpectQuery("SELECT .* FROM users WHERE lower\\(email\\) = ANY\\(\\$1\\)").
+		WithArgs([]string{"alice@example.com", "alicesmith", "bob", "nobody"}).
+		WillReturnRows(pgxmock.NewRows([]string{"id", "name", "email", "created_at", "lower", "lower"}).
+			AddRow(alice.ID, alice.Name, alice.Email, alice.CreatedAt, "alice@example.com", "alicesmith").
+			AddRow(bob1.ID, bob1.Name, bob1.Email, bob1.CreatedAt, "bob@example.com", "bob").
+			AddRow(bob2.ID, bob2.Name, bob2.Email, bob2.CreatedAt, "robert@example.com", "bob"))
+
+	users, err := repo.Lookup(context.Background(), []string{"Alice@Example.com", "AliceSmith", "bob", "nobody"})
+	require.NoError(t, err)
+	require.Len(t, users, 2)
+	require.Equal(t, alice.ID, users["alice@example.com"].ID)
+	require.Equal(t, alice.ID, users["alicesmith"].ID)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
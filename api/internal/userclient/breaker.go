@@ -0,0 +1,64 @@
+package userclient
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// breakerThreshold is the number of failures in a row that open the breaker.
+	breakerThreshold = 5
+	// breakerCooldown is how long an open breaker refuses requests before letting one
+	// through to probe the user service.
+	breakerCooldown = 30 * time.Second
+)
+
+// breaker is a circuit breaker: after breakerThreshold failed requests in a row it refuses
+// requests for breakerCooldown, then lets a single probe through, closing again when that
+// succeeds.
+type breaker struct {
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	probing   bool
+	now       func() time.Time
+}
+
+// allow reports whether a request may be sent.
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures < breakerThreshold {
+		return true
+	}
+	if b.probing || b.now().Before(b.openUntil) {
+		return false
+	}
+	b.probing = true
+	return true
+}
+
+// record counts the outcome of an allowed request.
+func (b *breaker) record(ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if ok {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.failures >= breakerThreshold {
+		b.openUntil = b.now().Add(breakerCooldown)
+	}
+}
+
+// abandon gives up an allowed request without an outcome, such as when its caller went
+// away.
+func (b *breaker) abandon() {
+	b.mu.Lock()
+	b.probing = false
+	b.mu.Unlock()
+}
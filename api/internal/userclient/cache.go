@@ -0,0 +1,74 @@
+package userclient
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"overengineeredtodo/internal/user"
+)
+
+const (
+	// cacheTTL is how long a user is remembered.
+	cacheTTL = 30 * time.Second
+	// notFoundTTL is how long an unknown user is remembered; short, as the user may be
+	// created any moment.
+	notFoundTTL = 5 * time.Second
+	// maxCached bounds the number of remembered users.
+	maxCached = 10000
+)
+
+type cacheEntry struct {
+	user    user.User
+	found   bool
+	expires time.Time
+}
+
+// cache remembers the users fetched by id for a short while.
+type cache struct {
+	mu      sync.Mutex
+	entries map[uuid.UUID]cacheEntry
+	now     func() time.Time
+}
+
+func (c *cache) get(id uuid.UUID) (cacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[id]
+	if !ok || !c.now().Before(entry.expires) {
+		return cacheEntry{}, false
+	}
+	return entry, true
+}
+
+// put remembers u, or that no user has the id when found is false.
+func (c *cache) put(id uuid.UUID, u user.User, found bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if len(c.entries) >= maxCached {
+		for key, entry := range c.entries {
+			if !now.Before(entry.expires) {
+				delete(c.entries, key)
+			}
+		}
+		if len(c.entries) >= maxCached {
+			clear(c.entries)
+		}
+	}
+
+	ttl := cacheTTL
+	if !found {
+		ttl = notFoundTTL
+	}
+	c.entries[id] = cacheEntry{user: u, found: found, expires: now.Add(ttl)}
+}
+
+func (c *cache) forget(id uuid.UUID) {
+	c.mu.Lock()
+	delete(c.entries, id)
+	c.mu.Unlock()
+}
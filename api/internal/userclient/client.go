@@ -0,0 +1,175 @@
+package userclient
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"overengineeredtodo/internal/user"
+)
+
+const (
+	// defaultTimeout bounds a single request to the user service.
+	defaultTimeout = 2 * time.Second
+	// maxAttempts is the number of times a request is sent before giving up.
+	maxAttempts = 3
+	// retryBackoff is the wait before the first retry; it doubles for every further one.
+	retryBackoff = 100 * time.Millisecond
+)
+
+// Client calls the user service over HTTP. Requests that fail for reasons on the service's
+// side are retried, a circuit breaker stops asking a failing service and users fetched by
+// id are cached briefly.
+type Client struct {
+	baseURL string
+	http    *http.Client
+	backoff time.Duration
+	breaker *breaker
+	cache   *cache
+}
+
+// New returns a client for the user service at baseURL, such as http://userservice:8080.
+// A nil httpClient uses one with a two second timeout.
+func New(baseURL string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: defaultTimeout}
+	}
+	return &Client{
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		http:    httpClient,
+		backoff: retryBackoff,
+		breaker: &breaker{now: time.Now},
+		cache:   &cache{entries: make(map[uuid.UUID]cacheEntry), now: time.Now},
+	}
+}
+
+// Get fetches a user, failing with ErrNotFound for users the service does not know.
+func (c *Client) Get(ctx context.Context, id uuid.UUID) (user.User, error) {
+	if entry, ok := c.cache.get(id); ok {
+		if !entry.found {
+			return user.User{}, ErrNotFound
+		}
+		return entry.user, nil
+	}
+
+	var u user.User
+	err := c.send(ctx, "/v1/users/"+id.String(), &u)
+	switch {
+	case err == nil:
+		c.cache.put(id, u, true)
+		return u, nil
+	case err == ErrNotFound:
+		c.cache.put(id, user.User{}, false)
+		return user.User{}, ErrNotFound
+	default:
+		return user.User{}, fmt.Errorf("get user: %w", err)
+	}
+}
+
+// Exists reports whether the user service knows the user.
+func (c *Client) Exists(ctx context.Context, id uuid.UUID) (bool, error) {
+	_, err := c.Get(ctx, id)
+	switch {
+	case err == nil:
+		return true, nil
+	case err == ErrNotFound:
+		return false, nil
+	default:
+		return false, err
+	}
+}
+
+// Forget drops the cached user, such as after it was deleted.
+func (c *Client) Forget(id uuid.UUID) {
+	c.cache.forget(id)
+}
+
+// Lookup resolves handles, emails or names, to users. The result is keyed by the
+// lower-cased handle and leaves out handles that do not name exactly one user.
+func (c *Client) Lookup(ctx context.Context, handles []string) (map[string]user.User, error) {
+	if len(handles) == 0 {
+		return map[string]user.User{}, nil
+	}
+
+	query := url.Values{"handle": handles}
+	var body struct {
+		Users map[string]user.User `json:"users"`
+	}
+	if err := c.send(ctx, "/v1/users/lookup?"+query.Encode(), &body); err != nil {
+		return nil, fmt.Errorf("lookup users: %w", err)
+	}
+	return body.Users, nil
+}
+
+// send GETs path, decoding the response into out. Failures of the service are retried
+// with growing waits; a 404 fails with ErrNotFound right away.
+func (c *Client) send(ctx context.Context, path string, out any) error {
+	var err error
+	for attempt := range maxAttempts {
+		if attempt > 0 {
+			timer := time.NewTimer(c.backoff << (attempt - 1))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
+		}
+
+		if !c.breaker.allow() {
+			return ErrUnavailable
+		}
+		var retry bool
+		retry, err = c.do(ctx, path, out)
+		if ctx.Err() != nil {
+			c.breaker.abandon()
+			return ctx.Err()
+		}
+		c.breaker.record(!retry)
+		if !retry {
+			return err
+		}
+	}
+	return err
+}
+
+// do sends a single request, reporting whether it failed in a way worth retrying.
+func (c *Client) do(ctx context.Context, path string, out any) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return false, fmt.Errorf("build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return false, fmt.Errorf("decode response: %w", err)
+		}
+		return false, nil
+	case resp.StatusCode == http.StatusNotFound:
+		return false, ErrNotFound
+	default:
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		err := fmt.Errorf("unexpected status %s: %s", resp.Status, strings.TrimSpace(string(message)))
+		return retryable(resp.StatusCode), err
+	}
+}
+
+// retryable reports whether a response status may go away when the request is repeated.
+func retryable(status int) bool {
+	return status >= http.StatusInternalServerError || status == http.StatusTooManyRequests
+}
@@ -0,0 +1,169 @@
+package userclient
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"overengineeredtodo/internal/user"
+)
+
+func TestLookup(t *testing.T) {
+	alice := user.User{ID: uuid.New(), Name: "Alice", Email: "alice@example.com"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/users/lookup", r.URL.Path)
+		require.Equal(t, []string{"alice", "ghost"}, r.URL.Query()["handle"])
+		_ = json.NewEncoder(w).Encode(map[string]any{"users": map[string]user.User{"alice": alice}})
+	}))
+	defer server.Close()
+
+	users, err := New(server.URL+"/", nil).Lookup(context.Background(), []string{"alice", "ghost"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]user.User{"alice": alice}, users)
+}
+
+func TestLookupError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	_, err := New(server.URL, nil).Lookup(context.Background(), []string{"alice"})
+	require.ErrorContains(t, err, "500")
+}
+
+// newTestClient returns a client for server that retries without waiting and whose
+// breaker and cache follow the returned clock.
+func newTestClient(server *httptest.Server) (*Client, *time.Time) {
+	now := time.Now()
+	client := New(server.URL, nil)
+	client.backoff = 0
+	client.breaker.now = func() time.Time { return now }
+	client.cache.now = func() time.Time { return now }
+	return client, &now
+}
+
+func TestGetCachesUsers(t *testing.T) {
+	alice := user.User{ID: uuid.New(), Name: "Alice", Email: "alice@example.com"}
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		require.Equal(t, "/v1/users/"+alice.ID.String(), r.URL.Path)
+		_ = json.NewEncoder(w).Encode(alice)
+	}))
+	defer server.Close()
+
+	client, now := newTestClient(server)
+	for range 2 {
+		got, err := client.Get(context.Background(), alice.ID)
+		require.NoError(t, err)
+		require.Equal(t, alice, got)
+	}
+	require.EqualValues(t, 1, calls.Load())
+
+	*now = now.Add(cacheTTL)
+	_, err := client.Get(context.Background(), alice.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, calls.Load())
+
+	client.Forget(alice.ID)
+	_, err = client.Get(context.Background(), alice.ID)
+	require.NoError(t, err)
+	require.EqualValues(t, 3, calls.Load())
+}
+
+func TestExistsUnknownUser(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, `{"error":"user not found"}`, http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client, now := newTestClient(server)
+	id := uuid.New()
+	for range 2 {
+		exists, err := client.Exists(context.Background(), id)
+		require.NoError(t, err)
+		require.False(t, exists)
+	}
+	require.EqualValues(t, 1, calls.Load(), "not found is neither retried nor asked again right away")
+
+	*now = now.Add(notFoundTTL)
+	_, err := client.Exists(context.Background(), id)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, calls.Load())
+}
+
+func TestGetRetriesServerErrors(t *testing.T) {
+	alice := user.User{ID: uuid.New(), Name: "Alice"}
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < maxAttempts {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(alice)
+	}))
+	defer server.Close()
+
+	client, _ := newTestClient(server)
+	got, err := client.Get(context.Background(), alice.ID)
+	require.NoError(t, err)
+	require.Equal(t, alice, got)
+	require.EqualValues(t, maxAttempts, calls.Load())
+}
+
+func TestGetDoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "bad", http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	client, _ := newTestClient(server)
+	_, err := client.Get(context.Background(), uuid.New())
+	require.ErrorContains(t, err, "400")
+	require.EqualValues(t, 1, calls.Load())
+}
+
+func TestBreakerOpensAfterFailuresAndProbes(t *testing.T) {
+	var calls atomic.Int32
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		if !healthy.Load() {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(user.User{})
+	}))
+	defer server.Close()
+
+	client, now := newTestClient(server)
+	_, err := client.Get(context.Background(), uuid.New())
+	require.ErrorContains(t, err, "500")
+	// The breaker opens at the fifth failure, during the retries of the second request.
+	for range 2 {
+		_, err = client.Get(context.Background(), uuid.New())
+		require.ErrorIs(t, err, ErrUnavailable)
+	}
+	require.EqualValues(t, breakerThreshold, calls.Load())
+
+	// After the cooldown a single probe goes through and closes the breaker.
+	healthy.Store(true)
+	*now = now.Add(breakerCooldown)
+	_, err = client.Get(context.Background(), uuid.New())
+	require.NoError(t, err)
+	_, err = client.Get(context.Background(), uuid.New())
+	require.NoError(t, err)
+	require.EqualValues(t, breakerThreshold+2, calls.Load())
+}
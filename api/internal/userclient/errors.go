@@ -0,0 +1,10 @@
+package userclient
+
+import "errors"
+
+var (
+	// ErrNotFound indicates the user service does not know the user.
+	ErrNotFound = errors.New("user not found")
+	// ErrUnavailable indicates the user service failed too often recently to be asked.
+	ErrUnavailable = errors.New("user service unavailable")
+)
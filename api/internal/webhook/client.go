@@ -0,0 +1,115 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// resolveTimeout bounds looking up the host of a webhook URL when it is registered.
+const resolveTimeout = 2 * time.Second
+
+// resolve looks up the addresses of a host; tests replace it.
+var resolve = net.DefaultResolver.LookupNetIP
+
+// nonPublic lists the ranges webhooks must not reach besides the loopback, private,
+// link-local, multicast and unspecified addresses netip reports: shared address space,
+// "this network", IETF protocol assignments, benchmarking, documentation and reserved
+// ranges.
+var nonPublic = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("192.0.2.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("198.51.100.0/24"),
+	netip.MustParsePrefix("203.0.113.0/24"),
+	netip.MustParsePrefix("240.0.0.0/4"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+	netip.MustParsePrefix("2001:db8::/32"),
+}
+
+// NewClient returns the HTTP client deliveries are sent with. It connects only to public
+// addresses, whatever the webhook's host resolves to at the time, ignores proxy settings
+// and does not follow redirects, so that webhooks cannot reach internal services.
+func NewClient(timeout time.Duration) *http.Client {
+	return newClient(timeout, isPublic)
+}
+
+func newClient(timeout time.Duration, allowed func(netip.Addr) bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			addrPort, err := netip.ParseAddrPort(address)
+			if err != nil {
+				return fmt.Errorf("parse address %q: %w", address, err)
+			}
+			if !allowed(addrPort.Addr()) {
+				return fmt.Errorf("connect to %s: %w", addrPort.Addr(), ErrPrivateURL)
+			}
+			return nil
+		},
+	}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			ForceAttemptHTTP2:   true,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// isPublic reports whether addr is a public unicast address.
+func isPublic(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() || addr.IsLoopback() || addr.IsLinkLocalUnicast() {
+		return false
+	}
+	for _, prefix := range nonPublic {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// validURL checks that raw is an absolute http or https URL whose host is, or resolves
+// to, public addresses only. Hosts that do not resolve pass, as delivering to them fails
+// anyway; the client checks the address again whenever it connects.
+func validURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Hostname() == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return ErrInvalidURL
+	}
+
+	if addr, err := netip.ParseAddr(u.Hostname()); err == nil {
+		if !isPublic(addr) {
+			return ErrPrivateURL
+		}
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, resolveTimeout)
+	defer cancel()
+	addrs, err := resolve(ctx, "ip", u.Hostname())
+	if err != nil {
+		return nil
+	}
+	for _, addr := range addrs {
+		if !isPublic(addr) {
+			return ErrPrivateURL
+		}
+	}
+	return nil
+}
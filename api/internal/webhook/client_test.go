@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidURLRejectsNonPublicAddresses(t *testing.T) {
+	defer func(previous func(context.Context, string, string) ([]netip.Addr, error)) { resolve = previous }(resolve)
+	resolve = func(_ context.Context, _, host string) ([]netip.Addr, error) {
+		switch host {
+		case "hooks.example.com":
+			return []netip.Addr{netip.MustParseAddr("93.184.216.34")}, nil
+		case "intranet.example.com":
+			return []netip.Addr{netip.MustParseAddr("93.184.216.34"), netip.MustParseAddr("10.20.30.40")}, nil
+		case "localhost":
+			return []netip.Addr{netip.MustParseAddr("127.0.0.1"), netip.MustParseAddr("::1")}, nil
+		default:
+			return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+	}
+
+	for raw, want := range map[string]error{
+		"https://hooks.example.com/todos":          nil,
+		"https://93.184.216.34/todos":              nil,
+		"https://unknown.example.com/todos":        nil,
+		"http://127.0.0.1:8080/v1/users":           ErrPrivateURL,
+		"http://10.0.0.8/hook":                     ErrPrivateURL,
+		"http://192.168.1.1/hook":                  ErrPrivateURL,
+		"http://169.254.169.254/latest/meta-data/": ErrPrivateURL,
+		"http://100.64.0.1/hook":                   ErrPrivateURL,
+		"http://[::1]:8081/hook":                   ErrPrivateURL,
+		"http://[::ffff:10.0.0.1]/hook":            ErrPrivateURL,
+		"http://[fd00::1]/hook":                    ErrPrivateURL,
+		"http://localhost:8080/hook":               ErrPrivateURL,
+		"https://intranet.example.com/hook":        ErrPrivateURL,
+		"ftp://hooks.example.com":                  ErrInvalidURL,
+	} {
+		require.Equal(t, want, validURL(context.Background(), raw), raw)
+	}
+}
+
+func TestClientRefusesNonPublicAddresses(t *testing.T) {
+	var hits atomic.Int32
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer internal.Close()
+
+	client := NewClient(time.Second)
+	for _, target := range []string{internal.URL, "http://10.0.0.1:9/hook"} {
+		resp, err := client.Post(target, "application/json", strings.NewReader(`{}`))
+		if resp != nil {
+			resp.Body.Close()
+		}
+		require.True(t, errors.Is(err, ErrPrivateURL), target)
+	}
+	require.Zero(t, hits.Load())
+}
+
+func TestClientDoesNotFollowRedirects(t *testing.T) {
+	var hits atomic.Int32
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+	}))
+	defer target.Close()
+	redirector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	defer redirector.Close()
+
+	// The test servers listen on loopback, which the client would refuse to reach at all.
+	client := newClient(time.Second, func(netip.Addr) bool { return true })
+	resp, err := client.Post(redirector.URL, "application/json", strings.NewReader(`{}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusTemporaryRedirect, resp.StatusCode)
+	require.Zero(t, hits.Load())
+}
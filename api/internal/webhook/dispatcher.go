@@ -0,0 +1,188 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// dispatchBatch is the number of due deliveries claimed at a time.
+	dispatchBatch = 50
+	// dispatchWorkers is the number of deliveries sent at once.
+	dispatchWorkers = 8
+	// lease is how long a claimed delivery waits before it is tried again, should its
+	// attempt never be recorded. It outlasts the client timeout.
+	lease = time.Minute
+	// maxAttempts is the number of attempts after which a delivery is given up.
+	maxAttempts = 8
+	// baseBackoff is the wait before the first retry; every further retry waits twice as
+	// long, up to maxBackoff.
+	baseBackoff = 30 * time.Second
+	maxBackoff  = 6 * time.Hour
+	// disableAfter is the number of failed attempts in a row that disables a webhook.
+	disableAfter = 15
+	// maxErrorBody is how much of a failed response is kept in the delivery log.
+	maxErrorBody = 512
+)
+
+// Dispatcher sends queued deliveries to their webhooks, retrying failed attempts with
+// exponential backoff.
+type Dispatcher struct {
+	repo     *Repository
+	client   *http.Client
+	interval time.Duration
+	logger   *slog.Logger
+	wg       sync.WaitGroup
+}
+
+// NewDispatcher constructs a Dispatcher that looks for due deliveries every interval. The
+// client should have a timeout shorter than a minute.
+func NewDispatcher(repo *Repository, client *http.Client, interval time.Duration, logger *slog.Logger) *Dispatcher {
+	return &Dispatcher{repo: repo, client: client, interval: interval, logger: logger}
+}
+
+// Start dispatches in the background until ctx is cancelled.
+func (d *Dispatcher) Start(ctx context.Context) {
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+		for {
+			if _, err := d.Dispatch(ctx); err != nil && ctx.Err() == nil {
+				d.logger.Error("webhook dispatch failed", slog.String("error", err.Error()))
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Wait blocks until a started dispatcher has stopped.
+func (d *Dispatcher) Wait() {
+	d.wg.Wait()
+}
+
+// Dispatch sends the deliveries that are due and reports how many were attempted.
+func (d *Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	attempted := 0
+	for {
+		due, err := d.repo.claimDue(ctx, dispatchBatch, time.Now().Add(lease))
+		if err != nil {
+			return attempted, err
+		}
+
+		var wg sync.WaitGroup
+		slots := make(chan struct{}, dispatchWorkers)
+		for _, q := range due {
+			wg.Add(1)
+			slots <- struct{}{}
+			go func() {
+				defer wg.Done()
+				defer func() { <-slots }()
+				if err := d.deliver(ctx, q); err != nil {
+					d.logger.Error("failed to record webhook delivery",
+						slog.String("delivery_id", q.ID.String()), slog.String("error", err.Error()))
+				}
+			}()
+		}
+		wg.Wait()
+		attempted += len(due)
+
+		if len(due) < dispatchBatch || ctx.Err() != nil {
+			return attempted, nil
+		}
+	}
+}
+
+// Redeliver sends the event of a delivery to its webhook again, as a new delivery that
+// is retried like any other should this attempt fail.
+func (d *Dispatcher) Redeliver(ctx context.Context, webhookID, deliveryID uuid.UUID) (Delivery, error) {
+	q, err := d.repo.redeliver(ctx, webhookID, deliveryID, time.Now().Add(lease))
+	if err != nil {
+		return Delivery{}, err
+	}
+	if err := d.deliver(ctx, q); err != nil {
+		return Delivery{}, err
+	}
+	return d.repo.GetDelivery(ctx, webhookID, q.ID)
+}
+
+// deliver makes one attempt at the delivery and records its outcome.
+func (d *Dispatcher) deliver(ctx context.Context, q queued) error {
+	started := time.Now()
+	statusCode, err := d.send(ctx, q)
+	duration := time.Since(started)
+
+	if err == nil {
+		return d.repo.recordSuccess(ctx, q.ID, *statusCode, duration)
+	}
+
+	attempts := q.Attempts + 1
+	var retryAt *time.Time
+	if attempts < maxAttempts {
+		at := time.Now().Add(backoff(attempts))
+		retryAt = &at
+	}
+
+	enabled, recordErr := d.repo.recordFailure(ctx, q.ID, statusCode, err.Error(), duration, retryAt, disableAfter)
+	if recordErr != nil {
+		return recordErr
+	}
+	if !enabled {
+		d.logger.Warn("webhook disabled after repeated failures", slog.String("webhook_id", q.WebhookID.String()))
+	}
+	return nil
+}
+
+// send posts the delivery's event, signed with the webhook's secret. Any 2xx response
+// accepts it. The status code is returned whenever the endpoint answered.
+func (d *Dispatcher) send(ctx context.Context, q queued) (*int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, q.url, bytes.NewReader(q.Payload))
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "overengineeredtodo-webhooks/1")
+	req.Header.Set(HeaderDelivery, q.ID.String())
+	req.Header.Set(HeaderEvent, q.EventType)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(q.secret, timestamp, q.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	statusCode := resp.StatusCode
+	if statusCode < 200 || statusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return &statusCode, fmt.Errorf("unexpected status %d: %s", statusCode, bytes.TrimSpace(body))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return &statusCode, nil
+}
+
+// backoff is the wait after the given number of failed attempts.
+func backoff(attempts int) time.Duration {
+	wait := baseBackoff
+	for i := 1; i < attempts && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, maxBackoff)
+}
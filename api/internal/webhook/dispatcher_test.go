@@ -0,0 +1,168 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+
+	"overengineeredtodo/internal/outbox"
+)
+
+const testSecret = "whsec_test"
+
+func newTestDispatcher(mock pgxmock.PgxPoolIface, client *http.Client) *Dispatcher {
+	return NewDispatcher(NewRepository(mock), client, time.Minute, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func pendingDelivery(attempts int) Delivery {
+	now := time.Now()
+	return Delivery{
+		ID: uuid.New(), WebhookID: uuid.New(), EventID: uuid.New(), EventType: outbox.TodoCreated,
+		Payload: []byte(`{"type":"TodoCreated"}`), Status: StatusPending, Attempts: attempts, NextAttemptAt: &now, CreatedAt: now,
+	}
+}
+
+func TestDispatchSendsSignedDeliveries(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	d := pendingDelivery(0)
+	received := make(chan http.Header, 1)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		require.NoError(t, Verify(testSecret, r.Header.Get(HeaderTimestamp), r.Header.Get(HeaderSignature), body, 5*time.Minute, time.Now()))
+		require.JSONEq(t, string(d.Payload), string(body))
+		received <- r.Header
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	mock.ExpectQuery("WITH claimed AS \\(\\s+UPDATE webhook_deliveries\\s+SET next_attempt_at = \\$2 .* WHERE d.status = 'pending' AND d.next_attempt_at <= now\\(\\) AND w.enabled").
+		WithArgs(dispatchBatch, pgxmock.AnyArg()).
+		WillReturnRows(newQueuedRows(receiver.URL, testSecret, d))
+	mock.ExpectExec("WITH delivered AS \\(\\s+UPDATE webhook_deliveries\\s+SET status = 'succeeded'.*UPDATE webhooks SET consecutive_failures = 0").
+		WithArgs(d.ID, http.StatusNoContent, pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	attempted, err := newTestDispatcher(mock, receiver.Client()).Dispatch(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, 1, attempted)
+
+	headers := <-received
+	require.Equal(t, d.ID.String(), headers.Get(HeaderDelivery))
+	require.Equal(t, outbox.TodoCreated, headers.Get(HeaderEvent))
+	require.Equal(t, "application/json", headers.Get("Content-Type"))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDispatchSchedulesRetryAfterFailure(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "maintenance", http.StatusServiceUnavailable)
+	}))
+	defer receiver.Close()
+
+	d := pendingDelivery(2)
+	status := http.StatusServiceUnavailable
+	mock.ExpectQuery("WITH claimed AS").
+		WithArgs(dispatchBatch, pgxmock.AnyArg()).
+		WillReturnRows(newQueuedRows(receiver.URL, testSecret, d))
+	mock.ExpectQuery("WITH failed AS .* UPDATE webhooks\\s+SET consecutive_failures = consecutive_failures \\+ 1").
+		WithArgs(d.ID, &status, "unexpected status 503: maintenance", pgxmock.AnyArg(), retryWithin(backoff(3)), disableAfter).
+		WillReturnRows(pgxmock.NewRows([]string{"enabled"}).AddRow(true))
+
+	_, err = newTestDispatcher(mock, receiver.Client()).Dispatch(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDispatchGivesUpAfterLastAttempt(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	// Nothing listens on a closed server, so the attempt fails without a status.
+	receiver := httptest.NewServer(http.NotFoundHandler())
+	receiver.Close()
+
+	d := pendingDelivery(maxAttempts - 1)
+	mock.ExpectQuery("WITH claimed AS").
+		WithArgs(dispatchBatch, pgxmock.AnyArg()).
+		WillReturnRows(newQueuedRows(receiver.URL, testSecret, d))
+	mock.ExpectQuery("WITH failed AS").
+		WithArgs(d.ID, (*int)(nil), pgxmock.AnyArg(), pgxmock.AnyArg(), (*time.Time)(nil), disableAfter).
+		WillReturnRows(pgxmock.NewRows([]string{"enabled"}).AddRow(false))
+
+	_, err = newTestDispatcher(mock, http.DefaultClient).Dispatch(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRedeliverSendsRightAway(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	calls := 0
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer receiver.Close()
+
+	original := pendingDelivery(maxAttempts)
+	original.Status = StatusFailed
+	redelivery := pendingDelivery(0)
+	redelivery.WebhookID, redelivery.EventID, redelivery.RedeliveryOf = original.WebhookID, original.EventID, &original.ID
+	delivered := redelivery
+	delivered.Status, delivered.Attempts = StatusSucceeded, 1
+
+	mock.ExpectQuery("WITH inserted AS \\(\\s+INSERT INTO webhook_deliveries .* COALESCE\\(redelivery_of, id\\)").
+		WithArgs(pgxmock.AnyArg(), original.ID, original.WebhookID, pgxmock.AnyArg()).
+		WillReturnRows(newQueuedRows(receiver.URL, testSecret, redelivery))
+	mock.ExpectExec("WITH delivered AS").
+		WithArgs(redelivery.ID, http.StatusOK, pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mock.ExpectQuery("SELECT .* FROM webhook_deliveries WHERE id = \\$1 AND webhook_id = \\$2").
+		WithArgs(redelivery.ID, original.WebhookID).
+		WillReturnRows(newDeliveryRows(delivered))
+
+	result, err := newTestDispatcher(mock, receiver.Client()).Redeliver(context.Background(), original.WebhookID, original.ID)
+	require.NoError(t, err)
+	require.Equal(t, StatusSucceeded, result.Status)
+	require.Equal(t, original.ID, *result.RedeliveryOf)
+	require.Equal(t, 1, calls)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestBackoffDoublesUpToMaximum(t *testing.T) {
+	require.Equal(t, 30*time.Second, backoff(1))
+	require.Equal(t, time.Minute, backoff(2))
+	require.Equal(t, 4*time.Minute, backoff(4))
+	require.Equal(t, maxBackoff, backoff(20))
+}
+
+// retryWithin matches a retry time about wait from now.
+type retryWithin time.Duration
+
+func (r retryWithin) Match(v any) bool {
+	at, ok := v.(*time.Time)
+	if !ok || at == nil {
+		return false
+	}
+	wait := time.Until(*at)
+	return wait > time.Duration(r)-time.Minute && wait <= time.Duration(r)
+}
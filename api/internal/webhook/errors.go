@@ -0,0 +1,23 @@
+package webhook
+
+import "errors"
+
+var (
+	// ErrNotFound indicates the requested webhook could not be located.
+	ErrNotFound = errors.New("webhook not found")
+	// ErrDeliveryNotFound indicates the requested delivery could not be located.
+	ErrDeliveryNotFound = errors.New("delivery not found")
+	// ErrInvalidURL indicates the endpoint is not an absolute http or https URL.
+	ErrInvalidURL = errors.New("url must be an absolute http or https URL")
+	// ErrPrivateURL indicates an endpoint on a loopback, private, link-local or otherwise
+	// non-public address.
+	ErrPrivateURL = errors.New("url must point to a public address")
+	// ErrInvalidEventTypes indicates no or unknown event types were subscribed to.
+	ErrInvalidEventTypes = errors.New("event_types must list TodoCreated, TodoUpdated, TodoCompleted or TodoDeleted")
+	// ErrDisabled indicates the webhook is disabled and receives no deliveries.
+	ErrDisabled = errors.New("webhook is disabled")
+	// ErrInvalidSignature indicates a delivery whose signature does not match its body.
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	// ErrStaleTimestamp indicates a delivery signed too long ago, or in the future.
+	ErrStaleTimestamp = errors.New("webhook timestamp outside tolerance")
+)
@@ -0,0 +1,237 @@
+package webhook
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultDeliveryLimit = 50
+	maxDeliveryLimit     = 200
+)
+
+// RegisterRoutes wires the webhook HTTP handlers to a sub-router.
+func RegisterRoutes(router *gin.RouterGroup, repo *Repository, dispatcher *Dispatcher) {
+	handler := &Handler{repo: repo, dispatcher: dispatcher}
+
+	router.POST("", handler.createWebhook)
+	router.GET("", handler.listWebhooks)
+	router.GET("/:id", handler.getWebhook)
+	router.PATCH("/:id", handler.updateWebhook)
+	router.DELETE("/:id", handler.deleteWebhook)
+	router.GET("/:id/deliveries", handler.listDeliveries)
+	router.GET("/:id/deliveries/:delivery_id", handler.getDelivery)
+	router.POST("/:id/deliveries/:delivery_id/redeliver", handler.redeliver)
+}
+
+// Handler exposes HTTP endpoints for webhooks.
+type Handler struct {
+	repo       *Repository
+	dispatcher *Dispatcher
+}
+
+func (h *Handler) createWebhook(c *gin.Context) {
+	var input CreateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	w, err := h.repo.Create(c.Request.Context(), input)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusCreated, w)
+	case err == ErrInvalidURL || err == ErrPrivateURL || err == ErrInvalidEventTypes:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (h *Handler) listWebhooks(c *gin.Context) {
+	userID, ok := queryUserID(c)
+	if !ok {
+		return
+	}
+
+	webhooks, err := h.repo.List(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, webhooks)
+}
+
+func (h *Handler) getWebhook(c *gin.Context) {
+	w, ok := h.webhook(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, w)
+}
+
+func (h *Handler) updateWebhook(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var input UpdateInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	w, err := h.repo.Update(c.Request.Context(), id, input)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, w)
+	case err == ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err == ErrInvalidURL || err == ErrPrivateURL || err == ErrInvalidEventTypes:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+func (h *Handler) deleteWebhook(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID, ok := queryUserID(c)
+	if !ok {
+		return
+	}
+
+	switch err := h.repo.Delete(c.Request.Context(), id, userID); {
+	case err == nil:
+		c.Status(http.StatusNoContent)
+	case err == ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// listDeliveries returns the delivery log of a webhook, newest first. status narrows it to
+// pending, succeeded or failed deliveries.
+func (h *Handler) listDeliveries(c *gin.Context) {
+	w, ok := h.webhook(c)
+	if !ok {
+		return
+	}
+
+	status := c.Query("status")
+	if status != "" && !slices.Contains([]string{StatusPending, StatusSucceeded, StatusFailed}, status) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid status"})
+		return
+	}
+
+	limit := defaultDeliveryLimit
+	if raw := c.Query("limit"); raw != "" {
+		if parsed, err := strconv.Atoi(raw); err == nil && parsed > 0 {
+			limit = min(parsed, maxDeliveryLimit)
+		}
+	}
+
+	deliveries, err := h.repo.ListDeliveries(c.Request.Context(), w.ID, status, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, deliveries)
+}
+
+func (h *Handler) getDelivery(c *gin.Context) {
+	w, ok := h.webhook(c)
+	if !ok {
+		return
+	}
+	deliveryID, err := uuid.Parse(c.Param("delivery_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	d, err := h.repo.GetDelivery(c.Request.Context(), w.ID, deliveryID)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusOK, d)
+	case err == ErrDeliveryNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// redeliver sends the event of a delivery again right away and returns the new delivery
+// with the outcome of its first attempt.
+func (h *Handler) redeliver(c *gin.Context) {
+	w, ok := h.webhook(c)
+	if !ok {
+		return
+	}
+	if !w.Enabled {
+		c.JSON(http.StatusConflict, gin.H{"error": ErrDisabled.Error()})
+		return
+	}
+	deliveryID, err := uuid.Parse(c.Param("delivery_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	d, err := h.dispatcher.Redeliver(c.Request.Context(), w.ID, deliveryID)
+	switch {
+	case err == nil:
+		c.JSON(http.StatusCreated, d)
+	case err == ErrDeliveryNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
+// webhook loads the webhook of the request path, which must belong to the user_id query
+// parameter.
+func (h *Handler) webhook(c *gin.Context) (Webhook, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return Webhook{}, false
+	}
+	userID, ok := queryUserID(c)
+	if !ok {
+		return Webhook{}, false
+	}
+
+	w, err := h.repo.Get(c.Request.Context(), id, userID)
+	switch {
+	case err == nil:
+		return w, true
+	case err == ErrNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+	return Webhook{}, false
+}
+
+func queryUserID(c *gin.Context) (uuid.UUID, bool) {
+	userID, err := uuid.Parse(c.Query("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+		return uuid.Nil, false
+	}
+	return userID, true
+}
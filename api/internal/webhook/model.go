@@ -0,0 +1,73 @@
+package webhook
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+
+	"overengineeredtodo/internal/outbox"
+)
+
+// EventTypes are the events webhooks can subscribe to.
+var EventTypes = []string{outbox.TodoCreated, outbox.TodoUpdated, outbox.TodoCompleted, outbox.TodoDeleted}
+
+// Delivery statuses.
+const (
+	StatusPending   = "pending"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// Webhook is an endpoint a user subscribed to todo events. The secret signs the
+// deliveries and is only returned when the webhook is created.
+type Webhook struct {
+	ID                  uuid.UUID  `json:"id"`
+	UserID              uuid.UUID  `json:"user_id"`
+	URL                 string     `json:"url"`
+	Secret              string     `json:"secret,omitempty"`
+	EventTypes          []string   `json:"event_types"`
+	Description         string     `json:"description"`
+	Enabled             bool       `json:"enabled"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	DisabledAt          *time.Time `json:"disabled_at,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
+}
+
+// CreateInput is the payload to register a webhook.
+type CreateInput struct {
+	UserID      uuid.UUID `json:"user_id" binding:"required"`
+	URL         string    `json:"url" binding:"required"`
+	EventTypes  []string  `json:"event_types" binding:"required"`
+	Description string    `json:"description"`
+}
+
+// UpdateInput changes a webhook. Enabling a webhook that was disabled after failing
+// clears its failures.
+type UpdateInput struct {
+	UserID      uuid.UUID `json:"user_id" binding:"required"`
+	URL         *string   `json:"url"`
+	EventTypes  []string  `json:"event_types"`
+	Description *string   `json:"description"`
+	Enabled     *bool     `json:"enabled"`
+}
+
+// Delivery is an event sent, or to be sent, to a webhook. Failed attempts are retried
+// until the delivery succeeds or runs out of attempts.
+type Delivery struct {
+	ID             uuid.UUID       `json:"id"`
+	WebhookID      uuid.UUID       `json:"webhook_id"`
+	EventID        uuid.UUID       `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	RedeliveryOf   *uuid.UUID      `json:"redelivery_of,omitempty"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"next_attempt_at,omitempty"`
+	LastStatusCode *int            `json:"last_status_code,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	DurationMS     *int64          `json:"duration_ms,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+}
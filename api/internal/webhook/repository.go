@@ -0,0 +1,424 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"overengineeredtodo/internal/outbox"
+)
+
+const (
+	webhookColumns  = `id, user_id, url, event_types, description, enabled, consecutive_failures, disabled_at, created_at, updated_at`
+	deliveryColumns = `id, webhook_id, event_id, event_type, payload, redelivery_of, status, attempts, next_attempt_at, last_status_code, last_error, duration_ms, created_at, delivered_at`
+)
+
+// secretBytes is the length of generated signing secrets.
+const secretBytes = 32
+
+// Repository provides Cockroach-backed persistence for webhooks and their deliveries.
+type Repository struct {
+	pool pgxPool
+}
+
+type pgxPool interface {
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+}
+
+// NewRepository constructs a repository around the supplied pgx pool.
+func NewRepository(pool pgxPool) *Repository {
+	return &Repository{pool: pool}
+}
+
+// queued is a delivery together with the endpoint it goes to.
+type queued struct {
+	Delivery
+	url    string
+	secret string
+}
+
+// Create registers a webhook with a newly generated secret, which is returned only here.
+func (r *Repository) Create(ctx context.Context, input CreateInput) (Webhook, error) {
+	if err := validURL(ctx, input.URL); err != nil {
+		return Webhook{}, err
+	}
+	eventTypes, err := normalizeEventTypes(input.EventTypes)
+	if err != nil {
+		return Webhook{}, err
+	}
+
+	raw := make([]byte, secretBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return Webhook{}, fmt.Errorf("generate webhook secret: %w", err)
+	}
+	secret := "whsec_" + base64.RawURLEncoding.EncodeToString(raw)
+
+	query := `
+		INSERT INTO webhooks (id, user_id, url, secret, event_types, description)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + webhookColumns
+
+	w, err := scanWebhook(r.pool.QueryRow(ctx, query, uuid.New(), input.UserID, input.URL, secret, eventTypes, strings.TrimSpace(input.Description)))
+	if err != nil {
+		return Webhook{}, fmt.Errorf("insert webhook: %w", err)
+	}
+	w.Secret = secret
+	return w, nil
+}
+
+// Get fetches one of the user's webhooks.
+func (r *Repository) Get(ctx context.Context, id, userID uuid.UUID) (Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE id = $1 AND user_id = $2`
+
+	w, err := scanWebhook(r.pool.QueryRow(ctx, query, id, userID))
+	switch {
+	case err == nil:
+		return w, nil
+	case err == pgx.ErrNoRows:
+		return Webhook{}, ErrNotFound
+	default:
+		return Webhook{}, fmt.Errorf("select webhook: %w", err)
+	}
+}
+
+// List returns the user's webhooks, oldest first.
+func (r *Repository) List(ctx context.Context, userID uuid.UUID) ([]Webhook, error) {
+	query := `SELECT ` + webhookColumns + ` FROM webhooks WHERE user_id = $1 ORDER BY created_at ASC, id ASC`
+
+	rows, err := r.pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("query webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	result := []Webhook{}
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan webhook: %w", err)
+		}
+		result = append(result, w)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate webhooks: %w", rows.Err())
+	}
+	return result, nil
+}
+
+// Update changes one of input.UserID's webhooks. Enabling a webhook clears its failures,
+// so that a webhook disabled for failing gets a fresh start.
+func (r *Repository) Update(ctx context.Context, id uuid.UUID, input UpdateInput) (Webhook, error) {
+	setClauses := []string{}
+	args := []any{}
+	next := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if input.URL != nil {
+		if err := validURL(ctx, *input.URL); err != nil {
+			return Webhook{}, err
+		}
+		setClauses = append(setClauses, "url = "+next(*input.URL))
+	}
+	if input.EventTypes != nil {
+		eventTypes, err := normalizeEventTypes(input.EventTypes)
+		if err != nil {
+			return Webhook{}, err
+		}
+		setClauses = append(setClauses, "event_types = "+next(eventTypes))
+	}
+	if input.Description != nil {
+		setClauses = append(setClauses, "description = "+next(strings.TrimSpace(*input.Description)))
+	}
+	if input.Enabled != nil {
+		if *input.Enabled {
+			setClauses = append(setClauses, "enabled = true", "consecutive_failures = 0", "disabled_at = NULL")
+		} else {
+			setClauses = append(setClauses, "enabled = false", "disabled_at = COALESCE(disabled_at, now())")
+		}
+	}
+
+	if len(setClauses) == 0 {
+		return r.Get(ctx, id, input.UserID)
+	}
+	setClauses = append(setClauses, "updated_at = now()")
+
+	query := fmt.Sprintf(`
+		UPDATE webhooks SET %s
+		WHERE id = %s AND user_id = %s
+		RETURNING %s
+	`, strings.Join(setClauses, ", "), next(id), next(input.UserID), webhookColumns)
+
+	w, err := scanWebhook(r.pool.QueryRow(ctx, query, args...))
+	switch {
+	case err == nil:
+		return w, nil
+	case err == pgx.ErrNoRows:
+		return Webhook{}, ErrNotFound
+	default:
+		return Webhook{}, fmt.Errorf("update webhook: %w", err)
+	}
+}
+
+// Delete removes one of the user's webhooks along with its deliveries.
+func (r *Repository) Delete(ctx context.Context, id, userID uuid.UUID) error {
+	tag, err := r.pool.Exec(ctx, `DELETE FROM webhooks WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("delete webhook: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// Enqueue queues a delivery of the event, encoded as body, to every enabled webhook that
+// subscribed to its type and whose user can see the todo: its owner and, for todos of a
+// project, the project's members. An event already queued for a webhook is skipped, so
+// publishing it again is harmless. Returns the number of deliveries queued.
+func (r *Repository) Enqueue(ctx context.Context, e outbox.Event, body []byte, ownerID uuid.UUID, projectID *uuid.UUID) (int64, error) {
+	query := `
+		INSERT INTO webhook_deliveries (id, webhook_id, event_id, event_type, payload)
+		SELECT gen_random_uuid(), w.id, $1, $2, $3
+		FROM webhooks w
+		WHERE w.enabled
+		  AND $2 = ANY(w.event_types)
+		  AND (w.user_id = $4 OR EXISTS (
+			SELECT 1 FROM project_members m WHERE m.project_id = $5 AND m.user_id = w.user_id
+		  ))
+		ON CONFLICT DO NOTHING
+	`
+
+	tag, err := r.pool.Exec(ctx, query, e.ID, e.Type, body, ownerID, projectID)
+	if err != nil {
+		return 0, fmt.Errorf("enqueue webhook deliveries: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ListDeliveries returns the deliveries of a webhook, newest first, optionally only those
+// with the status.
+func (r *Repository) ListDeliveries(ctx context.Context, webhookID uuid.UUID, status string, limit int) ([]Delivery, error) {
+	query := `
+		SELECT ` + deliveryColumns + `
+		FROM webhook_deliveries
+		WHERE webhook_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3
+	`
+
+	rows, err := r.pool.Query(ctx, query, webhookID, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("query webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	result := []Delivery{}
+	for rows.Next() {
+		d, err := scanDelivery(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan webhook delivery: %w", err)
+		}
+		result = append(result, d)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate webhook deliveries: %w", rows.Err())
+	}
+	return result, nil
+}
+
+// GetDelivery fetches a delivery of the webhook.
+func (r *Repository) GetDelivery(ctx context.Context, webhookID, id uuid.UUID) (Delivery, error) {
+	query := `SELECT ` + deliveryColumns + ` FROM webhook_deliveries WHERE id = $1 AND webhook_id = $2`
+
+	d, err := scanDelivery(r.pool.QueryRow(ctx, query, id, webhookID))
+	switch {
+	case err == nil:
+		return d, nil
+	case err == pgx.ErrNoRows:
+		return Delivery{}, ErrDeliveryNotFound
+	default:
+		return Delivery{}, fmt.Errorf("select webhook delivery: %w", err)
+	}
+}
+
+// claimDue takes up to limit deliveries whose attempt is due. Their next attempt moves to
+// leaseUntil, so that a delivery is not sent twice at once and is tried again should the
+// attempt never be recorded.
+func (r *Repository) claimDue(ctx context.Context, limit int, leaseUntil time.Time) ([]queued, error) {
+	query := `
+		WITH claimed AS (
+			UPDATE webhook_deliveries
+			SET next_attempt_at = $2
+			WHERE id IN (
+				SELECT d.id
+				FROM webhook_deliveries d
+				JOIN webhooks w ON w.id = d.webhook_id
+				WHERE d.status = 'pending' AND d.next_attempt_at <= now() AND w.enabled
+				ORDER BY d.next_attempt_at ASC
+				LIMIT $1
+			)
+			RETURNING ` + deliveryColumns + `
+		)
+		SELECT ` + prefixed("c", deliveryColumns) + `, w.url, w.secret
+		FROM claimed c
+		JOIN webhooks w ON w.id = c.webhook_id
+	`
+
+	rows, err := r.pool.Query(ctx, query, limit, leaseUntil)
+	if err != nil {
+		return nil, fmt.Errorf("claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var result []queued
+	for rows.Next() {
+		q, err := scanQueued(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan webhook delivery: %w", err)
+		}
+		result = append(result, q)
+	}
+
+	if rows.Err() != nil {
+		return nil, fmt.Errorf("iterate webhook deliveries: %w", rows.Err())
+	}
+	return result, nil
+}
+
+// redeliver queues a new delivery of the event of an earlier delivery, claimed until
+// leaseUntil so that the caller can send it right away.
+func (r *Repository) redeliver(ctx context.Context, webhookID, deliveryID uuid.UUID, leaseUntil time.Time) (queued, error) {
+	query := `
+		WITH inserted AS (
+			INSERT INTO webhook_deliveries (id, webhook_id, event_id, event_type, payload, redelivery_of, next_attempt_at)
+			SELECT $1, webhook_id, event_id, event_type, payload, COALESCE(redelivery_of, id), $4
+			FROM webhook_deliveries
+			WHERE id = $2 AND webhook_id = $3
+			RETURNING ` + deliveryColumns + `
+		)
+		SELECT ` + prefixed("i", deliveryColumns) + `, w.url, w.secret
+		FROM inserted i
+		JOIN webhooks w ON w.id = i.webhook_id
+	`
+
+	q, err := scanQueued(r.pool.QueryRow(ctx, query, uuid.New(), deliveryID, webhookID, leaseUntil))
+	switch {
+	case err == nil:
+		return q, nil
+	case err == pgx.ErrNoRows:
+		return queued{}, ErrDeliveryNotFound
+	default:
+		return queued{}, fmt.Errorf("insert webhook redelivery: %w", err)
+	}
+}
+
+// recordSuccess marks the delivery as delivered and clears the failures of its webhook.
+func (r *Repository) recordSuccess(ctx context.Context, id uuid.UUID, statusCode int, duration time.Duration) error {
+	query := `
+		WITH delivered AS (
+			UPDATE webhook_deliveries
+			SET status = 'succeeded', attempts = attempts + 1, next_attempt_at = NULL,
+				last_status_code = $2, last_error = '', duration_ms = $3, delivered_at = now()
+			WHERE id = $1
+			RETURNING webhook_id
+		)
+		UPDATE webhooks SET consecutive_failures = 0
+		WHERE id IN (SELECT webhook_id FROM delivered)
+	`
+
+	if _, err := r.pool.Exec(ctx, query, id, statusCode, duration.Milliseconds()); err != nil {
+		return fmt.Errorf("record webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// recordFailure records a failed attempt of the delivery, which is tried again at retryAt
+// or, without one, gives up. The webhook is disabled once it has failed disableAfter
+// times in a row; recordFailure reports whether it is still enabled.
+func (r *Repository) recordFailure(ctx context.Context, id uuid.UUID, statusCode *int, cause string, duration time.Duration, retryAt *time.Time, disableAfter int) (bool, error) {
+	query := `
+		WITH failed AS (
+			UPDATE webhook_deliveries
+			SET status = CASE WHEN $5::TIMESTAMPTZ IS NULL THEN 'failed' ELSE 'pending' END,
+				attempts = attempts + 1, next_attempt_at = $5,
+				last_status_code = $2, last_error = $3, duration_ms = $4
+			WHERE id = $1
+			RETURNING webhook_id
+		)
+		UPDATE webhooks
+		SET consecutive_failures = consecutive_failures + 1,
+			enabled = enabled AND consecutive_failures + 1 < $6,
+			disabled_at = CASE WHEN enabled AND consecutive_failures + 1 >= $6 THEN now() ELSE disabled_at END
+		WHERE id IN (SELECT webhook_id FROM failed)
+		RETURNING enabled
+	`
+
+	var enabled bool
+	if err := r.pool.QueryRow(ctx, query, id, statusCode, cause, duration.Milliseconds(), retryAt, disableAfter).Scan(&enabled); err != nil {
+		return false, fmt.Errorf("record failed webhook delivery: %w", err)
+	}
+	return enabled, nil
+}
+
+func scanWebhook(row pgx.Row) (Webhook, error) {
+	var w Webhook
+	err := row.Scan(&w.ID, &w.UserID, &w.URL, &w.EventTypes, &w.Description, &w.Enabled, &w.ConsecutiveFailures, &w.DisabledAt, &w.CreatedAt, &w.UpdatedAt)
+	return w, err
+}
+
+func scanDelivery(row pgx.Row) (Delivery, error) {
+	var d Delivery
+	err := row.Scan(deliveryFields(&d)...)
+	return d, err
+}
+
+func scanQueued(row pgx.Row) (queued, error) {
+	var q queued
+	err := row.Scan(append(deliveryFields(&q.Delivery), &q.url, &q.secret)...)
+	return q, err
+}
+
+func deliveryFields(d *Delivery) []any {
+	return []any{&d.ID, &d.WebhookID, &d.EventID, &d.EventType, &d.Payload, &d.RedeliveryOf, &d.Status, &d.Attempts,
+		&d.NextAttemptAt, &d.LastStatusCode, &d.LastError, &d.DurationMS, &d.CreatedAt, &d.DeliveredAt}
+}
+
+// prefixed qualifies each of the comma separated columns with alias.
+func prefixed(alias, columns string) string {
+	names := strings.Split(columns, ", ")
+	for i, name := range names {
+		names[i] = alias + "." + name
+	}
+	return strings.Join(names, ", ")
+}
+
+// normalizeEventTypes checks the subscribed event types and returns them sorted, without
+// duplicates.
+func normalizeEventTypes(eventTypes []string) ([]string, error) {
+	if len(eventTypes) == 0 {
+		return nil, ErrInvalidEventTypes
+	}
+	result := make([]string, 0, len(eventTypes))
+	for _, t := range eventTypes {
+		if !slices.Contains(EventTypes, t) {
+			return nil, ErrInvalidEventTypes
+		}
+		result = append(result, t)
+	}
+	slices.Sort(result)
+	return slices.Compact(result), nil
+}
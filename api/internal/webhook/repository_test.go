@@ -0,0 +1,128 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	pgxmock "github.com/pashagolub/pgxmock/v3"
+	"github.com/stretchr/testify/require"
+
+	"overengineeredtodo/internal/outbox"
+)
+
+func newWebhookRows(webhooks ...Webhook) *pgxmock.Rows {
+	rows := pgxmock.NewRows(strings.Split(webhookColumns, ", "))
+	for _, w := range webhooks {
+		rows.AddRow(w.ID, w.UserID, w.URL, w.EventTypes, w.Description, w.Enabled, w.ConsecutiveFailures, w.DisabledAt, w.CreatedAt, w.UpdatedAt)
+	}
+	return rows
+}
+
+func deliveryValues(d Delivery) []any {
+	return []any{d.ID, d.WebhookID, d.EventID, d.EventType, d.Payload, d.RedeliveryOf, d.Status, d.Attempts,
+		d.NextAttemptAt, d.LastStatusCode, d.LastError, d.DurationMS, d.CreatedAt, d.DeliveredAt}
+}
+
+func newDeliveryRows(deliveries ...Delivery) *pgxmock.Rows {
+	rows := pgxmock.NewRows(strings.Split(deliveryColumns, ", "))
+	for _, d := range deliveries {
+		rows.AddRow(deliveryValues(d)...)
+	}
+	return rows
+}
+
+// newQueuedRows builds the rows of deliveries claimed for sending to url.
+func newQueuedRows(url, secret string, deliveries ...Delivery) *pgxmock.Rows {
+	rows := pgxmock.NewRows(append(strings.Split(deliveryColumns, ", "), "url", "secret"))
+	for _, d := range deliveries {
+		rows.AddRow(append(deliveryValues(d), url, secret)...)
+	}
+	return rows
+}
+
+func TestRepositoryCreateGeneratesSecret(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	input := CreateInput{UserID: uuid.New(), URL: "https://hooks.example.com/todos", EventTypes: []string{outbox.TodoDeleted, outbox.TodoCreated, outbox.TodoCreated}}
+	created := Webhook{ID: uuid.New(), UserID: input.UserID, URL: input.URL, EventTypes: []string{outbox.TodoCreated, outbox.TodoDeleted}, Enabled: true, CreatedAt: time.Now(), UpdatedAt: time.Now()}
+
+	mock.ExpectQuery("INSERT INTO webhooks \\(id, user_id, url, secret, event_types, description\\)").
+		WithArgs(pgxmock.AnyArg(), input.UserID, input.URL, pgxmock.AnyArg(), []string{outbox.TodoCreated, outbox.TodoDeleted}, "").
+		WillReturnRows(newWebhookRows(created))
+
+	w, err := NewRepository(mock).Create(context.Background(), input)
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(w.Secret, "whsec_"))
+	require.Equal(t, created.EventTypes, w.EventTypes)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryCreateValidates(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	repo := NewRepository(mock)
+	userID := uuid.New()
+
+	_, err = repo.Create(context.Background(), CreateInput{UserID: userID, URL: "ftp://hooks.example.com", EventTypes: []string{outbox.TodoCreated}})
+	require.ErrorIs(t, err, ErrInvalidURL)
+	_, err = repo.Create(context.Background(), CreateInput{UserID: userID, URL: "/relative", EventTypes: []string{outbox.TodoCreated}})
+	require.ErrorIs(t, err, ErrInvalidURL)
+	_, err = repo.Create(context.Background(), CreateInput{UserID: userID, URL: "http://127.0.0.1:8081/v1/todos", EventTypes: []string{outbox.TodoCreated}})
+	require.ErrorIs(t, err, ErrPrivateURL)
+	_, err = repo.Create(context.Background(), CreateInput{UserID: userID, URL: "https://hooks.example.com", EventTypes: []string{outbox.UserCreated}})
+	require.ErrorIs(t, err, ErrInvalidEventTypes)
+	_, err = repo.Create(context.Background(), CreateInput{UserID: userID, URL: "https://hooks.example.com"})
+	require.ErrorIs(t, err, ErrInvalidEventTypes)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRepositoryUpdateEnableClearsFailures(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	id, userID := uuid.New(), uuid.New()
+	enabled := true
+	mock.ExpectQuery("UPDATE webhooks SET enabled = true, consecutive_failures = 0, disabled_at = NULL, updated_at = now\\(\\)\\s+WHERE id = \\$1 AND user_id = \\$2").
+		WithArgs(id, userID).
+		WillReturnError(pgx.ErrNoRows)
+
+	_, err = NewRepository(mock).Update(context.Background(), id, UpdateInput{UserID: userID, Enabled: &enabled})
+	require.ErrorIs(t, err, ErrNotFound)
+	require.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSinkEnqueuesTodoEvents(t *testing.T) {
+	mock, err := pgxmock.NewPool()
+	require.NoError(t, err)
+	defer mock.Close()
+
+	ownerID, projectID := uuid.New(), uuid.New()
+	e, err := outbox.NewEvent(outbox.TodoCompleted, outbox.AggregateTodo, uuid.New(), 3,
+		map[string]any{"id": uuid.New(), "user_id": ownerID, "project_id": projectID})
+	require.NoError(t, err)
+	body, err := json.Marshal(e)
+	require.NoError(t, err)
+
+	mock.ExpectExec("INSERT INTO webhook_deliveries .* SELECT gen_random_uuid\\(\\), w.id, \\$1, \\$2, \\$3\\s+FROM webhooks w\\s+WHERE w.enabled\\s+AND \\$2 = ANY\\(w.event_types\\) .* ON CONFLICT DO NOTHING").
+		WithArgs(e.ID, outbox.TodoCompleted, body, ownerID, &projectID).
+		WillReturnResult(pgxmock.NewResult("INSERT", 2))
+
+	sink := NewSink(NewRepository(mock))
+	require.NoError(t, sink.Publish(context.Background(), e))
+
+	// Events of users concern no webhook.
+	userEvent, err := outbox.NewEvent(outbox.UserCreated, outbox.AggregateUser, ownerID, 1, map[string]any{"id": ownerID})
+	require.NoError(t, err)
+	require.NoError(t, sink.Publish(context.Background(), userEvent))
+	require.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,33 @@
+package webhook
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignAndVerify(t *testing.T) {
+	now := time.Unix(1_750_000_000, 0)
+	body := []byte(`{"type":"TodoCreated"}`)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signature := Sign("whsec_test", now.Unix(), body)
+
+	require.Regexp(t, "^v1=[0-9a-f]{64}$", signature)
+	require.NoError(t, Verify("whsec_test", timestamp, signature, body, 5*time.Minute, now.Add(time.Minute)))
+
+	require.ErrorIs(t, Verify("whsec_other", timestamp, signature, body, 5*time.Minute, now), ErrInvalidSignature)
+	require.ErrorIs(t, Verify("whsec_test", timestamp, signature, []byte(`{"type":"TodoDeleted"}`), 5*time.Minute, now), ErrInvalidSignature)
+	require.ErrorIs(t, Verify("whsec_test", "yesterday", signature, body, 5*time.Minute, now), ErrInvalidSignature)
+}
+
+func TestVerifyRejectsReplays(t *testing.T) {
+	sent := time.Unix(1_750_000_000, 0)
+	body := []byte(`{}`)
+	signature := Sign("whsec_test", sent.Unix(), body)
+	timestamp := strconv.FormatInt(sent.Unix(), 10)
+
+	require.ErrorIs(t, Verify("whsec_test", timestamp, signature, body, 5*time.Minute, sent.Add(10*time.Minute)), ErrStaleTimestamp)
+	require.ErrorIs(t, Verify("whsec_test", timestamp, signature, body, 5*time.Minute, sent.Add(-10*time.Minute)), ErrStaleTimestamp)
+}
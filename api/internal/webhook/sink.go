@@ -0,0 +1,46 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"overengineeredtodo/internal/outbox"
+)
+
+// Sink takes the todo events published by the outbox relay and queues them for the
+// webhooks subscribed to them; the Dispatcher sends them on.
+type Sink struct {
+	repo *Repository
+}
+
+// NewSink returns a sink queueing deliveries in repo.
+func NewSink(repo *Repository) *Sink {
+	return &Sink{repo: repo}
+}
+
+// Publish queues the event for its subscribers. Events of other aggregates than todos are
+// ignored.
+func (s *Sink) Publish(ctx context.Context, e outbox.Event) error {
+	if e.AggregateType != outbox.AggregateTodo {
+		return nil
+	}
+
+	// Todo payloads, including those of deleted todos, name the todo's owner and project.
+	var owner struct {
+		UserID    uuid.UUID  `json:"user_id"`
+		ProjectID *uuid.UUID `json:"project_id"`
+	}
+	if err := json.Unmarshal(e.Payload, &owner); err != nil {
+		return fmt.Errorf("decode todo event: %w", err)
+	}
+
+	body, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encode event: %w", err)
+	}
+	_, err = s.repo.Enqueue(ctx, e, body, owner.UserID, owner.ProjectID)
+	return err
+}
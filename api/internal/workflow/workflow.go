@@ -0,0 +1,173 @@
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Keys of the default workflow statuses.
+const (
+	StatusBacklog    = "backlog"
+	StatusInProgress = "in_progress"
+	StatusReview     = "review"
+	StatusDone       = "done"
+)
+
+// ErrInvalid indicates a workflow definition is inconsistent.
+var ErrInvalid = errors.New("invalid workflow")
+
+// Status is one stage of a workflow. Todos in a Done status count as completed.
+type Status struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+	Done bool   `json:"done,omitempty"`
+}
+
+// Workflow lists the statuses in board order and the allowed transitions between them.
+// A nil Transitions map allows every move.
+type Workflow struct {
+	Initial     string              `json:"initial"`
+	Statuses    []Status            `json:"statuses"`
+	Transitions map[string][]string `json:"transitions,omitempty"`
+}
+
+// Default returns the workflow used by todos outside projects with a custom one:
+// backlog → in progress → review → done, where anything can be completed directly
+// and done todos reopen into progress.
+func Default() Workflow {
+	return Workflow{
+		Initial: StatusBacklog,
+		Statuses: []Status{
+			{Key: StatusBacklog, Name: "Backlog"},
+			{Key: StatusInProgress, Name: "In progress"},
+			{Key: StatusReview, Name: "Review"},
+			{Key: StatusDone, Name: "Done", Done: true},
+		},
+		Transitions: map[string][]string{
+			StatusBacklog:    {StatusInProgress, StatusDone},
+			StatusInProgress: {StatusBacklog, StatusReview, StatusDone},
+			StatusReview:     {StatusInProgress, StatusDone},
+			StatusDone:       {StatusInProgress},
+		},
+	}
+}
+
+// Validate checks that keys are unique, the initial status is open, at least one status
+// is done and transitions only refer to known statuses.
+func (w Workflow) Validate() error {
+	if len(w.Statuses) == 0 {
+		return fmt.Errorf("%w: at least one status is required", ErrInvalid)
+	}
+
+	keys := make(map[string]bool, len(w.Statuses))
+	hasDone := false
+	for _, s := range w.Statuses {
+		if strings.TrimSpace(s.Key) == "" {
+			return fmt.Errorf("%w: status keys must not be empty", ErrInvalid)
+		}
+		if keys[s.Key] {
+			return fmt.Errorf("%w: duplicate status %q", ErrInvalid, s.Key)
+		}
+		keys[s.Key] = true
+		hasDone = hasDone || s.Done
+	}
+
+	if !hasDone {
+		return fmt.Errorf("%w: at least one status must be done", ErrInvalid)
+	}
+	if !keys[w.Initial] {
+		return fmt.Errorf("%w: unknown initial status %q", ErrInvalid, w.Initial)
+	}
+	if w.IsDone(w.Initial) {
+		return fmt.Errorf("%w: the initial status must not be done", ErrInvalid)
+	}
+
+	for from, targets := range w.Transitions {
+		if !keys[from] {
+			return fmt.Errorf("%w: transition from unknown status %q", ErrInvalid, from)
+		}
+		for _, to := range targets {
+			if !keys[to] {
+				return fmt.Errorf("%w: transition to unknown status %q", ErrInvalid, to)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Has reports whether key is a status of the workflow.
+func (w Workflow) Has(key string) bool {
+	_, ok := w.status(key)
+	return ok
+}
+
+// IsDone reports whether key is a done status.
+func (w Workflow) IsDone(key string) bool {
+	s, ok := w.status(key)
+	return ok && s.Done
+}
+
+// CanTransition reports whether a todo may move from one status to another. Todos in a
+// status the workflow does not know, for example after the workflow changed, may move anywhere.
+func (w Workflow) CanTransition(from, to string) bool {
+	if !w.Has(to) {
+		return false
+	}
+	if from == to || w.Transitions == nil || !w.Has(from) {
+		return true
+	}
+	return slices.Contains(w.Transitions[from], to)
+}
+
+// Complete returns the done status a todo in from moves to when it is marked complete.
+func (w Workflow) Complete(from string) (string, bool) {
+	if w.IsDone(from) {
+		return from, true
+	}
+	return w.firstTarget(from, true)
+}
+
+// Reopen returns the open status a todo in from moves to when it is marked incomplete.
+func (w Workflow) Reopen(from string) (string, bool) {
+	if w.Has(from) && !w.IsDone(from) {
+		return from, true
+	}
+	if w.Transitions == nil || !w.Has(from) {
+		return w.Initial, true
+	}
+	return w.firstTarget(from, false)
+}
+
+// Fallback returns the status for a todo whose status is not part of the workflow.
+func (w Workflow) Fallback(completed bool) string {
+	if completed {
+		for _, s := range w.Statuses {
+			if s.Done {
+				return s.Key
+			}
+		}
+	}
+	return w.Initial
+}
+
+// firstTarget returns the first status reachable from from whose done flag matches.
+func (w Workflow) firstTarget(from string, done bool) (string, bool) {
+	for _, s := range w.Statuses {
+		if s.Done == done && s.Key != from && w.CanTransition(from, s.Key) {
+			return s.Key, true
+		}
+	}
+	return "", false
+}
+
+func (w Workflow) status(key string) (Status, bool) {
+	for _, s := range w.Statuses {
+		if s.Key == key {
+			return s, true
+		}
+	}
+	return Status{}, false
+}
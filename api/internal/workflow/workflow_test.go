@@ -0,0 +1,76 @@
+package workflow
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultWorkflow(t *testing.T) {
+	w := Default()
+	require.NoError(t, w.Validate())
+
+	require.True(t, w.CanTransition(StatusBacklog, StatusInProgress))
+	require.False(t, w.CanTransition(StatusBacklog, StatusReview))
+	require.False(t, w.CanTransition(StatusDone, "archived"))
+	require.True(t, w.CanTransition("legacy", StatusReview))
+
+	done, ok := w.Complete(StatusReview)
+	require.True(t, ok)
+	require.Equal(t, StatusDone, done)
+
+	open, ok := w.Reopen(StatusDone)
+	require.True(t, ok)
+	require.Equal(t, StatusInProgress, open)
+}
+
+func TestCustomWorkflowWithoutShortcut(t *testing.T) {
+	w := Workflow{
+		Initial: "todo",
+		Statuses: []Status{
+			{Key: "todo", Name: "To do"},
+			{Key: "qa", Name: "QA"},
+			{Key: "shipped", Name: "Shipped", Done: true},
+		},
+		Transitions: map[string][]string{
+			"todo": {"qa"},
+			"qa":   {"todo", "shipped"},
+		},
+	}
+	require.NoError(t, w.Validate())
+
+	_, ok := w.Complete("todo")
+	require.False(t, ok)
+
+	done, ok := w.Complete("qa")
+	require.True(t, ok)
+	require.Equal(t, "shipped", done)
+
+	// Shipped todos have no way back.
+	_, ok = w.Reopen("shipped")
+	require.False(t, ok)
+
+	require.Equal(t, "shipped", w.Fallback(true))
+	require.Equal(t, "todo", w.Fallback(false))
+}
+
+func TestValidateRejectsInconsistentWorkflows(t *testing.T) {
+	cases := map[string]Workflow{
+		"empty":           {},
+		"no done":         {Initial: "a", Statuses: []Status{{Key: "a"}}},
+		"duplicate":       {Initial: "a", Statuses: []Status{{Key: "a"}, {Key: "a", Done: true}}},
+		"initial done":    {Initial: "a", Statuses: []Status{{Key: "a", Done: true}}},
+		"unknown initial": {Initial: "x", Statuses: []Status{{Key: "a"}, {Key: "b", Done: true}}},
+		"unknown target": {
+			Initial:     "a",
+			Statuses:    []Status{{Key: "a"}, {Key: "b", Done: true}},
+			Transitions: map[string][]string{"a": {"c"}},
+		},
+	}
+
+	for name, w := range cases {
+		t.Run(name, func(t *testing.T) {
+			require.ErrorIs(t, w.Validate(), ErrInvalid)
+		})
+	}
+}
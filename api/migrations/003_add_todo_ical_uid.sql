@@ -0,0 +1,3 @@
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS ical_uid STRING;
+
+CREATE UNIQUE INDEX IF NOT EXISTS todos_user_id_ical_uid_idx ON todos (user_id, ical_uid);
@@ -0,0 +1,5 @@
+CREATE TABLE IF NOT EXISTS feed_tokens (
+    user_id UUID PRIMARY KEY REFERENCES users (id) ON DELETE CASCADE,
+    token_hash STRING NOT NULL UNIQUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,10 @@
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS version INT8 NOT NULL DEFAULT 1;
+
+CREATE TABLE IF NOT EXISTS todo_tombstones (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    uid STRING NOT NULL,
+    deleted_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS todo_tombstones_user_id_deleted_at_idx ON todo_tombstones (user_id, deleted_at);
@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS projects (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name STRING NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS projects_user_id_name_idx ON projects (user_id, name);
+
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS project_id UUID REFERENCES projects (id) ON DELETE SET NULL;
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS labels STRING[] NOT NULL DEFAULT ARRAY[]::STRING[];
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS priority INT2 NOT NULL DEFAULT 0;
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS external_source STRING;
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS external_id STRING;
+
+CREATE INDEX IF NOT EXISTS todos_project_id_idx ON todos (project_id);
+CREATE UNIQUE INDEX IF NOT EXISTS todos_user_id_external_idx ON todos (user_id, external_source, external_id);
+
+CREATE TABLE IF NOT EXISTS import_jobs (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    source STRING NOT NULL,
+    status STRING NOT NULL,
+    total INT8 NOT NULL DEFAULT 0,
+    processed INT8 NOT NULL DEFAULT 0,
+    created INT8 NOT NULL DEFAULT 0,
+    updated INT8 NOT NULL DEFAULT 0,
+    unchanged INT8 NOT NULL DEFAULT 0,
+    skipped INT8 NOT NULL DEFAULT 0,
+    error STRING,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    finished_at TIMESTAMPTZ
+);
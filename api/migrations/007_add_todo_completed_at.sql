@@ -0,0 +1,6 @@
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS completed_at TIMESTAMPTZ;
+
+-- Best guess for todos completed before the column existed.
+UPDATE todos SET completed_at = updated_at WHERE completed AND completed_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS todos_user_id_completed_at_idx ON todos (user_id, completed_at);
@@ -0,0 +1,7 @@
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS workflow JSONB;
+
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS status STRING NOT NULL DEFAULT 'backlog';
+
+UPDATE todos SET status = 'done' WHERE completed AND status = 'backlog';
+
+CREATE INDEX IF NOT EXISTS todos_user_id_status_idx ON todos (user_id, status);
@@ -0,0 +1,6 @@
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS custom_fields JSONB NOT NULL DEFAULT '[]';
+
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS custom_fields JSONB NOT NULL DEFAULT '{}';
+
+-- Serves the containment filters of the list endpoint.
+CREATE INVERTED INDEX IF NOT EXISTS todos_custom_fields_idx ON todos (custom_fields);
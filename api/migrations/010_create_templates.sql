@@ -0,0 +1,16 @@
+-- Subtasks of a deleted todo become top-level todos.
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES todos (id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS todos_parent_id_idx ON todos (parent_id);
+
+CREATE TABLE IF NOT EXISTS templates (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name STRING NOT NULL,
+    description STRING NOT NULL DEFAULT '',
+    items JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS templates_user_id_idx ON templates (user_id);
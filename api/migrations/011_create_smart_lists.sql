@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS smart_lists (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name STRING NOT NULL,
+    query STRING NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS smart_lists_user_id_name_idx ON smart_lists (user_id, name);
@@ -0,0 +1,12 @@
+-- Full-text search over titles and descriptions. Titles weigh more than descriptions in
+-- the ranking. CockroachDB backfills the stored column and builds the indexes online, so
+-- the table stays readable and writable while this migration runs.
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS search_vector TSVECTOR AS (
+    setweight(to_tsvector('english', title), 'A') || setweight(to_tsvector('english', description), 'B')
+) STORED;
+
+CREATE INVERTED INDEX IF NOT EXISTS todos_search_vector_idx ON todos (search_vector);
+
+-- Trigram indexes serve substring matches on words the stemmer does not split off.
+CREATE INVERTED INDEX IF NOT EXISTS todos_title_trgm_idx ON todos (title gin_trgm_ops);
+CREATE INVERTED INDEX IF NOT EXISTS todos_description_trgm_idx ON todos (description gin_trgm_ops);
@@ -0,0 +1,2 @@
+-- RFC 5545 RRULE value such as FREQ=WEEKLY;BYDAY=MO; empty for one-off todos.
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS recurrence STRING NOT NULL DEFAULT '';
@@ -0,0 +1,10 @@
+-- todo_id cannot start before blocker_id is completed.
+CREATE TABLE IF NOT EXISTS todo_dependencies (
+    todo_id UUID NOT NULL REFERENCES todos (id) ON DELETE CASCADE,
+    blocker_id UUID NOT NULL REFERENCES todos (id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (todo_id, blocker_id),
+    CHECK (todo_id != blocker_id)
+);
+
+CREATE INDEX IF NOT EXISTS todo_dependencies_blocker_id_idx ON todo_dependencies (blocker_id);
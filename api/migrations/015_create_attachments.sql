@@ -0,0 +1,14 @@
+-- todo_id is cleared when the todo is deleted; the service then removes the blob and the
+-- row. user_id has no foreign key for the same reason.
+CREATE TABLE IF NOT EXISTS attachments (
+    id UUID PRIMARY KEY,
+    todo_id UUID REFERENCES todos (id) ON DELETE SET NULL,
+    user_id UUID NOT NULL,
+    filename STRING NOT NULL,
+    content_type STRING NOT NULL,
+    size INT8 NOT NULL CHECK (size >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS attachments_todo_id_idx ON attachments (todo_id);
+CREATE INDEX IF NOT EXISTS attachments_user_id_idx ON attachments (user_id) STORING (size);
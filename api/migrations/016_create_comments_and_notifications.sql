@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS comments (
+    id UUID PRIMARY KEY,
+    todo_id UUID NOT NULL REFERENCES todos (id) ON DELETE CASCADE,
+    author_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    body STRING NOT NULL,
+    mentions UUID[] NOT NULL DEFAULT ARRAY[]::UUID[],
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    edited_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS comments_todo_id_created_at_idx ON comments (todo_id, created_at);
+
+-- Earlier bodies of edited comments.
+CREATE TABLE IF NOT EXISTS comment_revisions (
+    id UUID PRIMARY KEY,
+    comment_id UUID NOT NULL REFERENCES comments (id) ON DELETE CASCADE,
+    body STRING NOT NULL,
+    replaced_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS comment_revisions_comment_id_idx ON comment_revisions (comment_id, replaced_at);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    kind STRING NOT NULL,
+    todo_id UUID NOT NULL REFERENCES todos (id) ON DELETE CASCADE,
+    comment_id UUID REFERENCES comments (id) ON DELETE CASCADE,
+    actor_id UUID NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    read_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS notifications_user_id_created_at_idx ON notifications (user_id, created_at DESC);
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS project_members (
+    project_id UUID NOT NULL REFERENCES projects (id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    role STRING NOT NULL CHECK (role IN ('viewer', 'editor', 'owner')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (project_id, user_id)
+);
+
+CREATE INDEX IF NOT EXISTS project_members_user_id_idx ON project_members (user_id);
+
+-- Every existing project is owned by its creator; users who already keep todos in a
+-- project of someone else keep access as editors.
+INSERT INTO project_members (project_id, user_id, role, created_at)
+SELECT id, user_id, 'owner', created_at FROM projects
+ON CONFLICT (project_id, user_id) DO NOTHING;
+
+INSERT INTO project_members (project_id, user_id, role)
+SELECT DISTINCT t.project_id, t.user_id, 'editor'
+FROM todos t
+JOIN projects p ON p.id = t.project_id
+WHERE t.user_id <> p.user_id
+ON CONFLICT (project_id, user_id) DO NOTHING;
+
+-- Deletions in shared projects have to reach the sync clients of every member.
+ALTER TABLE todo_tombstones ADD COLUMN IF NOT EXISTS project_id UUID;
+
+CREATE INDEX IF NOT EXISTS todo_tombstones_project_id_deleted_at_idx ON todo_tombstones (project_id, deleted_at);
@@ -0,0 +1,19 @@
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS estimate_minutes INT8 CHECK (estimate_minutes >= 0);
+
+CREATE TABLE IF NOT EXISTS time_entries (
+    id UUID PRIMARY KEY,
+    todo_id UUID NOT NULL REFERENCES todos (id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    started_at TIMESTAMPTZ NOT NULL,
+    -- NULL while the timer is running.
+    ended_at TIMESTAMPTZ,
+    note STRING NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (ended_at IS NULL OR ended_at >= started_at)
+);
+
+CREATE INDEX IF NOT EXISTS time_entries_todo_id_started_at_idx ON time_entries (todo_id, started_at);
+CREATE INDEX IF NOT EXISTS time_entries_user_id_started_at_idx ON time_entries (user_id, started_at);
+
+-- Each user runs at most one timer at a time.
+CREATE UNIQUE INDEX IF NOT EXISTS time_entries_running_idx ON time_entries (user_id) WHERE ended_at IS NULL;
@@ -0,0 +1,3 @@
+-- Deferred and snoozed todos stay out of default listings until these times pass.
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS start_date TIMESTAMPTZ;
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS snoozed_until TIMESTAMPTZ;
@@ -0,0 +1,18 @@
+-- Domain events are written in the transaction of the change they record and published by
+-- the relay of the todo service.
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id UUID PRIMARY KEY,
+    type STRING NOT NULL,
+    schema_version INT8 NOT NULL,
+    aggregate_type STRING NOT NULL,
+    aggregate_id UUID NOT NULL,
+    aggregate_version INT8 NOT NULL,
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    payload JSONB NOT NULL,
+    published_at TIMESTAMPTZ,
+    attempts INT8 NOT NULL DEFAULT 0,
+    last_error STRING
+);
+
+CREATE INDEX IF NOT EXISTS outbox_events_pending_idx ON outbox_events (occurred_at, aggregate_version) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS outbox_events_published_idx ON outbox_events (published_at) WHERE published_at IS NOT NULL;
@@ -0,0 +1,38 @@
+-- Endpoints that users subscribe to todo events, and the deliveries of events to them.
+CREATE TABLE IF NOT EXISTS webhooks (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    url STRING NOT NULL,
+    secret STRING NOT NULL,
+    event_types STRING[] NOT NULL,
+    description STRING NOT NULL DEFAULT '',
+    enabled BOOL NOT NULL DEFAULT true,
+    consecutive_failures INT8 NOT NULL DEFAULT 0,
+    disabled_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS webhooks_user_id_idx ON webhooks (user_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id UUID PRIMARY KEY,
+    webhook_id UUID NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+    event_id UUID NOT NULL,
+    event_type STRING NOT NULL,
+    payload JSONB NOT NULL,
+    redelivery_of UUID REFERENCES webhook_deliveries (id) ON DELETE SET NULL,
+    status STRING NOT NULL DEFAULT 'pending',
+    attempts INT8 NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ DEFAULT now(),
+    last_status_code INT8,
+    last_error STRING NOT NULL DEFAULT '',
+    duration_ms INT8,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    delivered_at TIMESTAMPTZ
+);
+
+-- The relay delivers events at least once; an event reaches each webhook once unless redelivered.
+CREATE UNIQUE INDEX IF NOT EXISTS webhook_deliveries_event_idx ON webhook_deliveries (webhook_id, event_id) WHERE redelivery_of IS NULL;
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS webhook_deliveries_log_idx ON webhook_deliveries (webhook_id, created_at DESC);
@@ -0,0 +1,2 @@
+-- Todo service replicas follow the changes of todos, published or not, in the order they occurred.
+CREATE INDEX IF NOT EXISTS outbox_events_feed_idx ON outbox_events (aggregate_type, occurred_at, id);
@@ -0,0 +1,7 @@
+-- Only the todo service replica holding the relay lease publishes outbox events, which keeps
+-- the events of an aggregate in order and delivers each of them once per poll.
+CREATE TABLE IF NOT EXISTS outbox_leases (
+    name STRING PRIMARY KEY,
+    holder UUID NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL
+);
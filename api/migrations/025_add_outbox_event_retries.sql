@@ -0,0 +1,9 @@
+-- Failed events wait before they are tried again, and are given up after too many
+-- attempts, so that events a sink keeps rejecting do not hold back the others.
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS next_attempt_at TIMESTAMPTZ;
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS dead_at TIMESTAMPTZ;
+
+DROP INDEX IF EXISTS outbox_events@outbox_events_pending_idx;
+CREATE INDEX IF NOT EXISTS outbox_events_due_idx ON outbox_events (occurred_at, aggregate_version) WHERE published_at IS NULL AND dead_at IS NULL;
+-- The relay holds back the later events of aggregates with an event waiting to be retried.
+CREATE INDEX IF NOT EXISTS outbox_events_retry_idx ON outbox_events (aggregate_type, aggregate_id, aggregate_version) WHERE published_at IS NULL AND dead_at IS NULL AND next_attempt_at IS NOT NULL;
@@ -0,0 +1,3 @@
+-- Import jobs run inside a todo service replica; the replicas look for jobs whose runner
+-- has stopped updating them to fail them.
+CREATE INDEX IF NOT EXISTS import_jobs_unfinished_idx ON import_jobs (updated_at) WHERE finished_at IS NULL;
@@ -0,0 +1,5 @@
+-- Users belong to the user service. The todo service checks them through its API and hands
+-- over, unassigns or deletes the todos of deleted users when their UserDeleted event
+-- arrives, which a cascade on the users table would pre-empt.
+ALTER TABLE todos DROP CONSTRAINT IF EXISTS todos_user_id_fkey;
+ALTER TABLE todos DROP CONSTRAINT IF EXISTS todos_assignee_id_fkey;
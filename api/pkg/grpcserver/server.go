@@ -0,0 +1,98 @@
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// Server is a gRPC server that answers health checks and reflection requests besides the
+// services registered on it. Handlers that panic fail their call with codes.Internal
+// instead of taking the process down.
+type Server struct {
+	*grpc.Server
+	health *health.Server
+}
+
+// New returns a Server with the supplied options.
+func New(opts ...grpc.ServerOption) *Server {
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(recoverUnary),
+		grpc.ChainStreamInterceptor(recoverStream),
+	)
+	s := &Server{Server: grpc.NewServer(opts...), health: health.NewServer()}
+	healthpb.RegisterHealthServer(s.Server, s.health)
+	reflection.Register(s.Server)
+	return s
+}
+
+// Serve reports the registered services as serving and accepts connections on lis until
+// the context is cancelled. It then reports them as not serving and stops gracefully,
+// cutting off calls still running after the shutdown timeout.
+func (s *Server) Serve(ctx context.Context, lis net.Listener, shutdownTimeout time.Duration) error {
+	for name := range s.GetServiceInfo() {
+		s.health.SetServingStatus(name, healthpb.HealthCheckResponse_SERVING)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.Server.Serve(lis)
+	}()
+
+	select {
+	case <-ctx.Done():
+		s.health.Shutdown()
+		stopped := make(chan struct{})
+		go func() {
+			s.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(shutdownTimeout):
+			s.Stop()
+		}
+		return <-errCh
+	case err := <-errCh:
+		return err
+	}
+}
+
+// Run listens on port and serves until the context is cancelled, like Serve.
+func (s *Server) Run(ctx context.Context, port string, shutdownTimeout time.Duration) error {
+	lis, err := net.Listen("tcp", fmt.Sprintf(":%s", port))
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+	return s.Serve(ctx, lis, shutdownTimeout)
+}
+
+func recoverUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicked(r)
+		}
+	}()
+	return handler(ctx, req)
+}
+
+func recoverStream(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = panicked(r)
+		}
+	}()
+	return handler(srv, ss)
+}
+
+func panicked(r any) error {
+	return status.Errorf(codes.Internal, "panic: %v", r)
+}
@@ -0,0 +1,327 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"gopkg.in/yaml.v3"
+)
+
+// documentURL is where the compiled schemas resolve references to the document.
+const documentURL = "openapi.json"
+
+// methods are the operations a path item may declare, in the order they are read.
+var methods = []string{
+	http.MethodGet, http.MethodPut, http.MethodPost, http.MethodDelete,
+	http.MethodOptions, http.MethodHead, http.MethodPatch, http.MethodTrace,
+}
+
+// Spec is an OpenAPI 3.1 document together with the schemas of its operations.
+type Spec struct {
+	document   []byte
+	operations map[string]*operation
+}
+
+// operation holds the compiled schemas of an operation. bodyJSONOnly is set when JSON is
+// the only media type the request body may have.
+type operation struct {
+	parameters   []parameter
+	body         *jsonschema.Schema
+	bodyRequired bool
+	bodyJSONOnly bool
+	responses    map[string]*jsonschema.Schema
+}
+
+// parameter is a path, query or header parameter. Kind is the JSON type its raw values are
+// converted to before validation, items that of array elements.
+type parameter struct {
+	name     string
+	in       string
+	style    string
+	required bool
+	kind     string
+	items    string
+	schema   *jsonschema.Schema
+}
+
+// Load parses an OpenAPI 3.1 document written as YAML or JSON and compiles the schemas
+// of its parameters, request bodies and JSON responses.
+func Load(data []byte) (*Spec, error) {
+	var raw any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("parse document: %w", err)
+	}
+	document, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("encode document: %w", err)
+	}
+
+	// The schemas are compiled from the JSON form, which keeps numbers exact.
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(document))
+	if err != nil {
+		return nil, fmt.Errorf("decode document: %w", err)
+	}
+	root, _ := doc.(map[string]any)
+	if version, _ := root["openapi"].(string); !strings.HasPrefix(version, "3.1.") {
+		return nil, fmt.Errorf("unsupported openapi version %q", version)
+	}
+
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft2020)
+	compiler.AssertFormat()
+	if err := compiler.AddResource(documentURL, doc); err != nil {
+		return nil, fmt.Errorf("add document: %w", err)
+	}
+
+	l := loader{root: root, compiler: compiler}
+	spec := &Spec{document: document, operations: make(map[string]*operation)}
+	paths, _ := root["paths"].(map[string]any)
+	for path, value := range paths {
+		item, _ := value.(map[string]any)
+		itemPtr := "/paths/" + escape(path)
+		for _, method := range methods {
+			if _, ok := item[strings.ToLower(method)]; !ok {
+				continue
+			}
+			op, err := l.operation(item, itemPtr, strings.ToLower(method))
+			if err != nil {
+				return nil, fmt.Errorf("%s %s: %w", method, path, err)
+			}
+			spec.operations[method+" "+path] = op
+		}
+	}
+
+	return spec, nil
+}
+
+// Operations lists the operations of the document as "METHOD /path/{param}", sorted.
+func (s *Spec) Operations() []string {
+	result := make([]string, 0, len(s.operations))
+	for key := range s.operations {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// Compare matches the routes of a gin engine whose paths start with one of prefixes against
+// the operations of the document. It returns the routes the document lacks and the
+// operations no route serves, both sorted.
+func (s *Spec) Compare(routes gin.RoutesInfo, prefixes ...string) (undocumented, unserved []string) {
+	served := make(map[string]bool)
+	for _, route := range routes {
+		key := route.Method + " " + Path(route.Path)
+		served[key] = true
+		if _, ok := s.operations[key]; !ok && hasPrefix(route.Path, prefixes) {
+			undocumented = append(undocumented, key)
+		}
+	}
+	for _, key := range s.Operations() {
+		if !served[key] {
+			unserved = append(unserved, key)
+		}
+	}
+	sort.Strings(undocumented)
+	return undocumented, unserved
+}
+
+// JSON returns the document in its JSON form.
+func (s *Spec) JSON() []byte {
+	return s.document
+}
+
+// Path turns a gin route such as /v1/todos/:id into the OpenAPI form /v1/todos/{id}.
+func Path(route string) string {
+	segments := strings.Split(route, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// operation looks up the operation of a request to a gin route, or nil when the document
+// does not describe it.
+func (s *Spec) operation(method, route string) *operation {
+	if route == "" {
+		return nil
+	}
+	return s.operations[method+" "+Path(route)]
+}
+
+// loader compiles the schemas of the operations of a document.
+type loader struct {
+	root     map[string]any
+	compiler *jsonschema.Compiler
+}
+
+func (l loader) operation(item map[string]any, itemPtr, method string) (*operation, error) {
+	opPtr := itemPtr + "/" + method
+	node, _ := item[method].(map[string]any)
+	op := &operation{responses: make(map[string]*jsonschema.Schema)}
+
+	// Parameters of the path item apply to all of its operations unless overridden.
+	declared := make(map[string]parameter)
+	var order []string
+	for _, source := range []struct {
+		list []any
+		ptr  string
+	}{
+		{list: asList(item["parameters"]), ptr: itemPtr + "/parameters"},
+		{list: asList(node["parameters"]), ptr: opPtr + "/parameters"},
+	} {
+		for i, value := range source.list {
+			p, err := l.parameter(value, fmt.Sprintf("%s/%d", source.ptr, i))
+			if err != nil {
+				return nil, err
+			}
+			key := p.in + " " + p.name
+			if _, ok := declared[key]; !ok {
+				order = append(order, key)
+			}
+			declared[key] = p
+		}
+	}
+	for _, key := range order {
+		op.parameters = append(op.parameters, declared[key])
+	}
+
+	if value, ok := node["requestBody"]; ok {
+		body, ptr, err := l.resolve(value, opPtr+"/requestBody")
+		if err != nil {
+			return nil, err
+		}
+		op.bodyRequired, _ = body["required"].(bool)
+		content, _ := body["content"].(map[string]any)
+		_, hasJSON := content["application/json"]
+		op.bodyJSONOnly = hasJSON && len(content) == 1
+		if op.body, err = l.jsonSchema(body, ptr); err != nil {
+			return nil, fmt.Errorf("request body: %w", err)
+		}
+	}
+
+	responses, _ := node["responses"].(map[string]any)
+	if len(responses) == 0 {
+		return nil, fmt.Errorf("no responses")
+	}
+	for status, value := range responses {
+		response, ptr, err := l.resolve(value, opPtr+"/responses/"+escape(status))
+		if err != nil {
+			return nil, err
+		}
+		if op.responses[strings.ToUpper(status)], err = l.jsonSchema(response, ptr); err != nil {
+			return nil, fmt.Errorf("response %s: %w", status, err)
+		}
+	}
+
+	return op, nil
+}
+
+func (l loader) parameter(value any, ptr string) (parameter, error) {
+	node, ptr, err := l.resolve(value, ptr)
+	if err != nil {
+		return parameter{}, err
+	}
+
+	p := parameter{}
+	p.name, _ = node["name"].(string)
+	p.in, _ = node["in"].(string)
+	p.style, _ = node["style"].(string)
+	p.required, _ = node["required"].(bool)
+	if p.name == "" || p.in == "" {
+		return parameter{}, fmt.Errorf("parameter at %s needs a name and a location", ptr)
+	}
+
+	schema, _ := node["schema"].(map[string]any)
+	if schema == nil {
+		return parameter{}, fmt.Errorf("parameter %s has no schema", p.name)
+	}
+	p.kind = l.kind(schema)
+	if items, ok := schema["items"].(map[string]any); ok {
+		p.items = l.kind(items)
+	}
+	if p.schema, err = l.compiler.Compile(documentURL + "#" + ptr + "/schema"); err != nil {
+		return parameter{}, fmt.Errorf("compile parameter %s: %w", p.name, err)
+	}
+	return p, nil
+}
+
+// jsonSchema compiles the schema of the application/json content of a request body or
+// response, if it has one.
+func (l loader) jsonSchema(node map[string]any, ptr string) (*jsonschema.Schema, error) {
+	content, _ := node["content"].(map[string]any)
+	media, ok := content["application/json"].(map[string]any)
+	if !ok {
+		return nil, nil
+	}
+	if _, ok := media["schema"]; !ok {
+		return nil, nil
+	}
+	return l.compiler.Compile(documentURL + "#" + ptr + "/content/application~1json/schema")
+}
+
+// resolve follows a $ref to a component, returning the object and its JSON pointer.
+func (l loader) resolve(value any, ptr string) (map[string]any, string, error) {
+	node, _ := value.(map[string]any)
+	ref, ok := node["$ref"].(string)
+	if !ok {
+		return node, ptr, nil
+	}
+
+	target, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil, "", fmt.Errorf("unsupported reference %q", ref)
+	}
+	var current any = l.root
+	for _, token := range strings.Split(strings.TrimPrefix(target, "/"), "/") {
+		obj, _ := current.(map[string]any)
+		if current = obj[unescape(token)]; current == nil {
+			return nil, "", fmt.Errorf("unresolved reference %q", ref)
+		}
+	}
+	return l.resolve(current, target)
+}
+
+// kind returns the JSON type of a parameter schema, following a reference to a component
+// schema. Schemas allowing several types count as strings.
+func (l loader) kind(schema map[string]any) string {
+	if ref, ok := schema["$ref"].(string); ok {
+		if node, _, err := l.resolve(map[string]any{"$ref": ref}, ""); err == nil {
+			return l.kind(node)
+		}
+	}
+	if kind, ok := schema["type"].(string); ok {
+		return kind
+	}
+	return "string"
+}
+
+func hasPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if path == prefix || strings.HasPrefix(path, strings.TrimSuffix(prefix, "/")+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func asList(value any) []any {
+	list, _ := value.([]any)
+	return list
+}
+
+// escape and unescape encode a JSON pointer token.
+func escape(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+func unescape(token string) string {
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+}
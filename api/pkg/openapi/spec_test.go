@@ -0,0 +1,171 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+const testSpec = `
+openapi: 3.1.0
+info:
+  title: Items
+  version: "1"
+paths:
+  /items:
+    get:
+      parameters:
+        - name: limit
+          in: query
+          schema: {type: integer, minimum: 1, maximum: 100}
+        - name: tag
+          in: query
+          schema:
+            type: array
+            items: {type: string, maxLength: 5}
+        - name: field
+          in: query
+          style: deepObject
+          schema:
+            type: object
+            additionalProperties: {type: string}
+      responses:
+        "200":
+          description: The items.
+          content:
+            application/json:
+              schema:
+                type: array
+                items: {$ref: "#/components/schemas/Item"}
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {$ref: "#/components/schemas/NewItem"}
+      responses:
+        "201":
+          description: The created item.
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/Item"}
+        4XX: {$ref: "#/components/responses/Error"}
+  /items/import:
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {type: array}
+          text/csv:
+            schema: {type: string}
+      responses:
+        "204":
+          description: The items were imported.
+  /items/{id}:
+    parameters:
+      - $ref: "#/components/parameters/ItemID"
+    get:
+      parameters:
+        - name: verbose
+          in: query
+          schema: {type: boolean}
+      responses:
+        "200":
+          description: The item.
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/Item"}
+        default: {$ref: "#/components/responses/Error"}
+    delete:
+      responses:
+        "204":
+          description: The item was deleted.
+components:
+  parameters:
+    ItemID:
+      name: id
+      in: path
+      required: true
+      schema: {type: string, format: uuid}
+  responses:
+    Error:
+      description: An error.
+      content:
+        application/json:
+          schema:
+            type: object
+            required: [error]
+            properties:
+              error: {type: string}
+  schemas:
+    NewItem:
+      type: object
+      required: [name]
+      properties:
+        name: {type: string, minLength: 1}
+        size: {type: [integer, "null"]}
+    Item:
+      allOf:
+        - $ref: "#/components/schemas/NewItem"
+        - type: object
+          required: [id]
+          properties:
+            id: {type: string, format: uuid}
+`
+
+func TestLoad(t *testing.T) {
+	spec, err := Load([]byte(testSpec))
+	require.NoError(t, err)
+	require.Equal(t, []string{"DELETE /items/{id}", "GET /items", "GET /items/{id}", "POST /items", "POST /items/import"}, spec.Operations())
+
+	var document map[string]any
+	require.NoError(t, json.Unmarshal(spec.JSON(), &document))
+	require.Equal(t, "3.1.0", document["openapi"])
+
+	op := spec.operation("GET", "/items/:id")
+	require.NotNil(t, op)
+	require.Len(t, op.parameters, 2)
+	require.Equal(t, "id", op.parameters[0].name)
+	require.Nil(t, spec.operation("GET", "/unknown"))
+}
+
+func TestLoadRejectsInvalidDocuments(t *testing.T) {
+	for name, document := range map[string]string{
+		"version":   "openapi: 3.0.3\npaths: {}\n",
+		"yaml":      "openapi: [3.1.0\n",
+		"reference": "openapi: 3.1.0\npaths:\n  /a:\n    get:\n      parameters: [{$ref: '#/components/parameters/Missing'}]\n      responses: {'200': {description: ok}}\n",
+		"responses": "openapi: 3.1.0\npaths:\n  /a:\n    get: {}\n",
+		"schema":    "openapi: 3.1.0\npaths:\n  /a:\n    get:\n      parameters: [{name: a, in: query}]\n      responses: {'200': {description: ok}}\n",
+	} {
+		_, err := Load([]byte(document))
+		require.Error(t, err, name)
+	}
+}
+
+func TestPath(t *testing.T) {
+	require.Equal(t, "/v1/todos/{id}/comments/{comment_id}", Path("/v1/todos/:id/comments/:comment_id"))
+	require.Equal(t, "/files/{path}", Path("/files/*path"))
+	require.Equal(t, "/v1/todos", Path("/v1/todos"))
+}
+
+func TestCompare(t *testing.T) {
+	spec, err := Load([]byte(testSpec))
+	require.NoError(t, err)
+
+	routes := gin.RoutesInfo{
+		{Method: http.MethodGet, Path: "/items"},
+		{Method: http.MethodPost, Path: "/items"},
+		{Method: http.MethodPost, Path: "/items/import"},
+		{Method: http.MethodGet, Path: "/items/:id"},
+		{Method: http.MethodPut, Path: "/items/:id"},
+		{Method: http.MethodGet, Path: "/itemsets"},
+		{Method: http.MethodGet, Path: "/healthz"},
+	}
+	undocumented, unserved := spec.Compare(routes, "/items")
+	require.Equal(t, []string{"PUT /items/{id}"}, undocumented)
+	require.Equal(t, []string{"DELETE /items/{id}"}, unserved)
+}
@@ -0,0 +1,243 @@
+package openapi
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// maxValidatedBody bounds the request bodies read for validation. Larger bodies are left to
+// the handlers, which enforce their own limits.
+const maxValidatedBody = 1 << 20
+
+var printer = message.NewPrinter(language.English)
+
+// ValidateRequests returns middleware that rejects requests to the described operations
+// whose parameters or JSON body do not match the document with 400. Requests to other
+// routes pass unchecked.
+func (s *Spec) ValidateRequests() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		op := s.operation(c.Request.Method, c.FullPath())
+		if op == nil {
+			return
+		}
+		if err := op.validateRequest(c); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
+	}
+}
+
+// ValidateResponses returns middleware that checks the responses of the described
+// operations against the document and passes mismatches to report. It keeps a copy of
+// every response body and is meant for tests.
+func (s *Spec) ValidateResponses(report func(error)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		op := s.operation(c.Request.Method, c.FullPath())
+		if op == nil {
+			return
+		}
+
+		recorder := &recorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+		c.Next()
+
+		if err := op.validateResponse(c.Writer.Status(), c.Writer.Header().Get("Content-Type"), recorder.body.Bytes()); err != nil {
+			report(fmt.Errorf("%s %s: %w", c.Request.Method, c.FullPath(), err))
+		}
+	}
+}
+
+// recorder keeps a copy of the response body while writing it.
+type recorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *recorder) Write(data []byte) (int, error) {
+	r.body.Write(data)
+	return r.ResponseWriter.Write(data)
+}
+
+func (r *recorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
+
+func (op *operation) validateRequest(c *gin.Context) error {
+	for _, p := range op.parameters {
+		value, ok := p.value(c)
+		if !ok {
+			if p.required {
+				return fmt.Errorf("%s parameter %s is required", p.in, p.name)
+			}
+			continue
+		}
+		if err := p.schema.Validate(value); err != nil {
+			return fmt.Errorf("invalid %s parameter %s: %s", p.in, p.name, describe(err))
+		}
+	}
+
+	// Bodies without a content type are read as JSON, as the handlers do, unless the
+	// operation accepts other media types too.
+	contentType := c.ContentType()
+	if op.body == nil || (contentType == "" && !op.bodyJSONOnly) || (contentType != "" && !isJSON(contentType)) {
+		return nil
+	}
+	data, err := io.ReadAll(io.LimitReader(c.Request.Body, maxValidatedBody+1))
+	if err != nil {
+		return fmt.Errorf("read request body: %w", err)
+	}
+	if len(data) > maxValidatedBody {
+		// Hand the complete body on to the handler without checking it.
+		c.Request.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(data), c.Request.Body), c.Request.Body}
+		return nil
+	}
+	c.Request.Body = io.NopCloser(bytes.NewReader(data))
+
+	if len(bytes.TrimSpace(data)) == 0 {
+		if op.bodyRequired {
+			return errors.New("request body is required")
+		}
+		return nil
+	}
+	body, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return errors.New("request body is not valid JSON")
+	}
+	if err := op.body.Validate(body); err != nil {
+		return fmt.Errorf("invalid request body: %s", describe(err))
+	}
+	return nil
+}
+
+func (op *operation) validateResponse(status int, contentType string, body []byte) error {
+	code := strconv.Itoa(status)
+	schema, ok := op.responses[code]
+	if !ok {
+		schema, ok = op.responses[code[:1]+"XX"]
+	}
+	if !ok {
+		schema, ok = op.responses["DEFAULT"]
+	}
+	if !ok {
+		return fmt.Errorf("undocumented status %d", status)
+	}
+	if schema == nil || len(body) == 0 {
+		return nil
+	}
+
+	if !isJSON(contentType) {
+		return fmt.Errorf("status %d: content type %q, want application/json", status, contentType)
+	}
+	value, err := jsonschema.UnmarshalJSON(bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("status %d: body is not valid JSON", status)
+	}
+	if err := schema.Validate(value); err != nil {
+		return fmt.Errorf("status %d: %s", status, describe(err))
+	}
+	return nil
+}
+
+// value reads the parameter from the request, converting it to the type of its schema.
+// Values that do not convert are validated as strings, which the schema rejects.
+func (p parameter) value(c *gin.Context) (any, bool) {
+	switch p.in {
+	case "path":
+		raw := c.Param(p.name)
+		return convert(p.kind, raw), raw != ""
+	case "header":
+		raw := c.GetHeader(p.name)
+		return convert(p.kind, raw), raw != ""
+	case "query":
+		if p.style == "deepObject" {
+			values, ok := c.GetQueryMap(p.name)
+			if !ok {
+				return nil, false
+			}
+			result := make(map[string]any, len(values))
+			for key, raw := range values {
+				result[key] = raw
+			}
+			return result, true
+		}
+
+		values, ok := c.GetQueryArray(p.name)
+		if !ok {
+			return nil, false
+		}
+		if p.kind != "array" {
+			return convert(p.kind, values[0]), true
+		}
+		result := make([]any, len(values))
+		for i, raw := range values {
+			result[i] = convert(p.items, raw)
+		}
+		return result, true
+	default:
+		return nil, false
+	}
+}
+
+func convert(kind, raw string) any {
+	switch kind {
+	case "integer":
+		if v, err := strconv.ParseInt(raw, 10, 64); err == nil {
+			return v
+		}
+	case "number":
+		if v, err := strconv.ParseFloat(raw, 64); err == nil {
+			return v
+		}
+	case "boolean":
+		if v, err := strconv.ParseBool(raw); err == nil {
+			return v
+		}
+	}
+	return raw
+}
+
+// describe lists the innermost causes of a validation error with the locations they
+// refer to.
+func describe(err error) string {
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		return err.Error()
+	}
+
+	var causes []string
+	var walk func(*jsonschema.ValidationError)
+	walk = func(e *jsonschema.ValidationError) {
+		if len(e.Causes) > 0 {
+			for _, cause := range e.Causes {
+				walk(cause)
+			}
+			return
+		}
+		text := e.ErrorKind.LocalizedString(printer)
+		if len(e.InstanceLocation) > 0 {
+			text = "/" + strings.Join(e.InstanceLocation, "/") + ": " + text
+		}
+		causes = append(causes, text)
+	}
+	walk(ve)
+	return strings.Join(causes, "; ")
+}
+
+func isJSON(contentType string) bool {
+	media, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (media == "application/json" || strings.HasSuffix(media, "+json"))
+}
@@ -0,0 +1,126 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRouter(t *testing.T, middleware gin.HandlerFunc, item string) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware)
+	router.GET("/items", func(c *gin.Context) {
+		c.Data(http.StatusOK, "application/json", []byte("["+item+"]"))
+	})
+	router.POST("/items", func(c *gin.Context) {
+		var input map[string]any
+		if err := c.ShouldBindJSON(&input); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.Data(http.StatusCreated, "application/json", []byte(item))
+	})
+	router.POST("/items/import", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	router.GET("/items/:id", func(c *gin.Context) {
+		if c.Query("verbose") == "true" {
+			c.JSON(http.StatusTeapot, gin.H{"message": "verbose"})
+			return
+		}
+		c.String(http.StatusOK, item)
+	})
+	router.DELETE("/items/:id", func(c *gin.Context) {
+		c.JSON(http.StatusConflict, gin.H{"error": "in use"})
+	})
+	router.GET("/healthz", func(c *gin.Context) {
+		c.Status(http.StatusNoContent)
+	})
+	return router
+}
+
+const testItem = `{"id": "4b4c7e30-6a8e-4b43-a6f1-4c1a2b4d8e9f", "name": "Box", "size": null}`
+
+func TestValidateRequests(t *testing.T) {
+	spec, err := Load([]byte(testSpec))
+	require.NoError(t, err)
+	router := newTestRouter(t, spec.ValidateRequests(), testItem)
+
+	for _, tc := range []struct {
+		method, target, body string
+		contentType          string
+		status               int
+		message              string
+	}{
+		{method: http.MethodGet, target: "/items?limit=10&tag=a&tag=b&field[color]=red", status: http.StatusOK},
+		{method: http.MethodGet, target: "/items?limit=0", status: http.StatusBadRequest, message: "invalid query parameter limit"},
+		{method: http.MethodGet, target: "/items?limit=ten", status: http.StatusBadRequest, message: "invalid query parameter limit"},
+		{method: http.MethodGet, target: "/items?tag=a&tag=toolong", status: http.StatusBadRequest, message: "/1: "},
+		{method: http.MethodGet, target: "/items/4b4c7e30-6a8e-4b43-a6f1-4c1a2b4d8e9f?verbose=false", status: http.StatusOK},
+		{method: http.MethodGet, target: "/items/nope", status: http.StatusBadRequest, message: "invalid path parameter id"},
+		{method: http.MethodGet, target: "/items/4b4c7e30-6a8e-4b43-a6f1-4c1a2b4d8e9f?verbose=maybe", status: http.StatusBadRequest, message: "invalid query parameter verbose"},
+		{method: http.MethodPost, target: "/items", body: `{"name": "Box", "size": 3}`, contentType: "application/json", status: http.StatusCreated},
+		{method: http.MethodPost, target: "/items", body: `{"size": 3}`, contentType: "application/json", status: http.StatusBadRequest, message: "missing property 'name'"},
+		{method: http.MethodPost, target: "/items", body: `{"name": "Box", "size": "L"}`, contentType: "application/json", status: http.StatusBadRequest, message: "/size: "},
+		{method: http.MethodPost, target: "/items", body: `{"name":`, contentType: "application/json", status: http.StatusBadRequest, message: "not valid JSON"},
+		{method: http.MethodPost, target: "/items", status: http.StatusBadRequest, message: "request body is required"},
+		// Bodies without a content type are only read as JSON where nothing else is accepted.
+		{method: http.MethodPost, target: "/items/import", body: "name\nBox", status: http.StatusNoContent},
+		{method: http.MethodPost, target: "/items/import", body: "{}", contentType: "application/json", status: http.StatusBadRequest, message: "invalid request body"},
+		{method: http.MethodPost, target: "/items", body: "name\nBox", status: http.StatusBadRequest, message: "not valid JSON"},
+		{method: http.MethodGet, target: "/healthz?limit=0", status: http.StatusNoContent},
+	} {
+		req := httptest.NewRequest(tc.method, tc.target, strings.NewReader(tc.body))
+		if tc.contentType != "" {
+			req.Header.Set("Content-Type", tc.contentType)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		require.Equal(t, tc.status, rec.Code, tc.target+" "+rec.Body.String())
+		require.Contains(t, rec.Body.String(), tc.message, tc.target)
+	}
+}
+
+func TestValidateResponses(t *testing.T) {
+	spec, err := Load([]byte(testSpec))
+	require.NoError(t, err)
+
+	var reported []error
+	report := func(err error) { reported = append(reported, err) }
+
+	router := newTestRouter(t, spec.ValidateResponses(report), testItem)
+	for _, target := range []string{"/items", "/healthz"} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		require.Less(t, rec.Code, 300)
+	}
+	require.Empty(t, reported)
+
+	for _, tc := range []struct {
+		item, method, target string
+		message              string
+	}{
+		// The item is written as text/plain.
+		{item: testItem, method: http.MethodGet, target: "/items/4b4c7e30-6a8e-4b43-a6f1-4c1a2b4d8e9f", message: "want application/json"},
+		{item: testItem, method: http.MethodGet, target: "/items/4b4c7e30-6a8e-4b43-a6f1-4c1a2b4d8e9f?verbose=true", message: "missing property 'error'"},
+		{item: testItem, method: http.MethodDelete, target: "/items/4b4c7e30-6a8e-4b43-a6f1-4c1a2b4d8e9f", message: "undocumented status 409"},
+		{item: `{"name": "Box"}`, method: http.MethodGet, target: "/items", message: "GET /items: status 200: /0: missing property 'id'"},
+	} {
+		reported = nil
+		router := newTestRouter(t, spec.ValidateResponses(report), tc.item)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.target, nil))
+
+		// The response reaches the client unchanged.
+		require.NotEmpty(t, rec.Body.String())
+		require.Len(t, reported, 1, tc.target)
+		require.Contains(t, reported[0].Error(), tc.message)
+	}
+}
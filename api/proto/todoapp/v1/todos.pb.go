@@ -0,0 +1,1206 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        v5.29.3
+// source: todos.proto
+
+package todoappv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	structpb "google.golang.org/protobuf/types/known/structpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Todo struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId          string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Title           string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	Description     string                 `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	DueDate         *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=due_date,json=dueDate,proto3" json:"due_date,omitempty"`
+	StartDate       *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`
+	SnoozedUntil    *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=snoozed_until,json=snoozedUntil,proto3" json:"snoozed_until,omitempty"`
+	Completed       bool                   `protobuf:"varint,8,opt,name=completed,proto3" json:"completed,omitempty"`
+	Status          string                 `protobuf:"bytes,9,opt,name=status,proto3" json:"status,omitempty"`
+	CreatedAt       *timestamppb.Timestamp `protobuf:"bytes,10,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt       *timestamppb.Timestamp `protobuf:"bytes,11,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	IcalUid         *string                `protobuf:"bytes,12,opt,name=ical_uid,json=icalUid,proto3,oneof" json:"ical_uid,omitempty"`
+	Version         int64                  `protobuf:"varint,13,opt,name=version,proto3" json:"version,omitempty"`
+	ProjectId       *string                `protobuf:"bytes,14,opt,name=project_id,json=projectId,proto3,oneof" json:"project_id,omitempty"`
+	ParentId        *string                `protobuf:"bytes,15,opt,name=parent_id,json=parentId,proto3,oneof" json:"parent_id,omitempty"`
+	AssigneeId      *string                `protobuf:"bytes,16,opt,name=assignee_id,json=assigneeId,proto3,oneof" json:"assignee_id,omitempty"`
+	EstimateMinutes *int32                 `protobuf:"varint,17,opt,name=estimate_minutes,json=estimateMinutes,proto3,oneof" json:"estimate_minutes,omitempty"`
+	TrackedMinutes  int32                  `protobuf:"varint,18,opt,name=tracked_minutes,json=trackedMinutes,proto3" json:"tracked_minutes,omitempty"`
+	Labels          []string               `protobuf:"bytes,19,rep,name=labels,proto3" json:"labels,omitempty"`
+	Priority        int32                  `protobuf:"varint,20,opt,name=priority,proto3" json:"priority,omitempty"`
+	CompletedAt     *timestamppb.Timestamp `protobuf:"bytes,21,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	Recurrence      string                 `protobuf:"bytes,22,opt,name=recurrence,proto3" json:"recurrence,omitempty"`
+	CustomFields    *structpb.Struct       `protobuf:"bytes,23,opt,name=custom_fields,json=customFields,proto3" json:"custom_fields,omitempty"`
+	BlockedBy       []string               `protobuf:"bytes,24,rep,name=blocked_by,json=blockedBy,proto3" json:"blocked_by,omitempty"`
+	Blocked         bool                   `protobuf:"varint,25,opt,name=blocked,proto3" json:"blocked,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Todo) Reset() {
+	*x = Todo{}
+	mi := &file_todos_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Todo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Todo) ProtoMessage() {}
+
+func (x *Todo) ProtoReflect() protoreflect.Message {
+	mi := &file_todos_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Todo.ProtoReflect.Descriptor instead.
+func (*Todo) Descriptor() ([]byte, []int) {
+	return file_todos_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Todo) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Todo) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *Todo) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Todo) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Todo) GetDueDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DueDate
+	}
+	return nil
+}
+
+func (x *Todo) GetStartDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartDate
+	}
+	return nil
+}
+
+func (x *Todo) GetSnoozedUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.SnoozedUntil
+	}
+	return nil
+}
+
+func (x *Todo) GetCompleted() bool {
+	if x != nil {
+		return x.Completed
+	}
+	return false
+}
+
+func (x *Todo) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Todo) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Todo) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+func (x *Todo) GetIcalUid() string {
+	if x != nil && x.IcalUid != nil {
+		return *x.IcalUid
+	}
+	return ""
+}
+
+func (x *Todo) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Todo) GetProjectId() string {
+	if x != nil && x.ProjectId != nil {
+		return *x.ProjectId
+	}
+	return ""
+}
+
+func (x *Todo) GetParentId() string {
+	if x != nil && x.ParentId != nil {
+		return *x.ParentId
+	}
+	return ""
+}
+
+func (x *Todo) GetAssigneeId() string {
+	if x != nil && x.AssigneeId != nil {
+		return *x.AssigneeId
+	}
+	return ""
+}
+
+func (x *Todo) GetEstimateMinutes() int32 {
+	if x != nil && x.EstimateMinutes != nil {
+		return *x.EstimateMinutes
+	}
+	return 0
+}
+
+func (x *Todo) GetTrackedMinutes() int32 {
+	if x != nil {
+		return x.TrackedMinutes
+	}
+	return 0
+}
+
+func (x *Todo) GetLabels() []string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *Todo) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *Todo) GetCompletedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return nil
+}
+
+func (x *Todo) GetRecurrence() string {
+	if x != nil {
+		return x.Recurrence
+	}
+	return ""
+}
+
+func (x *Todo) GetCustomFields() *structpb.Struct {
+	if x != nil {
+		return x.CustomFields
+	}
+	return nil
+}
+
+func (x *Todo) GetBlockedBy() []string {
+	if x != nil {
+		return x.BlockedBy
+	}
+	return nil
+}
+
+func (x *Todo) GetBlocked() bool {
+	if x != nil {
+		return x.Blocked
+	}
+	return false
+}
+
+type CreateTodoRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	UserId string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	// title may be left out when quick_add provides one.
+	Title           string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Description     string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	DueDate         *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=due_date,json=dueDate,proto3" json:"due_date,omitempty"`
+	StartDate       *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`
+	Completed       bool                   `protobuf:"varint,6,opt,name=completed,proto3" json:"completed,omitempty"`
+	Status          string                 `protobuf:"bytes,7,opt,name=status,proto3" json:"status,omitempty"`
+	ProjectId       *string                `protobuf:"bytes,8,opt,name=project_id,json=projectId,proto3,oneof" json:"project_id,omitempty"`
+	ParentId        *string                `protobuf:"bytes,9,opt,name=parent_id,json=parentId,proto3,oneof" json:"parent_id,omitempty"`
+	AssigneeId      *string                `protobuf:"bytes,10,opt,name=assignee_id,json=assigneeId,proto3,oneof" json:"assignee_id,omitempty"`
+	EstimateMinutes *int32                 `protobuf:"varint,11,opt,name=estimate_minutes,json=estimateMinutes,proto3,oneof" json:"estimate_minutes,omitempty"`
+	Labels          []string               `protobuf:"bytes,12,rep,name=labels,proto3" json:"labels,omitempty"`
+	Priority        int32                  `protobuf:"varint,13,opt,name=priority,proto3" json:"priority,omitempty"`
+	CustomFields    *structpb.Struct       `protobuf:"bytes,14,opt,name=custom_fields,json=customFields,proto3" json:"custom_fields,omitempty"`
+	Recurrence      string                 `protobuf:"bytes,15,opt,name=recurrence,proto3" json:"recurrence,omitempty"`
+	// quick_add describes the todo in English or German; tz and locale interpret it.
+	QuickAdd      string `protobuf:"bytes,16,opt,name=quick_add,json=quickAdd,proto3" json:"quick_add,omitempty"`
+	Tz            string `protobuf:"bytes,17,opt,name=tz,proto3" json:"tz,omitempty"`
+	Locale        string `protobuf:"bytes,18,opt,name=locale,proto3" json:"locale,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateTodoRequest) Reset() {
+	*x = CreateTodoRequest{}
+	mi := &file_todos_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateTodoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateTodoRequest) ProtoMessage() {}
+
+func (x *CreateTodoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todos_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateTodoRequest.ProtoReflect.Descriptor instead.
+func (*CreateTodoRequest) Descriptor() ([]byte, []int) {
+	return file_todos_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *CreateTodoRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CreateTodoRequest) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *CreateTodoRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CreateTodoRequest) GetDueDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DueDate
+	}
+	return nil
+}
+
+func (x *CreateTodoRequest) GetStartDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartDate
+	}
+	return nil
+}
+
+func (x *CreateTodoRequest) GetCompleted() bool {
+	if x != nil {
+		return x.Completed
+	}
+	return false
+}
+
+func (x *CreateTodoRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *CreateTodoRequest) GetProjectId() string {
+	if x != nil && x.ProjectId != nil {
+		return *x.ProjectId
+	}
+	return ""
+}
+
+func (x *CreateTodoRequest) GetParentId() string {
+	if x != nil && x.ParentId != nil {
+		return *x.ParentId
+	}
+	return ""
+}
+
+func (x *CreateTodoRequest) GetAssigneeId() string {
+	if x != nil && x.AssigneeId != nil {
+		return *x.AssigneeId
+	}
+	return ""
+}
+
+func (x *CreateTodoRequest) GetEstimateMinutes() int32 {
+	if x != nil && x.EstimateMinutes != nil {
+		return *x.EstimateMinutes
+	}
+	return 0
+}
+
+func (x *CreateTodoRequest) GetLabels() []string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *CreateTodoRequest) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *CreateTodoRequest) GetCustomFields() *structpb.Struct {
+	if x != nil {
+		return x.CustomFields
+	}
+	return nil
+}
+
+func (x *CreateTodoRequest) GetRecurrence() string {
+	if x != nil {
+		return x.Recurrence
+	}
+	return ""
+}
+
+func (x *CreateTodoRequest) GetQuickAdd() string {
+	if x != nil {
+		return x.QuickAdd
+	}
+	return ""
+}
+
+func (x *CreateTodoRequest) GetTz() string {
+	if x != nil {
+		return x.Tz
+	}
+	return ""
+}
+
+func (x *CreateTodoRequest) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+type GetTodoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTodoRequest) Reset() {
+	*x = GetTodoRequest{}
+	mi := &file_todos_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTodoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTodoRequest) ProtoMessage() {}
+
+func (x *GetTodoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todos_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTodoRequest.ProtoReflect.Descriptor instead.
+func (*GetTodoRequest) Descriptor() ([]byte, []int) {
+	return file_todos_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetTodoRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetTodoRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type ListTodosRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	ProjectId     *string                `protobuf:"bytes,2,opt,name=project_id,json=projectId,proto3,oneof" json:"project_id,omitempty"`
+	IncludeHidden bool                   `protobuf:"varint,3,opt,name=include_hidden,json=includeHidden,proto3" json:"include_hidden,omitempty"`
+	// assignee is "me" or a user id.
+	Assignee string `protobuf:"bytes,4,opt,name=assignee,proto3" json:"assignee,omitempty"`
+	// q filters with the query language; days start in the tz time zone.
+	Q             string `protobuf:"bytes,5,opt,name=q,proto3" json:"q,omitempty"`
+	Tz            string `protobuf:"bytes,6,opt,name=tz,proto3" json:"tz,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTodosRequest) Reset() {
+	*x = ListTodosRequest{}
+	mi := &file_todos_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTodosRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTodosRequest) ProtoMessage() {}
+
+func (x *ListTodosRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todos_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTodosRequest.ProtoReflect.Descriptor instead.
+func (*ListTodosRequest) Descriptor() ([]byte, []int) {
+	return file_todos_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListTodosRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListTodosRequest) GetProjectId() string {
+	if x != nil && x.ProjectId != nil {
+		return *x.ProjectId
+	}
+	return ""
+}
+
+func (x *ListTodosRequest) GetIncludeHidden() bool {
+	if x != nil {
+		return x.IncludeHidden
+	}
+	return false
+}
+
+func (x *ListTodosRequest) GetAssignee() string {
+	if x != nil {
+		return x.Assignee
+	}
+	return ""
+}
+
+func (x *ListTodosRequest) GetQ() string {
+	if x != nil {
+		return x.Q
+	}
+	return ""
+}
+
+func (x *ListTodosRequest) GetTz() string {
+	if x != nil {
+		return x.Tz
+	}
+	return ""
+}
+
+type UpdateTodoRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId         string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Title          *string                `protobuf:"bytes,3,opt,name=title,proto3,oneof" json:"title,omitempty"`
+	Description    *string                `protobuf:"bytes,4,opt,name=description,proto3,oneof" json:"description,omitempty"`
+	DueDate        *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=due_date,json=dueDate,proto3" json:"due_date,omitempty"`
+	ClearDueDate   bool                   `protobuf:"varint,6,opt,name=clear_due_date,json=clearDueDate,proto3" json:"clear_due_date,omitempty"`
+	StartDate      *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=start_date,json=startDate,proto3" json:"start_date,omitempty"`
+	ClearStartDate bool                   `protobuf:"varint,8,opt,name=clear_start_date,json=clearStartDate,proto3" json:"clear_start_date,omitempty"`
+	Completed      *bool                  `protobuf:"varint,9,opt,name=completed,proto3,oneof" json:"completed,omitempty"`
+	Status         *string                `protobuf:"bytes,10,opt,name=status,proto3,oneof" json:"status,omitempty"`
+	ProjectId      *string                `protobuf:"bytes,11,opt,name=project_id,json=projectId,proto3,oneof" json:"project_id,omitempty"`
+	ClearProject   bool                   `protobuf:"varint,12,opt,name=clear_project,json=clearProject,proto3" json:"clear_project,omitempty"`
+	// labels replaces the labels when set.
+	Labels   *Labels `protobuf:"bytes,13,opt,name=labels,proto3" json:"labels,omitempty"`
+	Priority *int32  `protobuf:"varint,14,opt,name=priority,proto3,oneof" json:"priority,omitempty"`
+	// custom_fields is merged into the todo's values; null removes a value.
+	CustomFields *structpb.Struct `protobuf:"bytes,15,opt,name=custom_fields,json=customFields,proto3" json:"custom_fields,omitempty"`
+	// recurrence replaces the rule; an empty string removes it.
+	Recurrence      *string `protobuf:"bytes,16,opt,name=recurrence,proto3,oneof" json:"recurrence,omitempty"`
+	AssigneeId      *string `protobuf:"bytes,17,opt,name=assignee_id,json=assigneeId,proto3,oneof" json:"assignee_id,omitempty"`
+	ClearAssignee   bool    `protobuf:"varint,18,opt,name=clear_assignee,json=clearAssignee,proto3" json:"clear_assignee,omitempty"`
+	EstimateMinutes *int32  `protobuf:"varint,19,opt,name=estimate_minutes,json=estimateMinutes,proto3,oneof" json:"estimate_minutes,omitempty"`
+	ClearEstimate   bool    `protobuf:"varint,20,opt,name=clear_estimate,json=clearEstimate,proto3" json:"clear_estimate,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *UpdateTodoRequest) Reset() {
+	*x = UpdateTodoRequest{}
+	mi := &file_todos_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTodoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTodoRequest) ProtoMessage() {}
+
+func (x *UpdateTodoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todos_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTodoRequest.ProtoReflect.Descriptor instead.
+func (*UpdateTodoRequest) Descriptor() ([]byte, []int) {
+	return file_todos_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *UpdateTodoRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *UpdateTodoRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *UpdateTodoRequest) GetTitle() string {
+	if x != nil && x.Title != nil {
+		return *x.Title
+	}
+	return ""
+}
+
+func (x *UpdateTodoRequest) GetDescription() string {
+	if x != nil && x.Description != nil {
+		return *x.Description
+	}
+	return ""
+}
+
+func (x *UpdateTodoRequest) GetDueDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.DueDate
+	}
+	return nil
+}
+
+func (x *UpdateTodoRequest) GetClearDueDate() bool {
+	if x != nil {
+		return x.ClearDueDate
+	}
+	return false
+}
+
+func (x *UpdateTodoRequest) GetStartDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartDate
+	}
+	return nil
+}
+
+func (x *UpdateTodoRequest) GetClearStartDate() bool {
+	if x != nil {
+		return x.ClearStartDate
+	}
+	return false
+}
+
+func (x *UpdateTodoRequest) GetCompleted() bool {
+	if x != nil && x.Completed != nil {
+		return *x.Completed
+	}
+	return false
+}
+
+func (x *UpdateTodoRequest) GetStatus() string {
+	if x != nil && x.Status != nil {
+		return *x.Status
+	}
+	return ""
+}
+
+func (x *UpdateTodoRequest) GetProjectId() string {
+	if x != nil && x.ProjectId != nil {
+		return *x.ProjectId
+	}
+	return ""
+}
+
+func (x *UpdateTodoRequest) GetClearProject() bool {
+	if x != nil {
+		return x.ClearProject
+	}
+	return false
+}
+
+func (x *UpdateTodoRequest) GetLabels() *Labels {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *UpdateTodoRequest) GetPriority() int32 {
+	if x != nil && x.Priority != nil {
+		return *x.Priority
+	}
+	return 0
+}
+
+func (x *UpdateTodoRequest) GetCustomFields() *structpb.Struct {
+	if x != nil {
+		return x.CustomFields
+	}
+	return nil
+}
+
+func (x *UpdateTodoRequest) GetRecurrence() string {
+	if x != nil && x.Recurrence != nil {
+		return *x.Recurrence
+	}
+	return ""
+}
+
+func (x *UpdateTodoRequest) GetAssigneeId() string {
+	if x != nil && x.AssigneeId != nil {
+		return *x.AssigneeId
+	}
+	return ""
+}
+
+func (x *UpdateTodoRequest) GetClearAssignee() bool {
+	if x != nil {
+		return x.ClearAssignee
+	}
+	return false
+}
+
+func (x *UpdateTodoRequest) GetEstimateMinutes() int32 {
+	if x != nil && x.EstimateMinutes != nil {
+		return *x.EstimateMinutes
+	}
+	return 0
+}
+
+func (x *UpdateTodoRequest) GetClearEstimate() bool {
+	if x != nil {
+		return x.ClearEstimate
+	}
+	return false
+}
+
+type Labels struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Values        []string               `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Labels) Reset() {
+	*x = Labels{}
+	mi := &file_todos_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Labels) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Labels) ProtoMessage() {}
+
+func (x *Labels) ProtoReflect() protoreflect.Message {
+	mi := &file_todos_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Labels.ProtoReflect.Descriptor instead.
+func (*Labels) Descriptor() ([]byte, []int) {
+	return file_todos_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *Labels) GetValues() []string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+type CompleteTodoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Force         bool                   `protobuf:"varint,3,opt,name=force,proto3" json:"force,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompleteTodoRequest) Reset() {
+	*x = CompleteTodoRequest{}
+	mi := &file_todos_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompleteTodoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompleteTodoRequest) ProtoMessage() {}
+
+func (x *CompleteTodoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todos_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompleteTodoRequest.ProtoReflect.Descriptor instead.
+func (*CompleteTodoRequest) Descriptor() ([]byte, []int) {
+	return file_todos_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CompleteTodoRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *CompleteTodoRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *CompleteTodoRequest) GetForce() bool {
+	if x != nil {
+		return x.Force
+	}
+	return false
+}
+
+type DeleteTodoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteTodoRequest) Reset() {
+	*x = DeleteTodoRequest{}
+	mi := &file_todos_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTodoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTodoRequest) ProtoMessage() {}
+
+func (x *DeleteTodoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_todos_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTodoRequest.ProtoReflect.Descriptor instead.
+func (*DeleteTodoRequest) Descriptor() ([]byte, []int) {
+	return file_todos_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DeleteTodoRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *DeleteTodoRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type DeleteTodoResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteTodoResponse) Reset() {
+	*x = DeleteTodoResponse{}
+	mi := &file_todos_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteTodoResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteTodoResponse) ProtoMessage() {}
+
+func (x *DeleteTodoResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_todos_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteTodoResponse.ProtoReflect.Descriptor instead.
+func (*DeleteTodoResponse) Descriptor() ([]byte, []int) {
+	return file_todos_proto_rawDescGZIP(), []int{8}
+}
+
+var File_todos_proto protoreflect.FileDescriptor
+
+const file_todos_proto_rawDesc = "" +
+	"\n" +
+	"\vtodos.proto\x12\n" +
+	"todoapp.v1\x1a\x1cgoogle/protobuf/struct.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\x9e\b\n" +
+	"\x04Todo\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x125\n" +
+	"\bdue_date\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\adueDate\x129\n" +
+	"\n" +
+	"start_date\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tstartDate\x12?\n" +
+	"\rsnoozed_until\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\fsnoozedUntil\x12\x1c\n" +
+	"\tcompleted\x18\b \x01(\bR\tcompleted\x12\x16\n" +
+	"\x06status\x18\t \x01(\tR\x06status\x129\n" +
+	"\n" +
+	"created_at\x18\n" +
+	" \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x129\n" +
+	"\n" +
+	"updated_at\x18\v \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\x12\x1e\n" +
+	"\bical_uid\x18\f \x01(\tH\x00R\aicalUid\x88\x01\x01\x12\x18\n" +
+	"\aversion\x18\r \x01(\x03R\aversion\x12\"\n" +
+	"\n" +
+	"project_id\x18\x0e \x01(\tH\x01R\tprojectId\x88\x01\x01\x12 \n" +
+	"\tparent_id\x18\x0f \x01(\tH\x02R\bparentId\x88\x01\x01\x12$\n" +
+	"\vassignee_id\x18\x10 \x01(\tH\x03R\n" +
+	"assigneeId\x88\x01\x01\x12.\n" +
+	"\x10estimate_minutes\x18\x11 \x01(\x05H\x04R\x0festimateMinutes\x88\x01\x01\x12'\n" +
+	"\x0ftracked_minutes\x18\x12 \x01(\x05R\x0etrackedMinutes\x12\x16\n" +
+	"\x06labels\x18\x13 \x03(\tR\x06labels\x12\x1a\n" +
+	"\bpriority\x18\x14 \x01(\x05R\bpriority\x12=\n" +
+	"\fcompleted_at\x18\x15 \x01(\v2\x1a.google.protobuf.TimestampR\vcompletedAt\x12\x1e\n" +
+	"\n" +
+	"recurrence\x18\x16 \x01(\tR\n" +
+	"recurrence\x12<\n" +
+	"\rcustom_fields\x18\x17 \x01(\v2\x17.google.protobuf.StructR\fcustomFields\x12\x1d\n" +
+	"\n" +
+	"blocked_by\x18\x18 \x03(\tR\tblockedBy\x12\x18\n" +
+	"\ablocked\x18\x19 \x01(\bR\ablockedB\v\n" +
+	"\t_ical_uidB\r\n" +
+	"\v_project_idB\f\n" +
+	"\n" +
+	"_parent_idB\x0e\n" +
+	"\f_assignee_idB\x13\n" +
+	"\x11_estimate_minutes\"\xc1\x05\n" +
+	"\x11CreateTodoRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x125\n" +
+	"\bdue_date\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\adueDate\x129\n" +
+	"\n" +
+	"start_date\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tstartDate\x12\x1c\n" +
+	"\tcompleted\x18\x06 \x01(\bR\tcompleted\x12\x16\n" +
+	"\x06status\x18\a \x01(\tR\x06status\x12\"\n" +
+	"\n" +
+	"project_id\x18\b \x01(\tH\x00R\tprojectId\x88\x01\x01\x12 \n" +
+	"\tparent_id\x18\t \x01(\tH\x01R\bparentId\x88\x01\x01\x12$\n" +
+	"\vassignee_id\x18\n" +
+	" \x01(\tH\x02R\n" +
+	"assigneeId\x88\x01\x01\x12.\n" +
+	"\x10estimate_minutes\x18\v \x01(\x05H\x03R\x0festimateMinutes\x88\x01\x01\x12\x16\n" +
+	"\x06labels\x18\f \x03(\tR\x06labels\x12\x1a\n" +
+	"\bpriority\x18\r \x01(\x05R\bpriority\x12<\n" +
+	"\rcustom_fields\x18\x0e \x01(\v2\x17.google.protobuf.StructR\fcustomFields\x12\x1e\n" +
+	"\n" +
+	"recurrence\x18\x0f \x01(\tR\n" +
+	"recurrence\x12\x1b\n" +
+	"\tquick_add\x18\x10 \x01(\tR\bquickAdd\x12\x0e\n" +
+	"\x02tz\x18\x11 \x01(\tR\x02tz\x12\x16\n" +
+	"\x06locale\x18\x12 \x01(\tR\x06localeB\r\n" +
+	"\v_project_idB\f\n" +
+	"\n" +
+	"_parent_idB\x0e\n" +
+	"\f_assignee_idB\x13\n" +
+	"\x11_estimate_minutes\"9\n" +
+	"\x0eGetTodoRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\"\xbf\x01\n" +
+	"\x10ListTodosRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\"\n" +
+	"\n" +
+	"project_id\x18\x02 \x01(\tH\x00R\tprojectId\x88\x01\x01\x12%\n" +
+	"\x0einclude_hidden\x18\x03 \x01(\bR\rincludeHidden\x12\x1a\n" +
+	"\bassignee\x18\x04 \x01(\tR\bassignee\x12\f\n" +
+	"\x01q\x18\x05 \x01(\tR\x01q\x12\x0e\n" +
+	"\x02tz\x18\x06 \x01(\tR\x02tzB\r\n" +
+	"\v_project_id\"\xa0\a\n" +
+	"\x11UpdateTodoRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x19\n" +
+	"\x05title\x18\x03 \x01(\tH\x00R\x05title\x88\x01\x01\x12%\n" +
+	"\vdescription\x18\x04 \x01(\tH\x01R\vdescription\x88\x01\x01\x125\n" +
+	"\bdue_date\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\adueDate\x12$\n" +
+	"\x0eclear_due_date\x18\x06 \x01(\bR\fclearDueDate\x129\n" +
+	"\n" +
+	"start_date\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tstartDate\x12(\n" +
+	"\x10clear_start_date\x18\b \x01(\bR\x0eclearStartDate\x12!\n" +
+	"\tcompleted\x18\t \x01(\bH\x02R\tcompleted\x88\x01\x01\x12\x1b\n" +
+	"\x06status\x18\n" +
+	" \x01(\tH\x03R\x06status\x88\x01\x01\x12\"\n" +
+	"\n" +
+	"project_id\x18\v \x01(\tH\x04R\tprojectId\x88\x01\x01\x12#\n" +
+	"\rclear_project\x18\f \x01(\bR\fclearProject\x12*\n" +
+	"\x06labels\x18\r \x01(\v2\x12.todoapp.v1.LabelsR\x06labels\x12\x1f\n" +
+	"\bpriority\x18\x0e \x01(\x05H\x05R\bpriority\x88\x01\x01\x12<\n" +
+	"\rcustom_fields\x18\x0f \x01(\v2\x17.google.protobuf.StructR\fcustomFields\x12#\n" +
+	"\n" +
+	"recurrence\x18\x10 \x01(\tH\x06R\n" +
+	"recurrence\x88\x01\x01\x12$\n" +
+	"\vassignee_id\x18\x11 \x01(\tH\aR\n" +
+	"assigneeId\x88\x01\x01\x12%\n" +
+	"\x0eclear_assignee\x18\x12 \x01(\bR\rclearAssignee\x12.\n" +
+	"\x10estimate_minutes\x18\x13 \x01(\x05H\bR\x0festimateMinutes\x88\x01\x01\x12%\n" +
+	"\x0eclear_estimate\x18\x14 \x01(\bR\rclearEstimateB\b\n" +
+	"\x06_titleB\x0e\n" +
+	"\f_descriptionB\f\n" +
+	"\n" +
+	"_completedB\t\n" +
+	"\a_statusB\r\n" +
+	"\v_project_idB\v\n" +
+	"\t_priorityB\r\n" +
+	"\v_recurrenceB\x0e\n" +
+	"\f_assignee_idB\x13\n" +
+	"\x11_estimate_minutes\" \n" +
+	"\x06Labels\x12\x16\n" +
+	"\x06values\x18\x01 \x03(\tR\x06values\"T\n" +
+	"\x13CompleteTodoRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\x14\n" +
+	"\x05force\x18\x03 \x01(\bR\x05force\"<\n" +
+	"\x11DeleteTodoRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\"\x14\n" +
+	"\x12DeleteTodoResponse2\x93\x03\n" +
+	"\vTodoService\x12=\n" +
+	"\n" +
+	"CreateTodo\x12\x1d.todoapp.v1.CreateTodoRequest\x1a\x10.todoapp.v1.Todo\x127\n" +
+	"\aGetTodo\x12\x1a.todoapp.v1.GetTodoRequest\x1a\x10.todoapp.v1.Todo\x12=\n" +
+	"\tListTodos\x12\x1c.todoapp.v1.ListTodosRequest\x1a\x10.todoapp.v1.Todo0\x01\x12=\n" +
+	"\n" +
+	"UpdateTodo\x12\x1d.todoapp.v1.UpdateTodoRequest\x1a\x10.todoapp.v1.Todo\x12A\n" +
+	"\fCompleteTodo\x12\x1f.todoapp.v1.CompleteTodoRequest\x1a\x10.todoapp.v1.Todo\x12K\n" +
+	"\n" +
+	"DeleteTodo\x12\x1d.todoapp.v1.DeleteTodoRequest\x1a\x1e.todoapp.v1.DeleteTodoResponseB/Z-overengineeredtodo/proto/todoapp/v1;todoappv1b\x06proto3"
+
+var (
+	file_todos_proto_rawDescOnce sync.Once
+	file_todos_proto_rawDescData []byte
+)
+
+func file_todos_proto_rawDescGZIP() []byte {
+	file_todos_proto_rawDescOnce.Do(func() {
+		file_todos_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_todos_proto_rawDesc), len(file_todos_proto_rawDesc)))
+	})
+	return file_todos_proto_rawDescData
+}
+
+var file_todos_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_todos_proto_goTypes = []any{
+	(*Todo)(nil),                  // 0: todoapp.v1.Todo
+	(*CreateTodoRequest)(nil),     // 1: todoapp.v1.CreateTodoRequest
+	(*GetTodoRequest)(nil),        // 2: todoapp.v1.GetTodoRequest
+	(*ListTodosRequest)(nil),      // 3: todoapp.v1.ListTodosRequest
+	(*UpdateTodoRequest)(nil),     // 4: todoapp.v1.UpdateTodoRequest
+	(*Labels)(nil),                // 5: todoapp.v1.Labels
+	(*CompleteTodoRequest)(nil),   // 6: todoapp.v1.CompleteTodoRequest
+	(*DeleteTodoRequest)(nil),     // 7: todoapp.v1.DeleteTodoRequest
+	(*DeleteTodoResponse)(nil),    // 8: todoapp.v1.DeleteTodoResponse
+	(*timestamppb.Timestamp)(nil), // 9: google.protobuf.Timestamp
+	(*structpb.Struct)(nil),       // 10: google.protobuf.Struct
+}
+var file_todos_proto_depIdxs = []int32{
+	9,  // 0: todoapp.v1.Todo.due_date:type_name -> google.protobuf.Timestamp
+	9,  // 1: todoapp.v1.Todo.start_date:type_name -> google.protobuf.Timestamp
+	9,  // 2: todoapp.v1.Todo.snoozed_until:type_name -> google.protobuf.Timestamp
+	9,  // 3: todoapp.v1.Todo.created_at:type_name -> google.protobuf.Timestamp
+	9,  // 4: todoapp.v1.Todo.updated_at:type_name -> google.protobuf.Timestamp
+	9,  // 5: todoapp.v1.Todo.completed_at:type_name -> google.protobuf.Timestamp
+	10, // 6: todoapp.v1.Todo.custom_fields:type_name -> google.protobuf.Struct
+	9,  // 7: todoapp.v1.CreateTodoRequest.due_date:type_name -> google.protobuf.Timestamp
+	9,  // 8: todoapp.v1.CreateTodoRequest.start_date:type_name -> google.protobuf.Timestamp
+	10, // 9: todoapp.v1.CreateTodoRequest.custom_fields:type_name -> google.protobuf.Struct
+	9,  // 10: todoapp.v1.UpdateTodoRequest.due_date:type_name -> google.protobuf.Timestamp
+	9,  // 11: todoapp.v1.UpdateTodoRequest.start_date:type_name -> google.protobuf.Timestamp
+	5,  // 12: todoapp.v1.UpdateTodoRequest.labels:type_name -> todoapp.v1.Labels
+	10, // 13: todoapp.v1.UpdateTodoRequest.custom_fields:type_name -> google.protobuf.Struct
+	1,  // 14: todoapp.v1.TodoService.CreateTodo:input_type -> todoapp.v1.CreateTodoRequest
+	2,  // 15: todoapp.v1.TodoService.GetTodo:input_type -> todoapp.v1.GetTodoRequest
+	3,  // 16: todoapp.v1.TodoService.ListTodos:input_type -> todoapp.v1.ListTodosRequest
+	4,  // 17: todoapp.v1.TodoService.UpdateTodo:input_type -> todoapp.v1.UpdateTodoRequest
+	6,  // 18: todoapp.v1.TodoService.CompleteTodo:input_type -> todoapp.v1.CompleteTodoRequest
+	7,  // 19: todoapp.v1.TodoService.DeleteTodo:input_type -> todoapp.v1.DeleteTodoRequest
+	0,  // 20: todoapp.v1.TodoService.CreateTodo:output_type -> todoapp.v1.Todo
+	0,  // 21: todoapp.v1.TodoService.GetTodo:output_type -> todoapp.v1.Todo
+	0,  // 22: todoapp.v1.TodoService.ListTodos:output_type -> todoapp.v1.Todo
+	0,  // 23: todoapp.v1.TodoService.UpdateTodo:output_type -> todoapp.v1.Todo
+	0,  // 24: todoapp.v1.TodoService.CompleteTodo:output_type -> todoapp.v1.Todo
+	8,  // 25: todoapp.v1.TodoService.DeleteTodo:output_type -> todoapp.v1.DeleteTodoResponse
+	20, // [20:26] is the sub-list for method output_type
+	14, // [14:20] is the sub-list for method input_type
+	14, // [14:14] is the sub-list for extension type_name
+	14, // [14:14] is the sub-list for extension extendee
+	0,  // [0:14] is the sub-list for field type_name
+}
+
+func init() { file_todos_proto_init() }
+func file_todos_proto_init() {
+	if File_todos_proto != nil {
+		return
+	}
+	file_todos_proto_msgTypes[0].OneofWrappers = []any{}
+	file_todos_proto_msgTypes[1].OneofWrappers = []any{}
+	file_todos_proto_msgTypes[3].OneofWrappers = []any{}
+	file_todos_proto_msgTypes[4].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_todos_proto_rawDesc), len(file_todos_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_todos_proto_goTypes,
+		DependencyIndexes: file_todos_proto_depIdxs,
+		MessageInfos:      file_todos_proto_msgTypes,
+	}.Build()
+	File_todos_proto = out.File
+	file_todos_proto_goTypes = nil
+	file_todos_proto_depIdxs = nil
+}